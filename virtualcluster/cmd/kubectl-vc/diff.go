@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/storageclass"
+)
+
+const (
+	diffExample = `
+	# Compare the storageclasses of a virtualcluster with super master
+	kubectl vc diff-storageclass -n foo bar
+
+	# Honor the storageclass allow list and deny list of the syncer
+	kubectl vc diff-storageclass --allow-list 'gold*' --deny-list 'local-*' foo/bar`
+)
+
+type DiffOption struct {
+	client     client.Client
+	vcclient   vcclient.Interface
+	kubeclient kubernetes.Interface
+	namespace  string
+	name       string
+	allowList  []string
+	denyList   []string
+}
+
+func NewCmdDiff(f Factory) *cobra.Command {
+	o := &DiffOption{}
+
+	cmd := &cobra.Command{
+		Use:     "diff-storageclass VC_NAME",
+		Short:   "Compare the storageclasses of virtualcluster with super master without changing either",
+		Example: diffExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckErr(o.Complete(f, cmd, args))
+			CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.namespace, "namespace", "n", metav1.NamespaceDefault, "If present, the namespace scope for this CLI request")
+	cmd.Flags().StringSliceVar(&o.allowList, "allow-list", nil, "The storageclass allow list of the syncer, glob patterns of the storageclass names")
+	cmd.Flags().StringSliceVar(&o.denyList, "deny-list", nil, "The storageclass deny list of the syncer, glob patterns of the storageclass names")
+
+	return cmd
+}
+
+func (o *DiffOption) Complete(f Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.vcclient, err = f.VirtualClusterClientSet()
+	if err != nil {
+		return err
+	}
+
+	o.client, err = f.GenericClient()
+	if err != nil {
+		return err
+	}
+
+	o.kubeclient, err = f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return UsageErrorf(cmd, "VC_NAME should not be empty")
+	}
+
+	o.name = args[0]
+	if strings.Contains(o.name, "/") {
+		namespacedName := strings.SplitN(o.name, "/", 2)
+		o.namespace = namespacedName[0]
+		o.name = namespacedName[1]
+	}
+
+	return nil
+}
+
+func (o *DiffOption) Run() error {
+	vc, err := o.vcclient.TenancyV1alpha1().VirtualClusters(o.namespace).Get(o.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	cv, err := o.vcclient.TenancyV1alpha1().ClusterVersions().Get(vc.Spec.ClusterVersionName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "cluster version not found")
+	}
+
+	kbBytes, err := genKubeConfig(o.client, vc, cv)
+	if err != nil {
+		return err
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kbBytes)
+	if err != nil {
+		return err
+	}
+
+	tenantClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	syncerConfig := &config.SyncerConfiguration{
+		SyncStorageClassAllowList: o.allowList,
+		SyncStorageClassDenyList:  o.denyList,
+	}
+	report, err := storageclass.DiffStorageClasses(context.TODO(), syncerConfig, vc, o.kubeclient, tenantClient)
+	if err != nil {
+		return err
+	}
+
+	report.Print(os.Stdout)
+	return nil
+}
@@ -42,6 +42,7 @@ func main() {
 
 	rootCmd.AddCommand(NewCmdCreate(f))
 	rootCmd.AddCommand(NewCmdExec(f))
+	rootCmd.AddCommand(NewCmdDiff(f))
 
 	CheckErr(rootCmd.Execute())
 }
@@ -26,6 +26,7 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
 
 // Config has all the context to run a Syncer.
@@ -44,6 +45,9 @@ type Config struct {
 	SuperClusterClient          clientset.Interface
 	SuperClusterInformerFactory informers.SharedInformerFactory
 
+	// the extra super clusters keyed by super cluster ID
+	ExtraSuperClusters map[string]plugin.SuperCluster
+
 	// the client only used for leader election
 	LeaderElectionClient clientset.Interface
 
@@ -62,6 +66,10 @@ type Config struct {
 	Port     string
 	CertFile string
 	KeyFile  string
+	// AdminPort is the port of the admin server, the admin endpoints are not served if it is empty.
+	AdminPort string
+	// AdminClientCAFile is the CA authenticating the clients of the admin server.
+	AdminClientCAFile string
 }
 
 type completedConfig struct {
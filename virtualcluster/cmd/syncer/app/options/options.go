@@ -20,11 +20,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
@@ -46,8 +48,10 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions"
 	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	syncerconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
 
 // ResourceSyncerOptions is the main context object for the resource syncer.
@@ -67,6 +71,18 @@ type ResourceSyncerOptions struct {
 	Port                string
 	CertFile            string
 	KeyFile             string
+	AdminPort           string
+	AdminClientCAFile   string
+	// PatrolPeriods is the raw per resource patrol periods, parsed into ComponentConfig.PatrolPeriods.
+	PatrolPeriods map[string]string
+	// StorageClassOwner is the raw storageclass owner, parsed into ComponentConfig.StorageClassOwner.
+	StorageClassOwner string
+	// GenericSyncingResources is the raw generic syncing resources, parsed into ComponentConfig.GenericSyncingResources.
+	GenericSyncingResources []string
+	// StorageClassTopologyPolicy is the raw storageclass topology policy, parsed into ComponentConfig.StorageClassTopologyPolicy.
+	StorageClassTopologyPolicy string
+	// ExtraSuperMasterKubeconfigs are the kubeconfig files of the extra super masters keyed by super cluster ID.
+	ExtraSuperMasterKubeconfigs map[string]string
 }
 
 // NewResourceSyncerOptions creates a new resource syncer with a default config.
@@ -87,8 +103,24 @@ func NewResourceSyncerOptions() (*ResourceSyncerOptions, error) {
 			DisableServiceAccountToken: true,
 			DefaultOpaqueMetaDomains:   []string{"kubernetes.io", "k8s.io"},
 			ExtraSyncingResources:      []string{},
-			VNAgentPort:                int32(10550),
-			VNAgentNamespacedName:      "vc-manager/vn-agent",
+			SyncStorageClassAllowList:  []string{},
+			SyncStorageClassDenyList:   []string{},
+			SyncRuntimeClassAllowList:  []string{},
+			StorageClassIgnoredAnnotationPrefixes: []string{
+				"kubectl.kubernetes.io/last-applied-configuration",
+				"meta.helm.sh/",
+				"argocd.argoproj.io/",
+			},
+			SuperCacheMaxStaleness:  15 * time.Minute,
+			PVReclaimGracePeriod:    syncerconstants.DefaultPVReclaimGracePeriod,
+			PatrolWriteBurst:        syncerconstants.DefaultPatrolWriteBurst,
+			PatrolStartJitter:       syncerconstants.DefaultPatrolStartJitter,
+			PatrolConcurrency:       syncerconstants.DefaultPatrolConcurrency,
+			PatrolOpTimeout:         syncerconstants.DefaultPatrolOpTimeout,
+			MaxDeletePercentPerPass: syncerconstants.DefaultMaxDeletePercentPerPass,
+			TenantSyncerUsers:       []string{syncerconstants.DefaultTenantSyncerUser},
+			VNAgentPort:             int32(10550),
+			VNAgentNamespacedName:   "vc-manager/vn-agent",
 			FeatureGates: map[string]bool{
 				featuregate.SuperClusterPooling:        false,
 				featuregate.SuperClusterServiceNetwork: false,
@@ -112,11 +144,50 @@ func (o *ResourceSyncerOptions) Flags() cliflag.NamedFlagSets {
 	fs.StringVar(&o.MetaClusterAddress, "meta-cluster-address", o.MetaClusterAddress, "The address of the meta cluster Kubernetes API server (overrides any value in meta-cluster-kubeconfig).")
 	fs.StringVar(&o.MetaClusterClientConnection.Kubeconfig, "meta-cluster-kubeconfig", o.MetaClusterClientConnection.Kubeconfig, "Path to kubeconfig file of the meta cluster. If it is not provided, the super cluster is used")
 	fs.BoolVar(&o.DeployOnMetaCluster, "deployment-on-meta", o.DeployOnMetaCluster, "Whether vc-syncer deploy on meta cluster")
+	fs.Var(cliflag.NewMapStringString(&o.ExtraSuperMasterKubeconfigs), "extra-super-master-kubeconfigs", "A set of id=kubeconfig pairs of the super masters, other than super-master, the tenants are mapped to by the "+syncerconstants.LabelTenantSuperCluster+" annotation, e.g., east=/etc/east.kubeconfig. "+
+		"Only the public storageclasses are taken from the extra super masters. The tenants without the annotation use super-master.")
 	fs.StringVar(&o.SyncerName, "syncer-name", o.SyncerName, "Syncer name (default vc).")
 	fs.BoolVar(&o.ComponentConfig.DisableServiceAccountToken, "disable-service-account-token", o.ComponentConfig.DisableServiceAccountToken, "DisableServiceAccountToken indicates whether disable service account token automatically mounted.")
 	fs.BoolVar(&o.ComponentConfig.DisablePodServiceLinks, "disable-service-links", o.ComponentConfig.DisablePodServiceLinks, "DisablePodServiceLinks indicates whether to disable the `EnableServiceLinks` field in pPod spec.")
 	fs.StringSliceVar(&o.ComponentConfig.DefaultOpaqueMetaDomains, "default-opaque-meta-domains", o.ComponentConfig.DefaultOpaqueMetaDomains, "DefaultOpaqueMetaDomains is the default opaque meta configuration for each Virtual Cluster.")
-	fs.StringSliceVar(&o.ComponentConfig.ExtraSyncingResources, "extra-syncing-resources", o.ComponentConfig.ExtraSyncingResources, "ExtraSyncingResources defines additional resources that need to be synced for each Virtual Cluster. (priorityclass, ingress, crd)")
+	fs.StringSliceVar(&o.ComponentConfig.ExtraSyncingResources, "extra-syncing-resources", o.ComponentConfig.ExtraSyncingResources, "ExtraSyncingResources defines additional resources that need to be synced for each Virtual Cluster. (priorityclass, ingress, crd, networkpolicy, poddisruptionbudget, horizontalpodautoscaler, resourcequota, limitrange, csidriver, volumesnapshotclass, endpointslice, ingressclass, runtimeclass)")
+	fs.StringSliceVar(&o.GenericSyncingResources, "generic-syncing-resources", o.GenericSyncingResources, "Namespaced custom resources synced to super master by the generic resource syncer, in the format of resource.version.group/Kind, e.g., certificates.v1.cert-manager.io/Certificate.")
+	fs.StringSliceVar(&o.ComponentConfig.SyncStorageClassAllowList, "sync-storageclass-allow-list", o.ComponentConfig.SyncStorageClassAllowList, "Name globs of the public super master storageclasses that are allowed to be synced to tenants. All public storageclasses are synced if it is empty.")
+	fs.StringSliceVar(&o.ComponentConfig.SyncStorageClassDenyList, "sync-storageclass-deny-list", o.ComponentConfig.SyncStorageClassDenyList, "Name globs of the public super master storageclasses that are never synced to tenants.")
+	fs.StringSliceVar(&o.ComponentConfig.SyncRuntimeClassAllowList, "sync-runtimeclass-allow-list", o.ComponentConfig.SyncRuntimeClassAllowList, "Name globs of the public super master runtimeclasses that are allowed to be synced to tenants. All public runtimeclasses are synced if it is empty.")
+	fs.StringSliceVar(&o.ComponentConfig.SyncSecretTypes, "sync-secret-types", o.ComponentConfig.SyncSecretTypes, "Types of the tenant secrets synced to super master, e.g., Opaque,kubernetes.io/dockerconfigjson. All types are synced if it is empty.")
+	fs.StringSliceVar(&o.ComponentConfig.StorageClassOwnedMetaPrefixes, "storageclass-owned-meta-prefixes", o.ComponentConfig.StorageClassOwnedMetaPrefixes, "Label/annotation key prefixes of the tenant storageclasses that are reconciled with super master. Other tenant added keys are left alone.")
+	fs.StringSliceVar(&o.ComponentConfig.StorageClassIgnoredAnnotationPrefixes, "storageclass-ignored-annotation-prefixes", o.ComponentConfig.StorageClassIgnoredAnnotationPrefixes, "Annotation key prefixes, or full keys, of the tenant storageclasses that are never compared with super master, e.g., the annotations stamped by kubectl apply or helm.")
+	fs.StringVar(&o.StorageClassOwner, "storageclass-owner", o.StorageClassOwner, "Cluster scoped tenant object stamped as the owner of the synced tenant storageclasses, in the format of apiVersion/kind/name, e.g., v1/Namespace/tenant-anchor. It is only stamped once the owner exists in the tenant master.")
+	fs.StringVar(&o.StorageClassTopologyPolicy, "storageclass-topology-policy", o.StorageClassTopologyPolicy, "How the allowedTopologies of the synced storageclasses, which refer to super cluster node labels, are exposed to tenants, one of Keep, Rewrite (by meta-key-translations) or Drop.")
+	fs.BoolVar(&o.ComponentConfig.StorageClassBulkOrphanDeletion, "storageclass-bulk-orphan-deletion", o.ComponentConfig.StorageClassBulkOrphanDeletion, "Delete the orphan tenant storageclasses of a cluster with a single DeleteCollection request. It falls back to deleting them one by one if the request could match any other storageclass.")
+	fs.BoolVar(&o.ComponentConfig.DeferStorageClassDeletionToPatrol, "defer-storageclass-deletion-to-patrol", o.ComponentConfig.DeferStorageClassDeletionToPatrol, "Leave the tenant copies of a deleted super master storageclass to the patroller instead of deleting them as soon as the deletion is observed.")
+	fs.BoolVar(&o.ComponentConfig.RecreateStorageClassOnImmutableChange, "recreate-storageclass-on-immutable-change", o.ComponentConfig.RecreateStorageClassOnImmutableChange, "Delete the tenant storageclasses whose provisioner, parameters or reclaimPolicy differ from super master, which cannot be updated in place, and recreate them from super master. It deletes tenant objects, the tenant edits to them are lost.")
+	fs.Int32Var(&o.ComponentConfig.MaxTenantPriority, "max-tenant-priority", o.ComponentConfig.MaxTenantPriority, "Upper bound of the priority of tenant pods in super master. Public priorityclasses above it are not synced to tenants. Priority is not capped if it is 0.")
+	fs.Var(cliflag.NewMapStringString(&o.PatrolPeriods), "patrol-periods", "A set of resource=duration pairs that override the default periods of the resource checkers, e.g., storageclass=10m,pod=30s.")
+	fs.StringSliceVar(&o.ComponentConfig.StatusUpsyncResources, "status-upsync-resources", o.ComponentConfig.StatusUpsyncResources, "Resources whose checkers copy the status of super master objects to tenant masters, e.g., persistentvolumeclaim.")
+	fs.BoolVar(&o.ComponentConfig.SyncTenantWebhooks, "sync-tenant-webhooks", o.ComponentConfig.SyncTenantWebhooks, "Populate the admission webhooks of tenants to super master, scoped to the tenant namespaces. Tenant webhooks are ignored with a warning event if it is false.")
+	fs.Float64Var(&o.ComponentConfig.PatrolStartJitter, "patrol-start-jitter", o.ComponentConfig.PatrolStartJitter, "The fraction of its period, between 0 and 1, the first round of each checker is delayed by at most, so that the checkers do not patrol at the same time. 0 disables the delay.")
+	fs.BoolVar(&o.ComponentConfig.ResyncOnStart, "resync-on-start", o.ComponentConfig.ResyncOnStart, "Run a full patrol round of each checker as soon as the super master caches are synced, before the periodic patrols start.")
+	fs.DurationVar(&o.ComponentConfig.SuperCacheMaxStaleness, "super-cache-max-staleness", o.ComponentConfig.SuperCacheMaxStaleness, "How long the super master informer cache may go without a new resource version before the checkers stop deleting orphans. 0 disables the guard.")
+	fs.DurationVar(&o.ComponentConfig.PVReclaimGracePeriod, "pv-reclaim-grace-period", o.ComponentConfig.PVReclaimGracePeriod, "How long a super master PV with the Delete reclaim policy may stay released after its tenant PVC is deleted before it is reported as leaked. Leaked PVs are never deleted by the syncer.")
+	fs.BoolVar(&o.ComponentConfig.ValidateSuperNamespaces, "validate-super-namespaces", o.ComponentConfig.ValidateSuperNamespaces, "Make the checkers skip requeuing the tenant objects missing in super master until the namespace syncer has created their super master namespace. Missing super master namespaces are reported, never created by the checkers.")
+	fs.Float32Var(&o.ComponentConfig.PatrolWriteQPS, "patrol-write-qps", o.ComponentConfig.PatrolWriteQPS, "QPS of the writes the patrollers issue to each tenant master, e.g., orphan deletions. 0 leaves them unthrottled. It can be overridden per VirtualCluster by the "+syncerconstants.LabelTenantPatrolWriteQPS+" annotation.")
+	fs.IntVar(&o.ComponentConfig.PatrolWriteBurst, "patrol-write-burst", o.ComponentConfig.PatrolWriteBurst, "Burst of the writes the patrollers issue to each tenant master once --patrol-write-qps is set. It can be overridden per VirtualCluster by the "+syncerconstants.LabelTenantPatrolWriteBurst+" annotation.")
+	fs.BoolVar(&o.ComponentConfig.PatrollerDryRun, "patroller-dry-run", o.ComponentConfig.PatrollerDryRun, "Only report the inconsistencies found by the checkers without deleting or requeuing any object.")
+	fs.IntVar(&o.ComponentConfig.PatrolConcurrency, "patrol-concurrency", o.ComponentConfig.PatrolConcurrency, "The number of tenant clusters each checker checks in parallel.")
+	fs.DurationVar(&o.ComponentConfig.PatrolOpTimeout, "patrol-op-timeout", o.ComponentConfig.PatrolOpTimeout, "The timeout of each tenant operation issued by the checkers.")
+	fs.DurationVar(&o.ComponentConfig.OrphanTTL, "orphan-ttl", o.ComponentConfig.OrphanTTL, "How long an orphan tenant object has to be observed before the checkers delete it. 0 deletes orphans in the first round that observes them.")
+	fs.BoolVar(&o.ComponentConfig.PatrolOnRelist, "patrol-on-relist", o.ComponentConfig.PatrolOnRelist, "Run a checker round as soon as the super master informer of the checker relists.")
+	fs.IntVar(&o.ComponentConfig.MaxDeletePercentPerPass, "max-delete-percent-per-pass", o.ComponentConfig.MaxDeletePercentPerPass, "The max percentage of the syncer managed objects of a tenant the checkers delete in a single round, a round beyond it is aborted. 100 or more disables the limit.")
+	fs.BoolVar(&o.ComponentConfig.CheckerAuditLog, "checker-audit-log", o.ComponentConfig.CheckerAuditLog, "Log every remediation taken by the checkers, e.g., requeues, deletions and finalizer removals, as an audit record.")
+	fs.BoolVar(&o.ComponentConfig.MetricsPerClusterLabels, "metrics-per-cluster-labels", o.ComponentConfig.MetricsPerClusterLabels, "Break down the checker metrics by tenant cluster. It may result in a large number of series with many tenants.")
+	fs.BoolVar(&o.ComponentConfig.ValidateTenantPublicNames, "validate-tenant-public-names", o.ComponentConfig.ValidateTenantPublicNames, "Serve an admission webhook at /validate-public-names rejecting tenant cluster scoped objects named after public super master objects.")
+	fs.StringSliceVar(&o.ComponentConfig.TenantSyncerUsers, "tenant-syncer-users", o.ComponentConfig.TenantSyncerUsers, "The users syncer is authenticated as in tenant masters. Their requests are admitted by the /validate-public-names webhook.")
+	fs.StringVar(&o.ComponentConfig.ClusterSelector, "cluster-selector", o.ComponentConfig.ClusterSelector, "Label selector of the VirtualClusters managed by this syncer, e.g., rollout=canary. All VirtualClusters are managed if it is empty.")
+	fs.StringSliceVar(&o.ComponentConfig.ClusterNamePrefixes, "cluster-name-prefixes", o.ComponentConfig.ClusterNamePrefixes, "Prefixes of the namespace/name of the VirtualClusters managed by this syncer, e.g., tenant-1/. All VirtualClusters are managed if it is empty.")
+	fs.Var(cliflag.NewMapStringString(&o.ComponentConfig.MetaKeyTranslations), "meta-key-translations", "A set of super=tenant pairs that rewrite the label/annotation keys of super master objects seen by tenants, e.g., topology.kubernetes.io/= strips the keys with that prefix.")
+	fs.Var(cliflag.NewMapStringBool(&o.ComponentConfig.SyncerFeatureGates), "syncer-feature-gates", "A set of resource=bool pairs that enable or disable the resource syncers, e.g., StorageClass=true,NetworkPolicy=false. It overrides extra-syncing-resources.")
 	fs.Var(cliflag.NewMapStringBool(&o.ComponentConfig.FeatureGates), "feature-gates", "A set of key=value pairs that describe featuregate gates for various features.")
 	fs.Int32Var(&o.ComponentConfig.VNAgentPort, "vn-agent-port", 10550, "Port the vn-agent listens on")
 	fs.StringVar(&o.ComponentConfig.VNAgentNamespacedName, "vn-agent-namespace-name", "vc-manager/vn-agent", "Namespace/Name of the vn-agent running in cluster, used for VNodeProviderService")
@@ -126,6 +197,8 @@ func (o *ResourceSyncerOptions) Flags() cliflag.NamedFlagSets {
 	serverFlags.StringVar(&o.Port, "port", o.Port, "The server port.")
 	serverFlags.StringVar(&o.CertFile, "cert-file", o.CertFile, "CertFile is the file containing x509 Certificate for HTTPS.")
 	serverFlags.StringVar(&o.KeyFile, "key-file", o.KeyFile, "KeyFile is the file containing x509 private key matching certFile.")
+	serverFlags.StringVar(&o.AdminPort, "admin-port", o.AdminPort, "The port of the admin server, which serves the endpoints changing the syncer state, i.e., /patrol, /patrol/pause and /patrol/resume. It is disabled if empty. It requires cert-file, key-file and admin-client-ca-file.")
+	serverFlags.StringVar(&o.AdminClientCAFile, "admin-client-ca-file", o.AdminClientCAFile, "The CA bundle authenticating the clients of the admin server. Only the clients presenting a certificate signed by it are served.")
 
 	BindFlags(&o.ComponentConfig.LeaderElection, fss.FlagSet("leader election"))
 
@@ -163,11 +236,42 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 	c := &syncerappconfig.Config{}
 	c.ComponentConfig = o.ComponentConfig
 
+	patrolPeriods, err := parsePatrolPeriods(o.PatrolPeriods)
+	if err != nil {
+		return nil, err
+	}
+	c.ComponentConfig.PatrolPeriods = patrolPeriods
+
+	if jitter := c.ComponentConfig.PatrolStartJitter; jitter < 0 || jitter > 1 {
+		return nil, fmt.Errorf("invalid patrol start jitter %v: must be between 0 and 1", jitter)
+	}
+
+	if o.AdminPort != "" && (o.CertFile == "" || o.KeyFile == "" || o.AdminClientCAFile == "") {
+		return nil, fmt.Errorf("admin server requires cert-file, key-file and admin-client-ca-file")
+	}
+
+	storageClassOwner, err := parseOwnerAnchor(o.StorageClassOwner)
+	if err != nil {
+		return nil, err
+	}
+	c.ComponentConfig.StorageClassOwner = storageClassOwner
+
+	topologyPolicy, err := parseTopologyPolicy(o.StorageClassTopologyPolicy)
+	if err != nil {
+		return nil, err
+	}
+	c.ComponentConfig.StorageClassTopologyPolicy = topologyPolicy
+
+	genericSyncingResources, err := parseGenericResources(o.GenericSyncingResources)
+	if err != nil {
+		return nil, err
+	}
+	c.ComponentConfig.GenericSyncingResources = genericSyncingResources
+
 	// Prepare kube clients
 	var (
 		metaRestConfig, superRestConfig *restclient.Config
 		leaderElectionRestConfig        restclient.Config
-		err                             error
 	)
 	superRestConfig, err = getClientConfig(c.ComponentConfig.ClientConnection, o.SuperClusterAddress, !o.DeployOnMetaCluster)
 	if err != nil {
@@ -223,6 +327,10 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 			return nil, err
 		}
 	}
+	c.ComponentConfig.Identity, err = replicaIdentity(leaderElectionConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	featuregate.DefaultFeatureGate, err = featuregate.NewFeatureGate(c.ComponentConfig.FeatureGates)
 	if err != nil {
@@ -239,6 +347,10 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 	c.MetaClusterClient = metaClusterClient
 	c.SuperClusterClient = superClusterClient
 	c.SuperClusterInformerFactory = informers.NewSharedInformerFactory(superClusterClient, 0)
+	c.ExtraSuperClusters, err = o.extraSuperClusters()
+	if err != nil {
+		return nil, err
+	}
 	c.Broadcaster = eventBroadcaster
 	c.Recorder = recorder
 	c.LeaderElectionClient = leaderElectionClient
@@ -248,6 +360,8 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 	c.Port = o.Port
 	c.CertFile = o.CertFile
 	c.KeyFile = o.KeyFile
+	c.AdminPort = o.AdminPort
+	c.AdminClientCAFile = o.AdminClientCAFile
 
 	return c, nil
 }
@@ -293,6 +407,19 @@ func makeLeaderElectionConfig(config syncerconfig.SyncerLeaderElectionConfigurat
 	}, nil
 }
 
+// replicaIdentity returns the identity of the syncer replica. Only the leader syncs and patrols the tenant
+// clusters, so its leader election identity is the one reported as their owner.
+func replicaIdentity(leaderElectionConfig *leaderelection.LeaderElectionConfig) (string, error) {
+	if leaderElectionConfig != nil {
+		return leaderElectionConfig.Lock.Identity(), nil
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("unable to get hostname: %v", err)
+	}
+	return hostname, nil
+}
+
 func getInClusterNamespace() (string, error) {
 	// Check whether the namespace file exists.
 	// If not, we are not running in cluster so can't guess the namespace.
@@ -350,3 +477,103 @@ func getClientConfig(config componentbaseconfig.ClientConnectionConfiguration, m
 
 	return restConfig, nil
 }
+
+// parsePatrolPeriods converts the resource=duration pairs to patrol periods.
+func parsePatrolPeriods(raw map[string]string) (map[string]time.Duration, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	periods := make(map[string]time.Duration, len(raw))
+	for resource, value := range raw {
+		period, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid patrol period %q of resource %s: %v", value, resource, err)
+		}
+		if period <= 0 {
+			return nil, fmt.Errorf("invalid patrol period %q of resource %s: must be positive", value, resource)
+		}
+		periods[resource] = period
+	}
+	return periods, nil
+}
+
+// parseOwnerAnchor parses the owner in the format of apiVersion/kind/name, the apiVersion may include the group.
+func parseOwnerAnchor(raw string) (*syncerconfig.OwnerAnchor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	parts := strings.Split(raw, "/")
+	if len(parts) < 3 || len(parts) > 4 {
+		return nil, fmt.Errorf("invalid owner %q: must be in the format of apiVersion/kind/name", raw)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid owner %q: must be in the format of apiVersion/kind/name", raw)
+		}
+	}
+	n := len(parts)
+	return &syncerconfig.OwnerAnchor{
+		APIVersion: strings.Join(parts[:n-2], "/"),
+		Kind:       parts[n-2],
+		Name:       parts[n-1],
+	}, nil
+}
+
+// parseTopologyPolicy validates the topology policy, Keep is used if it is empty.
+func parseTopologyPolicy(raw string) (syncerconfig.TopologyPolicy, error) {
+	switch policy := syncerconfig.TopologyPolicy(raw); policy {
+	case "":
+		return syncerconfig.TopologyKeep, nil
+	case syncerconfig.TopologyKeep, syncerconfig.TopologyRewrite, syncerconfig.TopologyDrop:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid topology policy %q: must be one of %s, %s or %s", raw, syncerconfig.TopologyKeep, syncerconfig.TopologyRewrite, syncerconfig.TopologyDrop)
+	}
+}
+
+// parseGenericResources parses the resources in the format of resource.version.group/Kind.
+func parseGenericResources(raw []string) ([]syncerconfig.GenericResource, error) {
+	var resources []syncerconfig.GenericResource
+	for _, r := range raw {
+		parts := strings.Split(r, "/")
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid generic syncing resource %q: must be in the format of resource.version.group/Kind", r)
+		}
+		gvr, _ := schema.ParseResourceArg(parts[0])
+		if gvr == nil {
+			return nil, fmt.Errorf("invalid generic syncing resource %q: must be in the format of resource.version.group/Kind", r)
+		}
+		resources = append(resources, syncerconfig.GenericResource{
+			Group:    gvr.Group,
+			Version:  gvr.Version,
+			Resource: gvr.Resource,
+			Kind:     parts[1],
+		})
+	}
+	return resources, nil
+}
+
+// extraSuperClusters creates the clients of the extra super masters.
+func (o *ResourceSyncerOptions) extraSuperClusters() (map[string]plugin.SuperCluster, error) {
+	if len(o.ExtraSuperMasterKubeconfigs) == 0 {
+		return nil, nil
+	}
+	supers := make(map[string]plugin.SuperCluster, len(o.ExtraSuperMasterKubeconfigs))
+	for id, kubeconfig := range o.ExtraSuperMasterKubeconfigs {
+		if id == "" || kubeconfig == "" {
+			return nil, fmt.Errorf("invalid extra super master %q=%q: both id and kubeconfig are required", id, kubeconfig)
+		}
+		connection := o.ComponentConfig.ClientConnection
+		connection.Kubeconfig = kubeconfig
+		restConfig, err := getClientConfig(connection, "", false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig of extra super master %s: %v", id, err)
+		}
+		client, err := clientset.NewForConfig(restclient.AddUserAgent(restConfig, constants.ResourceSyncerUserAgent))
+		if err != nil {
+			return nil, err
+		}
+		supers[id] = plugin.SuperCluster{Client: client, Informer: informers.NewSharedInformerFactory(client, 0)}
+	}
+	return supers, nil
+}
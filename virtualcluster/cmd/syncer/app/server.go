@@ -100,6 +100,7 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 		cc.MetaClusterClient,
 		cc.SuperClusterClient,
 		cc.SuperClusterInformerFactory,
+		cc.ExtraSuperClusters,
 		cc.Recorder)
 
 	if err != nil {
@@ -114,9 +115,15 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 	// Start all informers.
 	go cc.VirtualClusterInformer.Informer().Run(stopCh)
 	cc.SuperClusterInformerFactory.Start(stopCh)
+	for _, super := range cc.ExtraSuperClusters {
+		super.Informer.Start(stopCh)
+	}
 
 	// Wait for all caches to sync before resource sync.
 	cc.SuperClusterInformerFactory.WaitForCacheSync(stopCh)
+	for _, super := range cc.ExtraSuperClusters {
+		super.Informer.WaitForCacheSync(stopCh)
+	}
 
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
@@ -133,9 +140,18 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 	}()
 
 	if cc.LeaderElection != nil {
+		// The syncer, including the patrollers remediating the tenant objects, runs only in the leader and the
+		// process exits once the leadership is lost, so the replicas never remediate the same objects concurrently.
 		cc.LeaderElection.Callbacks = leaderelection.LeaderCallbacks{
 			OnStartedLeading: run,
 			OnStoppedLeading: func() {
+				select {
+				case <-stopCh:
+					// the leader is shutting down, let it finish the in-flight remediations before exiting.
+					ss.WaitForShutdown()
+					return
+				default:
+				}
 				klog.Fatalf("leaderelection lost")
 			},
 		}
@@ -151,6 +167,7 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 
 	// Leader election is disabled, so runCommand inline until done.
 	run(ctx)
+	ss.WaitForShutdown()
 	return fmt.Errorf("finished without leader elect")
 }
 
@@ -160,6 +177,11 @@ func startSyncer(ctx context.Context, s syncer.Bootstrap, cc *syncerconfig.Compl
 		go func() {
 			s.ListenAndServe(net.JoinHostPort(cc.Address, cc.Port), cc.CertFile, cc.KeyFile)
 		}()
+		if cc.AdminPort != "" {
+			go func() {
+				s.ListenAndServeAdmin(net.JoinHostPort(cc.Address, cc.AdminPort), cc.CertFile, cc.KeyFile, cc.AdminClientCAFile)
+			}()
+		}
 		go func() {
 			// start a pprof http server
 			klog.Fatal(http.ListenAndServe(":6060", nil))
@@ -167,7 +189,7 @@ func startSyncer(ctx context.Context, s syncer.Bootstrap, cc *syncerconfig.Compl
 		go func() {
 			// start a health http server.
 			mux := http.NewServeMux()
-			healthz.InstallHandler(mux)
+			healthz.InstallHandler(mux, healthz.PingHealthz, healthz.NamedCheck("patrol", s.PatrolHealthz))
 			klog.Fatal(http.ListenAndServe(":8080", mux))
 		}()
 		<-ctx.Done()
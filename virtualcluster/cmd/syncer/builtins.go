@@ -29,4 +29,5 @@ import (
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/service"
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/serviceaccount"
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/storageclass"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/webhookconfiguration"
 )
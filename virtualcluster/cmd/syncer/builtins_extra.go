@@ -18,6 +18,16 @@ package main
 
 import (
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/crd"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/csidriver"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/endpointslice"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/hpa"
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/ingress"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/ingressclass"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/limitrange"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/networkpolicy"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/poddisruptionbudget"
 	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/priorityclass"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/resourcequota"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/runtimeclass"
+	_ "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/volumesnapshotclass"
 )
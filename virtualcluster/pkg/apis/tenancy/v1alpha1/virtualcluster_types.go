@@ -95,11 +95,28 @@ const (
 	ClusterError ClusterPhase = "Error"
 )
 
+type ClusterConditionType string
+
+const (
+	// StorageClassSynced indicates whether the tenant storageclasses are consistent with super master
+	// as of the last syncer patrol.
+	StorageClassSynced ClusterConditionType = "StorageClassSynced"
+)
+
 type ClusterCondition struct {
+	// Type of the condition, e.g., StorageClassSynced.
+	// It is empty for the conditions recording cluster phase transitions.
+	// +optional
+	Type ClusterConditionType `json:"type,omitempty"`
+
 	// Cluster Condition Status
 	// Can be True, False, Unknown.
 	Status corev1.ConditionStatus `json:"status"`
 
+	// Last time the condition was probed.
+	// +optional
+	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty"`
+
 	// Last time the condition transitioned from one status to another.
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
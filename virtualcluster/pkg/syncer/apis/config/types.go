@@ -17,6 +17,8 @@ limitations under the License.
 package config
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	componentbaseconfig "k8s.io/component-base/config"
@@ -64,11 +66,223 @@ type SyncerConfiguration struct {
 	// service, this is used for feature VNodeProviderService.
 	VNAgentNamespacedName string
 
+	// SyncStorageClassAllowList is a list of name globs. If it is not empty, only the public
+	// super master storageclasses matching one of the globs are synced to tenant masters.
+	SyncStorageClassAllowList []string
+
+	// SyncStorageClassDenyList is a list of name globs. The public super master storageclasses
+	// matching one of the globs are never synced to tenant masters and are removed if present.
+	SyncStorageClassDenyList []string
+
+	// SyncRuntimeClassAllowList is a list of name globs. If it is not empty, only the public super master
+	// runtimeclasses matching one of the globs are synced to tenant masters, the tenant copies of the others
+	// are removed. All public runtimeclasses are synced if it is empty.
+	SyncRuntimeClassAllowList []string
+
+	// StorageClassOwnedMetaPrefixes is a list of label/annotation key prefixes owned by super master.
+	// The matching keys of the tenant storageclasses are reconciled with the super master storageclasses,
+	// other keys added by tenants are preserved. No label/annotation is reconciled if it is empty.
+	StorageClassOwnedMetaPrefixes []string
+
+	// StorageClassIgnoredAnnotationPrefixes is a list of annotation key prefixes, or full keys, ignored entirely
+	// when the tenant storageclasses are compared with the super master storageclasses, e.g., the annotations
+	// stamped by the tools applying the storageclasses. The matching tenant annotations are never changed.
+	StorageClassIgnoredAnnotationPrefixes []string
+
+	// StorageClassOwner, if not nil, is the cluster scoped tenant object stamped as an owner reference onto the
+	// storageclasses synced to tenant masters, so that they are garbage collected along with it. The reference is
+	// only stamped once the owner exists in the tenant master, a reference to a missing owner would make the tenant
+	// garbage collector delete the storageclasses right after they are synced.
+	StorageClassOwner *OwnerAnchor
+
+	// StorageClassTopologyPolicy decides how the allowedTopologies of the public super master storageclasses, which
+	// refer to super cluster node labels, are synced to tenant masters. Keep syncs them as is, Rewrite translates
+	// their keys by MetaKeyTranslations and drops the expressions whose keys are stripped, Drop removes them from
+	// the tenant storageclasses. Keep is used if it is empty.
+	StorageClassTopologyPolicy TopologyPolicy
+
+	// StorageClassBulkOrphanDeletion makes the storageclass patroller delete the orphan tenant storageclasses of a
+	// cluster with a single DeleteCollection request instead of one request per storageclass.
+	StorageClassBulkOrphanDeletion bool
+
+	// DeferStorageClassDeletionToPatrol leaves the tenant copies of a deleted super master storageclass to the
+	// storageclass patroller, which deletes them as orphans subject to its grace period and delete limit. They are
+	// deleted from all tenant masters as soon as the deletion is observed otherwise.
+	DeferStorageClassDeletionToPatrol bool
+
+	// RecreateStorageClassOnImmutableChange makes the storageclass patroller delete and requeue, subject to its
+	// delete limit, the tenant storageclasses whose provisioner, parameters or reclaimPolicy differ from super
+	// master, since the tenant apiserver rejects the update to them. It deletes tenant objects, hence it is off by
+	// default, in which case they are requeued to be updated.
+	RecreateStorageClassOnImmutableChange bool
+
+	// SuperCacheMaxStaleness is how long the super master informer cache may go without observing a new resource
+	// version before the checkers stop trusting it to declare tenant objects orphaned. The orphans are only logged
+	// while the cache looks stale. The guard is disabled if it is 0.
+	SuperCacheMaxStaleness time.Duration
+
+	// PVReclaimGracePeriod is how long a super master PV with the Delete reclaim policy may stay released after its
+	// tenant PVC is deleted before the persistentvolume checker reports it as leaked. The default grace period is
+	// used if it is 0.
+	PVReclaimGracePeriod time.Duration
+
+	// ValidateSuperNamespaces makes the checkers of the namespaced resources check that the super master namespace
+	// of a tenant namespace exists before they requeue the tenant objects missing in it. A missing super master
+	// namespace is reported and left to the namespace syncer.
+	ValidateSuperNamespaces bool
+
+	// SyncSecretTypes is a list of secret types, e.g., Opaque. If it is not empty, only the tenant secrets
+	// of these types are synced to super master and checked by the secret checker.
+	SyncSecretTypes []string
+
+	// PatrolPeriods overrides the period of the periodic checker of each resource, keyed by the resource name,
+	// e.g., storageclass. The resources not specified use their default periods.
+	PatrolPeriods map[string]time.Duration
+
+	// PatrolStartJitter delays the first round of each patroller by a random fraction of its period no larger
+	// than it, so that the patrols of the resources started together are spread over time. It is between 0 and 1,
+	// the rounds are not delayed if it is 0.
+	PatrolStartJitter float64
+
+	// PatrolWriteQPS and PatrolWriteBurst bound the rate of the writes the patrollers issue to each tenant master,
+	// e.g., the deletions of orphans. A VirtualCluster may override them by its annotations. The writes are not
+	// throttled if PatrolWriteQPS is 0.
+	PatrolWriteQPS   float32
+	PatrolWriteBurst int
+
+	// PatrollerDryRun makes the checkers only report the inconsistencies they find without deleting or requeuing
+	// any object.
+	PatrollerDryRun bool
+
+	// PatrolConcurrency bounds the number of tenant clusters a checker checks in parallel. The default concurrency
+	// is used if it is 0.
+	PatrolConcurrency int
+
+	// PatrolOpTimeout is the timeout of each tenant operation issued by a checker. The default timeout is used if
+	// it is 0.
+	PatrolOpTimeout time.Duration
+
+	// OrphanTTL is how long an orphan tenant object has to be observed before a checker deletes it. Orphans are
+	// deleted in the first round that observes them if it is 0.
+	OrphanTTL time.Duration
+
+	// PatrolOnRelist makes the checkers run a round as soon as their super master informer relists, on top of
+	// the periodic rounds.
+	PatrolOnRelist bool
+
+	// MaxDeletePercentPerPass is the max percentage of the syncer managed objects of a tenant cluster a checker
+	// deletes in a single round, a round beyond it is aborted since it most likely results from a broken super
+	// master cache. The default percentage is used if it is 0, and the limit is disabled if it is 100 or more.
+	MaxDeletePercentPerPass int
+
+	// CheckerAuditLog makes the checkers log every remediation they take as an audit record, unless the resource
+	// syncer is given an audit sink of its own. The audit records are dropped if it is false.
+	CheckerAuditLog bool
+
+	// MetricsPerClusterLabels indicates whether the checker metrics are broken down by tenant cluster.
+	// The metrics are aggregated over all tenant clusters if it is false, which bounds their cardinality.
+	MetricsPerClusterLabels bool
+
+	// MaxTenantPriority caps the priority of the tenant pods in super master, so that they cannot preempt super
+	// master critical workloads. The public super master priorityclasses above it are not synced to tenant masters,
+	// and the pods whose priority exceeds it are created in super master without their priorityclass. The priority
+	// is not capped if it is 0.
+	MaxTenantPriority int32
+
+	// SyncTenantWebhooks indicates whether the admission webhooks registered in tenant masters are populated to
+	// super master, where they are scoped to the namespaces of the tenant. The tenant webhooks are ignored and
+	// a warning event is recorded for each of them if it is false.
+	SyncTenantWebhooks bool
+
+	// StatusUpsyncResources is a list of resources, e.g., persistentvolumeclaim, whose checkers copy the status of
+	// the super master objects to the tenant objects if they differ. The spec is still synced downward and the
+	// status is only upsynced once the spec is consistent. The resources without status, e.g., storageclass, and
+	// the ones whose status is already populated by the upward syncers, e.g., pod, are ignored.
+	StatusUpsyncResources []string
+
+	// SyncerFeatureGates enables or disables the resource syncers, keyed by the resource name, e.g.,
+	// StorageClass=true,NetworkPolicy=false. It overrides the resource syncers enabled by default and the ones
+	// enabled by ExtraSyncingResources. The resources not specified keep their default.
+	SyncerFeatureGates map[string]bool
+
+	// ValidateTenantPublicNames indicates whether the syncer serves an admission webhook at /validate-public-names,
+	// which rejects the tenant creation of cluster scoped objects, e.g., storageclasses, whose names are taken by
+	// the public super master objects synced to tenant masters. The webhook has to be registered in the tenant masters.
+	ValidateTenantPublicNames bool
+
+	// TenantSyncerUsers are the users syncer is authenticated as in tenant masters, i.e., the users of the tenant
+	// kubeconfigs. The public name webhook admits their creations of the objects named after public super master objects.
+	TenantSyncerUsers []string
+
+	// GenericSyncingResources is a list of namespaced custom resources, e.g., cert-manager certificates, whose tenant
+	// objects are synced to the super master namespaces of their tenant namespaces by the generic resource syncer.
+	// The status is neither synced nor checked. Cluster scoped resources are not supported, their names would
+	// collide among tenants in super master.
+	GenericSyncingResources []GenericResource
+
+	// ClusterSelector is a label selector of the VirtualClusters managed by the syncer, e.g., rollout=canary,
+	// so that the tenants can be split among syncer instances. All VirtualClusters are managed if it is empty.
+	ClusterSelector string
+
+	// ClusterNamePrefixes is a list of prefixes of the namespace/name of the VirtualClusters managed by the syncer.
+	// A VirtualCluster has to match both ClusterSelector and one of the prefixes, if any, to be managed.
+	ClusterNamePrefixes []string
+
+	// ResyncOnStart indicates whether each checker runs a patrol round synchronously as soon as the super master
+	// caches are synced, before its periodic timer is started, so that the drift accumulated while the syncer was
+	// down is reconciled without waiting for a full period. The round is serialized with the periodic ones.
+	ResyncOnStart bool
+
+	// MetaKeyTranslations rewrites the label/annotation keys of super master objects into the keys seen by
+	// tenants, keyed by the super master key. A key ending with "/" rewrites every key with that prefix, and an
+	// empty value strips the keys from tenant masters, e.g., topology.kubernetes.io/= hides the cloud provider
+	// topology annotations of the public storageclasses. The rewritten keys are owned by super master, they are
+	// ignored when the metadata of the objects synced downward is compared.
+	MetaKeyTranslations map[string]string
+
 	// FeatureGates enabled by the user.
 	FeatureGates map[string]bool
 
 	// Super cluster rest config
 	RestConfig *rest.Config
+
+	// Identity of the syncer replica, i.e., its leader election identity if leader election is enabled, or its
+	// hostname otherwise. The tenant clusters synced by the replica are reported under it.
+	Identity string
+}
+
+// GenericResource identifies a custom resource synced by the generic resource syncer.
+type GenericResource struct {
+	// Group is the API group of the resource, e.g., cert-manager.io.
+	Group string
+	// Version is the API version of the resource, e.g., v1.
+	Version string
+	// Resource is the plural resource name, e.g., certificates.
+	Resource string
+	// Kind is the kind of the resource, e.g., Certificate.
+	Kind string
+}
+
+// TopologyPolicy is the policy of syncing the super cluster topologies referred by the public super master objects.
+type TopologyPolicy string
+
+const (
+	// TopologyKeep syncs the topologies as is.
+	TopologyKeep TopologyPolicy = "Keep"
+	// TopologyRewrite translates the topology keys by MetaKeyTranslations.
+	TopologyRewrite TopologyPolicy = "Rewrite"
+	// TopologyDrop removes the topologies from the tenant objects.
+	TopologyDrop TopologyPolicy = "Drop"
+)
+
+// OwnerAnchor identifies a cluster scoped tenant object used as the owner of the objects synced by syncer.
+type OwnerAnchor struct {
+	// APIVersion is the API version of the owner, e.g., v1.
+	APIVersion string
+	// Kind is the kind of the owner, e.g., Namespace.
+	Kind string
+	// Name is the name of the owner.
+	Name string
 }
 
 // SyncerLeaderElectionConfiguration expands LeaderElectionConfiguration
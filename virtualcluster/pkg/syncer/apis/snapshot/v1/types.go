@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1 contains the subset of the snapshot.storage.k8s.io/v1 API used by the syncer.
+// The types mirror the ones defined by the kubernetes-csi external-snapshotter so that
+// the syncer does not depend on its client library.
+// +kubebuilder:object:generate=true
+// +groupName=snapshot.storage.k8s.io
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeletionPolicy describes a policy for end-of-life maintenance of volume snapshot contents.
+type DeletionPolicy string
+
+const (
+	// VolumeSnapshotContentDelete means the snapshot will be deleted from the
+	// underlying storage system on release from its volume snapshot.
+	VolumeSnapshotContentDelete DeletionPolicy = "Delete"
+
+	// VolumeSnapshotContentRetain means the snapshot will be left in its current
+	// state on release from its volume snapshot.
+	VolumeSnapshotContentRetain DeletionPolicy = "Retain"
+)
+
+// +kubebuilder:object:root=true
+
+// VolumeSnapshotClass specifies parameters that a underlying storage system uses when
+// creating a volume snapshot. VolumeSnapshotClasses are non-namespaced.
+type VolumeSnapshotClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Driver is the name of the storage driver that handles this VolumeSnapshotClass.
+	Driver string `json:"driver"`
+
+	// Parameters is a key-value map with storage driver specific parameters for creating snapshots.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// DeletionPolicy determines whether a VolumeSnapshotContent created through
+	// the VolumeSnapshotClass should be deleted when its bound VolumeSnapshot is deleted.
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy"`
+}
+
+// +kubebuilder:object:root=true
+
+// VolumeSnapshotClassList is a collection of VolumeSnapshotClasses.
+type VolumeSnapshotClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	// Items is the list of VolumeSnapshotClasses.
+	Items []VolumeSnapshotClass `json:"items"`
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+// ClusterSelector decides which VirtualClusters are managed by the syncer. The VirtualClusters not selected are
+// never added, hence neither synced nor patrolled, and are left to other syncer instances.
+type ClusterSelector struct {
+	selector labels.Selector
+	prefixes []string
+}
+
+// NewClusterSelector creates a ClusterSelector from config.ClusterSelector and config.ClusterNamePrefixes.
+func NewClusterSelector(config *config.SyncerConfiguration) (*ClusterSelector, error) {
+	selector, err := labels.Parse(config.ClusterSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster selector %q: %v", config.ClusterSelector, err)
+	}
+	return &ClusterSelector{selector: selector, prefixes: config.ClusterNamePrefixes}, nil
+}
+
+// Matches returns true if the VirtualCluster is managed by the syncer.
+func (c *ClusterSelector) Matches(vc *v1alpha1.VirtualCluster) bool {
+	if !c.selector.Matches(labels.Set(vc.GetLabels())) {
+		return false
+	}
+	if len(c.prefixes) == 0 {
+		return true
+	}
+	key := vc.Namespace + "/" + vc.Name
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+func TestClusterSelector(t *testing.T) {
+	canary := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-1", Name: "vc", Labels: map[string]string{"rollout": "canary"}},
+	}
+	stable := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-2", Name: "vc"},
+	}
+
+	testcases := map[string]struct {
+		config   config.SyncerConfiguration
+		expected map[*v1alpha1.VirtualCluster]bool
+	}{
+		"all clusters": {
+			expected: map[*v1alpha1.VirtualCluster]bool{canary: true, stable: true},
+		},
+		"label selector": {
+			config:   config.SyncerConfiguration{ClusterSelector: "rollout=canary"},
+			expected: map[*v1alpha1.VirtualCluster]bool{canary: true, stable: false},
+		},
+		"negative label selector": {
+			config:   config.SyncerConfiguration{ClusterSelector: "rollout!=canary"},
+			expected: map[*v1alpha1.VirtualCluster]bool{canary: false, stable: true},
+		},
+		"name prefixes": {
+			config:   config.SyncerConfiguration{ClusterNamePrefixes: []string{"tenant-2/"}},
+			expected: map[*v1alpha1.VirtualCluster]bool{canary: false, stable: true},
+		},
+		"label selector and name prefixes": {
+			config:   config.SyncerConfiguration{ClusterSelector: "rollout=canary", ClusterNamePrefixes: []string{"tenant-2/"}},
+			expected: map[*v1alpha1.VirtualCluster]bool{canary: false, stable: false},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewClusterSelector(&tc.config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for vc, expected := range tc.expected {
+				if got := s.Matches(vc); got != expected {
+					t.Errorf("expected %s/%s matched %v, got %v", vc.Namespace, vc.Name, expected, got)
+				}
+			}
+		})
+	}
+
+	if _, err := NewClusterSelector(&config.SyncerConfiguration{ClusterSelector: "rollout in ("}); err == nil {
+		t.Errorf("expected invalid cluster selector to be rejected")
+	}
+}
@@ -66,11 +66,103 @@ const (
 
 	// PublicObjectKey is a label key which marks the super master object that should be populated to every tenant master.
 	PublicObjectKey = "tenancy.x-k8s.io/super.public"
+	// LabelManagedBy is a label key which marks the tenant master object populated from super master by syncer.
+	LabelManagedBy = "tenancy.x-k8s.io/managed-by"
+	// ManagedBySyncer is the LabelManagedBy value of the tenant master objects populated by syncer.
+	ManagedBySyncer = "vc-syncer"
+	// EndpointSliceManagedBySyncer is the endpointslice.kubernetes.io/managed-by value of the tenant master
+	// endpointslices mirrored from super master, so that the tenant endpointslice controller leaves them alone.
+	EndpointSliceManagedBySyncer = "vc-syncer.tenancy.x-k8s.io"
+
+	// LabelTenantDefaultStorageClass is a VirtualCluster annotation key whose value is the name of the synced
+	// storageclass that should be marked as default in the tenant master.
+	LabelTenantDefaultStorageClass = "tenancy.x-k8s.io/default-storageclass"
+	// LabelTenantStorageClassParameters is a VirtualCluster annotation key whose value overrides the parameters of
+	// the synced storageclasses in the tenant master. It is a JSON object keyed by storageclass name and then by
+	// parameter, e.g., {"encrypted":{"kmsKeyId":"tenant-1-key"}}. An overridden parameter takes precedence over the
+	// super master value, and an empty value removes it. The other parameters follow super master. An invalid value
+	// is ignored as a whole.
+	LabelTenantStorageClassParameters = "tenancy.x-k8s.io/storageclass-parameters"
+	// LabelTenantPatrolWriteQPS and LabelTenantPatrolWriteBurst are VirtualCluster annotation keys whose values
+	// override the qps and burst of the writes the patrollers issue to the tenant master, e.g., "5" and "10".
+	LabelTenantPatrolWriteQPS   = "tenancy.x-k8s.io/patrol-write-qps"
+	LabelTenantPatrolWriteBurst = "tenancy.x-k8s.io/patrol-write-burst"
+	// LabelTenantSuperCluster is a VirtualCluster annotation key whose value is the ID of the super master the
+	// tenant is mapped to, among the extra super masters given to syncer by --extra-super-master-kubeconfigs.
+	// The tenants without it are mapped to the super master syncer runs against.
+	LabelTenantSuperCluster = "tenancy.x-k8s.io/super-cluster"
+	// AnnotationIsDefaultStorageClass is the annotation key which marks a storageclass as the cluster default.
+	AnnotationIsDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
+	// AnnotationDeletionPropagationPolicy is the annotation key of a syncer managed tenant object which overrides
+	// the propagation policy syncer deletes it with, i.e., Orphan, Background or Foreground, e.g., Foreground for
+	// the objects whose dependents must be removed first.
+	AnnotationDeletionPropagationPolicy = "tenancy.x-k8s.io/deletion-propagation-policy"
+	// AnnotationProtected is the annotation key which protects a tenant object from being deleted by syncer if its
+	// value is "true", e.g., a storageclass the tenant keeps independent of super master.
+	AnnotationProtected = "tenancy.x-k8s.io/protected"
+	// AnnotationMirrorOnly is the annotation key of a public super master object whose tenant copy is only mirrored
+	// if its value is "true". The tenant copy is created once and kept existing, the tenant edits to it are kept.
+	AnnotationMirrorOnly = "tenancy.x-k8s.io/mirror-only"
+	// LabelTenantDefaultIngressClass is a VirtualCluster annotation key whose value is the name of the synced
+	// ingressclass that should be marked as default in the tenant master.
+	LabelTenantDefaultIngressClass = "tenancy.x-k8s.io/default-ingressclass"
+	// AnnotationIsDefaultIngressClass is the annotation key which marks an ingressclass as the cluster default.
+	AnnotationIsDefaultIngressClass = "ingressclass.kubernetes.io/is-default-class"
 
 	LabelVirtualNode = "tenancy.x-k8s.io/virtualnode"
 	// LabelSuperClusterID is a label key added to the vNode object in tenant when SuperClusterPooling feature is enabled.
 	LabelSuperClusterID = "tenancy.x-k8s.io/superclusterid"
 
+	// DefaultPatrolPeriod is the default period of a patroller.
+	DefaultPatrolPeriod = time.Second * 60
+	// DefaultClusterScopedPatrolPeriod is the default period of the patrollers of the cluster scoped resources
+	// populated from super master, which rarely change.
+	DefaultClusterScopedPatrolPeriod = time.Minute * 5
+
+	// DefaultPatrolStartJitter is the default fraction of the period the first round of a patroller is delayed by at most.
+	DefaultPatrolStartJitter = 0.1
+
+	// DefaultPatrolMinOnDemandInterval is the minimal interval between two patrol rounds run on demand.
+	DefaultPatrolMinOnDemandInterval = time.Second * 30
+
+	// DefaultShutdownGracePeriod is the time the patrollers and upward controllers are given to finish their
+	// in-flight remediations once the syncer is stopped. It is shorter than the default leader election lease
+	// duration, so the next leader does not start remediating before they are done.
+	DefaultShutdownGracePeriod = time.Second * 10
+
+	// PatrolUnhealthyPeriods is the number of patrol periods a patroller may go without completing a round
+	// before it is reported unhealthy.
+	PatrolUnhealthyPeriods = 3
+
+	// DefaultPatrolConcurrency is the default number of tenant clusters a patroller checks in parallel.
+	DefaultPatrolConcurrency = 10
+	// DefaultPatrolListChunkSize is the default number of super master objects a checker fetches from the
+	// informer cache at a time when sweeping a resource.
+	DefaultPatrolListChunkSize = 500
+	// DefaultPatrolOpTimeout is the default timeout of each tenant operation issued by a patroller.
+	DefaultPatrolOpTimeout = time.Second * 30
+	// DefaultPatrolRequeueBaseDelay and DefaultPatrolRequeueMaxDelay bound the exponential backoff of the keys
+	// requeued by a patroller.
+	DefaultPatrolRequeueBaseDelay = time.Millisecond * 5
+	DefaultPatrolRequeueMaxDelay  = time.Minute * 5
+	// DefaultPatrolDeleteAttempts is the number of attempts a patroller makes to delete a tenant object before
+	// handing it over to the cleanup queue, the attempts are DefaultPatrolDeleteRetryDelay apart with jitter.
+	DefaultPatrolDeleteAttempts   = 3
+	DefaultPatrolDeleteRetryDelay = time.Millisecond * 200
+	// DefaultPatrolWriteBurst is the default burst of the writes a patroller issues to a tenant master once
+	// their qps is limited.
+	DefaultPatrolWriteBurst = 10
+	// DefaultMaxDeletePercentPerPass is the default max percentage of the syncer managed objects of a tenant
+	// cluster deleted in a single patrol pass.
+	DefaultMaxDeletePercentPerPass = 50
+	// PatrolDeleteLimitFloor is the number of deletions a patrol pass is always allowed to make regardless of
+	// the percentage, so that the last few orphans of a small tenant cluster can be removed.
+	PatrolDeleteLimitFloor = 2
+
+	// DefaultPVReclaimGracePeriod is the default grace period before a released super master PV with the Delete
+	// reclaim policy, whose tenant PVC is deleted, is treated as leaked.
+	DefaultPVReclaimGracePeriod = time.Hour
+
 	// DefaultvNodeGCGracePeriod is the grace period of time before deleting an orphan vNode in tenant master.
 	DefaultvNodeGCGracePeriod = time.Second * 120
 
@@ -81,6 +173,9 @@ const (
 	LabelSuperClusterIP = "transparency.tenancy.x-k8s.io/clusterIP"
 
 	KubeconfigAdminSecretName = "admin-kubeconfig"
+
+	// DefaultTenantSyncerUser is the user of the tenant admin kubeconfig, which syncer uses to access tenant masters.
+	DefaultTenantSyncerUser = "admin"
 )
 
 const (
@@ -92,3 +187,14 @@ const (
 )
 
 var DefaultDeletionPolicy = metav1.DeletePropagationBackground
+
+// DefaultPatrolPeriods are the default patroller periods of the resources which do not use DefaultPatrolPeriod.
+var DefaultPatrolPeriods = map[string]time.Duration{
+	"storageclass":        DefaultClusterScopedPatrolPeriod,
+	"priorityclass":       DefaultClusterScopedPatrolPeriod,
+	"csidriver":           DefaultClusterScopedPatrolPeriod,
+	"ingressclass":        DefaultClusterScopedPatrolPeriod,
+	"runtimeclass":        DefaultClusterScopedPatrolPeriod,
+	"volumesnapshotclass": DefaultClusterScopedPatrolPeriod,
+	"crd":                 DefaultClusterScopedPatrolPeriod,
+}
@@ -17,34 +17,61 @@ limitations under the License.
 package conversion
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"strings"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	v1 "k8s.io/api/core/v1"
-	v1beta1extensions "k8s.io/api/extensions/v1beta1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	nodev1 "k8s.io/api/node/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	v1scheduling "k8s.io/api/scheduling/v1"
 	v1storage "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	snapshotv1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/snapshot/v1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 )
 
 type vcEquality struct {
 	config *config.SyncerConfiguration
 	vc     *v1alpha1.VirtualCluster
+	// owner is the owner reference stamped by syncer onto the tenant object, it is reconciled if not nil.
+	owner *metav1.OwnerReference
 }
 
+// Equality returns the equality checker of the objects synced for the tenant virtual cluster, which honors the
+// transparent and opaque meta prefixes and the default class overrides of the virtual cluster.
 func Equality(syncerConfig *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster) *vcEquality {
 	return &vcEquality{config: syncerConfig, vc: vc}
 }
 
+// ConfigEquality returns the equality checker of the objects compared regardless of the tenant virtual cluster,
+// e.g., persistent volumes, CRDs and priority classes. A nil syncer configuration compares by the defaults.
+func ConfigEquality(syncerConfig *config.SyncerConfiguration) *vcEquality {
+	return Equality(syncerConfig, nil)
+}
+
+// WithOwnerReference makes the equality check reconcile the owner reference stamped by syncer onto the tenant
+// object. The other owner references of the tenant object are left alone.
+func (e *vcEquality) WithOwnerReference(owner *metav1.OwnerReference) *vcEquality {
+	e.owner = owner
+	return e
+}
+
 // CheckPodEquality check whether super master Pod object and virtual Pod object
 // are logically equal. The source of truth is virtual Pod.
 // notes: we only care about the metadata and pod spec update.
@@ -194,7 +221,7 @@ func (e vcEquality) CheckUWObjectMetaEquality(pObj, vObj *metav1.ObjectMeta) *me
 }
 
 // checkUWKVEquality checks if any key in VC.Spec.TransparentMetaPrefixes that exists in pKV
-// does exist in vKV with the same value.
+// does exist in vKV with the same value. The keys are looked up in vKV as translated by MetaKeyTranslations.
 // Note that we cannot remove a key from tenant if the key was presented in VC.Spec.TransparentMetaPrefixes
 // since we did not track the key removal event.
 func (e vcEquality) checkUWKVEquality(pKV, vKV map[string]string) (map[string]string, bool) {
@@ -208,9 +235,13 @@ func (e vcEquality) checkUWKVEquality(pKV, vKV map[string]string) (map[string]st
 	moreOrDiff := make(map[string]string)
 	for pk, pv := range pKV {
 		if hasPrefixInArray(pk, matchingList) {
-			vv, ok := vKV[pk]
+			vk, ok := TranslateMetaKey(e.config, pk)
+			if !ok {
+				continue
+			}
+			vv, ok := vKV[vk]
 			if !ok || pv != vv {
-				moreOrDiff[pk] = pv
+				moreOrDiff[vk] = pv
 			}
 		}
 	}
@@ -230,7 +261,8 @@ func (e vcEquality) checkUWKVEquality(pKV, vKV map[string]string) (map[string]st
 // checkDWKVEquality check the whether super master object labels and virtual object labels
 // are logically equal. If not, return the updated value. The source of truth is virtual object.
 // The exceptional keys that used by super master object are specified in
-// VC.Spec.TransparentMetaPrefixes plus a white list (e.g., tenancy.x-k8s.io).
+// VC.Spec.TransparentMetaPrefixes plus a white list (e.g., tenancy.x-k8s.io), as well as the keys
+// rewritten by MetaKeyTranslations.
 func (e vcEquality) checkDWKVEquality(pKV, vKV map[string]string) (map[string]string, bool) {
 	var exceptionsList []string
 	if e.vc != nil {
@@ -249,7 +281,7 @@ func (e vcEquality) checkDWKVEquality(pKV, vKV map[string]string) (map[string]st
 			// tenant pod should not use exceptional keys. it may conflicts with syncer.
 			continue
 		}
-		if e.isOpaquedKey(vk) {
+		if e.isOpaquedKey(vk) || isTranslatedKey(e.config, vk) {
 			continue
 		}
 		pv, ok := pKV[vk]
@@ -264,7 +296,7 @@ func (e vcEquality) checkDWKVEquality(pKV, vKV map[string]string) (map[string]st
 		if hasPrefixInArray(pk, exceptionsList) {
 			continue
 		}
-		if e.isOpaquedKey(pk) {
+		if e.isOpaquedKey(pk) || isTranslatedKey(e.config, pk) {
 			continue
 		}
 
@@ -292,6 +324,37 @@ func (e vcEquality) checkDWKVEquality(pKV, vKV map[string]string) (map[string]st
 	return updated, false
 }
 
+// mergeOwnedKV reconciles the keys of vKV matching one of the owned prefixes with pKV,
+// and keeps the other keys of vKV untouched. If not equal, return the merged value.
+// The keys maintained by syncer itself are never reconciled.
+func mergeOwnedKV(pKV, vKV map[string]string, ownedPrefixes []string) (map[string]string, bool) {
+	skipped := sets.NewString(constants.LabelManagedBy, constants.AnnotationIsDefaultStorageClass)
+	updated := make(map[string]string)
+	equal := true
+	for vk, vv := range vKV {
+		if hasPrefixInArray(vk, ownedPrefixes) && !skipped.Has(vk) {
+			if _, ok := pKV[vk]; !ok {
+				equal = false
+				continue
+			}
+		}
+		updated[vk] = vv
+	}
+	for pk, pv := range pKV {
+		if !hasPrefixInArray(pk, ownedPrefixes) || skipped.Has(pk) {
+			continue
+		}
+		if vv, ok := vKV[pk]; !ok || vv != pv {
+			equal = false
+			updated[pk] = pv
+		}
+	}
+	if equal {
+		return nil, true
+	}
+	return updated, false
+}
+
 func (e vcEquality) isOpaquedKey(key string) bool {
 	if e.config == nil {
 		return false
@@ -476,6 +539,50 @@ func (e vcEquality) CheckConfigMapEquality(pObj, vObj *v1.ConfigMap) *v1.ConfigM
 	return updated
 }
 
+// unstructuredIgnoredFields are the top level fields of the custom resources which are not synced downward.
+var unstructuredIgnoredFields = sets.NewString("apiVersion", "kind", "metadata", "status")
+
+// CheckUnstructuredEquality checks whether super master custom resource object and virtual one are logically
+// equal. The source of truth is virtual object. The labels and annotations are compared like the other resources,
+// the status and the system maintained metadata are ignored, the other top level fields, e.g., spec, are compared
+// as a whole.
+func (e vcEquality) CheckUnstructuredEquality(pObj, vObj *unstructured.Unstructured) *unstructured.Unstructured {
+	var updated *unstructured.Unstructured
+	pMeta := &metav1.ObjectMeta{GenerateName: pObj.GetGenerateName(), Labels: pObj.GetLabels(), Annotations: pObj.GetAnnotations()}
+	vMeta := &metav1.ObjectMeta{GenerateName: vObj.GetGenerateName(), Labels: vObj.GetLabels(), Annotations: vObj.GetAnnotations()}
+	if updatedMeta := e.CheckDWObjectMetaEquality(pMeta, vMeta); updatedMeta != nil {
+		updated = pObj.DeepCopy()
+		updated.SetGenerateName(updatedMeta.GenerateName)
+		updated.SetLabels(updatedMeta.Labels)
+		updated.SetAnnotations(updatedMeta.Annotations)
+	}
+
+	fields := sets.NewString()
+	for k := range pObj.Object {
+		fields.Insert(k)
+	}
+	for k := range vObj.Object {
+		fields.Insert(k)
+	}
+	for _, field := range fields.Difference(unstructuredIgnoredFields).List() {
+		vValue, vExists := vObj.Object[field]
+		pValue, pExists := pObj.Object[field]
+		if vExists == pExists && equality.Semantic.DeepEqual(pValue, vValue) {
+			continue
+		}
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		if vExists {
+			updated.Object[field] = runtime.DeepCopyJSONValue(vValue)
+		} else {
+			delete(updated.Object, field)
+		}
+	}
+
+	return updated
+}
+
 func (e vcEquality) checkMapEquality(pObj, vObj map[string]string) (map[string]string, bool) {
 	if equality.Semantic.DeepEqual(pObj, vObj) {
 		return nil, true
@@ -566,6 +673,51 @@ func (e vcEquality) CheckSecretEquality(pObj, vObj *v1.Secret) *v1.Secret {
 	return updated
 }
 
+// SecretDataDiff returns the keys of data and stringData that differ between the super master secret and
+// the tenant secret, e.g., "data.password". The values are compared by their hashes and are never returned,
+// so the result is safe to be logged.
+func SecretDataDiff(pObj, vObj *v1.Secret) []string {
+	var diffs []string
+	diffs = append(diffs, hashedKVDiff("data", hashBinaryData(pObj.Data), hashBinaryData(vObj.Data))...)
+	diffs = append(diffs, hashedKVDiff("stringData", hashStringData(pObj.StringData), hashStringData(vObj.StringData))...)
+	return diffs
+}
+
+func hashBinaryData(data map[string][]byte) map[string][sha256.Size]byte {
+	hashes := make(map[string][sha256.Size]byte, len(data))
+	for k, v := range data {
+		hashes[k] = sha256.Sum256(v)
+	}
+	return hashes
+}
+
+func hashStringData(data map[string]string) map[string][sha256.Size]byte {
+	hashes := make(map[string][sha256.Size]byte, len(data))
+	for k, v := range data {
+		hashes[k] = sha256.Sum256([]byte(v))
+	}
+	return hashes
+}
+
+func hashedKVDiff(path string, pHashes, vHashes map[string][sha256.Size]byte) []string {
+	keys := sets.NewString()
+	for k, ph := range pHashes {
+		if vh, ok := vHashes[k]; !ok || vh != ph {
+			keys.Insert(k)
+		}
+	}
+	for k := range vHashes {
+		if _, ok := pHashes[k]; !ok {
+			keys.Insert(k)
+		}
+	}
+	var diffs []string
+	for _, k := range keys.List() {
+		diffs = append(diffs, path+"."+k)
+	}
+	return diffs
+}
+
 func filterSubSetTargetRef(ep *v1.Endpoints) []v1.EndpointSubset {
 	epSubsetCopy := ep.Subsets
 	for i, each := range epSubsetCopy {
@@ -602,17 +754,223 @@ func (e vcEquality) CheckEndpointsEquality(pObj, vObj *v1.Endpoints) *v1.Endpoin
 	return updated
 }
 
+// CheckStorageClassEquality checks whether the spec fields of super master StorageClass and tenant
+// StorageClass are the same. The super master object is the source of truth. If they differ, a copy
+// of the tenant object carrying the super master fields is returned.
+// The metadata of tenant StorageClass, including the default class annotation, is owned by tenant unless
+// the VirtualCluster overrides the default storageclass using LabelTenantDefaultStorageClass annotation.
 func (e vcEquality) CheckStorageClassEquality(pObj, vObj *v1storage.StorageClass) *v1storage.StorageClass {
-	pObjCopy := pObj.DeepCopy()
-	pObjCopy.ObjectMeta = vObj.ObjectMeta
-	// pObj.TypeMeta is empty
-	pObjCopy.TypeMeta = vObj.TypeMeta
+	var updated *v1storage.StorageClass
+	if pObj.Provisioner != vObj.Provisioner {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Provisioner = pObj.Provisioner
+	}
 
-	if !equality.Semantic.DeepEqual(vObj, pObjCopy) {
-		return pObjCopy
-	} else {
+	// the parameters overridden by the VirtualCluster are compared with the overrides.
+	if pParameters := TenantStorageClassParameters(e.vc, vObj.Name, pObj.Parameters); !equality.Semantic.DeepEqual(pParameters, vObj.Parameters) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Parameters = make(map[string]string, len(pParameters))
+		for k, v := range pParameters {
+			updated.Parameters[k] = v
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.ReclaimPolicy, vObj.ReclaimPolicy) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.ReclaimPolicy = pObj.DeepCopy().ReclaimPolicy
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.MountOptions, vObj.MountOptions) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.MountOptions = pObj.DeepCopy().MountOptions
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.AllowVolumeExpansion, vObj.AllowVolumeExpansion) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.AllowVolumeExpansion = pObj.DeepCopy().AllowVolumeExpansion
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.VolumeBindingMode, vObj.VolumeBindingMode) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.VolumeBindingMode = pObj.DeepCopy().VolumeBindingMode
+	}
+
+	if pTopologies := TenantAllowedTopologies(e.config, pObj.AllowedTopologies); !equality.Semantic.DeepEqual(pTopologies, vObj.AllowedTopologies) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.AllowedTopologies = pTopologies
+	}
+
+	if labels, equal := e.checkStorageClassKVEquality(pObj.GetLabels(), vObj.GetLabels(), nil); !equal {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.SetLabels(labels)
+	}
+	if annotations, equal := e.checkStorageClassKVEquality(pObj.GetAnnotations(), vObj.GetAnnotations(), e.storageClassIgnoredAnnotationPrefixes()); !equal {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.SetAnnotations(annotations)
+	}
+
+	if e.owner != nil && !HasOwnerReference(vObj, *e.owner) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		SetOwnerReference(updated, *e.owner)
+	}
+
+	// The default class annotation is owned by tenant and only reconciled when the VirtualCluster overrides it.
+	if value, overridden := tenantDefaultClassValue(e.vc, constants.LabelTenantDefaultStorageClass, vObj.Name); overridden && vObj.GetAnnotations()[constants.AnnotationIsDefaultStorageClass] != value {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		SetTenantDefaultStorageClass(e.vc, updated)
+	}
+
+	return updated
+}
+
+// checkStorageClassKVEquality reconciles the labels/annotations of tenant StorageClass with the ones of super
+// master StorageClass as seen by tenants. The keys matching StorageClassOwnedMetaPrefixes are reconciled, and
+// the keys stripped or rewritten by MetaKeyTranslations are removed from or renamed in tenant StorageClass.
+// The keys matching ignoredPrefixes are left out on both sides, the tenant ones are kept as is.
+func (e vcEquality) checkStorageClassKVEquality(pKV, vKV map[string]string, ignoredPrefixes []string) (map[string]string, bool) {
+	if e.config == nil {
+		return nil, true
+	}
+	pKV, _ = splitIgnoredKV(pKV, ignoredPrefixes)
+	vKV, vIgnored := splitIgnoredKV(vKV, ignoredPrefixes)
+	updated, equal := e.checkStorageClassOwnedKVEquality(pKV, vKV)
+	if equal {
+		return nil, true
+	}
+	if len(vIgnored) > 0 && updated == nil {
+		updated = make(map[string]string, len(vIgnored))
+	}
+	for k, v := range vIgnored {
+		updated[k] = v
+	}
+	return updated, false
+}
+
+func (e vcEquality) storageClassIgnoredAnnotationPrefixes() []string {
+	if e.config == nil {
+		return nil
+	}
+	return e.config.StorageClassIgnoredAnnotationPrefixes
+}
+
+// splitIgnoredKV splits the labels/annotations into the ones to compare and the ones matching ignoredPrefixes.
+func splitIgnoredKV(kv map[string]string, ignoredPrefixes []string) (map[string]string, map[string]string) {
+	if len(ignoredPrefixes) == 0 {
+		return kv, nil
+	}
+	var compared, ignored map[string]string
+	for k, v := range kv {
+		if hasPrefixInArray(k, ignoredPrefixes) {
+			if ignored == nil {
+				ignored = make(map[string]string)
+			}
+			ignored[k] = v
+			continue
+		}
+		if compared == nil {
+			compared = make(map[string]string)
+		}
+		compared[k] = v
+	}
+	return compared, ignored
+}
+
+func (e vcEquality) checkStorageClassOwnedKVEquality(pKV, vKV map[string]string) (map[string]string, bool) {
+	merged := vKV
+	if len(e.config.StorageClassOwnedMetaPrefixes) > 0 {
+		if owned, equal := mergeOwnedKV(TranslateMetaKeys(e.config, pKV), vKV, e.config.StorageClassOwnedMetaPrefixes); !equal {
+			merged = owned
+		}
+	}
+	translated := TranslateMetaKeys(e.config, merged)
+	if equality.Semantic.DeepEqual(translated, vKV) {
+		return nil, true
+	}
+	return translated, false
+}
+
+// StorageClassDiff returns the human readable differences between the tenant storageclass and the updated one
+// returned by CheckStorageClassEquality, in the format of "<field path>: <tenant value> -> <updated value>".
+func StorageClassDiff(vObj, updated *v1storage.StorageClass) []string {
+	if updated == nil {
 		return nil
 	}
+	var diffs []string
+	add := func(path string, old, new interface{}) {
+		if !equality.Semantic.DeepEqual(old, new) {
+			diffs = append(diffs, fmt.Sprintf("%s: %v -> %v", path, old, new))
+		}
+	}
+	addKV := func(path string, old, new map[string]string) {
+		keys := sets.NewString()
+		for k := range old {
+			keys.Insert(k)
+		}
+		for k := range new {
+			keys.Insert(k)
+		}
+		for _, k := range keys.List() {
+			ov, oOK := old[k]
+			nv, nOK := new[k]
+			if oOK != nOK || ov != nv {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: %s -> %s", path, k, kvString(ov, oOK), kvString(nv, nOK)))
+			}
+		}
+	}
+	addKV("metadata.labels", vObj.Labels, updated.Labels)
+	addKV("metadata.annotations", vObj.Annotations, updated.Annotations)
+	add("provisioner", vObj.Provisioner, updated.Provisioner)
+	addKV("parameters", vObj.Parameters, updated.Parameters)
+	add("reclaimPolicy", derefString((*string)(vObj.ReclaimPolicy)), derefString((*string)(updated.ReclaimPolicy)))
+	add("mountOptions", vObj.MountOptions, updated.MountOptions)
+	add("allowVolumeExpansion", derefBool(vObj.AllowVolumeExpansion), derefBool(updated.AllowVolumeExpansion))
+	add("volumeBindingMode", derefString((*string)(vObj.VolumeBindingMode)), derefString((*string)(updated.VolumeBindingMode)))
+	add("allowedTopologies", vObj.AllowedTopologies, updated.AllowedTopologies)
+	add("metadata.ownerReferences", vObj.OwnerReferences, updated.OwnerReferences)
+	return diffs
+}
+
+func kvString(v string, ok bool) string {
+	if !ok {
+		return "<unset>"
+	}
+	return v
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return "<unset>"
+	}
+	return *s
+}
+
+func derefBool(b *bool) string {
+	if b == nil {
+		return "<unset>"
+	}
+	return fmt.Sprintf("%t", *b)
 }
 
 func (e vcEquality) CheckPriorityClassEquality(pObj, vObj *v1scheduling.PriorityClass) *v1scheduling.PriorityClass {
@@ -641,17 +999,27 @@ func (e vcEquality) CheckCRDEquality(pObj, vObj *v1beta1.CustomResourceDefinitio
 	}
 }
 
-func (e vcEquality) CheckIngressEquality(pObj, vObj *v1beta1extensions.Ingress) *v1beta1extensions.Ingress {
-	pObjCopy := pObj.DeepCopy()
-	pObjCopy.ObjectMeta = vObj.ObjectMeta
-	// pObj.TypeMeta is empty
-	pObjCopy.TypeMeta = vObj.TypeMeta
+// CheckIngressEquality checks whether super master Ingress and virtual Ingress are logically equal.
+// Backend services and tls secrets are referenced by name within the Ingress namespace and keep
+// their names in the super master namespace, hence the spec is compared as is.
+func (e vcEquality) CheckIngressEquality(pObj, vObj *networkingv1.Ingress) *networkingv1.Ingress {
+	var updated *networkingv1.Ingress
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.ObjectMeta = *updatedMeta
+	}
 
-	if !equality.Semantic.DeepEqual(vObj, pObjCopy) {
-		return pObjCopy
-	} else {
-		return nil
+	if !equality.Semantic.DeepEqual(pObj.Spec, vObj.Spec) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec = *vObj.Spec.DeepCopy()
 	}
+
+	return updated
 }
 
 func filterNodePort(svc *v1.Service) *v1.ServiceSpec {
@@ -705,20 +1073,55 @@ func (e vcEquality) CheckPVCEquality(pObj, vObj *v1.PersistentVolumeClaim) *v1.P
 		}
 		updated.ObjectMeta = *updatedMeta
 	}
-	// ExpandPersistentVolumes feature allows storage size to be increased.
-	if pObj.Spec.Resources.Requests["storage"] != vObj.Spec.Resources.Requests["storage"] {
+	// ExpandPersistentVolumes feature allows storage size to be increased. A smaller request is never synced,
+	// since the super master rejects shrinking a pvc.
+	if pRequest := pvcStorageRequest(pObj); !PVCStorageShrunk(pObj, vObj) && !pRequest.Equal(pvcStorageRequest(vObj)) {
 		if updated == nil {
 			updated = pObj.DeepCopy()
 		}
 		if updated.Spec.Resources.Requests == nil {
 			updated.Spec.Resources.Requests = make(map[v1.ResourceName]resource.Quantity)
 		}
-		updated.Spec.Resources.Requests["storage"] = vObj.Spec.Resources.Requests["storage"]
+		updated.Spec.Resources.Requests[v1.ResourceStorage] = pvcStorageRequest(vObj)
 	}
 	// We don't check PVC status since it will be managed by tenant/master pv binder controller independently.
 	return updated
 }
 
+// CheckPVCCapacityEquality checks whether the capacity of the tenant pvc reflects the capacity of the super master
+// pvc, which grows once the super master expands the volume. It returns the tenant pvc with the capacity and the
+// resize conditions of the super master pvc if they differ, the other status fields are left alone.
+func (e vcEquality) CheckPVCCapacityEquality(pObj, vObj *v1.PersistentVolumeClaim) *v1.PersistentVolumeClaim {
+	pCapacity, pExists := pObj.Status.Capacity[v1.ResourceStorage]
+	if !pExists {
+		return nil
+	}
+	vCapacity, vExists := vObj.Status.Capacity[v1.ResourceStorage]
+	if vExists && pCapacity.Equal(vCapacity) && equality.Semantic.DeepEqual(pObj.Status.Conditions, vObj.Status.Conditions) {
+		return nil
+	}
+	updated := vObj.DeepCopy()
+	if updated.Status.Capacity == nil {
+		updated.Status.Capacity = make(v1.ResourceList)
+	}
+	updated.Status.Capacity[v1.ResourceStorage] = pCapacity.DeepCopy()
+	updated.Status.Conditions = nil
+	for _, condition := range pObj.Status.Conditions {
+		updated.Status.Conditions = append(updated.Status.Conditions, *condition.DeepCopy())
+	}
+	return updated
+}
+
+// PVCStorageShrunk returns true if the tenant pvc requests less storage than the super master pvc.
+func PVCStorageShrunk(pObj, vObj *v1.PersistentVolumeClaim) bool {
+	vRequest := pvcStorageRequest(vObj)
+	return vRequest.Cmp(pvcStorageRequest(pObj)) < 0
+}
+
+func pvcStorageRequest(pvc *v1.PersistentVolumeClaim) resource.Quantity {
+	return pvc.Spec.Resources.Requests[v1.ResourceStorage]
+}
+
 func (e vcEquality) CheckPVSpecEquality(pObj, vObj *v1.PersistentVolumeSpec) *v1.PersistentVolumeSpec {
 	var updatedPVSpec *v1.PersistentVolumeSpec
 	pCopy := pObj.DeepCopy()
@@ -738,3 +1141,339 @@ func (e vcEquality) CheckNamespaceEquality(pObj, vObj *v1.Namespace) *v1.Namespa
 	}
 	return updated
 }
+
+// CheckMutatingWebhookConfigurationEquality checks whether super master MutatingWebhookConfiguration and virtual
+// MutatingWebhookConfiguration are logically equal. The virtual webhooks are converted into tenant scope before comparison.
+func (e vcEquality) CheckMutatingWebhookConfigurationEquality(pObj, vObj *admissionregistrationv1.MutatingWebhookConfiguration) *admissionregistrationv1.MutatingWebhookConfiguration {
+	var updated *admissionregistrationv1.MutatingWebhookConfiguration
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		updated = pObj.DeepCopy()
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	vWebhooks := vObj.DeepCopy().Webhooks
+	MutateMutatingWebhooks(vWebhooks, pObj.GetAnnotations()[constants.LabelCluster], pObj.GetAnnotations()[constants.LabelVCName], pObj.GetAnnotations()[constants.LabelVCNamespace])
+	if !equality.Semantic.DeepEqual(pObj.Webhooks, vWebhooks) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Webhooks = vWebhooks
+	}
+	return updated
+}
+
+// CheckValidatingWebhookConfigurationEquality checks whether super master ValidatingWebhookConfiguration and virtual
+// ValidatingWebhookConfiguration are logically equal. The virtual webhooks are converted into tenant scope before comparison.
+func (e vcEquality) CheckValidatingWebhookConfigurationEquality(pObj, vObj *admissionregistrationv1.ValidatingWebhookConfiguration) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	var updated *admissionregistrationv1.ValidatingWebhookConfiguration
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		updated = pObj.DeepCopy()
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	vWebhooks := vObj.DeepCopy().Webhooks
+	MutateValidatingWebhooks(vWebhooks, pObj.GetAnnotations()[constants.LabelCluster], pObj.GetAnnotations()[constants.LabelVCName], pObj.GetAnnotations()[constants.LabelVCNamespace])
+	if !equality.Semantic.DeepEqual(pObj.Webhooks, vWebhooks) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Webhooks = vWebhooks
+	}
+	return updated
+}
+
+// CheckNetworkPolicyEquality checks whether super master NetworkPolicy and virtual NetworkPolicy
+// are logically equal. The spec of virtual NetworkPolicy is converted into tenant scope before comparison.
+func (e vcEquality) CheckNetworkPolicyEquality(pObj, vObj *networkingv1.NetworkPolicy) *networkingv1.NetworkPolicy {
+	var updated *networkingv1.NetworkPolicy
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	vSpec := vObj.Spec.DeepCopy()
+	MutateNetworkPolicySpec(vSpec, pObj.GetAnnotations()[constants.LabelCluster])
+	if !equality.Semantic.DeepEqual(&pObj.Spec, vSpec) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec = *vSpec
+	}
+
+	return updated
+}
+
+// CheckPodDisruptionBudgetEquality checks whether super master PodDisruptionBudget and virtual PodDisruptionBudget
+// are logically equal. The selector of virtual PodDisruptionBudget is converted into tenant scope before comparison.
+func (e vcEquality) CheckPodDisruptionBudgetEquality(pObj, vObj *policyv1.PodDisruptionBudget) *policyv1.PodDisruptionBudget {
+	var updated *policyv1.PodDisruptionBudget
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	vSpec := vObj.Spec.DeepCopy()
+	MutatePodDisruptionBudgetSpec(vSpec, pObj.GetAnnotations()[constants.LabelCluster])
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.MinAvailable, vSpec.MinAvailable) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.MinAvailable = vSpec.MinAvailable
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.MaxUnavailable, vSpec.MaxUnavailable) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.MaxUnavailable = vSpec.MaxUnavailable
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.Selector, vSpec.Selector) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.Selector = vSpec.Selector
+	}
+
+	return updated
+}
+
+// CheckHorizontalPodAutoscalerEquality checks whether super master HorizontalPodAutoscaler and virtual
+// HorizontalPodAutoscaler are logically equal. The status is owned by super master and is not compared.
+func (e vcEquality) CheckHorizontalPodAutoscalerEquality(pObj, vObj *v2beta2.HorizontalPodAutoscaler) *v2beta2.HorizontalPodAutoscaler {
+	var updated *v2beta2.HorizontalPodAutoscaler
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.ScaleTargetRef, vObj.Spec.ScaleTargetRef) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.ScaleTargetRef = vObj.Spec.ScaleTargetRef
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.MinReplicas, vObj.Spec.MinReplicas) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.MinReplicas = vObj.DeepCopy().Spec.MinReplicas
+	}
+
+	if pObj.Spec.MaxReplicas != vObj.Spec.MaxReplicas {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.MaxReplicas = vObj.Spec.MaxReplicas
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.Metrics, vObj.Spec.Metrics) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.Metrics = vObj.DeepCopy().Spec.Metrics
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.Behavior, vObj.Spec.Behavior) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.Behavior = vObj.DeepCopy().Spec.Behavior
+	}
+
+	return updated
+}
+
+// CheckResourceQuotaEquality checks whether super master ResourceQuota and virtual ResourceQuota
+// are logically equal. Only the hard limits and the scopes are compared, the usage is owned by super master.
+func (e vcEquality) CheckResourceQuotaEquality(pObj, vObj *v1.ResourceQuota) *v1.ResourceQuota {
+	var updated *v1.ResourceQuota
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.Hard, vObj.Spec.Hard) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.Hard = vObj.DeepCopy().Spec.Hard
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.Scopes, vObj.Spec.Scopes) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.Scopes = vObj.DeepCopy().Spec.Scopes
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.ScopeSelector, vObj.Spec.ScopeSelector) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.ScopeSelector = vObj.DeepCopy().Spec.ScopeSelector
+	}
+
+	return updated
+}
+
+// CheckLimitRangeEquality checks whether super master LimitRange and virtual LimitRange are logically equal.
+// Every limit item, including its min, max, default, defaultRequest and maxLimitRequestRatio, is compared.
+func (e vcEquality) CheckLimitRangeEquality(pObj, vObj *v1.LimitRange) *v1.LimitRange {
+	var updated *v1.LimitRange
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Spec.Limits, vObj.Spec.Limits) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec.Limits = vObj.DeepCopy().Spec.Limits
+	}
+
+	return updated
+}
+
+// CheckCSIDriverEquality checks whether the spec of super master CSIDriver and tenant CSIDriver are the same.
+// The super master object is the source of truth. If they differ, a copy of the tenant object carrying the
+// super master spec is returned.
+func (e vcEquality) CheckCSIDriverEquality(pObj, vObj *v1storage.CSIDriver) *v1storage.CSIDriver {
+	if equality.Semantic.DeepEqual(pObj.Spec, vObj.Spec) {
+		return nil
+	}
+	updated := vObj.DeepCopy()
+	updated.Spec = *pObj.Spec.DeepCopy()
+	return updated
+}
+
+// CheckIngressClassEquality checks whether the spec of super master IngressClass and tenant IngressClass are the
+// same. The default class annotation is owned by tenant unless the VirtualCluster overrides it. If they differ,
+// an updated tenant object is returned.
+func (e vcEquality) CheckIngressClassEquality(pObj, vObj *networkingv1.IngressClass) *networkingv1.IngressClass {
+	var updated *networkingv1.IngressClass
+	if !equality.Semantic.DeepEqual(pObj.Spec, vObj.Spec) {
+		updated = vObj.DeepCopy()
+		updated.Spec = *pObj.Spec.DeepCopy()
+	}
+
+	if value, overridden := tenantDefaultClassValue(e.vc, constants.LabelTenantDefaultIngressClass, vObj.Name); overridden && vObj.GetAnnotations()[constants.AnnotationIsDefaultIngressClass] != value {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		SetTenantDefaultIngressClass(e.vc, updated)
+	}
+
+	return updated
+}
+
+// CheckRuntimeClassEquality checks whether super master RuntimeClass and tenant RuntimeClass have the same handler,
+// overhead and scheduling. If they differ, an updated tenant object is returned. The handler is immutable, the caller
+// recreates the tenant RuntimeClass if it is the one which differs.
+func (e vcEquality) CheckRuntimeClassEquality(pObj, vObj *nodev1.RuntimeClass) *nodev1.RuntimeClass {
+	var updated *nodev1.RuntimeClass
+	if pObj.Handler != vObj.Handler {
+		updated = vObj.DeepCopy()
+		updated.Handler = pObj.Handler
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Overhead, vObj.Overhead) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Overhead = pObj.Overhead.DeepCopy()
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Scheduling, vObj.Scheduling) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Scheduling = pObj.Scheduling.DeepCopy()
+	}
+
+	return updated
+}
+
+// CheckVolumeSnapshotClassEquality checks whether super master VolumeSnapshotClass and tenant VolumeSnapshotClass
+// have the same driver, deletionPolicy and parameters. If they differ, an updated tenant object is returned.
+func (e vcEquality) CheckVolumeSnapshotClassEquality(pObj, vObj *snapshotv1.VolumeSnapshotClass) *snapshotv1.VolumeSnapshotClass {
+	var updated *snapshotv1.VolumeSnapshotClass
+	if pObj.Driver != vObj.Driver {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Driver = pObj.Driver
+	}
+
+	if pObj.DeletionPolicy != vObj.DeletionPolicy {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.DeletionPolicy = pObj.DeletionPolicy
+	}
+
+	if !equality.Semantic.DeepEqual(pObj.Parameters, vObj.Parameters) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Parameters = pObj.DeepCopy().Parameters
+	}
+
+	return updated
+}
+
+// CheckEndpointSliceEquality checks whether the tenant endpointslice has the same address type, endpoints,
+// ports and labels as expected, which is built from the super master endpointslice by BuildVirtualEndpointSlice.
+// If they differ, an updated tenant object is returned.
+func (e vcEquality) CheckEndpointSliceEquality(expected, vObj *discoveryv1.EndpointSlice) *discoveryv1.EndpointSlice {
+	var updated *discoveryv1.EndpointSlice
+	if expected.AddressType != vObj.AddressType {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.AddressType = expected.AddressType
+	}
+
+	if !equality.Semantic.DeepEqual(expected.Endpoints, vObj.Endpoints) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Endpoints = expected.DeepCopy().Endpoints
+	}
+
+	if !equality.Semantic.DeepEqual(expected.Ports, vObj.Ports) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Ports = expected.DeepCopy().Ports
+	}
+
+	if !equality.Semantic.DeepEqual(expected.Labels, vObj.Labels) {
+		if updated == nil {
+			updated = vObj.DeepCopy()
+		}
+		updated.Labels = expected.DeepCopy().Labels
+	}
+
+	return updated
+}
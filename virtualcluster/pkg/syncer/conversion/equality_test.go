@@ -17,19 +17,30 @@ limitations under the License.
 package conversion
 
 import (
+	"math/rand"
+	"strings"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	v1scheduling "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	snapshotv1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/snapshot/v1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 )
 
 func TestCheckDWKVEquality(t *testing.T) {
 	syncerConfig := &config.SyncerConfiguration{
 		DefaultOpaqueMetaDomains: []string{"kubernetes.io"},
+		MetaKeyTranslations:      map[string]string{"cloud.io/zone": "zone"},
 	}
 	vc := v1alpha1.VirtualCluster{
 		Spec: v1alpha1.VirtualClusterSpec{
@@ -44,6 +55,12 @@ func TestCheckDWKVEquality(t *testing.T) {
 		isEqual  bool
 		expected map[string]string
 	}{
+		{
+			name:    "translated keys are ignored",
+			super:   map[string]string{"a": "b", "cloud.io/zone": "z1"},
+			virtual: map[string]string{"a": "b", "zone": "z2"},
+			isEqual: true,
+		},
 		{
 			name:     "both empty",
 			super:    nil,
@@ -410,7 +427,7 @@ func TestCheckContainersImageEquality(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(tc *testing.T) {
-			got := Equality(nil, nil).checkContainersImageEquality(tt.pObj, tt.vObj)
+			got := ConfigEquality(nil).checkContainersImageEquality(tt.pObj, tt.vObj)
 			if !equality.Semantic.DeepEqual(got, tt.expected) {
 				t.Errorf("expected %+v, got %+v", tt.expected, got)
 			}
@@ -463,7 +480,7 @@ func TestCheckActiveDeadlineSecondsEquality(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(tc *testing.T) {
-			val, equal := Equality(nil, nil).checkInt64Equality(tt.pObj, tt.vObj)
+			val, equal := ConfigEquality(nil).checkInt64Equality(tt.pObj, tt.vObj)
 			if equal != tt.isEqual {
 				tc.Errorf("expected equal %v, got %v", tt.isEqual, equal)
 			}
@@ -796,7 +813,7 @@ func TestCheckUWPodStatusEquality(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(tc *testing.T) {
-			val := Equality(nil, nil).CheckUWPodStatusEquality(tt.pObj, tt.vObj)
+			val := ConfigEquality(nil).CheckUWPodStatusEquality(tt.pObj, tt.vObj)
 			if !equality.Semantic.DeepEqual(val, tt.updatedVal) {
 				tc.Errorf("expected val %v, got %v", tt.updatedVal, val)
 			}
@@ -979,3 +996,1164 @@ func TestCheckDWPodConditionEquality(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckStorageClassEquality(t *testing.T) {
+	retain := v1.PersistentVolumeReclaimRetain
+	deletePolicy := v1.PersistentVolumeReclaimDelete
+	immediate := storagev1.VolumeBindingImmediate
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+
+	base := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "sc",
+			ResourceVersion: "1",
+		},
+		Provisioner:          "a",
+		Parameters:           map[string]string{"type": "ssd"},
+		ReclaimPolicy:        &deletePolicy,
+		MountOptions:         []string{"ro"},
+		AllowVolumeExpansion: pointer.BoolPtr(false),
+		VolumeBindingMode:    &immediate,
+	}
+
+	for _, tt := range []struct {
+		name    string
+		modify  func(sc *storagev1.StorageClass)
+		isEqual bool
+	}{
+		{
+			name:    "equal",
+			modify:  func(sc *storagev1.StorageClass) {},
+			isEqual: true,
+		},
+		{
+			name:    "only metadata differs",
+			modify:  func(sc *storagev1.StorageClass) { sc.ResourceVersion = "2" },
+			isEqual: true,
+		},
+		{
+			name:   "provisioner differs",
+			modify: func(sc *storagev1.StorageClass) { sc.Provisioner = "b" },
+		},
+		{
+			name:   "parameters differ",
+			modify: func(sc *storagev1.StorageClass) { sc.Parameters = map[string]string{"type": "hdd"} },
+		},
+		{
+			name:   "reclaim policy differs",
+			modify: func(sc *storagev1.StorageClass) { sc.ReclaimPolicy = &retain },
+		},
+		{
+			name:   "volume binding mode differs",
+			modify: func(sc *storagev1.StorageClass) { sc.VolumeBindingMode = &waitForFirstConsumer },
+		},
+		{
+			name:   "allow volume expansion differs",
+			modify: func(sc *storagev1.StorageClass) { sc.AllowVolumeExpansion = pointer.BoolPtr(true) },
+		},
+		{
+			name:   "mount options differ",
+			modify: func(sc *storagev1.StorageClass) { sc.MountOptions = []string{"rw"} },
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vObj := base.DeepCopy()
+			pObj := base.DeepCopy()
+			pObj.ResourceVersion = ""
+			tt.modify(pObj)
+
+			updated := ConfigEquality(nil).CheckStorageClassEquality(pObj, vObj)
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			if updated.ResourceVersion != vObj.ResourceVersion {
+				tc.Errorf("expected tenant resource version %s, got %s", vObj.ResourceVersion, updated.ResourceVersion)
+			}
+			expected := pObj.DeepCopy()
+			expected.ObjectMeta = vObj.ObjectMeta
+			if !equality.Semantic.DeepEqual(updated, expected) {
+				tc.Errorf("expected updated %v, got %v", expected, updated)
+			}
+		})
+	}
+}
+
+func TestCheckStorageClassDefaultAnnotationEquality(t *testing.T) {
+	withDefault := func(value string) *storagev1.StorageClass {
+		sc := &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "sc",
+			},
+			Provisioner: "a",
+		}
+		if value != "" {
+			sc.Annotations = map[string]string{constants.AnnotationIsDefaultStorageClass: value}
+		}
+		return sc
+	}
+	withOverride := func(name string) *v1alpha1.VirtualCluster {
+		return &v1alpha1.VirtualCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{constants.LabelTenantDefaultStorageClass: name},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name          string
+		vc            *v1alpha1.VirtualCluster
+		pObj          *storagev1.StorageClass
+		vObj          *storagev1.StorageClass
+		expectedValue string
+		isEqual       bool
+	}{
+		{
+			name:    "no override, tenant unsets default",
+			pObj:    withDefault("true"),
+			vObj:    withDefault(""),
+			isEqual: true,
+		},
+		{
+			name:    "no override, tenant sets default",
+			vc:      &v1alpha1.VirtualCluster{},
+			pObj:    withDefault(""),
+			vObj:    withDefault("true"),
+			isEqual: true,
+		},
+		{
+			name:          "override names this storageclass",
+			vc:            withOverride("sc"),
+			pObj:          withDefault(""),
+			vObj:          withDefault(""),
+			expectedValue: "true",
+		},
+		{
+			name:          "override names another storageclass",
+			vc:            withOverride("other"),
+			pObj:          withDefault("true"),
+			vObj:          withDefault("true"),
+			expectedValue: "false",
+		},
+		{
+			name:    "override already applied",
+			vc:      withOverride("sc"),
+			pObj:    withDefault("false"),
+			vObj:    withDefault("true"),
+			isEqual: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			updated := Equality(nil, tt.vc).CheckStorageClassEquality(tt.pObj, tt.vObj)
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			if value := updated.Annotations[constants.AnnotationIsDefaultStorageClass]; value != tt.expectedValue {
+				tc.Errorf("expected default class annotation %q, got %q", tt.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestCheckStorageClassParameterOverrides(t *testing.T) {
+	withParameters := func(parameters map[string]string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "encrypted"},
+			Provisioner: "a",
+			Parameters:  parameters,
+		}
+	}
+	withOverrides := func(overrides string) *v1alpha1.VirtualCluster {
+		return &v1alpha1.VirtualCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{constants.LabelTenantStorageClassParameters: overrides},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name               string
+		vc                 *v1alpha1.VirtualCluster
+		vObj               *storagev1.StorageClass
+		expectedParameters map[string]string
+		isEqual            bool
+	}{
+		{
+			name:    "no overrides",
+			vc:      &v1alpha1.VirtualCluster{},
+			vObj:    withParameters(map[string]string{"type": "gp2", "kmsKeyId": "super-key"}),
+			isEqual: true,
+		},
+		{
+			name:               "overridden parameter differs from super master",
+			vc:                 withOverrides(`{"encrypted":{"kmsKeyId":"tenant-key"}}`),
+			vObj:               withParameters(map[string]string{"type": "gp2", "kmsKeyId": "super-key"}),
+			expectedParameters: map[string]string{"type": "gp2", "kmsKeyId": "tenant-key"},
+		},
+		{
+			name:    "override already applied",
+			vc:      withOverrides(`{"encrypted":{"kmsKeyId":"tenant-key"}}`),
+			vObj:    withParameters(map[string]string{"type": "gp2", "kmsKeyId": "tenant-key"}),
+			isEqual: true,
+		},
+		{
+			name:               "other parameters follow super master",
+			vc:                 withOverrides(`{"encrypted":{"kmsKeyId":"tenant-key"}}`),
+			vObj:               withParameters(map[string]string{"type": "io1", "kmsKeyId": "tenant-key"}),
+			expectedParameters: map[string]string{"type": "gp2", "kmsKeyId": "tenant-key"},
+		},
+		{
+			name:               "empty override removes the parameter",
+			vc:                 withOverrides(`{"encrypted":{"kmsKeyId":""}}`),
+			vObj:               withParameters(map[string]string{"type": "gp2", "kmsKeyId": "super-key"}),
+			expectedParameters: map[string]string{"type": "gp2"},
+		},
+		{
+			name:    "overrides of other storageclasses",
+			vc:      withOverrides(`{"standard":{"kmsKeyId":"tenant-key"}}`),
+			vObj:    withParameters(map[string]string{"type": "gp2", "kmsKeyId": "super-key"}),
+			isEqual: true,
+		},
+		{
+			name:               "invalid overrides are ignored",
+			vc:                 withOverrides(`{"encrypted":"tenant-key"}`),
+			vObj:               withParameters(map[string]string{"type": "gp2", "kmsKeyId": "tenant-key"}),
+			expectedParameters: map[string]string{"type": "gp2", "kmsKeyId": "super-key"},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			pObj := withParameters(map[string]string{"type": "gp2", "kmsKeyId": "super-key"})
+			updated := Equality(nil, tt.vc).CheckStorageClassEquality(pObj, tt.vObj)
+			if pObj.Parameters["kmsKeyId"] != "super-key" {
+				tc.Errorf("expected super master parameters untouched, got %v", pObj.Parameters)
+			}
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated.Parameters)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			if !equality.Semantic.DeepEqual(updated.Parameters, tt.expectedParameters) {
+				tc.Errorf("expected parameters %v, got %v", tt.expectedParameters, updated.Parameters)
+			}
+		})
+	}
+}
+
+func TestCheckStorageClassOwnedMetaEquality(t *testing.T) {
+	withMeta := func(labels, annotations map[string]string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "sc",
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Provisioner: "a",
+		}
+	}
+	owned := &config.SyncerConfiguration{StorageClassOwnedMetaPrefixes: []string{"super.io"}}
+
+	for _, tt := range []struct {
+		name                string
+		config              *config.SyncerConfiguration
+		pObj                *storagev1.StorageClass
+		vObj                *storagev1.StorageClass
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+		isEqual             bool
+	}{
+		{
+			name:    "no owned prefixes",
+			config:  &config.SyncerConfiguration{},
+			pObj:    withMeta(map[string]string{"super.io/a": "1"}, nil),
+			vObj:    withMeta(nil, map[string]string{"tenant.io/b": "2"}),
+			isEqual: true,
+		},
+		{
+			name:    "tenant added keys are preserved",
+			config:  owned,
+			pObj:    withMeta(map[string]string{"super.io/a": "1"}, nil),
+			vObj:    withMeta(map[string]string{"super.io/a": "1", "tenant.io/b": "2"}, map[string]string{"tenant.io/c": "3"}),
+			isEqual: true,
+		},
+		{
+			name:                "owned keys are reconciled",
+			config:              owned,
+			pObj:                withMeta(map[string]string{"super.io/a": "1"}, map[string]string{"super.io/d": "4"}),
+			vObj:                withMeta(map[string]string{"super.io/a": "0", "tenant.io/b": "2"}, map[string]string{"tenant.io/c": "3"}),
+			expectedLabels:      map[string]string{"super.io/a": "1", "tenant.io/b": "2"},
+			expectedAnnotations: map[string]string{"super.io/d": "4", "tenant.io/c": "3"},
+		},
+		{
+			name:                "owned keys removed from super master",
+			config:              owned,
+			pObj:                withMeta(nil, nil),
+			vObj:                withMeta(map[string]string{"super.io/a": "1", "tenant.io/b": "2"}, nil),
+			expectedLabels:      map[string]string{"tenant.io/b": "2"},
+			expectedAnnotations: nil,
+		},
+		{
+			name: "translated keys are compared",
+			config: &config.SyncerConfiguration{
+				StorageClassOwnedMetaPrefixes: []string{"tenant.io"},
+				MetaKeyTranslations:           map[string]string{"super.io/": "tenant.io/"},
+			},
+			pObj:    withMeta(map[string]string{"super.io/a": "1"}, nil),
+			vObj:    withMeta(map[string]string{"tenant.io/a": "1"}, nil),
+			isEqual: true,
+		},
+		{
+			name: "stripped keys are removed",
+			config: &config.SyncerConfiguration{
+				MetaKeyTranslations: map[string]string{"topology.kubernetes.io/": ""},
+			},
+			pObj:                withMeta(nil, map[string]string{"topology.kubernetes.io/zone": "a"}),
+			vObj:                withMeta(map[string]string{"tenant.io/b": "2"}, map[string]string{"topology.kubernetes.io/zone": "a", "tenant.io/c": "3"}),
+			expectedLabels:      map[string]string{"tenant.io/b": "2"},
+			expectedAnnotations: map[string]string{"tenant.io/c": "3"},
+		},
+		{
+			name: "ignored annotations are not compared",
+			config: &config.SyncerConfiguration{
+				StorageClassOwnedMetaPrefixes:         []string{"kubectl.kubernetes.io"},
+				StorageClassIgnoredAnnotationPrefixes: []string{"kubectl.kubernetes.io/last-applied-configuration"},
+			},
+			pObj:    withMeta(nil, nil),
+			vObj:    withMeta(nil, map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"}),
+			isEqual: true,
+		},
+		{
+			name: "ignored annotations are kept",
+			config: &config.SyncerConfiguration{
+				StorageClassOwnedMetaPrefixes:         []string{"super.io", "meta.helm.sh"},
+				StorageClassIgnoredAnnotationPrefixes: []string{"meta.helm.sh/"},
+			},
+			pObj:                withMeta(map[string]string{"meta.helm.sh/release-name": "super"}, map[string]string{"super.io/d": "4", "meta.helm.sh/release-name": "super"}),
+			vObj:                withMeta(nil, map[string]string{"super.io/d": "0", "meta.helm.sh/release-name": "tenant"}),
+			expectedLabels:      map[string]string{"meta.helm.sh/release-name": "super"},
+			expectedAnnotations: map[string]string{"super.io/d": "4", "meta.helm.sh/release-name": "tenant"},
+		},
+		{
+			name:    "managed-by label is kept",
+			config:  &config.SyncerConfiguration{StorageClassOwnedMetaPrefixes: []string{"tenancy.x-k8s.io"}},
+			pObj:    withMeta(nil, nil),
+			vObj:    withMeta(map[string]string{constants.LabelManagedBy: constants.ManagedBySyncer}, nil),
+			isEqual: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			updated := ConfigEquality(tt.config).CheckStorageClassEquality(tt.pObj, tt.vObj)
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			if !equality.Semantic.DeepEqual(updated.Labels, tt.expectedLabels) {
+				tc.Errorf("expected labels %v, got %v", tt.expectedLabels, updated.Labels)
+			}
+			if !equality.Semantic.DeepEqual(updated.Annotations, tt.expectedAnnotations) {
+				tc.Errorf("expected annotations %v, got %v", tt.expectedAnnotations, updated.Annotations)
+			}
+		})
+	}
+}
+
+func TestCheckStorageClassOwnerReferenceEquality(t *testing.T) {
+	owner := metav1.OwnerReference{APIVersion: "v1", Kind: "Namespace", Name: "anchor", UID: "uid-1"}
+	tenantOwner := metav1.OwnerReference{APIVersion: "tenant.io/v1", Kind: "Team", Name: "team", UID: "uid-2"}
+	withOwners := func(refs ...metav1.OwnerReference) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "sc", OwnerReferences: refs},
+			Provisioner: "a",
+		}
+	}
+	recreated := owner
+	recreated.UID = "uid-3"
+
+	for _, tt := range []struct {
+		name           string
+		owner          *metav1.OwnerReference
+		vObj           *storagev1.StorageClass
+		expectedOwners []metav1.OwnerReference
+		isEqual        bool
+	}{
+		{
+			name:    "no owner",
+			vObj:    withOwners(tenantOwner),
+			isEqual: true,
+		},
+		{
+			name:    "owner stamped",
+			owner:   &owner,
+			vObj:    withOwners(tenantOwner, owner),
+			isEqual: true,
+		},
+		{
+			name:           "owner missing",
+			owner:          &owner,
+			vObj:           withOwners(tenantOwner),
+			expectedOwners: []metav1.OwnerReference{tenantOwner, owner},
+		},
+		{
+			name:           "owner recreated",
+			owner:          &recreated,
+			vObj:           withOwners(owner, tenantOwner),
+			expectedOwners: []metav1.OwnerReference{recreated, tenantOwner},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			updated := ConfigEquality(nil).WithOwnerReference(tt.owner).CheckStorageClassEquality(withOwners(), tt.vObj)
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			if !equality.Semantic.DeepEqual(updated.OwnerReferences, tt.expectedOwners) {
+				tc.Errorf("expected owner references %v, got %v", tt.expectedOwners, updated.OwnerReferences)
+			}
+		})
+	}
+}
+
+// randomKV returns nil, an empty map or a random subset of the keys, so that the properties below cover the
+// nil and empty maps the apiserver may return for the same object.
+func randomKV(r *rand.Rand, keys []string) map[string]string {
+	switch r.Intn(3) {
+	case 0:
+		return nil
+	case 1:
+		return map[string]string{}
+	}
+	kv := make(map[string]string)
+	for _, k := range keys {
+		if r.Intn(2) == 0 {
+			kv[k] = []string{"a", "b"}[r.Intn(2)]
+		}
+	}
+	return kv
+}
+
+func randomStorageClass(r *rand.Rand) *storagev1.StorageClass {
+	metaKeys := []string{"owned.io/a", "super.io/b", "tenant.io/b", "ignored.io/c", "plain", constants.AnnotationIsDefaultStorageClass}
+	sc := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sc",
+			Labels:      randomKV(r, metaKeys),
+			Annotations: randomKV(r, metaKeys),
+		},
+		Provisioner: []string{"p1", "p2"}[r.Intn(2)],
+		Parameters:  randomKV(r, []string{"type", "kmsKeyId"}),
+	}
+	switch r.Intn(3) {
+	case 1:
+		sc.MountOptions = []string{}
+	case 2:
+		sc.MountOptions = []string{"ro"}
+	}
+	switch r.Intn(3) {
+	case 1:
+		sc.AllowedTopologies = []v1.TopologySelectorTerm{}
+	case 2:
+		sc.AllowedTopologies = []v1.TopologySelectorTerm{{
+			MatchLabelExpressions: []v1.TopologySelectorLabelRequirement{{Key: "super.io/zone", Values: []string{"z1"}}},
+		}}
+	}
+	if r.Intn(2) == 0 {
+		policy := v1.PersistentVolumeReclaimRetain
+		sc.ReclaimPolicy = &policy
+	}
+	if r.Intn(2) == 0 {
+		sc.AllowVolumeExpansion = pointer.BoolPtr(r.Intn(2) == 0)
+	}
+	return sc
+}
+
+// flipNilMap turns a nil map into an empty one and vice versa.
+func flipNilMap(kv map[string]string) map[string]string {
+	if kv == nil {
+		return map[string]string{}
+	}
+	if len(kv) == 0 {
+		return nil
+	}
+	return kv
+}
+
+func TestCheckStorageClassEqualityProperties(t *testing.T) {
+	configs := []*config.SyncerConfiguration{nil, {}, {
+		StorageClassOwnedMetaPrefixes:         []string{"owned.io/"},
+		MetaKeyTranslations:                   map[string]string{"super.io/": "tenant.io/"},
+		StorageClassIgnoredAnnotationPrefixes: []string{"ignored.io/"},
+		StorageClassTopologyPolicy:            config.TopologyRewrite,
+	}}
+	vcs := []*v1alpha1.VirtualCluster{nil, {
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				constants.LabelTenantDefaultStorageClass:    "sc",
+				constants.LabelTenantStorageClassParameters: `{"sc":{"kmsKeyId":"k","type":""}}`,
+			},
+		},
+	}}
+	flips := map[string]func(*storagev1.StorageClass){
+		"labels":      func(sc *storagev1.StorageClass) { sc.Labels = flipNilMap(sc.Labels) },
+		"annotations": func(sc *storagev1.StorageClass) { sc.Annotations = flipNilMap(sc.Annotations) },
+		"parameters":  func(sc *storagev1.StorageClass) { sc.Parameters = flipNilMap(sc.Parameters) },
+	}
+
+	// a fixed seed keeps the test deterministic, a failure is reproduced by the iteration.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		syncerConfig := configs[r.Intn(len(configs))]
+		vc := vcs[r.Intn(len(vcs))]
+		e := Equality(syncerConfig, vc)
+		pObj := randomStorageClass(r)
+
+		// a tenant copy freshly built the way the upward syncer does never drifts.
+		vObj := BuildVirtualStorageClass("cluster", pObj)
+		TranslateObjectMeta(syncerConfig, vObj)
+		vObj.AllowedTopologies = TenantAllowedTopologies(syncerConfig, pObj.AllowedTopologies)
+		SetTenantDefaultStorageClass(vc, vObj)
+		vObj.Parameters = TenantStorageClassParameters(vc, vObj.Name, vObj.Parameters)
+		if updated := e.CheckStorageClassEquality(pObj, vObj); updated != nil {
+			t.Fatalf("iteration %d: expected no drift for a fresh tenant copy, got %v", i, StorageClassDiff(vObj, updated))
+		}
+
+		// nil and empty maps compare equal on either side.
+		for name, flip := range flips {
+			flippedV := vObj.DeepCopy()
+			flip(flippedV)
+			if updated := e.CheckStorageClassEquality(pObj, flippedV); updated != nil {
+				t.Fatalf("iteration %d: expected no drift with tenant %s flipped between nil and empty, got %v", i, name, StorageClassDiff(flippedV, updated))
+			}
+			flippedP := pObj.DeepCopy()
+			flip(flippedP)
+			if updated := e.CheckStorageClassEquality(flippedP, vObj); updated != nil {
+				t.Fatalf("iteration %d: expected no drift with super %s flipped between nil and empty, got %v", i, name, StorageClassDiff(vObj, updated))
+			}
+		}
+
+		// syncing twice is a no-op.
+		drifted := randomStorageClass(r)
+		updated := e.CheckStorageClassEquality(pObj, drifted)
+		if updated == nil {
+			continue
+		}
+		if again := e.CheckStorageClassEquality(pObj, updated); again != nil {
+			t.Fatalf("iteration %d: expected the updated tenant copy to be consistent, got %v", i, StorageClassDiff(updated, again))
+		}
+		// the apiserver may drop the empty maps of the update.
+		for name, flip := range flips {
+			stored := updated.DeepCopy()
+			flip(stored)
+			if again := e.CheckStorageClassEquality(pObj, stored); again != nil {
+				t.Fatalf("iteration %d: expected the stored update with %s flipped between nil and empty to be consistent, got %v", i, name, StorageClassDiff(stored, again))
+			}
+		}
+	}
+}
+
+func TestStorageClassDiff(t *testing.T) {
+	retain := v1.PersistentVolumeReclaimRetain
+	vObj := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "sc",
+		},
+		Provisioner: "a",
+		Parameters:  map[string]string{"type": "gp2", "fs": "ext4"},
+	}
+
+	for _, tt := range []struct {
+		name          string
+		modify        func(sc *storagev1.StorageClass)
+		expectedDiffs []string
+	}{
+		{
+			name:   "equal",
+			modify: nil,
+		},
+		{
+			name:          "parameter differs",
+			modify:        func(sc *storagev1.StorageClass) { sc.Parameters["type"] = "gp3" },
+			expectedDiffs: []string{"parameters.type: gp2 -> gp3"},
+		},
+		{
+			name: "parameter removed and provisioner differs",
+			modify: func(sc *storagev1.StorageClass) {
+				sc.Provisioner = "b"
+				delete(sc.Parameters, "fs")
+			},
+			expectedDiffs: []string{"provisioner: a -> b", "parameters.fs: ext4 -> <unset>"},
+		},
+		{
+			name:          "reclaim policy set",
+			modify:        func(sc *storagev1.StorageClass) { sc.ReclaimPolicy = &retain },
+			expectedDiffs: []string{"reclaimPolicy: <unset> -> Retain"},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			var updated *storagev1.StorageClass
+			if tt.modify != nil {
+				updated = vObj.DeepCopy()
+				tt.modify(updated)
+			}
+			diffs := StorageClassDiff(vObj, updated)
+			if !equality.Semantic.DeepEqual(diffs, tt.expectedDiffs) {
+				tc.Errorf("expected diffs %v, got %v", tt.expectedDiffs, diffs)
+			}
+		})
+	}
+}
+
+func TestSecretDataDiff(t *testing.T) {
+	pObj := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "secret",
+		},
+		Data:       map[string][]byte{"user": []byte("admin"), "password": []byte("s3cr3t")},
+		StringData: map[string]string{"token": "t0ken"},
+	}
+
+	for _, tt := range []struct {
+		name          string
+		modify        func(secret *v1.Secret)
+		expectedDiffs []string
+	}{
+		{
+			name: "equal",
+		},
+		{
+			name:          "data value differs",
+			modify:        func(secret *v1.Secret) { secret.Data["password"] = []byte("leaked") },
+			expectedDiffs: []string{"data.password"},
+		},
+		{
+			name: "data key added and removed",
+			modify: func(secret *v1.Secret) {
+				delete(secret.Data, "user")
+				secret.Data["extra"] = []byte("value")
+			},
+			expectedDiffs: []string{"data.extra", "data.user"},
+		},
+		{
+			name:          "string data value differs",
+			modify:        func(secret *v1.Secret) { secret.StringData["token"] = "other" },
+			expectedDiffs: []string{"stringData.token"},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vObj := pObj.DeepCopy()
+			if tt.modify != nil {
+				tt.modify(vObj)
+			}
+			diffs := SecretDataDiff(pObj, vObj)
+			if !equality.Semantic.DeepEqual(diffs, tt.expectedDiffs) {
+				tc.Errorf("expected diffs %v, got %v", tt.expectedDiffs, diffs)
+			}
+			for _, diff := range diffs {
+				for _, value := range []string{"admin", "s3cr3t", "t0ken", "leaked", "value", "other"} {
+					if strings.Contains(diff, value) {
+						tc.Errorf("diff %q leaks secret value %q", diff, value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCheckLimitRangeEquality(t *testing.T) {
+	base := &v1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "lr",
+			Namespace:       "default",
+			ResourceVersion: "1",
+		},
+		Spec: v1.LimitRangeSpec{
+			Limits: []v1.LimitRangeItem{
+				{
+					Type:                 v1.LimitTypeContainer,
+					Min:                  v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+					Max:                  v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+					Default:              v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+					DefaultRequest:       v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")},
+					MaxLimitRequestRatio: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name    string
+		modify  func(item *v1.LimitRangeItem)
+		isEqual bool
+	}{
+		{
+			name:    "equal",
+			modify:  func(item *v1.LimitRangeItem) {},
+			isEqual: true,
+		},
+		{
+			name:    "same quantity in different format",
+			modify:  func(item *v1.LimitRangeItem) { item.Max = v1.ResourceList{v1.ResourceCPU: resource.MustParse("1000m")} },
+			isEqual: true,
+		},
+		{
+			name:   "type differs",
+			modify: func(item *v1.LimitRangeItem) { item.Type = v1.LimitTypePod },
+		},
+		{
+			name:   "min differs",
+			modify: func(item *v1.LimitRangeItem) { item.Min = v1.ResourceList{v1.ResourceCPU: resource.MustParse("50m")} },
+		},
+		{
+			name:   "max differs",
+			modify: func(item *v1.LimitRangeItem) { item.Max = v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")} },
+		},
+		{
+			name:   "default differs",
+			modify: func(item *v1.LimitRangeItem) { item.Default = v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")} },
+		},
+		{
+			name:   "default request differs",
+			modify: func(item *v1.LimitRangeItem) { item.DefaultRequest = nil },
+		},
+		{
+			name: "max limit request ratio differs",
+			modify: func(item *v1.LimitRangeItem) {
+				item.MaxLimitRequestRatio = v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+			},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vObj := base.DeepCopy()
+			pObj := base.DeepCopy()
+			tt.modify(&pObj.Spec.Limits[0])
+
+			updated := ConfigEquality(nil).CheckLimitRangeEquality(pObj, vObj)
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			if !equality.Semantic.DeepEqual(updated.Spec, vObj.Spec) {
+				tc.Errorf("expected updated spec %v, got %v", vObj.Spec, updated.Spec)
+			}
+		})
+	}
+}
+
+func TestCheckPVCEquality(t *testing.T) {
+	withStorage := func(request, capacity string) *v1.PersistentVolumeClaim {
+		pvc := &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc", Namespace: "default"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(request)},
+				},
+			},
+		}
+		if capacity != "" {
+			pvc.Status.Capacity = v1.ResourceList{v1.ResourceStorage: resource.MustParse(capacity)}
+		}
+		return pvc
+	}
+
+	for _, tt := range []struct {
+		name            string
+		pObj            *v1.PersistentVolumeClaim
+		vObj            *v1.PersistentVolumeClaim
+		expectedRequest string
+		expectedCap     string
+	}{
+		{
+			name: "equal",
+			pObj: withStorage("10Gi", "10Gi"),
+			vObj: withStorage("10Gi", "10Gi"),
+		},
+		{
+			name: "same quantity in different format",
+			pObj: withStorage("1Gi", "1Gi"),
+			vObj: withStorage("1024Mi", "1024Mi"),
+		},
+		{
+			name:            "expanded",
+			pObj:            withStorage("10Gi", "10Gi"),
+			vObj:            withStorage("20Gi", "10Gi"),
+			expectedRequest: "20Gi",
+		},
+		{
+			name: "shrunk",
+			pObj: withStorage("20Gi", "20Gi"),
+			vObj: withStorage("10Gi", "20Gi"),
+		},
+		{
+			name:        "capacity grown in super master",
+			pObj:        withStorage("20Gi", "20Gi"),
+			vObj:        withStorage("20Gi", "10Gi"),
+			expectedCap: "20Gi",
+		},
+		{
+			name: "super master pvc not bound",
+			pObj: withStorage("20Gi", ""),
+			vObj: withStorage("20Gi", "10Gi"),
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			updated := ConfigEquality(nil).CheckPVCEquality(tt.pObj, tt.vObj)
+			if tt.expectedRequest == "" && updated != nil {
+				tc.Errorf("expected no spec update, got %v", updated.Spec)
+			}
+			if tt.expectedRequest != "" {
+				if updated == nil {
+					tc.Fatalf("expected spec update, got nil")
+				}
+				if request := updated.Spec.Resources.Requests[v1.ResourceStorage]; request.String() != tt.expectedRequest {
+					tc.Errorf("expected storage request %s, got %s", tt.expectedRequest, request.String())
+				}
+			}
+
+			updated = ConfigEquality(nil).CheckPVCCapacityEquality(tt.pObj, tt.vObj)
+			if tt.expectedCap == "" && updated != nil {
+				tc.Errorf("expected no capacity update, got %v", updated.Status)
+			}
+			if tt.expectedCap != "" {
+				if updated == nil {
+					tc.Fatalf("expected capacity update, got nil")
+				}
+				if capacity := updated.Status.Capacity[v1.ResourceStorage]; capacity.String() != tt.expectedCap {
+					tc.Errorf("expected capacity %s, got %s", tt.expectedCap, capacity.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCheckRuntimeClassEquality(t *testing.T) {
+	base := &nodev1.RuntimeClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "gvisor",
+			ResourceVersion: "1",
+		},
+		Handler: "runsc",
+		Overhead: &nodev1.Overhead{
+			PodFixed: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m")},
+		},
+	}
+
+	for _, tt := range []struct {
+		name    string
+		modify  func(rc *nodev1.RuntimeClass)
+		isEqual bool
+	}{
+		{
+			name:    "equal",
+			modify:  func(rc *nodev1.RuntimeClass) {},
+			isEqual: true,
+		},
+		{
+			name:    "only metadata differs",
+			modify:  func(rc *nodev1.RuntimeClass) { rc.ResourceVersion = "2" },
+			isEqual: true,
+		},
+		{
+			name:   "handler differs",
+			modify: func(rc *nodev1.RuntimeClass) { rc.Handler = "kata" },
+		},
+		{
+			name: "overhead differs",
+			modify: func(rc *nodev1.RuntimeClass) {
+				rc.Overhead.PodFixed[v1.ResourceMemory] = resource.MustParse("120Mi")
+			},
+		},
+		{
+			name:   "overhead removed",
+			modify: func(rc *nodev1.RuntimeClass) { rc.Overhead = nil },
+		},
+		{
+			name: "scheduling differs",
+			modify: func(rc *nodev1.RuntimeClass) {
+				rc.Scheduling = &nodev1.Scheduling{NodeSelector: map[string]string{"sandbox": "gvisor"}}
+			},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vObj := base.DeepCopy()
+			pObj := base.DeepCopy()
+			pObj.ResourceVersion = ""
+			tt.modify(pObj)
+
+			updated := ConfigEquality(nil).CheckRuntimeClassEquality(pObj, vObj)
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			expected := pObj.DeepCopy()
+			expected.ObjectMeta = vObj.ObjectMeta
+			if !equality.Semantic.DeepEqual(updated, expected) {
+				tc.Errorf("expected updated %v, got %v", expected, updated)
+			}
+		})
+	}
+}
+
+func TestCheckVolumeSnapshotClassEquality(t *testing.T) {
+	base := &snapshotv1.VolumeSnapshotClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "vsc",
+			ResourceVersion: "1",
+		},
+		Driver:         "a",
+		Parameters:     map[string]string{"type": "incremental"},
+		DeletionPolicy: snapshotv1.VolumeSnapshotContentDelete,
+	}
+
+	for _, tt := range []struct {
+		name    string
+		modify  func(vsc *snapshotv1.VolumeSnapshotClass)
+		isEqual bool
+	}{
+		{
+			name:    "equal",
+			modify:  func(vsc *snapshotv1.VolumeSnapshotClass) {},
+			isEqual: true,
+		},
+		{
+			name:    "only metadata differs",
+			modify:  func(vsc *snapshotv1.VolumeSnapshotClass) { vsc.ResourceVersion = "2" },
+			isEqual: true,
+		},
+		{
+			name:   "driver differs",
+			modify: func(vsc *snapshotv1.VolumeSnapshotClass) { vsc.Driver = "b" },
+		},
+		{
+			name:   "deletion policy differs",
+			modify: func(vsc *snapshotv1.VolumeSnapshotClass) { vsc.DeletionPolicy = snapshotv1.VolumeSnapshotContentRetain },
+		},
+		{
+			name:   "parameters differ",
+			modify: func(vsc *snapshotv1.VolumeSnapshotClass) { vsc.Parameters = map[string]string{"type": "full"} },
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vObj := base.DeepCopy()
+			pObj := base.DeepCopy()
+			pObj.ResourceVersion = ""
+			tt.modify(pObj)
+
+			updated := ConfigEquality(nil).CheckVolumeSnapshotClassEquality(pObj, vObj)
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			expected := pObj.DeepCopy()
+			expected.ObjectMeta = vObj.ObjectMeta
+			if !equality.Semantic.DeepEqual(updated, expected) {
+				tc.Errorf("expected updated %v, got %v", expected, updated)
+			}
+		})
+	}
+}
+
+func TestCheckUnstructuredEquality(t *testing.T) {
+	object := func(labels map[string]string, fields map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetAPIVersion("cert-manager.io/v1")
+		obj.SetKind("Certificate")
+		obj.SetName("cert")
+		obj.SetLabels(labels)
+		for k, v := range fields {
+			obj.Object[k] = v
+		}
+		return obj
+	}
+	spec := func(secretName string) map[string]interface{} {
+		return map[string]interface{}{"secretName": secretName}
+	}
+
+	for _, tt := range []struct {
+		name           string
+		pObj           *unstructured.Unstructured
+		vObj           *unstructured.Unstructured
+		expectedLabels map[string]string
+		expectedFields map[string]interface{}
+		isEqual        bool
+	}{
+		{
+			name:    "equal",
+			pObj:    object(nil, map[string]interface{}{"spec": spec("a")}),
+			vObj:    object(nil, map[string]interface{}{"spec": spec("a")}),
+			isEqual: true,
+		},
+		{
+			name: "status and system metadata are ignored",
+			pObj: object(nil, map[string]interface{}{"spec": spec("a"), "status": map[string]interface{}{"ready": true}}),
+			vObj: func() *unstructured.Unstructured {
+				obj := object(nil, map[string]interface{}{"spec": spec("a")})
+				obj.SetUID("12345")
+				obj.SetResourceVersion("10")
+				return obj
+			}(),
+			isEqual: true,
+		},
+		{
+			name:           "spec changed",
+			pObj:           object(nil, map[string]interface{}{"spec": spec("a")}),
+			vObj:           object(nil, map[string]interface{}{"spec": spec("b")}),
+			expectedFields: map[string]interface{}{"spec": spec("b")},
+		},
+		{
+			name:           "top level field removed",
+			pObj:           object(nil, map[string]interface{}{"spec": spec("a"), "data": "x"}),
+			vObj:           object(nil, map[string]interface{}{"spec": spec("a")}),
+			expectedFields: map[string]interface{}{"spec": spec("a")},
+		},
+		{
+			name:           "labels changed",
+			pObj:           object(map[string]string{"a": "1"}, map[string]interface{}{"spec": spec("a")}),
+			vObj:           object(map[string]string{"a": "2"}, map[string]interface{}{"spec": spec("a")}),
+			expectedLabels: map[string]string{"a": "2"},
+			expectedFields: map[string]interface{}{"spec": spec("a")},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			updated := ConfigEquality(nil).CheckUnstructuredEquality(tt.pObj, tt.vObj)
+			if tt.isEqual {
+				if updated != nil {
+					tc.Errorf("expected no update, got %v", updated)
+				}
+				return
+			}
+			if updated == nil {
+				tc.Fatalf("expected update, got nil")
+			}
+			if tt.expectedLabels != nil && !equality.Semantic.DeepEqual(updated.GetLabels(), tt.expectedLabels) {
+				tc.Errorf("expected labels %v, got %v", tt.expectedLabels, updated.GetLabels())
+			}
+			for _, field := range []string{"spec", "data"} {
+				if !equality.Semantic.DeepEqual(updated.Object[field], tt.expectedFields[field]) {
+					tc.Errorf("expected %s %v, got %v", field, tt.expectedFields[field], updated.Object[field])
+				}
+			}
+		})
+	}
+}
+
+// equalityCase is an equality check run in isolation, check reports whether the checker returns an update.
+type equalityCase struct {
+	check         func(e *vcEquality) bool
+	expectUpdated bool
+}
+
+// runEqualityCases runs each case against a checker built by newEquality.
+func runEqualityCases(t *testing.T, newEquality func() *vcEquality, cases map[string]equalityCase) {
+	t.Helper()
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if updated := tc.check(newEquality()); updated != tc.expectUpdated {
+				t.Errorf("expected updated %v, got %v", tc.expectUpdated, updated)
+			}
+		})
+	}
+}
+
+func TestConfigEquality(t *testing.T) {
+	syncerConfig := &config.SyncerConfiguration{}
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				constants.LabelTenantDefaultStorageClass: "standard",
+				constants.LabelTenantDefaultIngressClass: "nginx",
+			},
+		},
+		Spec: v1alpha1.VirtualClusterSpec{
+			TransparentMetaPrefixes: []string{"tenancy.x-k8s.io"},
+			OpaqueMetaPrefixes:      []string{"tenancy.x-k8s.io"},
+		},
+	}
+
+	priorityClass := func(value int32) *v1scheduling.PriorityClass {
+		return &v1scheduling.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}, Value: value}
+	}
+	pvSpec := func(capacity string) *v1.PersistentVolumeSpec {
+		return &v1.PersistentVolumeSpec{Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse(capacity)}}
+	}
+	csiDriver := func(attachRequired bool) *storagev1.CSIDriver {
+		return &storagev1.CSIDriver{Spec: storagev1.CSIDriverSpec{AttachRequired: pointer.BoolPtr(attachRequired)}}
+	}
+	cases := map[string]equalityCase{
+		"priorityclass equal": {
+			check: func(e *vcEquality) bool {
+				return e.CheckPriorityClassEquality(priorityClass(2000), priorityClass(2000)) != nil
+			},
+		},
+		"priorityclass value changed": {
+			check: func(e *vcEquality) bool {
+				return e.CheckPriorityClassEquality(priorityClass(2000), priorityClass(1000)) != nil
+			},
+			expectUpdated: true,
+		},
+		"pv spec equal": {
+			check: func(e *vcEquality) bool {
+				return e.CheckPVSpecEquality(pvSpec("1Gi"), pvSpec("1Gi")) != nil
+			},
+		},
+		"pv spec expanded": {
+			check: func(e *vcEquality) bool {
+				return e.CheckPVSpecEquality(pvSpec("2Gi"), pvSpec("1Gi")) != nil
+			},
+			expectUpdated: true,
+		},
+		"csidriver spec changed": {
+			check: func(e *vcEquality) bool {
+				return e.CheckCSIDriverEquality(csiDriver(true), csiDriver(false)) != nil
+			},
+			expectUpdated: true,
+		},
+	}
+
+	// the checks compared regardless of the virtual cluster give the same results with or without one.
+	for name, newEquality := range map[string]func() *vcEquality{
+		"config":          func() *vcEquality { return ConfigEquality(syncerConfig) },
+		"virtual cluster": func() *vcEquality { return Equality(syncerConfig, vc) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			runEqualityCases(t, newEquality, cases)
+		})
+	}
+}
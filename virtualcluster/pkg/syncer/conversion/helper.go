@@ -23,14 +23,19 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	nodev1 "k8s.io/api/node/v1"
 	v1scheduling "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -38,6 +43,8 @@ import (
 	listersv1 "k8s.io/client-go/listers/core/v1"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	snapshotv1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/snapshot/v1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -64,6 +71,12 @@ func ToSuperMasterNamespace(cluster, ns string) string {
 	return targetNamespace
 }
 
+// ToSuperMasterClusterScopedName returns the name of the cluster scoped super master object populated from a tenant
+// master object, which is unique across tenants in the same way as the super master namespaces.
+func ToSuperMasterClusterScopedName(cluster, name string) string {
+	return ToSuperMasterNamespace(cluster, name)
+}
+
 // GetVirtualNamespace is used to find the corresponding namespace in tenant master for objects created in super master originally, e.g., events.
 func GetVirtualNamespace(nsLister listersv1.NamespaceLister, pNamespace string) (cluster, namespace string, err error) {
 	vcInfo, err := nsLister.Get(pNamespace)
@@ -185,6 +198,16 @@ func BuildSuperMasterNamespace(cluster, vcName, vcNamespace, vcUID string, obj c
 	anno[constants.LabelVCUID] = vcUID
 	m.SetAnnotations(anno)
 
+	// The owner labels allow super master selectors, e.g., the namespace selectors of the tenant webhooks,
+	// to select the namespaces of a tenant.
+	labels := m.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[constants.LabelVCName] = vcName
+	labels[constants.LabelVCNamespace] = vcNamespace
+	m.SetLabels(labels)
+
 	ResetMetadata(m)
 
 	targetName := ToSuperMasterNamespace(cluster, m.GetName())
@@ -204,6 +227,30 @@ func ResetMetadata(obj client.Object) {
 	obj.SetClusterName("")
 }
 
+// superMasterAnnotations are the annotations only meaningful in super master, hence they are not
+// populated to tenant masters.
+var superMasterAnnotations = []string{v1.LastAppliedConfigAnnotation}
+
+// BuildVirtualObject returns a copy of the cluster scoped super master object which is ready to be
+// created in or updated to tenant masters. The fields populated by super master apiserver, e.g.,
+// uid, resourceVersion, managedFields and creationTimestamp, are stripped.
+func BuildVirtualObject(pObj client.Object) client.Object {
+	vObj := pObj.DeepCopyObject().(client.Object)
+	ResetMetadata(vObj)
+	vObj.SetManagedFields(nil)
+	vObj.SetCreationTimestamp(metav1.Time{})
+	if anno := vObj.GetAnnotations(); len(anno) > 0 {
+		for _, key := range superMasterAnnotations {
+			delete(anno, key)
+		}
+		if len(anno) == 0 {
+			anno = nil
+		}
+		vObj.SetAnnotations(anno)
+	}
+	return vObj
+}
+
 func BuildVirtualEvent(cluster string, pEvent *v1.Event, vObj client.Object) *v1.Event {
 	vEvent := pEvent.DeepCopy()
 	ResetMetadata(vEvent)
@@ -219,21 +266,309 @@ func BuildVirtualEvent(cluster string, pEvent *v1.Event, vObj client.Object) *v1
 }
 
 func BuildVirtualStorageClass(cluster string, pStorageClass *storagev1.StorageClass) *storagev1.StorageClass {
-	vStorageClass := pStorageClass.DeepCopy()
-	ResetMetadata(vStorageClass)
+	vStorageClass := BuildVirtualObject(pStorageClass).(*storagev1.StorageClass)
+	SetSyncerManaged(vStorageClass)
 	return vStorageClass
 }
 
+// SetSyncerManaged marks the tenant master object as populated from super master by syncer.
+func SetSyncerManaged(vObj client.Object) {
+	labels := vObj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[constants.LabelManagedBy] = constants.ManagedBySyncer
+	vObj.SetLabels(labels)
+}
+
+// IsSyncerManaged returns true if the tenant master object is populated from super master by syncer.
+// Objects created by tenants themselves are not managed and must not be removed by syncer.
+func IsSyncerManaged(vObj client.Object) bool {
+	return vObj.GetLabels()[constants.LabelManagedBy] == constants.ManagedBySyncer
+}
+
+// IsProtected returns true if the tenant master object is protected by the tenant, syncer never deletes it
+// even if it is an orphan.
+func IsProtected(vObj client.Object) bool {
+	return vObj.GetAnnotations()[constants.AnnotationProtected] == "true"
+}
+
+// MirrorOnly returns true if the tenant copy of the super master object is a read-only mirror, i.e., syncer only
+// ensures it exists and never reconciles its fields.
+func MirrorOnly(pObj client.Object) bool {
+	return pObj.GetAnnotations()[constants.AnnotationMirrorOnly] == "true"
+}
+
+// SetOwnerReference stamps the owner reference onto the tenant object. It replaces the reference to the object of
+// the same API version, kind and name, e.g., the one recreated with a new UID, and keeps the other references.
+func SetOwnerReference(vObj client.Object, owner metav1.OwnerReference) {
+	refs := vObj.GetOwnerReferences()
+	for i := range refs {
+		if sameOwner(refs[i], owner) {
+			refs[i] = owner
+			vObj.SetOwnerReferences(refs)
+			return
+		}
+	}
+	vObj.SetOwnerReferences(append(refs, owner))
+}
+
+// HasOwnerReference returns true if the tenant object carries exactly the owner reference.
+func HasOwnerReference(vObj client.Object, owner metav1.OwnerReference) bool {
+	for _, ref := range vObj.GetOwnerReferences() {
+		if sameOwner(ref, owner) {
+			return equality.Semantic.DeepEqual(ref, owner)
+		}
+	}
+	return false
+}
+
+func sameOwner(a, b metav1.OwnerReference) bool {
+	return a.APIVersion == b.APIVersion && a.Kind == b.Kind && a.Name == b.Name
+}
+
+// SetTenantDefaultStorageClass overrides the default class annotation of the tenant storageclass if the
+// VirtualCluster names its default storageclass. The named storageclass becomes the only default one in the tenant
+// master, regardless of which storageclass is the default in super master.
+func SetTenantDefaultStorageClass(vc *v1alpha1.VirtualCluster, vStorageClass *storagev1.StorageClass) {
+	value, overridden := tenantDefaultClassValue(vc, constants.LabelTenantDefaultStorageClass, vStorageClass.Name)
+	if !overridden {
+		return
+	}
+	anno := vStorageClass.GetAnnotations()
+	if anno == nil {
+		anno = make(map[string]string)
+	}
+	anno[constants.AnnotationIsDefaultStorageClass] = value
+	vStorageClass.SetAnnotations(anno)
+}
+
+// DeletionPropagationPolicy returns the propagation policy the tenant object is deleted with by syncer, i.e., the
+// one named by its AnnotationDeletionPropagationPolicy annotation or defaultPolicy if it is not annotated. The
+// defaultPolicy is returned along with an error if the annotation is not a known propagation policy.
+func DeletionPropagationPolicy(obj metav1.Object, defaultPolicy metav1.DeletionPropagation) (metav1.DeletionPropagation, error) {
+	value, exists := obj.GetAnnotations()[constants.AnnotationDeletionPropagationPolicy]
+	if !exists {
+		return defaultPolicy, nil
+	}
+	switch policy := metav1.DeletionPropagation(value); policy {
+	case metav1.DeletePropagationOrphan, metav1.DeletePropagationBackground, metav1.DeletePropagationForeground:
+		return policy, nil
+	default:
+		return defaultPolicy, fmt.Errorf("unknown propagation policy %q in annotation %s", value, constants.AnnotationDeletionPropagationPolicy)
+	}
+}
+
+// TenantPatrolWriteLimit returns the qps and burst of the writes the patrollers issue to the tenant master of the
+// VirtualCluster, i.e., the ones set by its LabelTenantPatrolWriteQPS and LabelTenantPatrolWriteBurst annotations,
+// or the given ones if it is not annotated. A qps of 0 leaves the writes unthrottled. The given qps and burst are
+// returned along with an error if either annotation is invalid.
+func TenantPatrolWriteLimit(vc *v1alpha1.VirtualCluster, qps float32, burst int) (float32, int, error) {
+	annotations := vc.GetAnnotations()
+	tenantQPS, tenantBurst := qps, burst
+	if value, exists := annotations[constants.LabelTenantPatrolWriteQPS]; exists {
+		parsed, err := strconv.ParseFloat(value, 32)
+		if err != nil || parsed < 0 {
+			return qps, burst, fmt.Errorf("invalid qps %q in annotation %s", value, constants.LabelTenantPatrolWriteQPS)
+		}
+		tenantQPS = float32(parsed)
+	}
+	if value, exists := annotations[constants.LabelTenantPatrolWriteBurst]; exists {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return qps, burst, fmt.Errorf("invalid burst %q in annotation %s", value, constants.LabelTenantPatrolWriteBurst)
+		}
+		tenantBurst = parsed
+	}
+	return tenantQPS, tenantBurst, nil
+}
+
+// TenantStorageClassParameterOverrides returns the storageclass parameter overrides of the VirtualCluster set by the
+// LabelTenantStorageClassParameters annotation, keyed by storageclass name. It returns an error if the annotation
+// is not a JSON object of parameters keyed by storageclass name, or a storageclass name or parameter is empty.
+func TenantStorageClassParameterOverrides(vc *v1alpha1.VirtualCluster) (map[string]map[string]string, error) {
+	value, exists := vc.GetAnnotations()[constants.LabelTenantStorageClassParameters]
+	if !exists {
+		return nil, nil
+	}
+	overrides := make(map[string]map[string]string)
+	if err := json.Unmarshal([]byte(value), &overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode storageclass parameters from annotation %s: %v", constants.LabelTenantStorageClassParameters, err)
+	}
+	for name, parameters := range overrides {
+		if name == "" {
+			return nil, fmt.Errorf("annotation %s overrides the parameters of a storageclass without name", constants.LabelTenantStorageClassParameters)
+		}
+		if _, exists := parameters[""]; exists {
+			return nil, fmt.Errorf("annotation %s overrides a parameter without name of storageclass %s", constants.LabelTenantStorageClassParameters, name)
+		}
+	}
+	return overrides, nil
+}
+
+// TenantStorageClassParameters returns the parameters of the tenant copy of the named public storageclass, i.e.,
+// the super master parameters patched by the overrides of the VirtualCluster. The super master parameters are
+// left untouched, and they are used as is if the overrides of the VirtualCluster are invalid.
+func TenantStorageClassParameters(vc *v1alpha1.VirtualCluster, name string, parameters map[string]string) map[string]string {
+	if vc == nil {
+		return parameters
+	}
+	overrides, err := TenantStorageClassParameterOverrides(vc)
+	if err != nil || len(overrides[name]) == 0 {
+		return parameters
+	}
+	patched := make(map[string]string, len(parameters)+len(overrides[name]))
+	for k, v := range parameters {
+		patched[k] = v
+	}
+	for k, v := range overrides[name] {
+		if v == "" {
+			delete(patched, k)
+			continue
+		}
+		patched[k] = v
+	}
+	return patched
+}
+
+// TenantAllowedTopologies returns the allowedTopologies of the tenant copy of a public super master storageclass
+// according to StorageClassTopologyPolicy. The super master topologies are left untouched.
+func TenantAllowedTopologies(syncerConfig *config.SyncerConfiguration, topologies []v1.TopologySelectorTerm) []v1.TopologySelectorTerm {
+	if len(topologies) == 0 {
+		return nil
+	}
+	policy := config.TopologyKeep
+	if syncerConfig != nil && syncerConfig.StorageClassTopologyPolicy != "" {
+		policy = syncerConfig.StorageClassTopologyPolicy
+	}
+	switch policy {
+	case config.TopologyDrop:
+		return nil
+	case config.TopologyRewrite:
+		var rewritten []v1.TopologySelectorTerm
+		for _, term := range topologies {
+			var expressions []v1.TopologySelectorLabelRequirement
+			for _, expression := range term.MatchLabelExpressions {
+				key, ok := TranslateMetaKey(syncerConfig, expression.Key)
+				if !ok {
+					continue
+				}
+				expression := *expression.DeepCopy()
+				expression.Key = key
+				expressions = append(expressions, expression)
+			}
+			// a term whose expressions are all stripped would match any node, it is dropped instead.
+			if len(expressions) > 0 {
+				rewritten = append(rewritten, v1.TopologySelectorTerm{MatchLabelExpressions: expressions})
+			}
+		}
+		return rewritten
+	default:
+		copied := make([]v1.TopologySelectorTerm, 0, len(topologies))
+		for i := range topologies {
+			copied = append(copied, *topologies[i].DeepCopy())
+		}
+		return copied
+	}
+}
+
+// SuperTopologyStorageClass returns true if the tenant copy of the public super master storageclass is still bound
+// to the super cluster topology, i.e., it keeps allowedTopologies or delays binding until the first consumer is
+// scheduled, which may produce unschedulable tenant pvcs.
+func SuperTopologyStorageClass(syncerConfig *config.SyncerConfiguration, pStorageClass *storagev1.StorageClass) bool {
+	if len(TenantAllowedTopologies(syncerConfig, pStorageClass.AllowedTopologies)) > 0 {
+		return true
+	}
+	return pStorageClass.VolumeBindingMode != nil && *pStorageClass.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+}
+
+// tenantDefaultClassValue returns the expected default class annotation value of the named class in tenant
+// master, the default class is named by the VirtualCluster annotation vcKey. It returns false if the VirtualCluster
+// does not override the default class, in which case the annotation is owned by the tenant.
+func tenantDefaultClassValue(vc *v1alpha1.VirtualCluster, vcKey, name string) (string, bool) {
+	if vc == nil {
+		return "", false
+	}
+	defaultClass, exists := vc.GetAnnotations()[vcKey]
+	if !exists || defaultClass == "" {
+		return "", false
+	}
+	if defaultClass == name {
+		return "true", true
+	}
+	return "false", true
+}
+
+func BuildVirtualIngressClass(cluster string, pIngressClass *networkingv1.IngressClass) *networkingv1.IngressClass {
+	vIngressClass := BuildVirtualObject(pIngressClass).(*networkingv1.IngressClass)
+	SetSyncerManaged(vIngressClass)
+	return vIngressClass
+}
+
+// SetTenantDefaultIngressClass overrides the default class annotation of the tenant ingressclass if the
+// VirtualCluster names its default ingressclass, the same way as SetTenantDefaultStorageClass does.
+func SetTenantDefaultIngressClass(vc *v1alpha1.VirtualCluster, vIngressClass *networkingv1.IngressClass) {
+	value, overridden := tenantDefaultClassValue(vc, constants.LabelTenantDefaultIngressClass, vIngressClass.Name)
+	if !overridden {
+		return
+	}
+	anno := vIngressClass.GetAnnotations()
+	if anno == nil {
+		anno = make(map[string]string)
+	}
+	anno[constants.AnnotationIsDefaultIngressClass] = value
+	vIngressClass.SetAnnotations(anno)
+}
+
+func BuildVirtualRuntimeClass(cluster string, pRuntimeClass *nodev1.RuntimeClass) *nodev1.RuntimeClass {
+	vRuntimeClass := BuildVirtualObject(pRuntimeClass).(*nodev1.RuntimeClass)
+	SetSyncerManaged(vRuntimeClass)
+	return vRuntimeClass
+}
+
+func BuildVirtualCSIDriver(cluster string, pCSIDriver *storagev1.CSIDriver) *storagev1.CSIDriver {
+	return BuildVirtualObject(pCSIDriver).(*storagev1.CSIDriver)
+}
+
+func BuildVirtualVolumeSnapshotClass(cluster string, pVolumeSnapshotClass *snapshotv1.VolumeSnapshotClass) *snapshotv1.VolumeSnapshotClass {
+	return BuildVirtualObject(pVolumeSnapshotClass).(*snapshotv1.VolumeSnapshotClass)
+}
+
+// BuildVirtualEndpointSlice returns a copy of the super master endpointslice of pService which is ready to
+// be created in or updated to the tenant namespace of vService. The service name label is rewritten to the
+// tenant service name and the endpoint target references point to the tenant namespace.
+func BuildVirtualEndpointSlice(pSlice *discoveryv1.EndpointSlice, vService *v1.Service) *discoveryv1.EndpointSlice {
+	vSlice := BuildVirtualObject(pSlice).(*discoveryv1.EndpointSlice)
+	vSlice.Namespace = vService.Namespace
+	labels := vSlice.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[discoveryv1.LabelServiceName] = vService.Name
+	labels[discoveryv1.LabelManagedBy] = constants.EndpointSliceManagedBySyncer
+	vSlice.SetLabels(labels)
+	vSlice.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(vService, v1.SchemeGroupVersion.WithKind("Service"))}
+	for i := range vSlice.Endpoints {
+		if ref := vSlice.Endpoints[i].TargetRef; ref != nil {
+			ref.Namespace = vService.Namespace
+			ref.UID = ""
+			ref.ResourceVersion = ""
+		}
+	}
+	return vSlice
+}
+
+// IsSyncerManagedEndpointSlice returns true if the tenant master endpointslice is mirrored from super master by syncer.
+func IsSyncerManagedEndpointSlice(vSlice *discoveryv1.EndpointSlice) bool {
+	return vSlice.Labels[discoveryv1.LabelManagedBy] == constants.EndpointSliceManagedBySyncer
+}
+
 func BuildVirtualPriorityClass(cluster string, pPriorityClass *v1scheduling.PriorityClass) *v1scheduling.PriorityClass {
-	vPriorityClass := pPriorityClass.DeepCopy()
-	ResetMetadata(vPriorityClass)
-	return vPriorityClass
+	return BuildVirtualObject(pPriorityClass).(*v1scheduling.PriorityClass)
 }
 
 func BuildVirtualCRD(cluster string, pCRD *v1beta1.CustomResourceDefinition) *v1beta1.CustomResourceDefinition {
-	vCRD := pCRD.DeepCopy()
-	ResetMetadata(vCRD)
-	return vCRD
+	return BuildVirtualObject(pCRD).(*v1beta1.CustomResourceDefinition)
 }
 
 func BuildVirtualPersistentVolume(cluster, vcNS, vcName string, pPV *v1.PersistentVolume, vPVC *v1.PersistentVolumeClaim) *v1.PersistentVolume {
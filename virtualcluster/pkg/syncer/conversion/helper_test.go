@@ -4,10 +4,13 @@ import (
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 )
 
@@ -332,3 +335,285 @@ func TestIsControlPlaneService(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildVirtualObject(t *testing.T) {
+	now := metav1.Now()
+	for _, tt := range []struct {
+		name                string
+		annotations         map[string]string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name: "no annotations",
+		},
+		{
+			name:                "super master annotations are stripped",
+			annotations:         map[string]string{v1.LastAppliedConfigAnnotation: "{}", "a": "b"},
+			expectedAnnotations: map[string]string{"a": "b"},
+		},
+		{
+			name:        "only super master annotations",
+			annotations: map[string]string{v1.LastAppliedConfigAnnotation: "{}"},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			pObj := &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "sc",
+					UID:               "12345",
+					ResourceVersion:   "1",
+					CreationTimestamp: now,
+					ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+					Labels:            map[string]string{"foo": "bar"},
+					Annotations:       tt.annotations,
+				},
+				Provisioner: "a",
+			}
+			expected := &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "sc",
+					Labels:      map[string]string{"foo": "bar"},
+					Annotations: tt.expectedAnnotations,
+				},
+				Provisioner: "a",
+			}
+			vObj := BuildVirtualObject(pObj)
+			if !equality.Semantic.DeepEqual(vObj, expected) {
+				tc.Errorf("expected %+v, got %+v", expected, vObj)
+			}
+			if pObj.UID != "12345" || len(pObj.ManagedFields) != 1 {
+				tc.Errorf("super master object should not be modified, got %+v", pObj)
+			}
+		})
+	}
+}
+
+func TestTenantAllowedTopologies(t *testing.T) {
+	term := func(keys ...string) v1.TopologySelectorTerm {
+		var expressions []v1.TopologySelectorLabelRequirement
+		for _, key := range keys {
+			expressions = append(expressions, v1.TopologySelectorLabelRequirement{Key: key, Values: []string{"a"}})
+		}
+		return v1.TopologySelectorTerm{MatchLabelExpressions: expressions}
+	}
+	topologies := []v1.TopologySelectorTerm{
+		term("topology.kubernetes.io/zone", "super.io/rack"),
+		term("super.io/rack"),
+	}
+	translations := map[string]string{"super.io/rack": "", "topology.kubernetes.io/": "tenant.io/"}
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+
+	for _, tt := range []struct {
+		name          string
+		config        *config.SyncerConfiguration
+		bindingMode   *storagev1.VolumeBindingMode
+		expected      []v1.TopologySelectorTerm
+		superTopology bool
+	}{
+		{
+			name:          "nil config keeps topologies",
+			expected:      topologies,
+			superTopology: true,
+		},
+		{
+			name:          "keep",
+			config:        &config.SyncerConfiguration{StorageClassTopologyPolicy: config.TopologyKeep},
+			expected:      topologies,
+			superTopology: true,
+		},
+		{
+			name:          "rewrite",
+			config:        &config.SyncerConfiguration{StorageClassTopologyPolicy: config.TopologyRewrite, MetaKeyTranslations: translations},
+			expected:      []v1.TopologySelectorTerm{term("tenant.io/zone")},
+			superTopology: true,
+		},
+		{
+			name:   "drop",
+			config: &config.SyncerConfiguration{StorageClassTopologyPolicy: config.TopologyDrop},
+		},
+		{
+			name:          "drop keeps wait for first consumer",
+			config:        &config.SyncerConfiguration{StorageClassTopologyPolicy: config.TopologyDrop},
+			bindingMode:   &waitForFirstConsumer,
+			superTopology: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			pStorageClass := &storagev1.StorageClass{
+				ObjectMeta:        metav1.ObjectMeta{Name: "sc"},
+				AllowedTopologies: topologies,
+				VolumeBindingMode: tt.bindingMode,
+			}
+			if got := TenantAllowedTopologies(tt.config, pStorageClass.AllowedTopologies); !equality.Semantic.DeepEqual(got, tt.expected) {
+				tc.Errorf("expected topologies %+v, got %+v", tt.expected, got)
+			}
+			if got := SuperTopologyStorageClass(tt.config, pStorageClass); got != tt.superTopology {
+				tc.Errorf("expected super topology %v, got %v", tt.superTopology, got)
+			}
+			if pStorageClass.AllowedTopologies[0].MatchLabelExpressions[0].Key != "topology.kubernetes.io/zone" {
+				tc.Errorf("super master topologies should not be modified, got %+v", pStorageClass.AllowedTopologies)
+			}
+		})
+	}
+}
+
+func TestTenantStorageClassParameterOverrides(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		annotations map[string]string
+		expected    map[string]map[string]string
+		expectedErr bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:        "valid",
+			annotations: map[string]string{constants.LabelTenantStorageClassParameters: `{"encrypted":{"kmsKeyId":"tenant-key","fsType":""}}`},
+			expected:    map[string]map[string]string{"encrypted": {"kmsKeyId": "tenant-key", "fsType": ""}},
+		},
+		{
+			name:        "not json",
+			annotations: map[string]string{constants.LabelTenantStorageClassParameters: `encrypted=kmsKeyId`},
+			expectedErr: true,
+		},
+		{
+			name:        "parameters not a map",
+			annotations: map[string]string{constants.LabelTenantStorageClassParameters: `{"encrypted":["kmsKeyId"]}`},
+			expectedErr: true,
+		},
+		{
+			name:        "storageclass without name",
+			annotations: map[string]string{constants.LabelTenantStorageClassParameters: `{"":{"kmsKeyId":"tenant-key"}}`},
+			expectedErr: true,
+		},
+		{
+			name:        "parameter without name",
+			annotations: map[string]string{constants.LabelTenantStorageClassParameters: `{"encrypted":{"":"tenant-key"}}`},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vc := &v1alpha1.VirtualCluster{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			overrides, err := TenantStorageClassParameterOverrides(vc)
+			if (err != nil) != tt.expectedErr {
+				tc.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if !equality.Semantic.DeepEqual(overrides, tt.expected) {
+				tc.Errorf("expected overrides %v, got %v", tt.expected, overrides)
+			}
+		})
+	}
+}
+
+func TestDeletionPropagationPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		annotations map[string]string
+		expected    metav1.DeletionPropagation
+		expectedErr bool
+	}{
+		{
+			name:     "no annotation",
+			expected: metav1.DeletePropagationBackground,
+		},
+		{
+			name:        "orphan",
+			annotations: map[string]string{constants.AnnotationDeletionPropagationPolicy: "Orphan"},
+			expected:    metav1.DeletePropagationOrphan,
+		},
+		{
+			name:        "background",
+			annotations: map[string]string{constants.AnnotationDeletionPropagationPolicy: "Background"},
+			expected:    metav1.DeletePropagationBackground,
+		},
+		{
+			name:        "foreground",
+			annotations: map[string]string{constants.AnnotationDeletionPropagationPolicy: "Foreground"},
+			expected:    metav1.DeletePropagationForeground,
+		},
+		{
+			name:        "wrong case",
+			annotations: map[string]string{constants.AnnotationDeletionPropagationPolicy: "foreground"},
+			expected:    metav1.DeletePropagationBackground,
+			expectedErr: true,
+		},
+		{
+			name:        "empty",
+			annotations: map[string]string{constants.AnnotationDeletionPropagationPolicy: ""},
+			expected:    metav1.DeletePropagationBackground,
+			expectedErr: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			obj := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			policy, err := DeletionPropagationPolicy(obj, metav1.DeletePropagationBackground)
+			if (err != nil) != tt.expectedErr {
+				tc.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if policy != tt.expected {
+				tc.Errorf("expected policy %s, got %s", tt.expected, policy)
+			}
+		})
+	}
+}
+
+func TestTenantPatrolWriteLimit(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		annotations   map[string]string
+		expectedQPS   float32
+		expectedBurst int
+		expectedErr   bool
+	}{
+		{
+			name:          "no annotation",
+			expectedQPS:   5,
+			expectedBurst: 10,
+		},
+		{
+			name:          "qps and burst",
+			annotations:   map[string]string{constants.LabelTenantPatrolWriteQPS: "0.5", constants.LabelTenantPatrolWriteBurst: "2"},
+			expectedQPS:   0.5,
+			expectedBurst: 2,
+		},
+		{
+			name:          "unthrottled",
+			annotations:   map[string]string{constants.LabelTenantPatrolWriteQPS: "0"},
+			expectedQPS:   0,
+			expectedBurst: 10,
+		},
+		{
+			name:          "invalid qps",
+			annotations:   map[string]string{constants.LabelTenantPatrolWriteQPS: "fast", constants.LabelTenantPatrolWriteBurst: "2"},
+			expectedQPS:   5,
+			expectedBurst: 10,
+			expectedErr:   true,
+		},
+		{
+			name:          "negative qps",
+			annotations:   map[string]string{constants.LabelTenantPatrolWriteQPS: "-1"},
+			expectedQPS:   5,
+			expectedBurst: 10,
+			expectedErr:   true,
+		},
+		{
+			name:          "zero burst",
+			annotations:   map[string]string{constants.LabelTenantPatrolWriteQPS: "1", constants.LabelTenantPatrolWriteBurst: "0"},
+			expectedQPS:   5,
+			expectedBurst: 10,
+			expectedErr:   true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vc := &v1alpha1.VirtualCluster{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			qps, burst, err := TenantPatrolWriteLimit(vc, 5, 10)
+			if (err != nil) != tt.expectedErr {
+				tc.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if qps != tt.expectedQPS || burst != tt.expectedBurst {
+				tc.Errorf("expected qps %v and burst %d, got %v and %d", tt.expectedQPS, tt.expectedBurst, qps, burst)
+			}
+		})
+	}
+}
@@ -21,7 +21,10 @@ import (
 	"fmt"
 	"strings"
 
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 	"k8s.io/utils/pointer"
@@ -94,6 +97,117 @@ func mutatePodAffinityTerms(terms []v1.PodAffinityTerm, clusterName string) {
 	}
 }
 
+// MutateNetworkPolicySpec makes sure the peers of the NetworkPolicy are selected within tenant scope.
+// Peers selecting namespaces are limited to the pods of the tenant, and the namespace names used in the
+// namespace selectors are converted to super master namespaces.
+func MutateNetworkPolicySpec(spec *networkingv1.NetworkPolicySpec, clusterName string) {
+	for i := range spec.Ingress {
+		mutateNetworkPolicyPeers(spec.Ingress[i].From, clusterName)
+	}
+	for i := range spec.Egress {
+		mutateNetworkPolicyPeers(spec.Egress[i].To, clusterName)
+	}
+}
+
+func mutateNetworkPolicyPeers(peers []networkingv1.NetworkPolicyPeer, clusterName string) {
+	for i, each := range peers {
+		if each.NamespaceSelector == nil {
+			continue
+		}
+		if name, exists := each.NamespaceSelector.MatchLabels[v1.LabelMetadataName]; exists {
+			peers[i].NamespaceSelector.MatchLabels[v1.LabelMetadataName] = ToSuperMasterNamespace(clusterName, name)
+		}
+		for j, expr := range each.NamespaceSelector.MatchExpressions {
+			if expr.Key != v1.LabelMetadataName {
+				continue
+			}
+			for k, name := range expr.Values {
+				peers[i].NamespaceSelector.MatchExpressions[j].Values[k] = ToSuperMasterNamespace(clusterName, name)
+			}
+		}
+
+		// Pods of all tenants share the same super master, hence only the pods of this tenant are selected.
+		if each.PodSelector == nil {
+			peers[i].PodSelector = &metav1.LabelSelector{}
+		}
+		if peers[i].PodSelector.MatchLabels == nil {
+			peers[i].PodSelector.MatchLabels = make(map[string]string)
+		}
+		peers[i].PodSelector.MatchLabels[constants.LabelCluster] = clusterName
+	}
+}
+
+// MutateMutatingWebhooks makes sure the tenant mutating webhooks only intercept the objects of the tenant.
+func MutateMutatingWebhooks(webhooks []admissionregistrationv1.MutatingWebhook, clusterName, vcName, vcNamespace string) {
+	for i := range webhooks {
+		mutateWebhookClientConfig(&webhooks[i].ClientConfig, clusterName)
+		mutateWebhookRules(webhooks[i].Rules)
+		webhooks[i].NamespaceSelector = mutateWebhookNamespaceSelector(webhooks[i].NamespaceSelector, clusterName, vcName, vcNamespace)
+	}
+}
+
+// MutateValidatingWebhooks makes sure the tenant validating webhooks only intercept the objects of the tenant.
+func MutateValidatingWebhooks(webhooks []admissionregistrationv1.ValidatingWebhook, clusterName, vcName, vcNamespace string) {
+	for i := range webhooks {
+		mutateWebhookClientConfig(&webhooks[i].ClientConfig, clusterName)
+		mutateWebhookRules(webhooks[i].Rules)
+		webhooks[i].NamespaceSelector = mutateWebhookNamespaceSelector(webhooks[i].NamespaceSelector, clusterName, vcName, vcNamespace)
+	}
+}
+
+// mutateWebhookClientConfig points the webhook to the tenant service synced to super master.
+func mutateWebhookClientConfig(clientConfig *admissionregistrationv1.WebhookClientConfig, clusterName string) {
+	if clientConfig.Service != nil {
+		clientConfig.Service.Namespace = ToSuperMasterNamespace(clusterName, clientConfig.Service.Namespace)
+	}
+}
+
+// mutateWebhookRules limits the rules to namespaced resources, the cluster scoped super master objects do not
+// belong to any tenant and the namespace selector does not apply to them.
+func mutateWebhookRules(rules []admissionregistrationv1.RuleWithOperations) {
+	for i := range rules {
+		scope := admissionregistrationv1.NamespacedScope
+		rules[i].Scope = &scope
+	}
+}
+
+// mutateWebhookNamespaceSelector limits the namespace selector to the super master namespaces of the tenant, and
+// the namespace names used in the selector are converted to super master namespaces.
+func mutateWebhookNamespaceSelector(selector *metav1.LabelSelector, clusterName, vcName, vcNamespace string) *metav1.LabelSelector {
+	if selector == nil {
+		selector = &metav1.LabelSelector{}
+	}
+	if selector.MatchLabels == nil {
+		selector.MatchLabels = make(map[string]string)
+	}
+	if name, exists := selector.MatchLabels[v1.LabelMetadataName]; exists {
+		selector.MatchLabels[v1.LabelMetadataName] = ToSuperMasterNamespace(clusterName, name)
+	}
+	for j, expr := range selector.MatchExpressions {
+		if expr.Key != v1.LabelMetadataName {
+			continue
+		}
+		for k, name := range expr.Values {
+			selector.MatchExpressions[j].Values[k] = ToSuperMasterNamespace(clusterName, name)
+		}
+	}
+	selector.MatchLabels[constants.LabelVCName] = vcName
+	selector.MatchLabels[constants.LabelVCNamespace] = vcNamespace
+	return selector
+}
+
+// MutatePodDisruptionBudgetSpec makes sure the PodDisruptionBudget only selects the pods of the tenant.
+func MutatePodDisruptionBudgetSpec(spec *policyv1.PodDisruptionBudgetSpec, clusterName string) {
+	// A nil selector selects no pods, hence it is kept as is.
+	if spec.Selector == nil {
+		return
+	}
+	if spec.Selector.MatchLabels == nil {
+		spec.Selector.MatchLabels = make(map[string]string)
+	}
+	spec.Selector.MatchLabels[constants.LabelCluster] = clusterName
+}
+
 func mutateWeightedPodAffinityTerms(weightedTerms []v1.WeightedPodAffinityTerm, clusterName string) {
 	for i, each := range weightedTerms {
 		if each.PodAffinityTerm.LabelSelector != nil {
@@ -406,6 +520,24 @@ func PodMutateServiceLink(disableServiceLinks bool) PodMutator {
 	}
 }
 
+// PodMutatePriority leaves the priority of the super master pod to the super master priority admission, which
+// rejects a pod whose priority differs from the value of its priorityclass in super master. The pods whose tenant
+// priority exceeds maxTenantPriority lose their priorityclass, hence they get the default priority of super master
+// rather than preempting super master critical workloads. The priority is kept as is if maxTenantPriority is 0.
+func PodMutatePriority(vPod *v1.Pod, maxTenantPriority int32) PodMutator {
+	return func(p *podMutateCtx) error {
+		if maxTenantPriority == 0 {
+			return nil
+		}
+		p.pPod.Spec.Priority = nil
+		p.pPod.Spec.PreemptionPolicy = nil
+		if vPod.Spec.Priority != nil && *vPod.Spec.Priority > maxTenantPriority {
+			p.pPod.Spec.PriorityClassName = ""
+		}
+		return nil
+	}
+}
+
 type ServiceMutateInterface interface {
 	Mutate(vService *v1.Service)
 }
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+// TranslateMetaKey returns the tenant master key of a super master label/annotation key according to
+// MetaKeyTranslations. An exact key takes precedence over the longest matching prefix, i.e., a key ending
+// with "/". It returns false if the key is stripped from tenant masters.
+func TranslateMetaKey(syncerConfig *config.SyncerConfiguration, key string) (string, bool) {
+	if syncerConfig == nil || len(syncerConfig.MetaKeyTranslations) == 0 {
+		return key, true
+	}
+	if to, ok := syncerConfig.MetaKeyTranslations[key]; ok {
+		return to, to != ""
+	}
+	matched := ""
+	for from := range syncerConfig.MetaKeyTranslations {
+		if strings.HasSuffix(from, "/") && strings.HasPrefix(key, from) && len(from) > len(matched) {
+			matched = from
+		}
+	}
+	if matched == "" {
+		return key, true
+	}
+	to := syncerConfig.MetaKeyTranslations[matched]
+	if to == "" {
+		return "", false
+	}
+	return to + strings.TrimPrefix(key, matched), true
+}
+
+// TranslateMetaKeys returns the labels/annotations of a super master object as seen by tenant masters.
+func TranslateMetaKeys(syncerConfig *config.SyncerConfiguration, kv map[string]string) map[string]string {
+	if kv == nil || syncerConfig == nil || len(syncerConfig.MetaKeyTranslations) == 0 {
+		return kv
+	}
+	translated := make(map[string]string, len(kv))
+	for k, v := range kv {
+		if tk, ok := TranslateMetaKey(syncerConfig, k); ok {
+			translated[tk] = v
+		}
+	}
+	return translated
+}
+
+// TranslateObjectMeta rewrites the labels and annotations of a tenant object built from a super master object.
+func TranslateObjectMeta(syncerConfig *config.SyncerConfiguration, vObj client.Object) {
+	vObj.SetLabels(TranslateMetaKeys(syncerConfig, vObj.GetLabels()))
+	vObj.SetAnnotations(TranslateMetaKeys(syncerConfig, vObj.GetAnnotations()))
+}
+
+// isTranslatedKey returns true if the key is rewritten by MetaKeyTranslations, or is the result of a rewrite.
+// Such keys are owned by super master and are ignored when the metadata owned by tenants is compared.
+func isTranslatedKey(syncerConfig *config.SyncerConfiguration, key string) bool {
+	if syncerConfig == nil {
+		return false
+	}
+	for from, to := range syncerConfig.MetaKeyTranslations {
+		if strings.HasSuffix(from, "/") {
+			if strings.HasPrefix(key, from) || (to != "" && strings.HasPrefix(key, to)) {
+				return true
+			}
+			continue
+		}
+		if key == from || (to != "" && key == to) {
+			return true
+		}
+	}
+	return false
+}
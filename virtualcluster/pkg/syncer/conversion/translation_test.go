@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+func TestTranslateMetaKeys(t *testing.T) {
+	syncerConfig := &config.SyncerConfiguration{
+		MetaKeyTranslations: map[string]string{
+			"topology.kubernetes.io/":            "",
+			"cloud.io/":                          "tenant.cloud.io/",
+			"cloud.io/zone":                      "zone",
+			"failure-domain.beta.kubernetes.io/": "",
+		},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		config   *config.SyncerConfiguration
+		kv       map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "no translations",
+			config:   &config.SyncerConfiguration{},
+			kv:       map[string]string{"topology.kubernetes.io/zone": "a"},
+			expected: map[string]string{"topology.kubernetes.io/zone": "a"},
+		},
+		{
+			name:     "prefix stripped",
+			config:   syncerConfig,
+			kv:       map[string]string{"topology.kubernetes.io/zone": "a", "app": "b"},
+			expected: map[string]string{"app": "b"},
+		},
+		{
+			name:     "prefix rewritten",
+			config:   syncerConfig,
+			kv:       map[string]string{"cloud.io/region": "r"},
+			expected: map[string]string{"tenant.cloud.io/region": "r"},
+		},
+		{
+			name:     "exact key takes precedence",
+			config:   syncerConfig,
+			kv:       map[string]string{"cloud.io/zone": "z"},
+			expected: map[string]string{"zone": "z"},
+		},
+		{
+			name:   "nil",
+			config: syncerConfig,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			if got := TranslateMetaKeys(tt.config, tt.kv); !reflect.DeepEqual(got, tt.expected) {
+				tc.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -17,18 +17,24 @@ limitations under the License.
 package manager
 
 import (
+	"context"
 	"sync"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
 
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -43,6 +49,71 @@ type ResourceSyncerOptions struct {
 	UWOptions     *uw.Options
 	PatrolOptions *pa.Options
 	IsFake        bool
+	// PatrollerDryRun makes the patroller only report the inconsistencies it finds
+	// without deleting or requeuing any object.
+	PatrollerDryRun bool
+	// PatrolConcurrency bounds the number of tenant clusters checked in parallel by the patroller.
+	PatrolConcurrency int
+	// PatrolOpTimeout is the timeout of each tenant operation issued by the patroller.
+	PatrolOpTimeout time.Duration
+	// PatrolListChunkSize is the number of super master objects the patroller fetches from the informer cache at a time.
+	// constants.DefaultPatrolListChunkSize is used if it is 0.
+	PatrolListChunkSize int
+	// OrphanTTL is the grace period before the patroller deletes an orphan tenant object.
+	// Orphans are deleted in the first patrol pass that observes them if it is zero.
+	OrphanTTL time.Duration
+	// PatrolOnRelist indicates that the patroller runs immediately when the super master informer relists.
+	PatrolOnRelist bool
+	// PVReclaimGracePeriod is how long a super master PV with the Delete reclaim policy may stay released after
+	// its tenant PVC is deleted before the patroller reports it as leaked. constants.DefaultPVReclaimGracePeriod
+	// is used if it is zero.
+	PVReclaimGracePeriod time.Duration
+	// ConflictPolicy decides which side wins when the patroller finds a tenant object inconsistent
+	// with its super master counterpart. SuperWins is used if it is empty.
+	ConflictPolicy ConflictPolicy
+	// MaxDeletePercentPerPass is the max percentage of the syncer managed objects of a tenant cluster the
+	// patroller deletes in a single pass, a pass beyond it is aborted since it most likely results from a
+	// broken super master cache. constants.DefaultMaxDeletePercentPerPass is used if it is zero, and the
+	// limit is disabled if it is 100 or more.
+	MaxDeletePercentPerPass int
+	// DeletionPropagationPolicy is the propagation policy of the tenant objects deleted by the resource syncer,
+	// e.g., Foreground for the objects whose dependents must be removed first. constants.DefaultDeletionPolicy
+	// is used if it is empty.
+	DeletionPropagationPolicy metav1.DeletionPropagation
+	// StuckFinalizerGracePeriod is how long a syncer managed tenant object may stay terminating before the
+	// patroller force removes its finalizers. The finalizers are never removed if it is zero.
+	StuckFinalizerGracePeriod time.Duration
+	// AuditSink receives the remediations taken by the patroller. If it is nil, they are logged if
+	// CheckerAuditLog is set in the syncer configuration and dropped otherwise.
+	AuditSink pa.AuditSink
+	// ExtraSuperClusters are the super masters, other than the one the resource syncer is created with, the
+	// tenants may be mapped to by the constants.LabelTenantSuperCluster annotation, keyed by super cluster ID.
+	ExtraSuperClusters map[string]plugin.SuperCluster
+}
+
+// ConflictPolicy is the policy used by the patroller to resolve inconsistent objects.
+type ConflictPolicy string
+
+const (
+	// SuperWins makes the patroller overwrite the tenant object with the super master one.
+	SuperWins ConflictPolicy = "SuperWins"
+	// TenantWins makes the patroller keep the tenant changes.
+	TenantWins ConflictPolicy = "TenantWins"
+	// DiffOnly makes the patroller only report the inconsistencies without any remediation.
+	DiffOnly ConflictPolicy = "DiffOnly"
+)
+
+// ResourceSyncerOptionsFromConfig returns the resource syncer options set by the syncer configuration.
+func ResourceSyncerOptionsFromConfig(config *config.SyncerConfiguration) ResourceSyncerOptions {
+	return ResourceSyncerOptions{
+		PatrollerDryRun:         config.PatrollerDryRun,
+		PatrolConcurrency:       config.PatrolConcurrency,
+		PatrolOpTimeout:         config.PatrolOpTimeout,
+		OrphanTTL:               config.OrphanTTL,
+		PatrolOnRelist:          config.PatrolOnRelist,
+		MaxDeletePercentPerPass: config.MaxDeletePercentPerPass,
+		PVReclaimGracePeriod:    config.PVReclaimGracePeriod,
+	}
 }
 
 func New() *ControllerManager {
@@ -62,6 +133,26 @@ type ResourceSyncer interface {
 	StartPatrol(stopCh <-chan struct{}) error
 }
 
+// PatrolTrigger is implemented by the resource syncers whose patroller can be run on demand.
+type PatrolTrigger interface {
+	// TriggerPatrolNow runs a single patrol round and returns the number of mismatched objects it found.
+	TriggerPatrolNow(ctx context.Context) (uint64, error)
+}
+
+// PatrolHealthChecker is implemented by the resource syncers whose patroller liveness can be probed.
+type PatrolHealthChecker interface {
+	// Healthy returns false if the patroller has not completed a successful round recently.
+	Healthy() bool
+}
+
+// PublicNameChecker is implemented by the resource syncers of the cluster scoped objects which are synced from
+// super master to all tenant masters.
+type PublicNameChecker interface {
+	// PublicNameTaken returns true if the name is taken by a public super master object, which would collide with
+	// a tenant object of the same name.
+	PublicNameTaken(name string) bool
+}
+
 // AddController adds a resource syncer to the ControllerManager.
 func (m *ControllerManager) AddResourceSyncer(s ResourceSyncer) {
 	m.resourceSyncers[s] = struct{}{}
@@ -97,10 +188,16 @@ func (b *BaseResourceSyncer) BackPopulate(s string) error {
 	return nil
 }
 
-func (b *BaseResourceSyncer) PatrollerDo() {
+func (b *BaseResourceSyncer) PatrollerDo(ctx context.Context) {
 	return
 }
 
+// Healthy returns false if the patroller of the resource syncer failed its last round or has not completed
+// a round for several periods.
+func (b *BaseResourceSyncer) Healthy() bool {
+	return b.Patroller.Healthy()
+}
+
 func (b *BaseResourceSyncer) GetListener() listener.ClusterChangeListener {
 	return listener.NewMCControllerListener(b.MultiClusterController, mc.WatchOptions{AttachUID: true})
 }
@@ -167,6 +264,14 @@ func (m *ControllerManager) Start(stop <-chan struct{}) error {
 	case <-doneCh:
 		return nil
 	case <-stop:
+		// the resource syncers finish their in-flight remediations within the shutdown grace period.
+		t := time.NewTimer(constants.DefaultShutdownGracePeriod)
+		defer t.Stop()
+		select {
+		case <-doneCh:
+		case <-t.C:
+			klog.Warningf("resource syncers do not stop in %v, leave them behind", constants.DefaultShutdownGracePeriod)
+		}
 		return nil
 	case err := <-errCh:
 		return err
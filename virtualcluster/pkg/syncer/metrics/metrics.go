@@ -25,17 +25,43 @@ import (
 )
 
 const (
-	ResourceSyncerSubsystem  = "syncer"
-	PodOperationsKey         = "pod_operations_total"
-	PodOperationsDurationKey = "pod_operations_duration_seconds"
-	CheckerMissMatchKey      = "checker_missmatch_count"
-	CheckerRemedyKey         = "checker_remedy_count"
-	CheckerScanDurationKey   = "checker_scan_duaration_seconds"
-	DWSOperationCounterKey   = "dws_operations_total"
-	DWSOperationDurationKey  = "dws_operations_duration_seconds"
-	UWSOperationCounterKey   = "uws_operations_total"
-	UWSOperationDurationKey  = "uws_operations_duration_seconds"
-	ClusterHealthKey         = "virtual_cluster_health"
+	ResourceSyncerSubsystem       = "syncer"
+	PodOperationsKey              = "pod_operations_total"
+	PodOperationsDurationKey      = "pod_operations_duration_seconds"
+	CheckerMissMatchKey           = "checker_missmatch_count"
+	CheckerRemedyKey              = "checker_remedy_count"
+	CheckerDryRunKey              = "checker_dryrun_count"
+	CheckerScanDurationKey        = "checker_scan_duaration_seconds"
+	CheckerClusterScanDurationKey = "checker_cluster_scan_duration_seconds"
+	CheckerConnectionErrorsKey    = "checker_connection_errors_total"
+	CheckerSkippedClustersKey     = "checker_skipped_clusters"
+	CheckerUnmanagedKey           = "checker_unmanaged_tenant_objects"
+	CheckerStuckTerminatingKey    = "checker_stuck_terminating_tenant_objects"
+	CheckerCorruptKey             = "checker_corrupt_tenant_objects"
+	CheckerProtectedKey           = "checker_protected_tenant_objects"
+	CheckerSuperTopologyKey       = "checker_super_topology_objects"
+	CheckerSyncedObjectsKey       = "checker_synced_objects"
+	CheckerDivergedObjectsKey     = "checker_diverged_objects"
+	CheckerClusterMissMatchKey    = "checker_cluster_missmatch_count"
+	CheckerClusterRemedyKey       = "checker_cluster_remedy_count"
+	CheckerAbortedDestructiveKey  = "checker_aborted_destructive_total"
+	CheckerRecreatedObjectsKey    = "checker_recreated_objects_total"
+	CheckerMissingSuperNSKey      = "checker_missing_super_namespaces_total"
+	CheckerLastRunTimestampKey    = "checker_last_run_timestamp_seconds"
+	CheckerLastRunSuccessKey      = "checker_last_run_success"
+	CheckerPanicsKey              = "checker_panics_total"
+	PatrolPausedClustersKey       = "patrol_paused_clusters"
+	SyncLagSecondsKey             = "sync_lag_seconds"
+	DWSOperationCounterKey        = "dws_operations_total"
+	DWSOperationDurationKey       = "dws_operations_duration_seconds"
+	UWSOperationCounterKey        = "uws_operations_total"
+	UWSOperationDurationKey       = "uws_operations_duration_seconds"
+	UWSQueueDepthKey              = "uws_queue_depth"
+	UWSActiveReconcilesKey        = "uws_active_reconciles"
+	UWSConflictsKey               = "uws_conflicts_total"
+	ClusterHealthKey              = "virtual_cluster_health"
+	SyncerDisabledResourceKey     = "disabled_resource"
+	ClusterOwnerKey               = "cluster_owner"
 )
 
 var (
@@ -72,6 +98,30 @@ var (
 		},
 		[]string{"counter_name"},
 	)
+	CheckerClusterMissMatchStats = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerClusterMissMatchKey,
+			Help:      "Last checker scan results for mismatched resources per tenant cluster. Only emitted if per cluster labels are enabled.",
+		},
+		[]string{"counter_name", "cluster"},
+	)
+	CheckerClusterRemedyStats = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerClusterRemedyKey,
+			Help:      "Cumulative number of checker remediation actions per tenant cluster. Only emitted if per cluster labels are enabled.",
+		},
+		[]string{"counter_name", "cluster"},
+	)
+	CheckerDryRunStats = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerDryRunKey,
+			Help:      "Cumulative number of checker remediation actions skipped in dry run mode.",
+		},
+		[]string{"counter_name"},
+	)
 	CheckerScanDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Subsystem: ResourceSyncerSubsystem,
@@ -81,6 +131,151 @@ var (
 		},
 		[]string{"resource"},
 	)
+	CheckerLastRunTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerLastRunTimestampKey,
+			Help:      "Unix timestamp in seconds when each resource checker's last scan finished.",
+		},
+		[]string{"resource"},
+	)
+	CheckerLastRunSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerLastRunSuccessKey,
+			Help:      "Set to 1 if each resource checker's last scan succeeded, 0 if it failed or was given up.",
+		},
+		[]string{"resource"},
+	)
+	CheckerClusterScanDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerClusterScanDurationKey,
+			Help:      "Duration in seconds of each resource checker's scan time per tenant cluster.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"resource", "cluster"},
+	)
+	CheckerConnectionErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerConnectionErrorsKey,
+			Help:      "Cumulative number of tenant cluster access errors that made the checker skip the cluster.",
+		},
+		[]string{"resource", "cluster"},
+	)
+	CheckerPanics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerPanicsKey,
+			Help:      "Cumulative number of panics recovered while the checker checks a tenant cluster.",
+		},
+		[]string{"resource", "cluster"},
+	)
+	CheckerSkippedClusters = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerSkippedClustersKey,
+			Help:      "Number of tenant clusters skipped by the last checker scan because they are not ready.",
+		},
+		[]string{"resource"},
+	)
+	CheckerAbortedDestructive = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerAbortedDestructiveKey,
+			Help:      "Cumulative number of checker passes whose deletions are aborted because they exceed the delete limit.",
+		},
+		[]string{"resource"},
+	)
+	CheckerRecreatedObjects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerRecreatedObjectsKey,
+			Help:      "Cumulative number of tenant objects deleted and recreated by the checker because their immutable fields differ from super master.",
+		},
+		[]string{"resource"},
+	)
+	CheckerMissingSuperNamespaces = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerMissingSuperNSKey,
+			Help:      "Cumulative number of super master namespaces found missing by the checkers before requeuing the tenant objects of the namespaces.",
+		},
+		[]string{"resource"},
+	)
+	CheckerUnmanagedTenantObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerUnmanagedKey,
+			Help:      "Number of tenant objects found by the last checker scan without super master counterpart that are not managed by syncer.",
+		},
+		[]string{"resource"},
+	)
+	CheckerStuckTerminating = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerStuckTerminatingKey,
+			Help:      "Number of syncer managed tenant objects found terminating by the last checker scan, they are not deleted again.",
+		},
+		[]string{"resource"},
+	)
+	CheckerCorruptObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerCorruptKey,
+			Help:      "Number of syncer managed tenant objects found by the last checker scan with empty required fields, they are recreated from super master.",
+		},
+		[]string{"resource"},
+	)
+	CheckerProtectedObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerProtectedKey,
+			Help:      "Number of protected orphan tenant objects found by the last checker scan, they are never deleted by syncer.",
+		},
+		[]string{"resource"},
+	)
+	CheckerSuperTopology = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerSuperTopologyKey,
+			Help:      "Number of public super master objects found by the last checker scan that are synced to tenants bound to super cluster topologies.",
+		},
+		[]string{"resource"},
+	)
+	PatrolPausedClusters = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      PatrolPausedClustersKey,
+			Help:      "Tenant clusters whose patrol is paused, the value is 1 for each paused cluster.",
+		},
+		[]string{"cluster"},
+	)
+	SyncLagSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      SyncLagSecondsKey,
+			Help:      "Age in seconds of the oldest super master change not written to the tenant objects yet per tenant cluster.",
+		},
+		[]string{"resource", "cluster"},
+	)
+	SyncedObjectCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerSyncedObjectsKey,
+			Help:      "Number of tenant objects found consistent with super master by the last checker scan per tenant cluster.",
+		},
+		[]string{"resource", "cluster"},
+	)
+	DivergedObjectCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      CheckerDivergedObjectsKey,
+			Help:      "Number of public super master objects without a consistent tenant copy found by the last checker scan per tenant cluster, i.e., the objects missing or mismatched in tenant.",
+		},
+		[]string{"resource", "cluster"},
+	)
 	DWSOperationDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Subsystem: ResourceSyncerSubsystem,
@@ -112,6 +307,46 @@ var (
 			Help:      "Cumulative number of upward resource operations.",
 		},
 		[]string{"resource", "code"})
+	UWSQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      UWSQueueDepthKey,
+			Help:      "Number of keys waiting in the upward controller queue of each resource, including the ones requeued by the checker.",
+		},
+		[]string{"resource"},
+	)
+	UWSActiveReconciles = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      UWSActiveReconcilesKey,
+			Help:      "Number of keys being reconciled by the upward controller workers of each resource.",
+		},
+		[]string{"resource"},
+	)
+	UWSConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      UWSConflictsKey,
+			Help:      "Cumulative number of tenant objects not managed by syncer that the upward controller left alone instead of syncing them from super master.",
+		},
+		[]string{"resource"},
+	)
+	SyncerDisabledResource = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      SyncerDisabledResourceKey,
+			Help:      "Set to 1 for the resource syncers disabled at startup because super master does not serve their API.",
+		},
+		[]string{"resource"},
+	)
+	ClusterOwner = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      ClusterOwnerKey,
+			Help:      "Set to 1 for each tenant cluster synced and patrolled by the syncer replica, i.e., the leader if leader election is enabled.",
+		},
+		[]string{"cluster", "replica"},
+	)
 	ClusterHealthStats = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: ResourceSyncerSubsystem,
@@ -124,6 +359,29 @@ var (
 
 var registerMetrics sync.Once
 
+// perClusterLabels indicates whether the checker metrics are broken down by tenant cluster.
+// The cluster label is left empty otherwise, so the number of series does not grow with the tenants.
+var perClusterLabels bool
+
+// SetPerClusterLabels enables or disables the per tenant cluster series of the checker metrics.
+// It should be called before any metric is recorded.
+func SetPerClusterLabels(enabled bool) {
+	perClusterLabels = enabled
+}
+
+// PerClusterLabels returns true if the checker metrics are broken down by tenant cluster.
+func PerClusterLabels() bool {
+	return perClusterLabels
+}
+
+// ClusterLabelValue returns the value of the cluster label of the checker metrics.
+func ClusterLabelValue(cluster string) string {
+	if !perClusterLabels {
+		return ""
+	}
+	return cluster
+}
+
 // Register all metrics.
 func Register() {
 	registerMetrics.Do(func() {
@@ -131,12 +389,38 @@ func Register() {
 		prometheus.MustRegister(PodOperationsDuration)
 		prometheus.MustRegister(CheckerMissMatchStats)
 		prometheus.MustRegister(CheckerRemedyStats)
+		prometheus.MustRegister(CheckerClusterMissMatchStats)
+		prometheus.MustRegister(CheckerClusterRemedyStats)
+		prometheus.MustRegister(CheckerDryRunStats)
 		prometheus.MustRegister(CheckerScanDuration)
+		prometheus.MustRegister(CheckerClusterScanDuration)
+		prometheus.MustRegister(CheckerLastRunTimestamp)
+		prometheus.MustRegister(CheckerLastRunSuccess)
+		prometheus.MustRegister(CheckerConnectionErrors)
+		prometheus.MustRegister(CheckerPanics)
+		prometheus.MustRegister(PatrolPausedClusters)
+		prometheus.MustRegister(SyncLagSeconds)
+		prometheus.MustRegister(CheckerSkippedClusters)
+		prometheus.MustRegister(CheckerAbortedDestructive)
+		prometheus.MustRegister(CheckerRecreatedObjects)
+		prometheus.MustRegister(CheckerMissingSuperNamespaces)
+		prometheus.MustRegister(CheckerUnmanagedTenantObjects)
+		prometheus.MustRegister(CheckerStuckTerminating)
+		prometheus.MustRegister(CheckerCorruptObjects)
+		prometheus.MustRegister(CheckerProtectedObjects)
+		prometheus.MustRegister(CheckerSuperTopology)
+		prometheus.MustRegister(SyncedObjectCount)
+		prometheus.MustRegister(DivergedObjectCount)
 		prometheus.MustRegister(DWSOperationCounter)
 		prometheus.MustRegister(DWSOperationDuration)
 		prometheus.MustRegister(UWSOperationDuration)
 		prometheus.MustRegister(UWSOperationCounter)
+		prometheus.MustRegister(UWSQueueDepth)
+		prometheus.MustRegister(UWSActiveReconciles)
+		prometheus.MustRegister(UWSConflicts)
 		prometheus.MustRegister(ClusterHealthStats)
+		prometheus.MustRegister(SyncerDisabledResource)
+		prometheus.MustRegister(ClusterOwner)
 	})
 }
 
@@ -154,6 +438,53 @@ func RecordCheckerScanDuration(resource string, start time.Time) {
 	CheckerScanDuration.WithLabelValues(resource).Observe(SinceInSeconds(start))
 }
 
+// RecordCheckerLastRun records when the resource checker's scan finished and whether it succeeded.
+func RecordCheckerLastRun(resource string, success bool) {
+	CheckerLastRunTimestamp.WithLabelValues(resource).Set(float64(time.Now().Unix()))
+	value := 0.0
+	if success {
+		value = 1
+	}
+	CheckerLastRunSuccess.WithLabelValues(resource).Set(value)
+}
+
+func RecordCheckerClusterScanDuration(resource, cluster string, start time.Time) {
+	CheckerClusterScanDuration.With(prometheus.Labels{"resource": resource, "cluster": ClusterLabelValue(cluster)}).Observe(SinceInSeconds(start))
+}
+
+func RecordCheckerConnectionError(resource, cluster string) {
+	CheckerConnectionErrors.With(prometheus.Labels{"resource": resource, "cluster": ClusterLabelValue(cluster)}).Inc()
+}
+
+func RecordCheckerPanic(resource, cluster string) {
+	CheckerPanics.With(prometheus.Labels{"resource": resource, "cluster": ClusterLabelValue(cluster)}).Inc()
+}
+
+// RecordCheckerRemedy records a checker remediation action, and the per cluster one if enabled.
+func RecordCheckerRemedy(counterName, cluster string) {
+	CheckerRemedyStats.WithLabelValues(counterName).Inc()
+	if perClusterLabels {
+		CheckerClusterRemedyStats.With(prometheus.Labels{"counter_name": counterName, "cluster": cluster}).Inc()
+	}
+}
+
+// SetCheckerClusterMissMatch records the mismatched resources found in a tenant cluster if per cluster labels are enabled.
+func SetCheckerClusterMissMatch(counterName, cluster string, value float64) {
+	if perClusterLabels {
+		CheckerClusterMissMatchStats.With(prometheus.Labels{"counter_name": counterName, "cluster": cluster}).Set(value)
+	}
+}
+
+// DeleteCheckerClusterStats deletes the per cluster series of a removed tenant cluster.
+func DeleteCheckerClusterStats(resource, cluster string, counterNames ...string) {
+	SyncedObjectCount.Delete(prometheus.Labels{"resource": resource, "cluster": cluster})
+	DivergedObjectCount.Delete(prometheus.Labels{"resource": resource, "cluster": cluster})
+	for _, counterName := range counterNames {
+		CheckerClusterMissMatchStats.Delete(prometheus.Labels{"counter_name": counterName, "cluster": cluster})
+		CheckerClusterRemedyStats.Delete(prometheus.Labels{"counter_name": counterName, "cluster": cluster})
+	}
+}
+
 func RecordUWSOperationDuration(resource string, start time.Time) {
 	UWSOperationDuration.With(prometheus.Labels{"resource": resource}).Observe(SinceInSeconds(start))
 }
@@ -162,6 +493,14 @@ func RecordUWSOperationStatus(resource, code string) {
 	UWSOperationCounter.With(prometheus.Labels{"resource": resource, "code": code}).Inc()
 }
 
+func RecordUWSConflict(resource string) {
+	UWSConflicts.With(prometheus.Labels{"resource": resource}).Inc()
+}
+
+func RecordUWSQueueDepth(resource string, depth int) {
+	UWSQueueDepth.With(prometheus.Labels{"resource": resource}).Set(float64(depth))
+}
+
 func RecordDWSOperationDuration(resource, cluster string, start time.Time) {
 	DWSOperationDuration.With(prometheus.Labels{"resource": resource, "vc_name": cluster}).Observe(SinceInSeconds(start))
 }
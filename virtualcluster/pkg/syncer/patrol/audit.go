@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+)
+
+// AuditRecord describes a remediation taken by a checker on a tenant object.
+type AuditRecord struct {
+	// Time is when the remediation was taken.
+	Time time.Time
+	// Resource is the resource being checked, e.g., storageclass.
+	Resource string
+	// Cluster is the tenant cluster the object belongs to.
+	Cluster string
+	// Object is the name of the object.
+	Object string
+	// UID is the uid of the tenant object, it is empty if the object is missing in the tenant cluster.
+	UID types.UID
+	// Action is the remediation, i.e., requeue, delete, recreate or removeFinalizers.
+	Action string
+	// Reason is the reason of the remediation in the form of the event reasons, e.g., DeletedOrphan.
+	Reason string
+	// Message is the human readable description of the remediation.
+	Message string
+}
+
+// AuditSink receives the remediations taken by the checkers, e.g., to forward them to an external audit system.
+// Record is called synchronously by the patrol, hence it should not block.
+type AuditSink interface {
+	Record(record AuditRecord)
+}
+
+// NopAuditSink drops the audit records.
+type NopAuditSink struct{}
+
+// Record implements AuditSink.
+func (NopAuditSink) Record(AuditRecord) {}
+
+// LogAuditSink logs the audit records in the structured format of the checkers, regardless of the
+// StructuredLogging feature, so that they can be collected by the log pipeline.
+type LogAuditSink struct{}
+
+// Record implements AuditSink.
+func (LogAuditSink) Record(record AuditRecord) {
+	klog.Infof("audit %s uid=%q reason=%q time=%q", structuredMessage(record.Message, Fields{
+		Resource: record.Resource,
+		Cluster:  record.Cluster,
+		Object:   record.Object,
+		Action:   record.Action,
+	}), record.UID, record.Reason, record.Time.Format(time.RFC3339))
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+// ListInChunks iterates the objects of a super master informer store in batches of chunkSize, so that a
+// checker sweeping a heavy resource, e.g., secrets, never holds the whole object set at once. Only the keys
+// are listed upfront, each batch is fetched right before fn is called and released once fn returns.
+// The objects removed from the store during the sweep are skipped. The iteration stops if fn returns false.
+// constants.DefaultPatrolListChunkSize is used if chunkSize is not positive.
+func ListInChunks(store cache.Store, chunkSize int, fn func(objs []interface{}) bool) {
+	if chunkSize <= 0 {
+		chunkSize = constants.DefaultPatrolListChunkSize
+	}
+	keys := store.ListKeys()
+	batch := make([]interface{}, 0, chunkSize)
+	for start := 0; start < len(keys); start += chunkSize {
+		end := start + chunkSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batch = batch[:0]
+		for _, key := range keys[start:end] {
+			obj, exists, err := store.GetByKey(key)
+			if err != nil || !exists {
+				continue
+			}
+			batch = append(batch, obj)
+		}
+		if len(batch) > 0 && !fn(batch) {
+			return
+		}
+	}
+}
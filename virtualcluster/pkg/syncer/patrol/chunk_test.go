@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestListInChunks(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for i := 0; i < 5; i++ {
+		store.Add(&v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("s%d", i)}})
+	}
+
+	testcases := map[string]struct {
+		chunkSize      int
+		stopAfter      int
+		expectedChunks []int
+	}{
+		"chunks bounded by the chunk size": {
+			chunkSize:      2,
+			expectedChunks: []int{2, 2, 1},
+		},
+		"single chunk": {
+			chunkSize:      10,
+			expectedChunks: []int{5},
+		},
+		"default chunk size": {
+			chunkSize:      0,
+			expectedChunks: []int{5},
+		},
+		"stopped by the callback": {
+			chunkSize:      2,
+			stopAfter:      1,
+			expectedChunks: []int{2},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			var chunks []int
+			seen := sets.NewString()
+			ListInChunks(store, tc.chunkSize, func(objs []interface{}) bool {
+				chunks = append(chunks, len(objs))
+				for _, obj := range objs {
+					seen.Insert(obj.(*v1.Secret).Name)
+				}
+				return tc.stopAfter == 0 || len(chunks) < tc.stopAfter
+			})
+			if fmt.Sprint(chunks) != fmt.Sprint(tc.expectedChunks) {
+				t.Errorf("expected chunks %v, got %v", tc.expectedChunks, chunks)
+			}
+			if tc.stopAfter == 0 && seen.Len() != 5 {
+				t.Errorf("expected all 5 objects to be visited, got %v", seen.List())
+			}
+		})
+	}
+}
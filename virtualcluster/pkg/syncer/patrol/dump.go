@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const redacted = "<redacted>"
+
+// sensitiveKey matches the keys of the values which may carry credentials, e.g., the parameters of the
+// provisioners taking them inline.
+var sensitiveKey = regexp.MustCompile(`(?i)secret|password|passwd|token|credential|key`)
+
+// lastAppliedConfigAnnotation holds the object applied by kubectl in full, including the data of secrets.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// DumpObject returns the object in JSON for diagnosing the field level drift found by a checker. The sensitive
+// values are redacted, i.e., the data of secrets and the credential-like parameters of storageclasses.
+func DumpObject(obj runtime.Object) string {
+	if obj == nil {
+		return "null"
+	}
+	obj = obj.DeepCopyObject()
+	switch t := obj.(type) {
+	case *corev1.Secret:
+		for k := range t.Data {
+			t.Data[k] = []byte(redacted)
+		}
+		for k := range t.StringData {
+			t.StringData[k] = redacted
+		}
+		if _, ok := t.Annotations[lastAppliedConfigAnnotation]; ok {
+			t.Annotations[lastAppliedConfigAnnotation] = redacted
+		}
+	case *storagev1.StorageClass:
+		for k := range t.Parameters {
+			if sensitiveKey.MatchString(k) {
+				t.Parameters[k] = redacted
+			}
+		}
+	}
+	// the redaction marker is not escaped, so that the logged objects stay readable.
+	b := &bytes.Buffer{}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(obj); err != nil {
+		return fmt.Sprintf("<failed to dump object: %v>", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
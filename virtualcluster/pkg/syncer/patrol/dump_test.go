@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestDumpObject(t *testing.T) {
+	testcases := map[string]struct {
+		obj      runtime.Object
+		included []string
+		excluded []string
+	}{
+		"storageclass": {
+			obj: &storagev1.StorageClass{
+				ObjectMeta:  metav1.ObjectMeta{Name: "standard"},
+				Provisioner: "kubernetes.io/quobyte",
+				Parameters: map[string]string{
+					"type":            "pd-ssd",
+					"adminSecretName": "quobyte-admin",
+					"password":        "hunter2",
+				},
+			},
+			included: []string{`"name":"standard"`, `"type":"pd-ssd"`, `"password":"<redacted>"`, `"adminSecretName":"<redacted>"`},
+			excluded: []string{"hunter2", "quobyte-admin"},
+		},
+		"secret": {
+			obj: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "token",
+					Annotations: map[string]string{lastAppliedConfigAnnotation: `{"stringData":{"token":"hunter2"}}`},
+				},
+				Data:       map[string][]byte{"token": []byte("hunter2")},
+				StringData: map[string]string{"token": "hunter2"},
+			},
+			included: []string{`"name":"token"`},
+			excluded: []string{"hunter2", "aHVudGVyMg=="},
+		},
+		"nil": {
+			included: []string{"null"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			dumped := DumpObject(tc.obj)
+			for _, s := range tc.included {
+				if !strings.Contains(dumped, s) {
+					t.Errorf("expected %s in the dumped object, got %s", s, dumped)
+				}
+			}
+			for _, s := range tc.excluded {
+				if strings.Contains(dumped, s) {
+					t.Errorf("expected %s redacted from the dumped object, got %s", s, dumped)
+				}
+			}
+		})
+	}
+
+	secret := &corev1.Secret{Data: map[string][]byte{"token": []byte("hunter2")}}
+	DumpObject(secret)
+	if string(secret.Data["token"]) != "hunter2" {
+		t.Errorf("expected the dumped object not to be modified")
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// SyncLag tracks the keys of the tenant objects whose super master objects changed but are not written to the tenant
+// cluster yet, so that the worst-case staleness of the tenant objects can be reported per tenant cluster as the
+// SyncLagSeconds gauge. The lag of a key runs from the super master change to the successful tenant write.
+type SyncLag struct {
+	resource string
+	now      func() time.Time
+
+	sync.Mutex
+	// pending records, per tenant cluster, when the super master object of each pending key changed.
+	pending map[string]map[string]time.Time
+	// reported are the clusters whose lag has been reported, so that they are reset once nothing is pending.
+	reported sets.String
+}
+
+// NewSyncLag creates the sync lag tracker of the resource, e.g., StorageClass.
+func NewSyncLag(resource string) *SyncLag {
+	return &SyncLag{
+		resource: resource,
+		now:      time.Now,
+		pending:  make(map[string]map[string]time.Time),
+		reported: sets.NewString(),
+	}
+}
+
+// Changed records that the super master object of the key of the tenant cluster changed at the given time, i.e.,
+// the time of the informer event, or the last change recorded on the object if the change is found by the
+// patroller. A key changed again before it is synced keeps its first timestamp.
+func (l *SyncLag) Changed(clusterName, key string, at time.Time) {
+	l.Lock()
+	defer l.Unlock()
+	if l.pending[clusterName] == nil {
+		l.pending[clusterName] = make(map[string]time.Time)
+	}
+	if _, exists := l.pending[clusterName][key]; !exists {
+		l.pending[clusterName][key] = at
+	}
+}
+
+// Synced clears the key of the tenant cluster once it is written to the tenant cluster, or found consistent.
+func (l *SyncLag) Synced(clusterName, key string) {
+	l.Lock()
+	defer l.Unlock()
+	delete(l.pending[clusterName], key)
+	if len(l.pending[clusterName]) == 0 {
+		delete(l.pending, clusterName)
+	}
+}
+
+// Prune drops the pending keys of the tenant clusters which are no longer registered.
+func (l *SyncLag) Prune(clusterNames []string) {
+	l.Lock()
+	defer l.Unlock()
+	active := sets.NewString(clusterNames...)
+	for clusterName := range l.pending {
+		if !active.Has(clusterName) {
+			delete(l.pending, clusterName)
+		}
+	}
+}
+
+// Lag returns the age of the oldest pending key of the tenant cluster, or 0 if nothing is pending.
+func (l *SyncLag) Lag(clusterName string) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+	return l.lag(clusterName, l.now())
+}
+
+func (l *SyncLag) lag(clusterName string, now time.Time) time.Duration {
+	var oldest time.Duration
+	for _, changed := range l.pending[clusterName] {
+		if age := now.Sub(changed); age > oldest {
+			oldest = age
+		}
+	}
+	return oldest
+}
+
+// Report sets the SyncLagSeconds gauge of each tenant cluster. The lag of all tenant clusters is reported as the
+// worst one of them if the checker metrics are not broken down by tenant cluster.
+func (l *SyncLag) Report() {
+	l.Lock()
+	defer l.Unlock()
+	now := l.now()
+	lags := make(map[string]float64)
+	for clusterName := range l.pending {
+		label := metrics.ClusterLabelValue(clusterName)
+		if lag := l.lag(clusterName, now).Seconds(); lag >= lags[label] {
+			lags[label] = lag
+		}
+	}
+	for label := range l.reported {
+		if _, exists := lags[label]; !exists {
+			metrics.SyncLagSeconds.Delete(prometheus.Labels{"resource": l.resource, "cluster": label})
+			l.reported.Delete(label)
+		}
+	}
+	for label, lag := range lags {
+		metrics.SyncLagSeconds.With(prometheus.Labels{"resource": l.resource, "cluster": label}).Set(lag)
+		l.reported.Insert(label)
+	}
+}
+
+// LastChanged returns the last change time recorded on the object, i.e., the latest time of its managed fields, or
+// its creation time if it has none. It returns the current time if the object records neither.
+func LastChanged(obj metav1.Object) time.Time {
+	var last time.Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(last) {
+			last = entry.Time.Time
+		}
+	}
+	if last.IsZero() {
+		last = obj.GetCreationTimestamp().Time
+	}
+	if last.IsZero() {
+		last = time.Now()
+	}
+	return last
+}
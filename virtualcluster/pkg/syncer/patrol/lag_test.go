@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+func TestSyncLag(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewSyncLag("StorageClass")
+	l.now = func() time.Time { return now }
+	metrics.SyncLagSeconds.Reset()
+	metrics.SetPerClusterLabels(true)
+	defer metrics.SetPerClusterLabels(false)
+
+	l.Changed("cluster1", "cluster1/a", now)
+	now = now.Add(10 * time.Second)
+	l.Changed("cluster1", "cluster1/b", now)
+	// the change found by the patroller is measured from the last change of the super master object.
+	l.Changed("cluster2", "cluster2/a", now.Add(-5*time.Second))
+	now = now.Add(5 * time.Second)
+	// changed again before it is synced, the first timestamp is kept.
+	l.Changed("cluster1", "cluster1/a", now)
+
+	if lag := l.Lag("cluster1"); lag != 15*time.Second {
+		t.Errorf("expected cluster1 lag 15s, got %v", lag)
+	}
+	l.Report()
+	if v := testutil.ToFloat64(metrics.SyncLagSeconds.WithLabelValues("StorageClass", "cluster2")); v != 10 {
+		t.Errorf("expected cluster2 lag 10s reported, got %v", v)
+	}
+
+	l.Synced("cluster1", "cluster1/a")
+	if lag := l.Lag("cluster1"); lag != 5*time.Second {
+		t.Errorf("expected cluster1 lag 5s once the oldest key is synced, got %v", lag)
+	}
+
+	l.Prune([]string{"cluster1"})
+	l.Synced("cluster1", "cluster1/b")
+	l.Report()
+	if n := testutil.CollectAndCount(metrics.SyncLagSeconds); n != 0 {
+		t.Errorf("expected no lag reported once nothing is pending, got %d series", n)
+	}
+}
+
+func TestLastChanged(t *testing.T) {
+	created := metav1.NewTime(time.Unix(1000, 0))
+	applied := metav1.NewTime(time.Unix(2000, 0))
+	updated := metav1.NewTime(time.Unix(3000, 0))
+
+	obj := &metav1.ObjectMeta{CreationTimestamp: created}
+	if last := LastChanged(obj); !last.Equal(created.Time) {
+		t.Errorf("expected the creation time %v without managed fields, got %v", created.Time, last)
+	}
+	obj.ManagedFields = []metav1.ManagedFieldsEntry{{Time: &updated}, {Time: &applied}, {}}
+	if last := LastChanged(obj); !last.Equal(updated.Time) {
+		t.Errorf("expected the latest managed fields time %v, got %v", updated.Time, last)
+	}
+	if last := LastChanged(&metav1.ObjectMeta{}); time.Since(last) > time.Minute {
+		t.Errorf("expected the current time without any recorded time, got %v", last)
+	}
+}
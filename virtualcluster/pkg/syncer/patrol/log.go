@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+)
+
+// Fields are the keys shared by the structured logs of the checkers. Empty fields are omitted.
+type Fields struct {
+	// Resource is the resource being checked, e.g., storageclass.
+	Resource string
+	// Cluster is the tenant cluster the log is about.
+	Cluster string
+	// Object is the name of the object the log is about.
+	Object string
+	// Action is the remediation taken or skipped by the checker, e.g., delete or requeue.
+	Action string
+	// Err is the error encountered.
+	Err error
+}
+
+// Infof logs the message of a checker. If the StructuredLogging feature is enabled the message is followed
+// by the fields, otherwise the message is logged as is.
+func Infof(fields Fields, format string, args ...interface{}) {
+	klog.InfoDepth(1, message(fields, format, args...))
+}
+
+// Warningf logs the warning of a checker in the same way as Infof.
+func Warningf(fields Fields, format string, args ...interface{}) {
+	klog.WarningDepth(1, message(fields, format, args...))
+}
+
+// Errorf logs the error of a checker in the same way as Infof.
+func Errorf(fields Fields, format string, args ...interface{}) {
+	klog.ErrorDepth(1, message(fields, format, args...))
+}
+
+func message(fields Fields, format string, args ...interface{}) string {
+	msg := fmt.Sprintf(format, args...)
+	if !featuregate.DefaultFeatureGate.Enabled(featuregate.StructuredLogging) {
+		return msg
+	}
+	return structuredMessage(msg, fields)
+}
+
+// structuredMessage formats the message and the fields like klog InfoS, e.g.,
+// "storageclass is deleted" resource="storageclass" cluster="tenant-1" object="standard" action="delete".
+func structuredMessage(msg string, fields Fields) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%q", msg)
+	for _, kv := range []struct{ key, value string }{
+		{"resource", fields.Resource},
+		{"cluster", fields.Cluster},
+		{"object", fields.Object},
+		{"action", fields.Action},
+	} {
+		if kv.value != "" {
+			fmt.Fprintf(b, " %s=%q", kv.key, kv.value)
+		}
+	}
+	if fields.Err != nil {
+		fmt.Fprintf(b, " err=%q", fields.Err.Error())
+	}
+	return b.String()
+}
+
+// Verbose logs the message of a checker only if the verbosity is high enough, like klog.Verbose does.
+type Verbose bool
+
+// V reports whether the verbosity is at least the requested level.
+func V(level klog.Level) Verbose {
+	return Verbose(klog.V(level))
+}
+
+// Infof is equivalent to the global Infof function, guarded by the value of v.
+func (v Verbose) Infof(fields Fields, format string, args ...interface{}) {
+	if v {
+		klog.InfoDepth(1, message(fields, format, args...))
+	}
+}
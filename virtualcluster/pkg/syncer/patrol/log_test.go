@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"errors"
+	"testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+)
+
+func TestMessage(t *testing.T) {
+	fields := Fields{
+		Resource: "storageclass",
+		Cluster:  "tenant-1",
+		Object:   "standard",
+		Action:   "delete",
+		Err:      errors.New("connection refused"),
+	}
+
+	testcases := map[string]struct {
+		structured bool
+		fields     Fields
+		expected   string
+	}{
+		"unstructured": {
+			fields:   fields,
+			expected: `error deleting storageclass standard`,
+		},
+		"structured": {
+			structured: true,
+			fields:     fields,
+			expected:   `"error deleting storageclass standard" resource="storageclass" cluster="tenant-1" object="standard" action="delete" err="connection refused"`,
+		},
+		"structured with empty fields": {
+			structured: true,
+			fields:     Fields{Resource: "storageclass"},
+			expected:   `"error deleting storageclass standard" resource="storageclass"`,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if err := featuregate.DefaultFeatureGate.Set(featuregate.StructuredLogging, tc.structured); err != nil {
+				t.Fatalf("failed to set feature gate: %v", err)
+			}
+			defer featuregate.DefaultFeatureGate.Set(featuregate.StructuredLogging, false)
+			if got := message(tc.fields, "error deleting storageclass %s", "standard"); got != tc.expected {
+				t.Errorf("expected message %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/informers"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+// SuperNamespaceGuard validates the super master namespace of a tenant namespace before the checker of a namespaced
+// resource requeues the tenant objects missing in it. The super master namespace is created by the namespace
+// syncer, a checker running ahead of it would otherwise requeue the objects only to fail with namespace not found.
+// The guard only reports the super master namespaces that are not ready, it never writes them, so that the namespace
+// syncer stays their only writer.
+type SuperNamespaceGuard struct {
+	resource string
+	enabled  bool
+	lister   listersv1.NamespaceLister
+	synced   cache.InformerSynced
+	mc       *mc.MultiClusterController
+}
+
+// NewSuperNamespaceGuard creates the super master namespace guard of the checker of the resource. The guard lets
+// all namespaces through unless ValidateSuperNamespaces is set in the syncer configuration.
+func NewSuperNamespaceGuard(resource string, config *config.SyncerConfiguration, informer informers.SharedInformerFactory, mccontroller *mc.MultiClusterController) *SuperNamespaceGuard {
+	g := &SuperNamespaceGuard{resource: resource, mc: mccontroller}
+	if config == nil || !config.ValidateSuperNamespaces {
+		return g
+	}
+	g.enabled = true
+	g.lister = informer.Core().V1().Namespaces().Lister()
+	g.synced = informer.Core().V1().Namespaces().Informer().HasSynced
+	return g
+}
+
+// Validate returns true if the tenant objects of the namespace can be requeued to be created in super master. It
+// returns false if the super master namespace is missing, belongs to another tenant cluster or is terminating, in
+// which case the objects are left to the next patrol round, after the namespace syncer has caught up. A super master
+// namespace missing the tenant labels is reported but let through.
+func (g *SuperNamespaceGuard) Validate(clusterName, namespace string) bool {
+	if g == nil || !g.enabled || namespace == "" {
+		return true
+	}
+	// the namespaces missing in a cache not synced yet are not necessarily missing in super master.
+	if !g.synced() {
+		return true
+	}
+	logFields := Fields{Resource: g.resource, Cluster: clusterName, Object: namespace}
+	targetNamespace := conversion.ToSuperMasterNamespace(clusterName, namespace)
+	pNamespace, err := g.lister.Get(targetNamespace)
+	if errors.IsNotFound(err) {
+		metrics.CheckerMissingSuperNamespaces.WithLabelValues(g.resource).Inc()
+		Warningf(logFields, "super master namespace %s of cluster %s is missing, skip requeuing the objects of namespace %s until the namespace syncer creates it", targetNamespace, clusterName, namespace)
+		return false
+	}
+	if err != nil {
+		logFields.Err = err
+		Errorf(logFields, "failed to get super master namespace %s from cache: %v", targetNamespace, err)
+		return false
+	}
+	if pNamespace.Annotations[constants.LabelCluster] != clusterName {
+		Errorf(logFields, "super master namespace %s does not belong to cluster %s, skip requeuing the objects of namespace %s", targetNamespace, clusterName, namespace)
+		return false
+	}
+	if pNamespace.DeletionTimestamp != nil {
+		V(4).Infof(logFields, "super master namespace %s of cluster %s is terminating, skip requeuing the objects of namespace %s", targetNamespace, clusterName, namespace)
+		return false
+	}
+	vcName, vcNamespace, _, err := g.mc.GetOwnerInfo(clusterName)
+	if err != nil {
+		logFields.Err = err
+		Errorf(logFields, "failed to get the owner of cluster %s: %v", clusterName, err)
+		return false
+	}
+	if pNamespace.Labels[constants.LabelVCName] != vcName || pNamespace.Labels[constants.LabelVCNamespace] != vcNamespace {
+		Warningf(logFields, "super master namespace %s of cluster %s does not carry the owner labels of the tenant, the super master selectors of the tenant do not select it", targetNamespace, clusterName)
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+type nopReconciler struct{}
+
+func (nopReconciler) Reconcile(reconciler.Request) (reconciler.Result, error) {
+	return reconciler.Result{}, nil
+}
+
+func TestSuperNamespaceGuard(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	vNamespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", UID: "12345"}}
+	superNamespace := func(mFuncs ...func(*v1.Namespace)) *v1.Namespace {
+		obj, err := conversion.BuildSuperMasterNamespace(clusterName, testTenant.Name, testTenant.Namespace, string(testTenant.UID), vNamespace)
+		if err != nil {
+			t.Fatalf("error building super master namespace: %v", err)
+		}
+		ns := obj.(*v1.Namespace)
+		for _, f := range mFuncs {
+			f(ns)
+		}
+		return ns
+	}
+
+	testcases := map[string]struct {
+		disabled              bool
+		unsynced              bool
+		existingObjectInSuper []*v1.Namespace
+		expected              bool
+		expectedMissing       float64
+	}{
+		"super namespace exists": {
+			existingObjectInSuper: []*v1.Namespace{superNamespace()},
+			expected:              true,
+		},
+		"super namespace missing": {
+			expectedMissing: 1,
+		},
+		"super namespace without tenant labels": {
+			existingObjectInSuper: []*v1.Namespace{superNamespace(func(ns *v1.Namespace) {
+				ns.Labels = nil
+			})},
+			expected: true,
+		},
+		"super namespace of another cluster": {
+			existingObjectInSuper: []*v1.Namespace{superNamespace(func(ns *v1.Namespace) {
+				ns.Annotations[constants.LabelCluster] = "another"
+			})},
+		},
+		"super namespace terminating": {
+			existingObjectInSuper: []*v1.Namespace{superNamespace(func(ns *v1.Namespace) {
+				now := metav1.Now()
+				ns.DeletionTimestamp = &now
+			})},
+		},
+		"guard disabled": {
+			disabled: true,
+			expected: true,
+		},
+		"super namespace cache not synced": {
+			unsynced: true,
+			expected: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			mccontroller, err := mc.NewMCController(&v1.ConfigMap{}, &v1.ConfigMapList{}, nopReconciler{})
+			if err != nil {
+				t.Fatalf("error creating mccontroller: %v", err)
+			}
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(vNamespace), fakeClient.NewFakeClient())
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			if err := mccontroller.RegisterClusterResource(tenantCluster, mc.WatchOptions{}); err != nil {
+				t.Fatalf("error registering tenant cluster: %v", err)
+			}
+			defer mccontroller.TeardownClusterResource(tenantCluster)
+
+			var superObjects []runtime.Object
+			for _, ns := range tc.existingObjectInSuper {
+				superObjects = append(superObjects, ns)
+			}
+			superClient := fake.NewSimpleClientset(superObjects...)
+			superInformer := informers.NewSharedInformerFactory(superClient, 0)
+			for _, ns := range tc.existingObjectInSuper {
+				superInformer.Core().V1().Namespaces().Informer().GetStore().Add(ns)
+			}
+			g := NewSuperNamespaceGuard("configmap", &config.SyncerConfiguration{ValidateSuperNamespaces: !tc.disabled}, superInformer, mccontroller)
+			if g.enabled {
+				g.synced = func() bool { return !tc.unsynced }
+			}
+			missing := testutil.ToFloat64(metrics.CheckerMissingSuperNamespaces.WithLabelValues("configmap"))
+
+			if ok := g.Validate(clusterName, vNamespace.Name); ok != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, ok)
+			}
+
+			// the guard never writes super master namespaces.
+			if actions := superClient.Actions(); len(actions) != 0 {
+				t.Errorf("expected no super master actions, got %v", actions)
+			}
+			if v := testutil.ToFloat64(metrics.CheckerMissingSuperNamespaces.WithLabelValues("configmap")) - missing; v != tc.expectedMissing {
+				t.Errorf("expected %v missing super master namespaces, got %v", tc.expectedMissing, v)
+			}
+		})
+	}
+}
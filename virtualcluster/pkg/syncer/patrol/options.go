@@ -19,6 +19,8 @@ package patrol
 import (
 	"time"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -33,6 +35,25 @@ func WithOptions(o *Options) OptConfig {
 		WithControllerName(o.name)(options)
 		WithReconciler(o.Reconciler)(options)
 		WithPeriod(o.Period)(options)
+		WithShutdownGracePeriod(o.ShutdownGracePeriod)(options)
+		if o.ResyncOnStart {
+			options.ResyncOnStart = true
+		}
+		if o.StartJitter > 0 {
+			options.StartJitter = o.StartJitter
+		}
+		if o.MinOnDemandInterval > 0 {
+			options.MinOnDemandInterval = o.MinOnDemandInterval
+		}
+	}
+}
+
+// WithShutdownGracePeriod sets the time the running round is given to finish once the patroller is stopped.
+func WithShutdownGracePeriod(t time.Duration) OptConfig {
+	return func(options *Options) {
+		if t > 0 {
+			options.ShutdownGracePeriod = t
+		}
 	}
 }
 
@@ -62,3 +83,37 @@ func WithPeriod(t time.Duration) OptConfig {
 		}
 	}
 }
+
+// WithResyncOnStart makes the patroller run a round synchronously when it starts if config.ResyncOnStart is set.
+func WithResyncOnStart(config *config.SyncerConfiguration) OptConfig {
+	return func(options *Options) {
+		if config != nil && config.ResyncOnStart {
+			options.ResyncOnStart = true
+		}
+	}
+}
+
+// WithStartJitter sets the fraction of the period the first round is delayed by at most to config.PatrolStartJitter.
+func WithStartJitter(config *config.SyncerConfiguration) OptConfig {
+	return func(options *Options) {
+		if config != nil && config.PatrolStartJitter > 0 {
+			options.StartJitter = config.PatrolStartJitter
+		}
+	}
+}
+
+// WithResourcePeriod set patrol period of the resource, it is read from config.PatrolPeriods
+// and falls back to the default period of the resource.
+func WithResourcePeriod(config *config.SyncerConfiguration, resource string) OptConfig {
+	return func(options *Options) {
+		if config != nil {
+			if t, ok := config.PatrolPeriods[resource]; ok && t > 0 {
+				options.Period = t
+				return
+			}
+		}
+		if t, ok := constants.DefaultPatrolPeriods[resource]; ok {
+			options.Period = t
+		}
+	}
+}
@@ -17,14 +17,17 @@ limitations under the License.
 package patrol
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
-	"k8s.io/apimachinery/pkg/util/wait"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/klog"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,6 +36,23 @@ import (
 type Patroller struct {
 	// objectKind is the kind of target object this controller watched.
 	objectKind string
+	// trigger requests an immediate patrol round. It is buffered so that repeated triggers are coalesced.
+	trigger chan struct{}
+	// runLock serializes the periodic patrol rounds and the ones run on demand.
+	runLock sync.Mutex
+	// onDemandLock guards onDemand, the last round run on demand.
+	onDemandLock sync.Mutex
+	onDemand     *onDemandRound
+	// statusLock guards started, lastRun and lastRunSucceeded, which are read by the health probe while a
+	// round is running.
+	statusLock       sync.Mutex
+	started          time.Time
+	lastRun          time.Time
+	lastRunSucceeded bool
+	// now is replaced in tests.
+	now func() time.Time
+	// rand picks the start delay, it is replaced by a seeded one in tests.
+	rand *rand.Rand
 
 	Options
 }
@@ -42,6 +62,23 @@ type Options struct {
 	name       string
 	Reconciler reconciler.PatrolReconciler
 	Period     time.Duration
+	// ResyncOnStart makes the patroller run a round synchronously when it starts, before its timer is set.
+	ResyncOnStart bool
+	// ShutdownGracePeriod is the time the running round is given to finish once the patroller is stopped,
+	// the round is cancelled after that.
+	ShutdownGracePeriod time.Duration
+	// StartJitter is the fraction of Period the first periodic round is delayed by at most, so that the
+	// patrollers started together do not run their rounds at the same time.
+	StartJitter float64
+	// MinOnDemandInterval is the minimal interval between two rounds run on demand. The rounds requested while
+	// one is running or within the interval after it finishes share its result instead.
+	MinOnDemandInterval time.Duration
+}
+
+// onDemandRound is a patrol round run on demand, done is closed once it finishes.
+type onDemandRound struct {
+	done       chan struct{}
+	finishedAt time.Time
 }
 
 func NewPatroller(objectType client.Object, rc reconciler.PatrolReconciler, opts ...OptConfig) (*Patroller, error) {
@@ -52,10 +89,15 @@ func NewPatroller(objectType client.Object, rc reconciler.PatrolReconciler, opts
 
 	p := &Patroller{
 		objectKind: kinds[0].Kind,
+		trigger:    make(chan struct{}, 1),
+		now:        time.Now,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
 		Options: Options{
-			name:       fmt.Sprintf("%s-patroller", strings.ToLower(kinds[0].Kind)),
-			Reconciler: rc,
-			Period:     60 * time.Second,
+			name:                fmt.Sprintf("%s-patroller", strings.ToLower(kinds[0].Kind)),
+			Reconciler:          rc,
+			Period:              constants.DefaultPatrolPeriod,
+			ShutdownGracePeriod: constants.DefaultShutdownGracePeriod,
+			MinOnDemandInterval: constants.DefaultPatrolMinOnDemandInterval,
 		},
 	}
 
@@ -70,11 +112,234 @@ func NewPatroller(objectType client.Object, rc reconciler.PatrolReconciler, opts
 }
 
 func (p *Patroller) Start(stop <-chan struct{}) {
-	klog.Infof("start periodic checker %s", p.name)
-	wait.Until(p.run, p.Period, stop)
+	Infof(p.fields(), "start periodic checker %s", p.name)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+		case <-ctx.Done():
+			return
+		}
+		// no round is started once stopped, the running one is cancelled if it does not finish in time,
+		// so that its remediations are not cut off halfway on restarts.
+		t := time.NewTimer(p.ShutdownGracePeriod)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			Warningf(p.fields(), "periodic checker %s does not finish in %v after stopped, cancel it", p.name, p.ShutdownGracePeriod)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	p.statusLock.Lock()
+	p.started = p.now()
+	p.statusLock.Unlock()
+	resynced := false
+	if p.ResyncOnStart {
+		Infof(p.fields(), "periodic checker %s resyncs on start", p.name)
+		p.runSafely(ctx)
+		resynced = true
+	}
+	// the first periodic round is delayed by the start delay, it is added to the first period if the round
+	// is replaced by the resync on start.
+	delay := p.startDelay()
+	if delay > 0 {
+		V(4).Infof(p.fields(), "periodic checker %s delays its first round by %v", p.name, delay)
+	}
+	if !resynced && delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-stop:
+			t.Stop()
+			return
+		case <-p.trigger:
+			t.Stop()
+			V(4).Infof(p.fields(), "periodic checker %s is triggered", p.name)
+		case <-t.C:
+		}
+		delay = 0
+	}
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		// the first round is skipped if it is already run by the resync on start.
+		if !resynced {
+			p.runSafely(ctx)
+		}
+		resynced = false
+
+		t := time.NewTimer(p.Period + delay)
+		delay = 0
+		select {
+		case <-stop:
+			t.Stop()
+			return
+		case <-p.trigger:
+			t.Stop()
+			V(4).Infof(p.fields(), "periodic checker %s is triggered", p.name)
+		case <-t.C:
+		}
+	}
+}
+
+// startDelay returns a random delay within StartJitter of Period.
+func (p *Patroller) startDelay() time.Duration {
+	if p.StartJitter <= 0 {
+		return 0
+	}
+	return time.Duration(p.rand.Float64() * p.StartJitter * float64(p.Period))
+}
+
+// runSafely runs a patrol round and recovers the panic of the reconciler, if any.
+func (p *Patroller) runSafely(ctx context.Context) {
+	defer utilruntime.HandleCrash()
+	p.run(ctx, nil)
+}
+
+// Trigger requests the patroller to run a patrol round immediately instead of waiting for the next period.
+// It never blocks, triggers issued while a round is pending are merged into it.
+func (p *Patroller) Trigger() {
+	select {
+	case p.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// RunOnce runs a single patrol round out of band. It waits for the running round, if any, to finish,
+// so that two rounds never run concurrently. report, if not nil, is called before any other round
+// can start, hence it observes the results of this round.
+// The requests issued while a round run on demand is running, or within MinOnDemandInterval after it
+// finishes, are coalesced into that round, so that repeated requests do not force back to back rounds.
+// report is called once the shared round finishes, before any other round can start.
+func (p *Patroller) RunOnce(ctx context.Context, report func()) {
+	p.onDemandLock.Lock()
+	round := p.onDemand
+	if round != nil {
+		select {
+		case <-round.done:
+			if p.now().Sub(round.finishedAt) >= p.MinOnDemandInterval {
+				round = nil
+			}
+		default:
+		}
+	}
+	if round == nil {
+		round = &onDemandRound{done: make(chan struct{})}
+		p.onDemand = round
+		p.onDemandLock.Unlock()
+		defer func() {
+			p.onDemandLock.Lock()
+			round.finishedAt = p.now()
+			p.onDemandLock.Unlock()
+			close(round.done)
+		}()
+		V(4).Infof(p.fields(), "periodic checker %s is run on demand", p.name)
+		p.run(ctx, report)
+		return
+	}
+	p.onDemandLock.Unlock()
+
+	V(4).Infof(p.fields(), "periodic checker %s is requested on demand again, share the last round", p.name)
+	select {
+	case <-round.done:
+	case <-ctx.Done():
+		return
+	}
+	if report != nil {
+		p.runLock.Lock()
+		defer p.runLock.Unlock()
+		report()
+	}
+}
+
+// fields returns the structured log fields of the patroller.
+func (p *Patroller) fields() Fields {
+	return Fields{Resource: strings.ToLower(p.objectKind)}
 }
 
-func (p *Patroller) run() {
-	defer metrics.RecordCheckerScanDuration(p.objectKind, time.Now())
-	p.Reconciler.PatrollerDo()
+func (p *Patroller) run(ctx context.Context, report func()) {
+	p.runLock.Lock()
+	defer p.runLock.Unlock()
+	func() {
+		defer metrics.RecordCheckerScanDuration(p.objectKind, time.Now())
+		result := &runResult{}
+		ctx := context.WithValue(ctx, runResultKey{}, result)
+		// the round which panics or is cancelled is recorded as failed, the panic is handled by the caller.
+		defer func() {
+			r := recover()
+			succeeded := r == nil && ctx.Err() == nil && !result.isFailed()
+			metrics.RecordCheckerLastRun(p.objectKind, succeeded)
+			p.recordLastRun(succeeded)
+			if r != nil {
+				panic(r)
+			}
+		}()
+		p.Reconciler.PatrollerDo(ctx)
+	}()
+	if report != nil {
+		report()
+	}
+}
+
+// recordLastRun records when the last patrol round completed and whether it succeeded, like the
+// CheckerLastRunTimestamp and CheckerLastRunSuccess metrics.
+func (p *Patroller) recordLastRun(succeeded bool) {
+	p.statusLock.Lock()
+	defer p.statusLock.Unlock()
+	p.lastRun = p.now()
+	p.lastRunSucceeded = succeeded
+}
+
+// Healthy returns false if the last patrol round failed, or if no round has completed for
+// PatrolUnhealthyPeriods periods, e.g., the patroller is wedged by a hung tenant request. A patroller which
+// is not started, e.g., the syncer is not the leader, is healthy.
+func (p *Patroller) Healthy() bool {
+	if p == nil {
+		return true
+	}
+	p.statusLock.Lock()
+	defer p.statusLock.Unlock()
+	if p.started.IsZero() {
+		return true
+	}
+	if !p.lastRun.IsZero() && !p.lastRunSucceeded {
+		return false
+	}
+	last := p.lastRun
+	if last.Before(p.started) {
+		last = p.started
+	}
+	return p.now().Sub(last) <= constants.PatrolUnhealthyPeriods*p.Period
+}
+
+// runResultKey is the context key of the result of the running patrol round.
+type runResultKey struct{}
+
+// runResult records whether a patrol round fails, it may be reported by the goroutines of the round.
+type runResult struct {
+	sync.Mutex
+	failed bool
+}
+
+func (r *runResult) isFailed() bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.failed
+}
+
+// ReportFailure marks the patrol round of the context failed, e.g., it gives up early because the super master
+// objects cannot be listed. It is a no-op if the context does not belong to a patrol round.
+func ReportFailure(ctx context.Context) {
+	r, ok := ctx.Value(runResultKey{}).(*runResult)
+	if !ok {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.failed = true
 }
@@ -0,0 +1,411 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	storagev1 "k8s.io/api/storage/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+type fakeReconciler func(ctx context.Context)
+
+func (f fakeReconciler) PatrollerDo(ctx context.Context) {
+	f(ctx)
+}
+
+func TestRunRecordsLastRun(t *testing.T) {
+	testcases := map[string]struct {
+		do       func(ctx context.Context)
+		panics   bool
+		expected float64
+	}{
+		"succeeded": {
+			do:       func(ctx context.Context) {},
+			expected: 1,
+		},
+		"reported failure": {
+			do:       func(ctx context.Context) { ReportFailure(ctx) },
+			expected: 0,
+		},
+		"panicked": {
+			do:       func(ctx context.Context) { panic("unexpected object") },
+			panics:   true,
+			expected: 0,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewPatroller(&storagev1.StorageClass{}, fakeReconciler(tc.do))
+			if err != nil {
+				t.Fatalf("unexpected error creating patroller: %v", err)
+			}
+			metrics.CheckerLastRunTimestamp.Reset()
+			metrics.CheckerLastRunSuccess.Reset()
+
+			func() {
+				defer func() {
+					if r := recover(); (r != nil) != tc.panics {
+						t.Errorf("expected panic %v, got %v", tc.panics, r)
+					}
+				}()
+				p.run(context.Background(), nil)
+			}()
+
+			if got := testutil.ToFloat64(metrics.CheckerLastRunSuccess.WithLabelValues(p.objectKind)); got != tc.expected {
+				t.Errorf("expected last run success %v, got %v", tc.expected, got)
+			}
+			if got := testutil.ToFloat64(metrics.CheckerLastRunTimestamp.WithLabelValues(p.objectKind)); got == 0 {
+				t.Errorf("expected last run timestamp to be recorded")
+			}
+		})
+	}
+}
+
+func TestReportFailureOutsideRound(t *testing.T) {
+	// must not panic when the context does not belong to a patrol round.
+	ReportFailure(context.Background())
+}
+
+func TestStartResyncOnStart(t *testing.T) {
+	var rounds int32
+	p, err := NewPatroller(&storagev1.StorageClass{}, fakeReconciler(func(ctx context.Context) {
+		atomic.AddInt32(&rounds, 1)
+	}), WithPeriod(time.Hour), WithOptions(&Options{ResyncOnStart: true}))
+	if err != nil {
+		t.Fatalf("unexpected error creating patroller: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Start(stop)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&rounds) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// the resync round replaces the first periodic round, the next one waits for the timer.
+	time.Sleep(100 * time.Millisecond)
+	if got := atomic.LoadInt32(&rounds); got != 1 {
+		t.Errorf("expected 1 round before the timer fires, got %d", got)
+	}
+
+	close(stop)
+	<-done
+}
+
+func TestHealthy(t *testing.T) {
+	failed := false
+	p, err := NewPatroller(&storagev1.StorageClass{}, fakeReconciler(func(ctx context.Context) {
+		if failed {
+			ReportFailure(ctx)
+		}
+	}), WithPeriod(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error creating patroller: %v", err)
+	}
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	now = now.Add(time.Hour)
+	if !p.Healthy() {
+		t.Errorf("expected a patroller which is not started to be healthy")
+	}
+
+	p.started = now
+	now = now.Add(2 * time.Minute)
+	if !p.Healthy() {
+		t.Errorf("expected a patroller starting its first round to be healthy")
+	}
+	now = now.Add(2 * time.Minute)
+	if p.Healthy() {
+		t.Errorf("expected a patroller which never completes a round to be unhealthy")
+	}
+
+	p.run(context.Background(), nil)
+	if !p.Healthy() {
+		t.Errorf("expected a patroller which just completed a round to be healthy")
+	}
+	now = now.Add(4 * time.Minute)
+	if p.Healthy() {
+		t.Errorf("expected a patroller wedged for more than %d periods to be unhealthy", constants.PatrolUnhealthyPeriods)
+	}
+
+	failed = true
+	p.run(context.Background(), nil)
+	if p.Healthy() {
+		t.Errorf("expected a patroller whose last round failed to be unhealthy")
+	}
+	failed = false
+	p.run(context.Background(), nil)
+	if !p.Healthy() {
+		t.Errorf("expected a patroller to be healthy once a round succeeds again")
+	}
+}
+
+func TestStartShutdownGracePeriod(t *testing.T) {
+	testcases := map[string]struct {
+		gracePeriod     time.Duration
+		finishes        bool
+		expectCancelled bool
+	}{
+		"running round finishes": {
+			gracePeriod: time.Minute,
+			finishes:    true,
+		},
+		"running round is cancelled after the grace period": {
+			gracePeriod:     50 * time.Millisecond,
+			expectCancelled: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			started := make(chan struct{})
+			finish := make(chan struct{})
+			var cancelled int32
+			p, err := NewPatroller(&storagev1.StorageClass{}, fakeReconciler(func(ctx context.Context) {
+				close(started)
+				select {
+				case <-finish:
+				case <-ctx.Done():
+					atomic.StoreInt32(&cancelled, 1)
+				}
+			}), WithPeriod(time.Hour), WithShutdownGracePeriod(tc.gracePeriod))
+			if err != nil {
+				t.Fatalf("unexpected error creating patroller: %v", err)
+			}
+
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				p.Start(stop)
+				close(done)
+			}()
+			<-started
+			close(stop)
+			if tc.finishes {
+				// the round is still running after the patroller is stopped.
+				time.Sleep(50 * time.Millisecond)
+				close(finish)
+			}
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("expected the patroller to stop")
+			}
+			if got := atomic.LoadInt32(&cancelled) == 1; got != tc.expectCancelled {
+				t.Errorf("expected round cancelled %v, got %v", tc.expectCancelled, got)
+			}
+		})
+	}
+}
+
+func TestStartDelay(t *testing.T) {
+	newPatroller := func(jitter float64, seed int64) *Patroller {
+		p, err := NewPatroller(&storagev1.StorageClass{}, fakeReconciler(func(ctx context.Context) {}),
+			WithPeriod(time.Minute), WithStartJitter(&config.SyncerConfiguration{PatrolStartJitter: jitter}))
+		if err != nil {
+			t.Fatalf("unexpected error creating patroller: %v", err)
+		}
+		p.rand = rand.New(rand.NewSource(seed))
+		return p
+	}
+
+	if got := newPatroller(0, 1).startDelay(); got != 0 {
+		t.Errorf("expected no start delay without jitter, got %v", got)
+	}
+
+	delays := map[time.Duration]bool{}
+	for seed := int64(0); seed < 20; seed++ {
+		got := newPatroller(0.5, seed).startDelay()
+		if got < 0 || got >= 30*time.Second {
+			t.Errorf("seed %d: expected start delay within half of the period, got %v", seed, got)
+		}
+		// the delay is deterministic given the seed.
+		if again := newPatroller(0.5, seed).startDelay(); again != got {
+			t.Errorf("seed %d: expected the same start delay %v, got %v", seed, got, again)
+		}
+		delays[got] = true
+	}
+	if len(delays) < 2 {
+		t.Errorf("expected the start delays to differ across seeds, got %v", delays)
+	}
+}
+
+func TestStartJitterDelaysFirstRound(t *testing.T) {
+	rounds := make(chan struct{}, 1)
+	p, err := NewPatroller(&storagev1.StorageClass{}, fakeReconciler(func(ctx context.Context) {
+		rounds <- struct{}{}
+	}), WithPeriod(time.Hour), WithOptions(&Options{StartJitter: 1}))
+	if err != nil {
+		t.Fatalf("unexpected error creating patroller: %v", err)
+	}
+	p.rand = rand.New(rand.NewSource(1))
+	if p.startDelay() < time.Minute {
+		t.Fatalf("expected the seeded start delay to outlast the test")
+	}
+	p.rand = rand.New(rand.NewSource(1))
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Start(stop)
+		close(done)
+	}()
+
+	select {
+	case <-rounds:
+		t.Errorf("expected the first round to be delayed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	// a trigger does not wait for the start delay.
+	p.Trigger()
+	select {
+	case <-rounds:
+	case <-time.After(5 * time.Second):
+		t.Errorf("expected the triggered round to run")
+	}
+
+	close(stop)
+	<-done
+}
+
+func TestTriggerCoalesced(t *testing.T) {
+	rounds := make(chan struct{}, 10)
+	release := make(chan struct{})
+	p, err := NewPatroller(&storagev1.StorageClass{}, fakeReconciler(func(ctx context.Context) {
+		rounds <- struct{}{}
+		<-release
+	}), WithPeriod(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error creating patroller: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Start(stop)
+		close(done)
+	}()
+	expectRounds := func(expected int) {
+		t.Helper()
+		for i := 0; i < expected; i++ {
+			select {
+			case <-rounds:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("expected %d rounds, got %d", expected, i)
+			}
+		}
+		select {
+		case <-rounds:
+			t.Errorf("expected no more than %d rounds", expected)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// the triggers issued while the first round is running are merged into a single round.
+	expectRounds(1)
+	p.Trigger()
+	p.Trigger()
+	p.Trigger()
+	release <- struct{}{}
+	expectRounds(1)
+	release <- struct{}{}
+
+	// the patroller waits for the next period once the triggered round is done.
+	expectRounds(0)
+
+	// a trigger issued while idle runs a single round immediately.
+	p.Trigger()
+	expectRounds(1)
+	release <- struct{}{}
+
+	close(stop)
+	<-done
+}
+
+func TestRunOnceCoalesced(t *testing.T) {
+	rounds := make(chan struct{}, 10)
+	release := make(chan struct{})
+	p, err := NewPatroller(&storagev1.StorageClass{}, fakeReconciler(func(ctx context.Context) {
+		rounds <- struct{}{}
+		<-release
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error creating patroller: %v", err)
+	}
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	var reported int32
+	report := func() { atomic.AddInt32(&reported, 1) }
+	var wg sync.WaitGroup
+	runOnce := func() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.RunOnce(context.TODO(), report)
+		}()
+	}
+
+	// the requests issued while a round is running share it.
+	runOnce()
+	<-rounds
+	runOnce()
+	runOnce()
+	// give the requests time to join the running round.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := len(rounds); n != 0 {
+		t.Errorf("expected the requests to share a single round, got %d more", n)
+	}
+	if n := atomic.LoadInt32(&reported); n != 3 {
+		t.Errorf("expected every request to be reported, got %d", n)
+	}
+
+	// so do the requests issued within the interval after the round finishes.
+	now = now.Add(p.MinOnDemandInterval / 2)
+	p.RunOnce(context.TODO(), report)
+	if n := len(rounds); n != 0 {
+		t.Errorf("expected the request within the interval to share the last round, got %d more", n)
+	}
+
+	now = now.Add(p.MinOnDemandInterval)
+	p.RunOnce(context.TODO(), report)
+	if n := len(rounds); n != 1 {
+		t.Errorf("expected a new round once the interval elapses, got %d", n)
+	}
+	if n := atomic.LoadInt32(&reported); n != 5 {
+		t.Errorf("expected 5 reports, got %d", n)
+	}
+}
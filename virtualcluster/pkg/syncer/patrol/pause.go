@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// pausedClusters are the tenant clusters whose patrol is paused, shared by the patrollers of all resources.
+var pausedClusters = struct {
+	sync.RWMutex
+	names sets.String
+}{names: sets.NewString()}
+
+// PausePatrol pauses the patrol of the tenant cluster, e.g., during its maintenance window. The patrollers
+// neither check nor remediate the objects of a paused cluster, while the cluster stays registered.
+func PausePatrol(clusterName string) {
+	pausedClusters.Lock()
+	defer pausedClusters.Unlock()
+	pausedClusters.names.Insert(clusterName)
+	metrics.PatrolPausedClusters.WithLabelValues(clusterName).Set(1)
+}
+
+// ResumePatrol resumes the patrol of the tenant cluster. The cluster is checked in full by the next patrol round
+// of each resource.
+func ResumePatrol(clusterName string) {
+	pausedClusters.Lock()
+	defer pausedClusters.Unlock()
+	pausedClusters.names.Delete(clusterName)
+	metrics.PatrolPausedClusters.DeleteLabelValues(clusterName)
+}
+
+// PatrolPaused returns true if the patrol of the tenant cluster is paused.
+func PatrolPaused(clusterName string) bool {
+	pausedClusters.RLock()
+	defer pausedClusters.RUnlock()
+	return pausedClusters.names.Has(clusterName)
+}
+
+// PausedClusters returns the sorted names of the tenant clusters whose patrol is paused.
+func PausedClusters() []string {
+	pausedClusters.RLock()
+	defer pausedClusters.RUnlock()
+	return pausedClusters.names.List()
+}
+
+// ActiveClusters filters out the tenant clusters whose patrol is paused.
+func ActiveClusters(clusterNames []string) []string {
+	pausedClusters.RLock()
+	defer pausedClusters.RUnlock()
+	if pausedClusters.names.Len() == 0 {
+		return clusterNames
+	}
+	active := make([]string, 0, len(clusterNames))
+	for _, clusterName := range clusterNames {
+		if pausedClusters.names.Has(clusterName) {
+			continue
+		}
+		active = append(active, clusterName)
+	}
+	return active
+}
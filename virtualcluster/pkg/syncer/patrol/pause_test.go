@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+func TestPausePatrol(t *testing.T) {
+	clusterNames := []string{"cluster1", "cluster2", "cluster3"}
+
+	PausePatrol("cluster2")
+	if !PatrolPaused("cluster2") || PatrolPaused("cluster1") {
+		t.Errorf("expected only cluster2 to be paused, got %v", PausedClusters())
+	}
+	if active := ActiveClusters(clusterNames); !reflect.DeepEqual(active, []string{"cluster1", "cluster3"}) {
+		t.Errorf("expected active clusters [cluster1 cluster3], got %v", active)
+	}
+	if v := testutil.ToFloat64(metrics.PatrolPausedClusters.WithLabelValues("cluster2")); v != 1 {
+		t.Errorf("expected cluster2 to be reported paused, got %v", v)
+	}
+
+	ResumePatrol("cluster2")
+	if PatrolPaused("cluster2") {
+		t.Errorf("expected cluster2 to be resumed")
+	}
+	if active := ActiveClusters(clusterNames); !reflect.DeepEqual(active, clusterNames) {
+		t.Errorf("expected all clusters to be active, got %v", active)
+	}
+	if n := testutil.CollectAndCount(metrics.PatrolPausedClusters); n != 0 {
+		t.Errorf("expected no paused cluster reported, got %d", n)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// StatusUpsyncEnabled returns true if the checker of the resource copies the status of the super master
+// objects to the tenant objects, see config.SyncerConfiguration.StatusUpsyncResources.
+func StatusUpsyncEnabled(config *config.SyncerConfiguration, resource string) bool {
+	if config == nil {
+		return false
+	}
+	for _, r := range config.StatusUpsyncResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// UpsyncStatus is the status upsync pass of a checker. It calls update to copy the super master status to the
+// tenant object if pStatus and vStatus differ, the spec is never touched. The spec of the object is reconciled
+// downward by the checker before, the status should only be upsynced once the spec is consistent, otherwise
+// the status of a stale spec may be reported to the tenant. The remedy is recorded as metricName. It returns
+// true if the status differs.
+func UpsyncStatus(fields Fields, metricName string, pStatus, vStatus interface{}, dryRun bool, update func() error) bool {
+	if equality.Semantic.DeepEqual(pStatus, vStatus) {
+		return false
+	}
+	Warningf(fields, "status of %s %s diff in super&tenant master %s", fields.Resource, fields.Object, fields.Cluster)
+	fields.Action = "upsync"
+	if dryRun {
+		Infof(fields, "[dry-run] would upsync status of %s %s to cluster %s", fields.Resource, fields.Object, fields.Cluster)
+		metrics.CheckerDryRunStats.WithLabelValues(metricName).Inc()
+		return true
+	}
+	if err := update(); err != nil {
+		fields.Err = err
+		Errorf(fields, "failed to upsync status of %s %s to cluster %s: %v", fields.Resource, fields.Object, fields.Cluster, err)
+		return true
+	}
+	metrics.CheckerRemedyStats.WithLabelValues(metricName).Inc()
+	return true
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// SweepSummary is the result of a patrol sweep of a resource aggregated across the tenant clusters.
+type SweepSummary struct {
+	// Resource is the resource being checked, e.g., storageclass.
+	Resource string `json:"resource"`
+	// StartTime and EndTime are when the sweep started and completed.
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// Clusters is the number of tenant clusters checked in the sweep.
+	Clusters int `json:"clusters"`
+	// MismatchedClusters is the number of tenant clusters with at least one mismatched object.
+	MismatchedClusters int `json:"mismatchedClusters"`
+	// Mismatched is the number of mismatched objects summed over the tenant clusters.
+	Mismatched uint64 `json:"mismatched"`
+	// DeletedOrphans is the number of orphan tenant objects deleted in the sweep.
+	DeletedOrphans uint64 `json:"deletedOrphans"`
+	// Requeued is the number of objects requeued in the sweep, including the ones recreated.
+	Requeued uint64 `json:"requeued"`
+}
+
+// SweepRecorder aggregates the remediations a checker takes during a patrol sweep, along with the objects found
+// missing in the tenant clusters. It implements AuditSink, so that it is fed the same records as the audit sink of
+// the checker. The remediations taken between two sweeps, e.g., by a retry worker, are not attributed to any sweep.
+type SweepRecorder struct {
+	sync.Mutex
+	resource string
+	inSweep  bool
+	current  SweepSummary
+	last     *SweepSummary
+	// currentMissing and lastMissing map the names of the objects missing in tenant clusters to the clusters.
+	currentMissing map[string]sets.String
+	lastMissing    map[string]sets.String
+}
+
+// NewSweepRecorder creates a sweep recorder of the resource.
+func NewSweepRecorder(resource string) *SweepRecorder {
+	return &SweepRecorder{resource: resource}
+}
+
+// Start starts a new sweep, the remediations recorded so far are dropped.
+func (r *SweepRecorder) Start() {
+	r.Lock()
+	defer r.Unlock()
+	r.inSweep = true
+	r.current = SweepSummary{Resource: r.resource, StartTime: time.Now()}
+	r.currentMissing = make(map[string]sets.String)
+}
+
+// Missing records that the object is missing in the tenant cluster in the current sweep.
+func (r *SweepRecorder) Missing(clusterName, name string) {
+	r.Lock()
+	defer r.Unlock()
+	if !r.inSweep {
+		return
+	}
+	if _, exists := r.currentMissing[name]; !exists {
+		r.currentMissing[name] = sets.NewString()
+	}
+	r.currentMissing[name].Insert(clusterName)
+}
+
+// Record implements AuditSink.
+func (r *SweepRecorder) Record(record AuditRecord) {
+	r.Lock()
+	defer r.Unlock()
+	if !r.inSweep {
+		return
+	}
+	switch record.Action {
+	case "delete":
+		r.current.DeletedOrphans++
+	case "requeue", "recreate":
+		r.current.Requeued++
+	}
+}
+
+// Finish completes the sweep with the number of mismatched objects of each tenant cluster checked, and logs
+// the summary. A sweep which is not finished, e.g., a cancelled one, does not replace the last summary.
+func (r *SweepRecorder) Finish(clusterMismatches map[string]uint64) SweepSummary {
+	r.Lock()
+	defer r.Unlock()
+	summary := r.current
+	summary.EndTime = time.Now()
+	summary.Clusters = len(clusterMismatches)
+	for _, mismatched := range clusterMismatches {
+		if mismatched > 0 {
+			summary.MismatchedClusters++
+			summary.Mismatched += mismatched
+		}
+	}
+	r.inSweep = false
+	r.last = &summary
+	r.lastMissing = r.currentMissing
+	r.currentMissing = nil
+
+	Infof(Fields{Resource: r.resource, Action: "summary"}, "%s sweep summary: clusters=%d mismatchedClusters=%d mismatched=%d deletedOrphans=%d requeued=%d duration=%v",
+		r.resource, summary.Clusters, summary.MismatchedClusters, summary.Mismatched, summary.DeletedOrphans, summary.Requeued, summary.EndTime.Sub(summary.StartTime))
+	return summary
+}
+
+// Last returns the summary of the last finished sweep. It returns false if no sweep has finished yet.
+func (r *SweepRecorder) Last() (SweepSummary, bool) {
+	r.Lock()
+	defer r.Unlock()
+	if r.last == nil {
+		return SweepSummary{}, false
+	}
+	return *r.last, true
+}
+
+// ClustersMissing returns the sorted names of the tenant clusters where the object was found missing in the last
+// finished sweep.
+func (r *SweepRecorder) ClustersMissing(name string) []string {
+	r.Lock()
+	defer r.Unlock()
+	return r.lastMissing[name].List()
+}
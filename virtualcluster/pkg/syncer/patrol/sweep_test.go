@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"testing"
+)
+
+func TestSweepRecorder(t *testing.T) {
+	r := NewSweepRecorder("storageclass")
+	if _, ok := r.Last(); ok {
+		t.Fatalf("expected no summary before the first sweep")
+	}
+
+	// the remediations taken out of a sweep are not counted.
+	r.Record(AuditRecord{Action: "delete"})
+
+	r.Start()
+	r.Missing("c2", "sc")
+	r.Missing("c1", "sc")
+	for _, action := range []string{"delete", "delete", "requeue", "recreate", "removeFinalizers"} {
+		r.Record(AuditRecord{Action: action})
+	}
+	summary := r.Finish(map[string]uint64{"c1": 2, "c2": 0, "c3": 1})
+	if summary.Resource != "storageclass" || summary.Clusters != 3 || summary.MismatchedClusters != 2 || summary.Mismatched != 3 ||
+		summary.DeletedOrphans != 2 || summary.Requeued != 2 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if summary.EndTime.Before(summary.StartTime) {
+		t.Errorf("expected the sweep to end after it starts, got %+v", summary)
+	}
+	if clusters := r.ClustersMissing("sc"); len(clusters) != 2 || clusters[0] != "c1" || clusters[1] != "c2" {
+		t.Errorf("expected sc to be missing in c1 and c2, got %v", clusters)
+	}
+
+	// a sweep in progress does not replace the last summary.
+	r.Start()
+	r.Record(AuditRecord{Action: "delete"})
+	if last, ok := r.Last(); !ok || last != summary {
+		t.Errorf("expected the last summary %+v, got %+v", summary, last)
+	}
+	r.Finish(nil)
+	if last, _ := r.Last(); last.DeletedOrphans != 1 || last.Clusters != 0 {
+		t.Errorf("expected the summary of the second sweep, got %+v", last)
+	}
+	if clusters := r.ClustersMissing("sc"); len(clusters) != 0 {
+		t.Errorf("expected sc not to be missing in the second sweep, got %v", clusters)
+	}
+}
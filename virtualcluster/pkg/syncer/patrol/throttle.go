@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// WriteThrottle bounds the rate of the writes a checker issues to each tenant cluster, so that a burst of
+// remediations, e.g., the orphans left behind by a super master cleanup, does not hammer a tenant apiserver.
+// Each tenant cluster has a token bucket of its own.
+type WriteThrottle struct {
+	sync.Mutex
+	limiters map[string]*writeLimiter
+}
+
+type writeLimiter struct {
+	qps     float32
+	burst   int
+	limiter flowcontrol.RateLimiter
+}
+
+// NewWriteThrottle creates a write throttle which does not throttle any tenant cluster until its limit is set.
+func NewWriteThrottle() *WriteThrottle {
+	return &WriteThrottle{limiters: make(map[string]*writeLimiter)}
+}
+
+// SetLimit sets the qps and burst of the writes to the tenant cluster, the writes are not throttled if qps is not
+// positive. The token bucket of the cluster is kept as long as its limit does not change.
+func (t *WriteThrottle) SetLimit(clusterName string, qps float32, burst int) {
+	t.Lock()
+	defer t.Unlock()
+	if qps <= 0 {
+		delete(t.limiters, clusterName)
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	if l, exists := t.limiters[clusterName]; exists && l.qps == qps && l.burst == burst {
+		return
+	}
+	t.limiters[clusterName] = &writeLimiter{qps: qps, burst: burst, limiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst)}
+}
+
+// Wait blocks until a write to the tenant cluster is allowed. It returns an error if the context is done first.
+func (t *WriteThrottle) Wait(ctx context.Context, clusterName string) error {
+	t.Lock()
+	l := t.limiters[clusterName]
+	t.Unlock()
+	if l == nil {
+		return nil
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// Prune drops the limits of the tenant clusters which are no longer registered.
+func (t *WriteThrottle) Prune(clusterNames []string) {
+	t.Lock()
+	defer t.Unlock()
+	active := sets.NewString(clusterNames...)
+	for clusterName := range t.limiters {
+		if !active.Has(clusterName) {
+			delete(t.limiters, clusterName)
+		}
+	}
+}
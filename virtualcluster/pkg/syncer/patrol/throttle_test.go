@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patrol
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWriteThrottle(t *testing.T) {
+	throttle := NewWriteThrottle()
+	// a tiny qps, so that the bucket is not refilled during the test.
+	throttle.SetLimit("cluster1", 0.001, 2)
+
+	waitWithTimeout := func(clusterName string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		return throttle.Wait(ctx, clusterName)
+	}
+	for i := 0; i < 2; i++ {
+		if err := waitWithTimeout("cluster1"); err != nil {
+			t.Fatalf("expected write %d within the burst to be allowed, got %v", i, err)
+		}
+	}
+	if err := waitWithTimeout("cluster1"); err == nil {
+		t.Errorf("expected write beyond the burst to be throttled")
+	}
+	if err := waitWithTimeout("cluster2"); err != nil {
+		t.Errorf("expected writes to a cluster without limit not to be throttled, got %v", err)
+	}
+
+	// the bucket is kept as long as the limit is unchanged.
+	throttle.SetLimit("cluster1", 0.001, 2)
+	if err := waitWithTimeout("cluster1"); err == nil {
+		t.Errorf("expected the bucket to be kept when the limit is set again")
+	}
+	throttle.SetLimit("cluster1", 0.001, 3)
+	if err := waitWithTimeout("cluster1"); err != nil {
+		t.Errorf("expected a new bucket once the limit changes, got %v", err)
+	}
+
+	throttle.SetLimit("cluster1", 0, 0)
+	if err := waitWithTimeout("cluster1"); err != nil {
+		t.Errorf("expected writes not to be throttled once the limit is unset, got %v", err)
+	}
+
+	throttle.SetLimit("cluster2", 0.001, 1)
+	throttle.Prune([]string{"cluster1"})
+	for i := 0; i < 2; i++ {
+		if err := waitWithTimeout("cluster2"); err != nil {
+			t.Errorf("expected the limit of the removed cluster to be dropped, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+)
+
+// patrolResult is the response of a patrol round run on demand.
+type patrolResult struct {
+	Resource   string `json:"resource"`
+	Mismatched uint64 `json:"mismatched"`
+}
+
+// patrolHandler runs a patrol round of a resource on demand, e.g., POST /patrol?resource=storageclass.
+// It blocks until the round finishes and responds with the number of mismatched objects.
+type patrolHandler struct {
+	triggers map[string]manager.PatrolTrigger
+}
+
+func (h *patrolHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resource := r.URL.Query().Get("resource")
+	t, ok := h.triggers[resource]
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource %q does not support patrol on demand", resource), http.StatusNotFound)
+		return
+	}
+
+	klog.Infof("running %s patrol on demand", resource)
+	mismatched, err := t.TriggerPatrolNow(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(patrolResult{Resource: resource, Mismatched: mismatched}); err != nil {
+		klog.Errorf("failed to write %s patrol result: %v", resource, err)
+	}
+}
+
+// pausedClusters is the response of the patrol pause and resume requests.
+type pausedClusters struct {
+	Paused []string `json:"paused"`
+}
+
+// patrolPauseHandler pauses or resumes the patrol of a tenant cluster, e.g., POST /patrol/pause?cluster=foo.
+// It responds with the clusters whose patrol is paused.
+type patrolPauseHandler struct {
+	pause bool
+	// clusterKnown returns true if the cluster is managed by syncer, the unknown clusters are never paused.
+	clusterKnown func(clusterName string) bool
+}
+
+func (h *patrolPauseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cluster := r.URL.Query().Get("cluster")
+	if cluster == "" {
+		http.Error(w, "cluster must be specified", http.StatusBadRequest)
+		return
+	}
+	// an unknown cluster can only be resumed if it is paused before it is removed.
+	if !h.clusterKnown(cluster) && (h.pause || !pa.PatrolPaused(cluster)) {
+		http.Error(w, fmt.Sprintf("cluster %q is not managed by syncer", cluster), http.StatusNotFound)
+		return
+	}
+
+	if h.pause {
+		klog.Infof("pause patrol of cluster %s", cluster)
+		pa.PausePatrol(cluster)
+	} else {
+		klog.Infof("resume patrol of cluster %s", cluster)
+		pa.ResumePatrol(cluster)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(pausedClusters{Paused: pa.PausedClusters()}); err != nil {
+		klog.Errorf("failed to write paused clusters: %v", err)
+	}
+}
+
+// PatrolHealthz fails if the patroller of any resource is unhealthy, e.g., it is wedged by a hung tenant
+// request, so that the liveness probe of the syncer restarts it. It is installed as the patrol health check.
+func (s *Syncer) PatrolHealthz(_ *http.Request) error {
+	return checkPatrolHealth(s.patrolHealthCheckers)
+}
+
+func checkPatrolHealth(checkers map[string]manager.PatrolHealthChecker) error {
+	var unhealthy []string
+	for resource, c := range checkers {
+		if !c.Healthy() {
+			unhealthy = append(unhealthy, resource)
+		}
+	}
+	if len(unhealthy) == 0 {
+		return nil
+	}
+	sort.Strings(unhealthy)
+	return fmt.Errorf("unhealthy patrollers: %s", strings.Join(unhealthy, ", "))
+}
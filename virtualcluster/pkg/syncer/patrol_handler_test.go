@@ -0,0 +1,295 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	certutil "k8s.io/client-go/util/cert"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pkiutil "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/pki"
+)
+
+type fakePatrolTrigger struct {
+	mismatched uint64
+	err        error
+	calls      int
+}
+
+func (f *fakePatrolTrigger) TriggerPatrolNow(ctx context.Context) (uint64, error) {
+	f.calls++
+	return f.mismatched, f.err
+}
+
+func TestPatrolHandler(t *testing.T) {
+	testcases := map[string]struct {
+		method       string
+		resource     string
+		trigger      *fakePatrolTrigger
+		expectedCode int
+		expectedBody string
+		expectedRun  bool
+	}{
+		"run storageclass patrol": {
+			method:       http.MethodPost,
+			resource:     "storageclass",
+			trigger:      &fakePatrolTrigger{mismatched: 3},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"resource":"storageclass","mismatched":3}`,
+			expectedRun:  true,
+		},
+		"get is not allowed": {
+			method:       http.MethodGet,
+			resource:     "storageclass",
+			trigger:      &fakePatrolTrigger{},
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		"unknown resource": {
+			method:       http.MethodPost,
+			resource:     "pod",
+			trigger:      &fakePatrolTrigger{},
+			expectedCode: http.StatusNotFound,
+		},
+		"patrol not ready": {
+			method:       http.MethodPost,
+			resource:     "storageclass",
+			trigger:      &fakePatrolTrigger{err: errors.New("storageclass cache is not synced yet")},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: "storageclass cache is not synced yet",
+			expectedRun:  true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			h := &patrolHandler{triggers: map[string]manager.PatrolTrigger{"storageclass": tc.trigger}}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tc.method, "/patrol?resource="+tc.resource, nil))
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("expected code %d, got %d", tc.expectedCode, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); tc.expectedBody != "" && body != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, body)
+			}
+			if ran := tc.trigger.calls > 0; ran != tc.expectedRun {
+				t.Errorf("expected patrol run %v, got %v", tc.expectedRun, ran)
+			}
+		})
+	}
+}
+
+// newTestCert returns a certificate signed by the CA.
+func newTestCert(t *testing.T, caCert *x509.Certificate, caKey crypto.Signer, config certutil.Config) tls.Certificate {
+	crt, key, err := pkiutil.NewCertAndKey(caCert, caKey, &pkiutil.CertConfig{Config: config})
+	if err != nil {
+		t.Fatalf("failed to create certificate %s: %v", config.CommonName, err)
+	}
+	return tls.Certificate{Certificate: [][]byte{crt.Raw}, PrivateKey: key}
+}
+
+func TestAdminServer(t *testing.T) {
+	trigger := &fakePatrolTrigger{mismatched: 3}
+	s := &Syncer{
+		config:         &config.SyncerConfiguration{},
+		patrolTriggers: map[string]manager.PatrolTrigger{"storageclass": trigger},
+	}
+
+	// the patrol is never run nor paused on the server which serves the metrics.
+	for _, path := range []string{"/patrol?resource=storageclass", "/patrol/pause?cluster=foo", "/patrol/resume?cluster=foo"} {
+		w := httptest.NewRecorder()
+		s.serverMux().ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected code %d for %s on the metrics server, got %d", http.StatusNotFound, path, w.Code)
+		}
+	}
+
+	caCert, caKey, err := pkiutil.NewCertificateAuthority(&pkiutil.CertConfig{Config: certutil.Config{CommonName: "admin-ca"}})
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	otherCACert, otherCAKey, err := pkiutil.NewCertificateAuthority(&pkiutil.CertConfig{Config: certutil.Config{CommonName: "other-ca"}})
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	clientCAFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := ioutil.WriteFile(clientCAFile, pkiutil.EncodeCertPEM(caCert), 0600); err != nil {
+		t.Fatalf("failed to write client CA file: %v", err)
+	}
+	tlsConfig, err := adminTLSConfig(clientCAFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{newTestCert(t, caCert, caKey, certutil.Config{
+		CommonName: "syncer",
+		AltNames:   certutil.AltNames{IPs: []net.IP{net.ParseIP("127.0.0.1")}},
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})}
+	server := httptest.NewUnstartedServer(s.adminMux())
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(caCert)
+
+	clientUsages := []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	testcases := map[string]struct {
+		certificates []tls.Certificate
+		expectedRun  bool
+	}{
+		"client without certificate is rejected": {},
+		"client with certificate signed by another CA is rejected": {
+			certificates: []tls.Certificate{newTestCert(t, otherCACert, otherCAKey, certutil.Config{CommonName: "admin", Usages: clientUsages})},
+		},
+		"client with certificate signed by the client CA is served": {
+			certificates: []tls.Certificate{newTestCert(t, caCert, caKey, certutil.Config{CommonName: "admin", Usages: clientUsages})},
+			expectedRun:  true,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			trigger.calls = 0
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				Certificates: tc.certificates,
+				RootCAs:      rootCAs,
+			}}}
+			resp, err := client.Post(server.URL+"/patrol?resource=storageclass", "", nil)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if tc.expectedRun && (err != nil || resp.StatusCode != http.StatusOK) {
+				t.Errorf("expected the patrol to be run, got response %v, error %v", resp, err)
+			}
+			if !tc.expectedRun && err == nil {
+				t.Errorf("expected the request to be rejected, got code %d", resp.StatusCode)
+			}
+			if ran := trigger.calls > 0; ran != tc.expectedRun {
+				t.Errorf("expected patrol run %v, got %v", tc.expectedRun, ran)
+			}
+		})
+	}
+}
+
+func TestPatrolPauseHandler(t *testing.T) {
+	known := sets.NewString("tenant-1-abcdef-foo", "tenant-2-abcdef-bar")
+	pause := &patrolPauseHandler{pause: true, clusterKnown: known.Has}
+	resume := &patrolPauseHandler{pause: false, clusterKnown: known.Has}
+
+	testcases := []struct {
+		name         string
+		handler      http.Handler
+		method       string
+		cluster      string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "pause cluster",
+			handler:      pause,
+			method:       http.MethodPost,
+			cluster:      "tenant-1-abcdef-foo",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"paused":["tenant-1-abcdef-foo"]}`,
+		},
+		{
+			name:         "get is not allowed",
+			handler:      pause,
+			method:       http.MethodGet,
+			cluster:      "tenant-2-abcdef-bar",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "cluster missing",
+			handler:      resume,
+			method:       http.MethodPost,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown cluster is not paused",
+			handler:      pause,
+			method:       http.MethodPost,
+			cluster:      "tenant-3-abcdef-baz",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "unknown cluster is not resumed",
+			handler:      resume,
+			method:       http.MethodPost,
+			cluster:      "tenant-3-abcdef-baz",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "paused cluster is resumed after it is removed",
+			handler: &patrolPauseHandler{pause: false, clusterKnown: func(string) bool {
+				return false
+			}},
+			method:       http.MethodPost,
+			cluster:      "tenant-1-abcdef-foo",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"paused":[]}`,
+		},
+	}
+
+	for _, tc := range testcases {
+		w := httptest.NewRecorder()
+		tc.handler.ServeHTTP(w, httptest.NewRequest(tc.method, "/patrol/pause?cluster="+tc.cluster, nil))
+
+		if w.Code != tc.expectedCode {
+			t.Errorf("%s: expected code %d, got %d", tc.name, tc.expectedCode, w.Code)
+		}
+		if body := strings.TrimSpace(w.Body.String()); tc.expectedBody != "" && body != tc.expectedBody {
+			t.Errorf("%s: expected body %s, got %s", tc.name, tc.expectedBody, body)
+		}
+	}
+}
+
+type fakePatrolHealthChecker bool
+
+func (f fakePatrolHealthChecker) Healthy() bool {
+	return bool(f)
+}
+
+func TestCheckPatrolHealth(t *testing.T) {
+	checkers := map[string]manager.PatrolHealthChecker{
+		"storageclass": fakePatrolHealthChecker(true),
+		"pod":          fakePatrolHealthChecker(true),
+	}
+	if err := checkPatrolHealth(checkers); err != nil {
+		t.Errorf("expected healthy patrollers, got %v", err)
+	}
+
+	checkers["service"] = fakePatrolHealthChecker(false)
+	checkers["pod"] = fakePatrolHealthChecker(false)
+	err := checkPatrolHealth(checkers)
+	if err == nil || err.Error() != "unhealthy patrollers: pod, service" {
+		t.Errorf("expected the unhealthy patrollers to be reported, got %v", err)
+	}
+}
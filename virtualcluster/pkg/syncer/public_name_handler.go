@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+)
+
+// publicNameHandler is a validating admission webhook registered in tenant masters. It rejects the creation of
+// the tenant cluster scoped objects whose names are taken by public super master objects, which would otherwise
+// be overwritten or deleted by syncer. The objects populated by syncer itself are allowed.
+type publicNameHandler struct {
+	// checkers are keyed by plugin ID, which is the lower case kind of the objects, e.g., storageclass.
+	checkers map[string]manager.PublicNameChecker
+	// syncerUsers are the users syncer is authenticated as in tenant masters. The syncer is identified by the
+	// requesting user rather than the object labels, which can be set by any tenant.
+	syncerUsers sets.String
+}
+
+func (h *publicNameHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "admission review has no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = h.admit(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		klog.Errorf("failed to write admission review response: %v", err)
+	}
+}
+
+func (h *publicNameHandler) admit(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	allowed := &admissionv1.AdmissionResponse{Allowed: true}
+	if req.Operation != admissionv1.Create || req.Namespace != "" {
+		return allowed
+	}
+	checker, ok := h.checkers[strings.ToLower(req.Kind.Kind)]
+	if !ok {
+		return allowed
+	}
+	if h.syncerUsers.Has(req.UserInfo.Username) || !checker.PublicNameTaken(req.Name) {
+		return allowed
+	}
+
+	klog.Infof("reject the creation of %s %s by %s, the name is taken by a public super master object", req.Kind.Kind, req.Name, req.UserInfo.Username)
+	return &admissionv1.AdmissionResponse{
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusForbidden,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: fmt.Sprintf("%s %s is synced from super master, choose another name", req.Kind.Kind, req.Name),
+		},
+	}
+}
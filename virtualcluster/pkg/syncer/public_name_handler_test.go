@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+)
+
+type fakePublicNameChecker struct {
+	names sets.String
+}
+
+func (f *fakePublicNameChecker) PublicNameTaken(name string) bool {
+	return f.names.Has(name)
+}
+
+func TestPublicNameHandler(t *testing.T) {
+	testcases := map[string]struct {
+		operation    admissionv1.Operation
+		kind         string
+		name         string
+		user         string
+		labels       map[string]string
+		expectedCode int32
+	}{
+		"public name is rejected": {
+			operation:    admissionv1.Create,
+			kind:         "StorageClass",
+			name:         "standard",
+			expectedCode: http.StatusForbidden,
+		},
+		"other name is allowed": {
+			operation: admissionv1.Create,
+			kind:      "StorageClass",
+			name:      "tenant-sc",
+		},
+		"syncer populated object is allowed": {
+			operation: admissionv1.Create,
+			kind:      "StorageClass",
+			name:      "standard",
+			user:      constants.DefaultTenantSyncerUser,
+			labels:    map[string]string{constants.LabelManagedBy: constants.ManagedBySyncer},
+		},
+		"tenant object labeled as syncer managed is rejected": {
+			operation:    admissionv1.Create,
+			kind:         "StorageClass",
+			name:         "standard",
+			user:         "tenant",
+			labels:       map[string]string{constants.LabelManagedBy: constants.ManagedBySyncer},
+			expectedCode: http.StatusForbidden,
+		},
+		"update is allowed": {
+			operation: admissionv1.Update,
+			kind:      "StorageClass",
+			name:      "standard",
+		},
+		"unknown kind is allowed": {
+			operation: admissionv1.Create,
+			kind:      "ClusterRole",
+			name:      "standard",
+		},
+	}
+
+	h := &publicNameHandler{
+		checkers: map[string]manager.PublicNameChecker{
+			"storageclass": &fakePublicNameChecker{names: sets.NewString("standard")},
+		},
+		syncerUsers: sets.NewString(constants.DefaultTenantSyncerUser),
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			raw, err := json.Marshal(&metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: tc.name, Labels: tc.labels}})
+			if err != nil {
+				t.Fatalf("failed to encode object: %v", err)
+			}
+			body, err := json.Marshal(&admissionv1.AdmissionReview{
+				TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+				Request: &admissionv1.AdmissionRequest{
+					UID:       "uid",
+					Kind:      metav1.GroupVersionKind{Kind: tc.kind},
+					Name:      tc.name,
+					Operation: tc.operation,
+					UserInfo:  authenticationv1.UserInfo{Username: tc.user},
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to encode admission review: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/validate-public-names", bytes.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			review := &admissionv1.AdmissionReview{}
+			if err := json.Unmarshal(w.Body.Bytes(), review); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if review.Response == nil || review.Response.UID != "uid" {
+				t.Fatalf("expected response of request uid, got %+v", review.Response)
+			}
+			if allowed := tc.expectedCode == 0; review.Response.Allowed != allowed {
+				t.Errorf("expected allowed %v, got %v", allowed, review.Response.Allowed)
+			}
+			if tc.expectedCode != 0 && (review.Response.Result == nil || review.Response.Result.Code != tc.expectedCode) {
+				t.Errorf("expected result code %d, got %+v", tc.expectedCode, review.Response.Result)
+			}
+		})
+	}
+}
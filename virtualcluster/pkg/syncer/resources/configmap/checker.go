@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
@@ -49,16 +50,19 @@ func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 
 // PatrollerDo checks to see if configmaps in super master informer cache and tenant master
 // keep consistency.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "configmap")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	pConfigMaps, err := c.configMapLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing configmaps from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 	pSet := differ.NewDiffSet()
@@ -87,6 +91,9 @@ func (c *controller) PatrollerDo() {
 
 	configMapDiffer := differ.HandlerFuncs{}
 	configMapDiffer.AddFunc = func(vObj differ.ClusterObject) {
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
 		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
 			klog.Errorf("error requeue vConfigMap %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
 		} else {
@@ -44,6 +44,8 @@ func init() {
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
 	// super master configMap client
 	configMapClient v1core.ConfigMapsGetter
 	// super master configMap informer lister/synced function
@@ -78,7 +80,9 @@ func NewConfigMapController(config *config.SyncerConfiguration,
 		c.configMapSynced = informer.Core().V1().ConfigMaps().Informer().HasSynced
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.ConfigMap{}, c, pa.WithOptions(options.PatrolOptions))
+	c.superNamespaces = pa.NewSuperNamespaceGuard("configmap", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.ConfigMap{}, c, pa.WithResourcePeriod(config, "configmap"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 )
 
 var numMissMatchedCRD uint64
@@ -46,12 +47,14 @@ func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 }
 
 // PatrollerDo checks to see if annotated CRD is in super master informer cache and then synced to tenant cluster
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "CRD")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 	wg := sync.WaitGroup{}
 	numMissMatchedCRD = 0
 
@@ -68,6 +71,7 @@ func (c *controller) PatrollerDo() {
 	err := c.superClient.List(context.Background(), pCRDList)
 	if err != nil {
 		klog.Errorf("error listing crd from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 	for _, pCRD := range pCRDList.Items {
@@ -135,7 +139,7 @@ func (c *controller) checkCRDOfTenantCluster(clusterName string) {
 			klog.Errorf("failed to get CRD  %s from super master cache: %v", vCRD.Name, err)
 			continue
 		}
-		updatedCRD := conversion.Equality(nil, nil).CheckCRDEquality(pCRD, &crdList.Items[i])
+		updatedCRD := conversion.ConfigEquality(c.Config).CheckCRDEquality(pCRD, &crdList.Items[i])
 		if updatedCRD != nil {
 			atomic.AddUint64(&numMissMatchedCRD, 1)
 			if publicCRD(pCRD) {
@@ -162,7 +162,7 @@ func NewCrdController(config *config.SyncerConfiguration,
 	if err != nil {
 		return nil, err
 	}
-	c.Patroller, err = pa.NewPatroller(&v1beta1.CustomResourceDefinition{}, c, pa.WithOptions(options.PatrolOptions))
+	c.Patroller, err = pa.NewPatroller(&v1beta1.CustomResourceDefinition{}, c, pa.WithResourcePeriod(config, "crd"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create crd patroller: %v", err)
 	}
@@ -101,7 +101,7 @@ func (c *controller) BackPopulate(key string) error {
 			return err
 		}
 	} else {
-		updatedCRD := conversion.Equality(c.Config, nil).CheckCRDEquality(pCRD, vCRD)
+		updatedCRD := conversion.ConfigEquality(c.Config).CheckCRDEquality(pCRD, vCRD)
 		if updatedCRD != nil {
 			_, err = vcapiextensionsClient.CustomResourceDefinitions().Update(context.TODO(), updatedCRD, metav1.UpdateOptions{})
 			if err != nil {
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csidriver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.csidriverSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting CSIDriver checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if CSIDriver keeps consistency between super master and tenant masters.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "csidriver")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	wg := sync.WaitGroup{}
+	atomic.StoreUint64(&c.numMissMatchedCSIDrivers, 0)
+
+	// sem bounds the number of tenant clusters being checked at the same time.
+	sem := make(chan struct{}, c.patrolConcurrency)
+	for _, clusterName := range clusterNames {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(clusterName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.checkCSIDriverOfTenantCluster(ctx, clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		klog.Infof("csidriver patrol is cancelled: %v", ctx.Err())
+		return
+	}
+
+	pCSIDriverList, err := c.csidriverLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing csidriver from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+
+	for _, pCSIDriver := range pCSIDriverList {
+		if !publicCSIDriver(pCSIDriver) {
+			continue
+		}
+		for _, clusterName := range clusterNames {
+			if err := c.MultiClusterController.Get(clusterName, "", pCSIDriver.Name, &v1.CSIDriver{}); err != nil {
+				if errors.IsNotFound(err) {
+					if c.patrollerDryRun {
+						klog.Infof("[dry-run] would requeue csidriver %s for cluster %s", pCSIDriver.Name, clusterName)
+						metrics.CheckerDryRunStats.WithLabelValues("RequeuedSuperMasterCSIDrivers").Inc()
+						continue
+					}
+					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterCSIDrivers").Inc()
+					c.UpwardController.AddToQueue(clusterName + "/" + pCSIDriver.Name)
+				}
+				klog.Errorf("fail to get csidriver from cluster %s: %v", clusterName, err)
+			}
+		}
+	}
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedCSIDrivers").Set(float64(atomic.LoadUint64(&c.numMissMatchedCSIDrivers)))
+}
+
+func (c *controller) checkCSIDriverOfTenantCluster(ctx context.Context, clusterName string) {
+	defer metrics.RecordCheckerClusterScanDuration("CSIDriver", clusterName, time.Now())
+	csidriverList := &v1.CSIDriverList{}
+	if err := c.MultiClusterController.List(clusterName, csidriverList); err != nil {
+		klog.Errorf("error listing csidriver from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check csidriver consistency in cluster %s", clusterName)
+
+	for i, vCSIDriver := range csidriverList.Items {
+		if ctx.Err() != nil {
+			klog.V(4).Infof("stop checking csidriver in cluster %s: %v", clusterName, ctx.Err())
+			return
+		}
+		pCSIDriver, err := c.csidriverLister.Get(vCSIDriver.Name)
+		// csidriver which is no longer public is treated as orphan.
+		if errors.IsNotFound(err) || (err == nil && !publicCSIDriver(pCSIDriver)) {
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would delete orphan csidriver %s in cluster %s", vCSIDriver.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("DeletedOrphanTenantCSIDrivers").Inc()
+				continue
+			}
+			// super master is the source of the truth for csidriver object, delete tenant master obj
+			tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+			if err != nil {
+				klog.Errorf("error getting cluster %s clientset: %v", clusterName, err)
+				continue
+			}
+			opts := &metav1.DeleteOptions{
+				PropagationPolicy: &constants.DefaultDeletionPolicy,
+			}
+			deleteCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+			err = tenantClient.StorageV1().CSIDrivers().Delete(deleteCtx, vCSIDriver.Name, *opts)
+			cancel()
+			if err != nil {
+				klog.Errorf("error deleting csidriver %v in cluster %s: %v", vCSIDriver.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanTenantCSIDrivers").Inc()
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pCSIDriver %s from super master cache: %v", vCSIDriver.Name, err)
+			continue
+		}
+
+		updatedCSIDriver := conversion.ConfigEquality(c.Config).CheckCSIDriverEquality(pCSIDriver, &csidriverList.Items[i])
+		if updatedCSIDriver != nil {
+			atomic.AddUint64(&c.numMissMatchedCSIDrivers, 1)
+			klog.Warningf("spec of csidriver %v diff in super&tenant master", vCSIDriver.Name)
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would requeue csidriver %s for cluster %s", pCSIDriver.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("RequeuedDiffCSIDrivers").Inc()
+				continue
+			}
+			c.UpwardController.AddToQueue(clusterName + "/" + pCSIDriver.Name)
+		}
+	}
+}
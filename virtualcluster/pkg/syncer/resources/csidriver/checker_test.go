@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csidriver
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func TestCSIDriverPatrol(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	dryRun := func(r manager.ResourceSyncer) {
+		r.(*controller).patrollerDryRun = true
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedDeletedVObject []string
+		ExpectedCreatedVObject []string
+		ExpectedNoOperation    bool
+		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
+		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
+		StateModifyFunc        func(manager.ResourceSyncer)
+	}{
+		"pCSIDriver not public": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pCSIDriver exists, vCSIDriver does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345", public),
+			},
+			WaitUWS: true,
+			ExpectedCreatedVObject: []string{
+				"csi.example.com",
+			},
+		},
+		"pCSIDriver not found, vCSIDriver exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345"),
+			},
+			ExpectedDeletedVObject: []string{
+				"csi.example.com",
+			},
+		},
+		"pCSIDriver not public, vCSIDriver exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "123456"),
+			},
+			ExpectedDeletedVObject: []string{
+				"csi.example.com",
+			},
+		},
+		"pCSIDriver exists, vCSIDriver exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "123456", func(driver *v1.CSIDriver) {
+					driver.Spec.AttachRequired = pointer.BoolPtr(false)
+				}),
+			},
+			ExpectedDeletedVObject: []string{
+				"csi.example.com",
+			},
+			ExpectedCreatedVObject: []string{
+				"csi.example.com",
+			},
+			WaitUWS: true,
+		},
+		"dry run, pCSIDriver exists, vCSIDriver does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345", public),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
+		"dry run, pCSIDriver not found, vCSIDriver exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345"),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
+		"dry run, pCSIDriver exists, vCSIDriver exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "123456", func(driver *v1.CSIDriver) {
+					driver.Spec.AttachRequired = pointer.BoolPtr(false)
+				}),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenantActions, superActions, err := util.RunPatrol(NewCSIDriverController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, tc.StateModifyFunc)
+			if err != nil {
+				t.Errorf("%s: error running patrol: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(superActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v in super cluster", k, superActions)
+					return
+				}
+				if len(tenantActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v tenant cluster", k, tenantActions)
+					return
+				}
+				return
+			}
+
+			for _, expectedName := range tc.ExpectedDeletedVObject {
+				matched := false
+				for _, action := range tenantActions {
+					if !action.Matches("delete", "csidrivers") {
+						continue
+					}
+					fullName := action.(core.DeleteAction).GetName()
+					if fullName != expectedName {
+						t.Errorf("%s: Expect to delete pCSIDriver %s, got %s", k, expectedName, fullName)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect to delete pCSIDriver %s, but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedCreatedVObject {
+				matched := false
+				for _, action := range tenantActions {
+					if !action.Matches("create", "csidrivers") {
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.CSIDriver)
+					if created.Name != expectedName {
+						t.Errorf("%s: Expect to create pCSIDriver %s, got %s", k, expectedName, created.Name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect to create pCSIDriver %s, but not found", k, expectedName)
+				}
+			}
+		})
+	}
+}
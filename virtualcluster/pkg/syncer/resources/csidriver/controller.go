@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csidriver
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/storage/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	v1storage "k8s.io/client-go/kubernetes/typed/storage/v1"
+	listersv1 "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "csidriver",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewCSIDriverController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptionsFromConfig(ctx.Config.(*config.SyncerConfiguration)))
+		},
+		Disable: true,
+	})
+}
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// super master csidrivers client
+	client v1storage.CSIDriversGetter
+	// super master csidrivers lister/synced functions
+	csidriverLister listersv1.CSIDriverLister
+	csidriverSynced cache.InformerSynced
+	// patrollerDryRun indicates that the patroller only logs the remediation it would take.
+	patrollerDryRun bool
+	// patrolConcurrency is the max number of tenant clusters checked in parallel.
+	patrolConcurrency int
+	// patrolOpTimeout is the timeout of each tenant operation issued by the patroller.
+	patrolOpTimeout time.Duration
+	// numMissMatchedCSIDrivers is the number of mismatched csidrivers found in the last patrol.
+	numMissMatchedCSIDrivers uint64
+}
+
+func NewCSIDriverController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		client:            client.StorageV1(),
+		patrollerDryRun:   options.PatrollerDryRun,
+		patrolConcurrency: constants.DefaultPatrolConcurrency,
+		patrolOpTimeout:   constants.DefaultPatrolOpTimeout,
+	}
+	if options.PatrolConcurrency > 0 {
+		c.patrolConcurrency = options.PatrolConcurrency
+	}
+	if options.PatrolOpTimeout > 0 {
+		c.patrolOpTimeout = options.PatrolOpTimeout
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.CSIDriver{}, &v1.CSIDriverList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.csidriverLister = informer.Storage().V1().CSIDrivers().Lister()
+	if options.IsFake {
+		c.csidriverSynced = func() bool { return true }
+	} else {
+		c.csidriverSynced = informer.Storage().V1().CSIDrivers().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&v1.CSIDriver{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&v1.CSIDriver{}, c, pa.WithResourcePeriod(config, "csidriver"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	informer.Storage().V1().CSIDrivers().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.CSIDriver:
+					return publicCSIDriver(t)
+				case cache.DeletedFinalStateUnknown:
+					if e, ok := t.Obj.(*v1.CSIDriver); ok {
+						return publicCSIDriver(e)
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.CSIDriver", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super master csidriver controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueCSIDriver,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newCSIDriver := newObj.(*v1.CSIDriver)
+					oldCSIDriver := oldObj.(*v1.CSIDriver)
+					if newCSIDriver.ResourceVersion != oldCSIDriver.ResourceVersion {
+						c.enqueueCSIDriver(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueCSIDriver,
+			},
+		})
+	return c, nil
+}
+
+func publicCSIDriver(e *v1.CSIDriver) bool {
+	// We only backpopulate specific csidriver to tenant masters
+	return e.Labels[constants.PublicObjectKey] == "true"
+}
+
+func (c *controller) enqueueCSIDriver(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("No tenant masters, stop backpopulate csidriver %v", key)
+		return
+	}
+
+	for _, clusterName := range clusterNames {
+		c.UpwardController.AddToQueue(clusterName + "/" + key)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csidriver
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.csidriverSynced) {
+		return fmt.Errorf("failed to wait for caches to sync csidriver")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+func (c *controller) BackPopulate(key string) error {
+	// The key format is clustername/csidriverName.
+	clusterName, name, _ := cache.SplitMetaNamespaceKey(key)
+
+	op := reconciler.AddEvent
+	pCSIDriver, err := c.csidriverLister.Get(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		op = reconciler.DeleteEvent
+	} else if !publicCSIDriver(pCSIDriver) {
+		op = reconciler.DeleteEvent
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+
+	vCSIDriver := &v1.CSIDriver{}
+	if err := c.MultiClusterController.Get(clusterName, "", name, vCSIDriver); err != nil {
+		if errors.IsNotFound(err) {
+			if op == reconciler.AddEvent {
+				// Available in super, hence create a new in tenant master
+				vCSIDriver := conversion.BuildVirtualCSIDriver(clusterName, pCSIDriver)
+				_, err := tenantClient.StorageV1().CSIDrivers().Create(context.TODO(), vCSIDriver, metav1.CreateOptions{})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return err
+	}
+
+	if op == reconciler.AddEvent && conversion.ConfigEquality(c.Config).CheckCSIDriverEquality(pCSIDriver, vCSIDriver) == nil {
+		return nil
+	}
+
+	// The csidriver spec is immutable, hence a tenant csidriver which differs from super master is
+	// deleted and created again instead of being updated.
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+		Preconditions:     metav1.NewUIDPreconditions(string(vCSIDriver.UID)),
+	}
+	if err := tenantClient.StorageV1().CSIDrivers().Delete(context.TODO(), name, *opts); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if op == reconciler.DeleteEvent {
+		return nil
+	}
+	_, err = tenantClient.StorageV1().CSIDrivers().Create(context.TODO(), conversion.BuildVirtualCSIDriver(clusterName, pCSIDriver), metav1.CreateOptions{})
+	return err
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csidriver
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func makeCSIDriver(name, uid string, mFuncs ...func(*v1.CSIDriver)) *v1.CSIDriver {
+	driver := &v1.CSIDriver{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CSIDriver",
+			APIVersion: "storage.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(uid),
+		},
+		Spec: v1.CSIDriverSpec{
+			AttachRequired: pointer.BoolPtr(true),
+			PodInfoOnMount: pointer.BoolPtr(false),
+		},
+	}
+
+	for _, f := range mFuncs {
+		f(driver)
+	}
+	return driver
+}
+
+func public(driver *v1.CSIDriver) {
+	driver.Labels = map[string]string{
+		constants.PublicObjectKey: "true",
+	}
+}
+
+func TestUWCSIDriver(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueuedKey            string
+		ExpectedCreatedObject  []string
+		ExpectedDeletedObject  []string
+		ExpectedError          string
+		ExpectedNoOperation    bool
+	}{
+		"pCSIDriver exists but vCSIDriver not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345", public),
+			},
+			EnqueuedKey:           defaultClusterKey + "/csi.example.com",
+			ExpectedCreatedObject: []string{"csi.example.com"},
+		},
+		"pCSIDriver not public, vCSIDriver not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/csi.example.com",
+			ExpectedNoOperation: true,
+		},
+		"pCSIDriver exists, vCSIDriver exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "123456"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/csi.example.com",
+			ExpectedNoOperation: true,
+		},
+		"pCSIDriver exists, vCSIDriver exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "123456", func(driver *v1.CSIDriver) {
+					driver.Spec.AttachRequired = pointer.BoolPtr(false)
+				}),
+			},
+			EnqueuedKey:           defaultClusterKey + "/csi.example.com",
+			ExpectedDeletedObject: []string{"csi.example.com"},
+			ExpectedCreatedObject: []string{"csi.example.com"},
+		},
+		"pCSIDriver not found, vCSIDriver exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "123456"),
+			},
+			EnqueuedKey:           defaultClusterKey + "/csi.example.com",
+			ExpectedDeletedObject: []string{"csi.example.com"},
+		},
+		"pCSIDriver not public, vCSIDriver exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeCSIDriver("csi.example.com", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeCSIDriver("csi.example.com", "123456"),
+			},
+			EnqueuedKey:           defaultClusterKey + "/csi.example.com",
+			ExpectedDeletedObject: []string{"csi.example.com"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(NewCSIDriverController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedDeletedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("delete", "csidrivers") {
+						continue
+					}
+					name := action.(core.DeleteAction).GetName()
+					if name != expectedName {
+						t.Errorf("%s: Expected deleted vCSIDriver %s, got %s", k, expectedName, name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect deleted vCSIDriver %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedCreatedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("create", "csidrivers") {
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.CSIDriver)
+					if created.Name != expectedName {
+						t.Errorf("%s: Expected created vCSIDriver %s, got %s", k, expectedName, created.Name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect created vCSIDriver %s but not found", k, expectedName)
+				}
+			}
+		})
+	}
+}
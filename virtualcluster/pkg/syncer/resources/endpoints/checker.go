@@ -16,6 +16,7 @@ limitations under the License.
 package endpoints
 
 import (
+	"context"
 	"fmt"
 	"sync/atomic"
 
@@ -28,6 +29,7 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 )
 
@@ -47,12 +49,14 @@ func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 // PatrollerDo checks to see if Endpoints in super master informer cache and tenant master
 // keep consistency.
 // Note that eps are managed by tenant/super ep controller separately. The checker will not do GC but only report diff.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "endpoint")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	numMissingEndPoints = 0
 	numMissMatchedEndPoints = 0
@@ -60,6 +64,7 @@ func (c *controller) PatrollerDo() {
 	pList, err := c.endpointsLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing endpoints from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 	pSet := differ.NewDiffSet()
@@ -89,6 +94,9 @@ func (c *controller) PatrollerDo() {
 	d := differ.HandlerFuncs{}
 	d.AddFunc = func(vObj differ.ClusterObject) {
 		atomic.AddUint64(&numMissingEndPoints, 1)
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
 		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj); err != nil {
 			klog.Errorf("error requeue vEndpoints %s: %v", vObj.Key, err)
 		} else {
@@ -98,7 +106,7 @@ func (c *controller) PatrollerDo() {
 	d.UpdateFunc = func(vObj, pObj differ.ClusterObject) {
 		v := vObj.Object.(*v1.Endpoints)
 		p := pObj.Object.(*v1.Endpoints)
-		updated := conversion.Equality(c.Config, nil).CheckEndpointsEquality(p, v)
+		updated := conversion.ConfigEquality(c.Config).CheckEndpointsEquality(p, v)
 		if updated != nil {
 			atomic.AddUint64(&numMissMatchedEndPoints, 1)
 			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj); err != nil {
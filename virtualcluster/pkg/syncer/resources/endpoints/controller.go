@@ -44,6 +44,8 @@ func init() {
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
 	// super master endpoints client
 	endpointClient v1core.EndpointsGetter
 	// super master endpoints informer lister/synced function
@@ -77,7 +79,9 @@ func NewEndpointsController(config *config.SyncerConfiguration,
 		c.endpointsSynced = informer.Core().V1().Endpoints().Informer().HasSynced
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.Endpoints{}, c, pa.WithOptions(options.PatrolOptions))
+	c.superNamespaces = pa.NewSuperNamespaceGuard("endpoints", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.Endpoints{}, c, pa.WithResourcePeriod(config, "endpoints"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpointslice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if c.apiAbsent {
+		<-stopCh
+		return nil
+	}
+	if !cache.WaitForCacheSync(stopCh, c.endpointSliceSynced, c.serviceSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting EndpointSlice checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if the super master endpointslices of the synced services are mirrored to tenant masters.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "endpointslice")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	wg := sync.WaitGroup{}
+	atomic.StoreUint64(&c.numMissMatchedEndpointSlices, 0)
+
+	served := make(map[string]bool, len(clusterNames))
+	for _, clusterName := range clusterNames {
+		served[clusterName] = c.endpointSliceServedInCluster(clusterName)
+	}
+
+	// sem bounds the number of tenant clusters being checked at the same time.
+	sem := make(chan struct{}, c.patrolConcurrency)
+	for _, clusterName := range clusterNames {
+		if ctx.Err() != nil {
+			break
+		}
+		if !served[clusterName] {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(clusterName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.checkEndpointSlicesOfTenantCluster(ctx, clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		klog.Infof("endpointslice patrol is cancelled: %v", ctx.Err())
+		return
+	}
+
+	pEndpointSliceList, err := c.endpointSliceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing endpointslice from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+
+	for _, pEndpointSlice := range pEndpointSliceList {
+		pService, err := c.syncedService(pEndpointSlice)
+		if err != nil || pService == nil {
+			continue
+		}
+		clusterName, vNamespace := conversion.GetVirtualOwner(pService)
+		if !served[clusterName] {
+			continue
+		}
+		if err := c.MultiClusterController.Get(clusterName, vNamespace, pEndpointSlice.Name, &v1.EndpointSlice{}); err != nil {
+			if errors.IsNotFound(err) {
+				if c.patrollerDryRun {
+					klog.Infof("[dry-run] would requeue endpointslice %s/%s for cluster %s", pEndpointSlice.Namespace, pEndpointSlice.Name, clusterName)
+					metrics.CheckerDryRunStats.WithLabelValues("RequeuedSuperMasterEndpointSlices").Inc()
+					continue
+				}
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterEndpointSlices").Inc()
+				c.UpwardController.AddToQueue(pEndpointSlice.Namespace + "/" + pService.Name)
+				continue
+			}
+			klog.Errorf("fail to get endpointslice %s/%s from cluster %s: %v", vNamespace, pEndpointSlice.Name, clusterName, err)
+		}
+	}
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedEndpointSlices").Set(float64(atomic.LoadUint64(&c.numMissMatchedEndpointSlices)))
+}
+
+// syncedService returns the super master service of the endpointslice if the service is synced from a
+// tenant master and has a selector, otherwise it returns nil.
+func (c *controller) syncedService(pEndpointSlice *v1.EndpointSlice) (*corev1.Service, error) {
+	name := serviceName(pEndpointSlice)
+	if name == "" {
+		return nil, nil
+	}
+	pService, err := c.serviceLister.Services(pEndpointSlice.Namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(pService.Spec.Selector) == 0 {
+		return nil, nil
+	}
+	if clusterName, vNamespace := conversion.GetVirtualOwner(pService); clusterName == "" || vNamespace == "" {
+		return nil, nil
+	}
+	return pService, nil
+}
+
+func (c *controller) checkEndpointSlicesOfTenantCluster(ctx context.Context, clusterName string) {
+	defer metrics.RecordCheckerClusterScanDuration("EndpointSlice", clusterName, time.Now())
+	endpointSliceList := &v1.EndpointSliceList{}
+	if err := c.MultiClusterController.List(clusterName, endpointSliceList); err != nil {
+		klog.Errorf("error listing endpointslice from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check endpointslice consistency in cluster %s", clusterName)
+
+	for i, vEndpointSlice := range endpointSliceList.Items {
+		if ctx.Err() != nil {
+			klog.V(4).Infof("stop checking endpointslice in cluster %s: %v", clusterName, ctx.Err())
+			return
+		}
+		if !conversion.IsSyncerManagedEndpointSlice(&vEndpointSlice) {
+			continue
+		}
+		pNamespace := conversion.ToSuperMasterNamespace(clusterName, vEndpointSlice.Namespace)
+		key := pNamespace + "/" + serviceName(&vEndpointSlice)
+
+		pEndpointSlice, err := c.endpointSliceLister.EndpointSlices(pNamespace).Get(vEndpointSlice.Name)
+		if errors.IsNotFound(err) {
+			// The orphan endpointslice is removed by the upward syncer.
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would requeue orphan endpointslice %s/%s in cluster %s", vEndpointSlice.Namespace, vEndpointSlice.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("RequeuedOrphanTenantEndpointSlices").Inc()
+				continue
+			}
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedOrphanTenantEndpointSlices").Inc()
+			c.UpwardController.AddToQueue(key)
+			continue
+		}
+		if err != nil {
+			klog.Errorf("failed to get pEndpointSlice %s/%s from super master cache: %v", pNamespace, vEndpointSlice.Name, err)
+			continue
+		}
+
+		vService := &corev1.Service{}
+		if err := c.MultiClusterController.Get(clusterName, vEndpointSlice.Namespace, serviceName(&vEndpointSlice), vService); err != nil {
+			if !errors.IsNotFound(err) {
+				klog.Errorf("failed to get service %s/%s from cluster %s: %v", vEndpointSlice.Namespace, serviceName(&vEndpointSlice), clusterName, err)
+			}
+			continue
+		}
+
+		expected := conversion.BuildVirtualEndpointSlice(pEndpointSlice, vService)
+		updatedEndpointSlice := conversion.ConfigEquality(c.Config).CheckEndpointSliceEquality(expected, &endpointSliceList.Items[i])
+		if updatedEndpointSlice != nil {
+			atomic.AddUint64(&c.numMissMatchedEndpointSlices, 1)
+			klog.Warningf("endpointslice %s/%s diff in super&tenant master %s", vEndpointSlice.Namespace, vEndpointSlice.Name, clusterName)
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would requeue endpointslice %s/%s for cluster %s", pNamespace, pEndpointSlice.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("RequeuedDiffEndpointSlices").Inc()
+				continue
+			}
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedDiffEndpointSlices").Inc()
+			c.UpwardController.AddToQueue(key)
+		}
+	}
+}
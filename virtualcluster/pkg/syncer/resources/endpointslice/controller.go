@@ -0,0 +1,264 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpointslice
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	listersdiscoveryv1 "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "endpointslice",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewEndpointSliceController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptionsFromConfig(ctx.Config.(*config.SyncerConfiguration)))
+		},
+		Disable: true,
+	})
+}
+
+// controller mirrors the super master endpointslices of the services synced from tenant masters back to
+// the tenant masters, so that tenant endpointslice consumers see the endpoints of the super master pods.
+// Only the services with a selector are handled, the endpoints of the services without a selector are
+// populated from tenant masters to super master by the endpoints syncer.
+type controller struct {
+	manager.BaseResourceSyncer
+	// apiAbsent indicates that super master does not serve discovery.k8s.io/v1, e.g., it only serves the
+	// deprecated v1beta1 endpointslices, in which case the syncer does nothing.
+	apiAbsent bool
+	// super master endpointslices lister/synced functions
+	endpointSliceLister listersdiscoveryv1.EndpointSliceLister
+	endpointSliceSynced cache.InformerSynced
+	// super master services lister/synced functions
+	serviceLister listersv1.ServiceLister
+	serviceSynced cache.InformerSynced
+	// patrollerDryRun indicates that the patroller only logs the remediation it would take.
+	patrollerDryRun bool
+	// patrolConcurrency is the max number of tenant clusters checked in parallel.
+	patrolConcurrency int
+	// patrolOpTimeout is the timeout of each tenant operation issued by the patroller.
+	patrolOpTimeout time.Duration
+	// numMissMatchedEndpointSlices is the number of mismatched endpointslices found in the last patrol.
+	numMissMatchedEndpointSlices uint64
+}
+
+func NewEndpointSliceController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		patrollerDryRun:   options.PatrollerDryRun,
+		patrolConcurrency: constants.DefaultPatrolConcurrency,
+		patrolOpTimeout:   constants.DefaultPatrolOpTimeout,
+	}
+	if options.PatrolConcurrency > 0 {
+		c.patrolConcurrency = options.PatrolConcurrency
+	}
+	if options.PatrolOpTimeout > 0 {
+		c.patrolOpTimeout = options.PatrolOpTimeout
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.EndpointSlice{}, &v1.EndpointSliceList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.IsFake {
+		served, err := endpointSliceServed(client.Discovery())
+		if err != nil {
+			return nil, err
+		}
+		if !served {
+			klog.Warningf("super master does not serve %s endpointslices, endpointslice syncer is a no-op", v1.SchemeGroupVersion)
+			c.apiAbsent = true
+			return c, nil
+		}
+	}
+
+	c.endpointSliceLister = informer.Discovery().V1().EndpointSlices().Lister()
+	c.serviceLister = informer.Core().V1().Services().Lister()
+	if options.IsFake {
+		c.endpointSliceSynced = func() bool { return true }
+		c.serviceSynced = func() bool { return true }
+	} else {
+		c.endpointSliceSynced = informer.Discovery().V1().EndpointSlices().Informer().HasSynced
+		c.serviceSynced = informer.Core().V1().Services().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&v1.EndpointSlice{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&v1.EndpointSlice{}, c, pa.WithResourcePeriod(config, "endpointslice"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	informer.Discovery().V1().EndpointSlices().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.EndpointSlice:
+					return serviceName(t) != ""
+				case cache.DeletedFinalStateUnknown:
+					if e, ok := t.Obj.(*v1.EndpointSlice); ok {
+						return serviceName(e) != ""
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.EndpointSlice", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super master endpointslice controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueEndpointSlice,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newEndpointSlice := newObj.(*v1.EndpointSlice)
+					oldEndpointSlice := oldObj.(*v1.EndpointSlice)
+					if newEndpointSlice.ResourceVersion != oldEndpointSlice.ResourceVersion {
+						c.enqueueEndpointSlice(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueEndpointSlice,
+			},
+		})
+	return c, nil
+}
+
+// endpointSliceServed checks whether super master serves discovery.k8s.io/v1 endpointslices. Clusters older
+// than v1.21 only serve v1beta1 endpointslices, which are not mirrored.
+func endpointSliceServed(dc discovery.DiscoveryInterface) (bool, error) {
+	resources, err := dc.ServerResourcesForGroupVersion(v1.SchemeGroupVersion.String())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == "endpointslices" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// minEndpointSliceV1Version is the first kubernetes version which serves discovery.k8s.io/v1 endpointslices.
+var minEndpointSliceV1Version = utilversion.MustParseGeneric("1.21.0")
+
+// endpointSliceServedInCluster returns false if the tenant cluster is too old to serve discovery.k8s.io/v1
+// endpointslices, the endpointslices of such a cluster are neither watched nor mirrored. The endpointslices
+// are mirrored if the version is unknown.
+func (c *controller) endpointSliceServedInCluster(clusterName string) bool {
+	info, err := c.MultiClusterController.GetClusterVersion(clusterName)
+	if err != nil {
+		klog.V(4).Infof("failed to get version of cluster %s: %v", clusterName, err)
+		return true
+	}
+	return endpointSliceServedInVersion(info)
+}
+
+func endpointSliceServedInVersion(info *version.Info) bool {
+	v, err := utilversion.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return true
+	}
+	return v.AtLeast(minEndpointSliceV1Version)
+}
+
+// serviceName returns the name of the service which the endpointslice belongs to.
+func serviceName(e *v1.EndpointSlice) string {
+	return e.Labels[v1.LabelServiceName]
+}
+
+func (c *controller) enqueueEndpointSlice(obj interface{}) {
+	var e *v1.EndpointSlice
+	switch t := obj.(type) {
+	case *v1.EndpointSlice:
+		e = t
+	case cache.DeletedFinalStateUnknown:
+		e = t.Obj.(*v1.EndpointSlice)
+	}
+	// The endpointslices of a service are reconciled together, the key format is pNamespace/serviceName.
+	c.UpwardController.AddToQueue(e.Namespace + "/" + serviceName(e))
+}
+
+// GetListener skips watching the tenant clusters which do not serve discovery.k8s.io/v1 endpointslices.
+func (c *controller) GetListener() listener.ClusterChangeListener {
+	if c.apiAbsent {
+		return noopListener{}
+	}
+	return &clusterChangeListener{
+		ClusterChangeListener: c.BaseResourceSyncer.GetListener(),
+		c:                     c,
+	}
+}
+
+type clusterChangeListener struct {
+	listener.ClusterChangeListener
+	c *controller
+}
+
+func (l *clusterChangeListener) WatchCluster(cluster mc.ClusterInterface) {
+	info, err := cluster.GetClusterVersion()
+	if err == nil && !endpointSliceServedInVersion(info) {
+		klog.Infof("cluster %s does not serve %s endpointslices, skip watching it", cluster.GetClusterName(), v1.SchemeGroupVersion)
+		return
+	}
+	l.ClusterChangeListener.WatchCluster(cluster)
+}
+
+// noopListener ignores tenant cluster changes, it is used when super master does not serve endpointslices.
+type noopListener struct{}
+
+var _ listener.ClusterChangeListener = noopListener{}
+
+func (noopListener) AddCluster(cluster mc.ClusterInterface)    {}
+func (noopListener) WatchCluster(cluster mc.ClusterInterface)  {}
+func (noopListener) RemoveCluster(cluster mc.ClusterInterface) {}
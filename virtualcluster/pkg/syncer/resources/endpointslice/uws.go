@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpointslice
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	v1discovery "k8s.io/client-go/kubernetes/typed/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if c.apiAbsent {
+		<-stopCh
+		return nil
+	}
+	if !cache.WaitForCacheSync(stopCh, c.endpointSliceSynced, c.serviceSynced) {
+		return fmt.Errorf("failed to wait for caches to sync endpointslice")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+// BackPopulate mirrors all super master endpointslices of a service to the tenant master.
+func (c *controller) BackPopulate(key string) error {
+	// The key format is pNamespace/serviceName.
+	pNamespace, pName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key %v: %v", key, err))
+		return nil
+	}
+
+	pService, err := c.serviceLister.Services(pNamespace).Get(pName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// The tenant endpointslices are garbage collected together with the tenant service.
+			return nil
+		}
+		return err
+	}
+	if len(pService.Spec.Selector) == 0 {
+		return nil
+	}
+
+	clusterName, vNamespace := conversion.GetVirtualOwner(pService)
+	if clusterName == "" || vNamespace == "" {
+		return nil
+	}
+	if !c.endpointSliceServedInCluster(clusterName) {
+		klog.V(4).Infof("cluster %s does not serve %s endpointslice, skip %s", clusterName, v1.SchemeGroupVersion, key)
+		return nil
+	}
+
+	vService := &corev1.Service{}
+	if err := c.MultiClusterController.Get(clusterName, vNamespace, pName, vService); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("could not find pService %s/%s's vService in controller cache: %v", vNamespace, pName, err)
+	}
+	if pService.Annotations[constants.LabelUID] != string(vService.UID) {
+		return fmt.Errorf("BackPopulated pService %s/%s delegated UID is different from updated object.", pNamespace, pName)
+	}
+
+	pEndpointSlices, err := c.endpointSliceLister.EndpointSlices(pNamespace).List(labels.SelectorFromSet(labels.Set{v1.LabelServiceName: pName}))
+	if err != nil {
+		return err
+	}
+	vEndpointSliceList := &v1.EndpointSliceList{}
+	if err := c.MultiClusterController.List(clusterName, vEndpointSliceList, client.InNamespace(vNamespace), client.MatchingLabels{v1.LabelServiceName: vService.Name}); err != nil {
+		return err
+	}
+	vEndpointSlices := make(map[string]*v1.EndpointSlice, len(vEndpointSliceList.Items))
+	for i := range vEndpointSliceList.Items {
+		vEndpointSlices[vEndpointSliceList.Items[i].Name] = &vEndpointSliceList.Items[i]
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	vClient := tenantClient.DiscoveryV1().EndpointSlices(vNamespace)
+
+	mirrored := make(map[string]bool, len(pEndpointSlices))
+	for _, pEndpointSlice := range pEndpointSlices {
+		mirrored[pEndpointSlice.Name] = true
+		expected := conversion.BuildVirtualEndpointSlice(pEndpointSlice, vService)
+		vEndpointSlice, exists := vEndpointSlices[pEndpointSlice.Name]
+		if !exists {
+			if _, err := vClient.Create(context.TODO(), expected, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create endpointslice %s/%s in cluster %s: %v", vNamespace, expected.Name, clusterName, err)
+			}
+			continue
+		}
+		if !conversion.IsSyncerManagedEndpointSlice(vEndpointSlice) {
+			klog.Warningf("endpointslice %s/%s in cluster %s is not managed by syncer, skip mirroring", vNamespace, vEndpointSlice.Name, clusterName)
+			continue
+		}
+		updated := conversion.ConfigEquality(c.Config).CheckEndpointSliceEquality(expected, vEndpointSlice)
+		if updated == nil {
+			continue
+		}
+		if updated.AddressType == vEndpointSlice.AddressType {
+			if _, err := vClient.Update(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("failed to update endpointslice %s/%s in cluster %s: %v", vNamespace, updated.Name, clusterName, err)
+			}
+			continue
+		}
+		// The address type is immutable, hence the endpointslice is deleted and created again.
+		if err := c.deleteEndpointSlice(vClient, vEndpointSlice); err != nil {
+			return fmt.Errorf("failed to delete endpointslice %s/%s in cluster %s: %v", vNamespace, vEndpointSlice.Name, clusterName, err)
+		}
+		if _, err := vClient.Create(context.TODO(), expected, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create endpointslice %s/%s in cluster %s: %v", vNamespace, expected.Name, clusterName, err)
+		}
+	}
+
+	for name, vEndpointSlice := range vEndpointSlices {
+		if mirrored[name] || !conversion.IsSyncerManagedEndpointSlice(vEndpointSlice) {
+			continue
+		}
+		if err := c.deleteEndpointSlice(vClient, vEndpointSlice); err != nil {
+			return fmt.Errorf("failed to delete endpointslice %s/%s in cluster %s: %v", vNamespace, name, clusterName, err)
+		}
+	}
+	return nil
+}
+
+func (c *controller) deleteEndpointSlice(vClient v1discovery.EndpointSliceInterface, vEndpointSlice *v1.EndpointSlice) error {
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+		Preconditions:     metav1.NewUIDPreconditions(string(vEndpointSlice.UID)),
+	}
+	if err := vClient.Delete(context.TODO(), vEndpointSlice.Name, *opts); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpointslice
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
+	core "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func superService(name, namespace, uid, clusterKey string, selector map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: conversion.ToSuperMasterNamespace(clusterKey, namespace),
+			Annotations: map[string]string{
+				constants.LabelCluster:   clusterKey,
+				constants.LabelNamespace: namespace,
+				constants.LabelUID:       uid,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+		},
+	}
+}
+
+func tenantService(name, namespace, uid string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+func makeEndpointSlice(name, namespace, service string, mFuncs ...func(*v1.EndpointSlice)) *v1.EndpointSlice {
+	e := &v1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				v1.LabelServiceName: service,
+			},
+		},
+		AddressType: v1.AddressTypeIPv4,
+		Endpoints: []v1.Endpoint{
+			{
+				Addresses: []string{"10.0.0.1"},
+				TargetRef: &corev1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: "pod-1", UID: "pod-uid"},
+			},
+		},
+		Ports: []v1.EndpointPort{
+			{Port: pointer.Int32Ptr(80)},
+		},
+	}
+	for _, f := range mFuncs {
+		f(e)
+	}
+	return e
+}
+
+func syncerManaged(e *v1.EndpointSlice) {
+	e.Labels[v1.LabelManagedBy] = constants.EndpointSliceManagedBySyncer
+}
+
+func TestUWEndpointSlice(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+	selector := map[string]string{"app": "web"}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueuedKey            string
+		ExpectedCreatedObject  []string
+		ExpectedUpdatedObject  []string
+		ExpectedDeletedObject  []string
+		ExpectedError          string
+		ExpectedNoOperation    bool
+	}{
+		"pEndpointSlice exists, vEndpointSlice not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				superService("svc", "default", "12345", defaultClusterKey, selector),
+				makeEndpointSlice("svc-abc", superDefaultNSName, "svc"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantService("svc", "default", "12345"),
+			},
+			EnqueuedKey:           superDefaultNSName + "/svc",
+			ExpectedCreatedObject: []string{"default/svc-abc"},
+		},
+		"pService without selector": {
+			ExistingObjectInSuper: []runtime.Object{
+				superService("svc", "default", "12345", defaultClusterKey, nil),
+				makeEndpointSlice("svc-abc", superDefaultNSName, "svc"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantService("svc", "default", "12345"),
+			},
+			EnqueuedKey:         superDefaultNSName + "/svc",
+			ExpectedNoOperation: true,
+		},
+		"vService uid mismatch": {
+			ExistingObjectInSuper: []runtime.Object{
+				superService("svc", "default", "12345", defaultClusterKey, selector),
+				makeEndpointSlice("svc-abc", superDefaultNSName, "svc"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantService("svc", "default", "123456"),
+			},
+			EnqueuedKey:   superDefaultNSName + "/svc",
+			ExpectedError: "delegated UID is different",
+		},
+		"pEndpointSlice exists, vEndpointSlice is consistent": {
+			ExistingObjectInSuper: []runtime.Object{
+				superService("svc", "default", "12345", defaultClusterKey, selector),
+				makeEndpointSlice("svc-abc", superDefaultNSName, "svc"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantService("svc", "default", "12345"),
+				makeEndpointSlice("svc-abc", "default", "svc", syncerManaged, func(e *v1.EndpointSlice) {
+					e.Endpoints[0].TargetRef.UID = ""
+				}),
+			},
+			EnqueuedKey:         superDefaultNSName + "/svc",
+			ExpectedNoOperation: true,
+		},
+		"pEndpointSlice exists, vEndpointSlice has different endpoints": {
+			ExistingObjectInSuper: []runtime.Object{
+				superService("svc", "default", "12345", defaultClusterKey, selector),
+				makeEndpointSlice("svc-abc", superDefaultNSName, "svc"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantService("svc", "default", "12345"),
+				makeEndpointSlice("svc-abc", "default", "svc", syncerManaged, func(e *v1.EndpointSlice) {
+					e.Endpoints[0].Addresses = []string{"10.0.0.2"}
+				}),
+			},
+			EnqueuedKey:           superDefaultNSName + "/svc",
+			ExpectedUpdatedObject: []string{"default/svc-abc"},
+		},
+		"pEndpointSlice exists, vEndpointSlice not managed by syncer": {
+			ExistingObjectInSuper: []runtime.Object{
+				superService("svc", "default", "12345", defaultClusterKey, selector),
+				makeEndpointSlice("svc-abc", superDefaultNSName, "svc"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantService("svc", "default", "12345"),
+				makeEndpointSlice("svc-abc", "default", "svc", func(e *v1.EndpointSlice) {
+					e.Endpoints[0].Addresses = []string{"10.0.0.2"}
+				}),
+			},
+			EnqueuedKey:         superDefaultNSName + "/svc",
+			ExpectedNoOperation: true,
+		},
+		"pEndpointSlice not found, vEndpointSlice exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superService("svc", "default", "12345", defaultClusterKey, selector),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantService("svc", "default", "12345"),
+				makeEndpointSlice("svc-abc", "default", "svc", syncerManaged),
+			},
+			EnqueuedKey:           superDefaultNSName + "/svc",
+			ExpectedDeletedObject: []string{"default/svc-abc"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(NewEndpointSliceController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+				}
+				return
+			}
+
+			for _, expectedName := range tc.ExpectedCreatedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("create", "endpointslices") {
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.EndpointSlice)
+					fullName := created.Namespace + "/" + created.Name
+					if fullName != expectedName {
+						t.Errorf("%s: Expected created vEndpointSlice %s, got %s", k, expectedName, fullName)
+					}
+					if created.Labels[v1.LabelServiceName] != "svc" || !conversion.IsSyncerManagedEndpointSlice(created) {
+						t.Errorf("%s: Expected created vEndpointSlice labels rewritten, got %v", k, created.Labels)
+					}
+					if ref := created.Endpoints[0].TargetRef; ref.Namespace != "default" || ref.UID != "" {
+						t.Errorf("%s: Expected target reference to point to tenant namespace, got %v", k, ref)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect created vEndpointSlice %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedUpdatedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("update", "endpointslices") {
+						continue
+					}
+					updated := action.(core.UpdateAction).GetObject().(*v1.EndpointSlice)
+					fullName := updated.Namespace + "/" + updated.Name
+					if fullName != expectedName {
+						t.Errorf("%s: Expected updated vEndpointSlice %s, got %s", k, expectedName, fullName)
+					}
+					if updated.Endpoints[0].Addresses[0] != "10.0.0.1" {
+						t.Errorf("%s: Expected updated vEndpointSlice addresses %v, got %v", k, []string{"10.0.0.1"}, updated.Endpoints[0].Addresses)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect updated vEndpointSlice %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedDeletedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("delete", "endpointslices") {
+						continue
+					}
+					deleted := action.(core.DeleteAction)
+					fullName := deleted.GetNamespace() + "/" + deleted.GetName()
+					if fullName != expectedName {
+						t.Errorf("%s: Expected deleted vEndpointSlice %s, got %s", k, expectedName, fullName)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect deleted vEndpointSlice %s but not found", k, expectedName)
+				}
+			}
+		})
+	}
+}
+
+func TestEndpointSliceServedInVersion(t *testing.T) {
+	for _, tt := range []struct {
+		gitVersion string
+		served     bool
+	}{
+		{gitVersion: "v1.20.7", served: false},
+		{gitVersion: "v1.21.1", served: true},
+		{gitVersion: "v1.22.0-beta.0", served: true},
+		{gitVersion: "unknown", served: true},
+	} {
+		if served := endpointSliceServedInVersion(&version.Info{GitVersion: tt.gitVersion}); served != tt.served {
+			t.Errorf("version %s: expected served %v, got %v", tt.gitVersion, tt.served, served)
+		}
+	}
+}
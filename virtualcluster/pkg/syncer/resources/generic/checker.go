@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+
+	if !cache.WaitForCacheSync(stopCh, c.synced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting %s checker", c.name())
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo checks to see if the custom resource objects in super master informer cache and tenant master
+// keep consistency.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", c.name())
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	pObjs, err := c.lister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing %s from super master informer cache: %v", c.name(), err)
+		pa.ReportFailure(ctx)
+		return
+	}
+	pSet := differ.NewDiffSet()
+	for _, obj := range pObjs {
+		pObj := obj.(*unstructured.Unstructured)
+		pSet.Insert(differ.ClusterObject{Object: pObj, Key: differ.DefaultClusterObjectKey(pObj, "")})
+	}
+
+	knownClusterSet := sets.NewString(clusterNames...)
+	vSet := differ.NewDiffSet()
+	for _, cluster := range clusterNames {
+		vList := c.newList()
+		if err := c.MultiClusterController.List(cluster, vList); err != nil {
+			klog.Errorf("error listing %s from cluster %s informer cache: %v", c.name(), cluster, err)
+			knownClusterSet.Delete(cluster)
+			continue
+		}
+
+		for i := range vList.Items {
+			vSet.Insert(differ.ClusterObject{
+				Object:       &vList.Items[i],
+				OwnerCluster: cluster,
+				Key:          differ.DefaultClusterObjectKey(&vList.Items[i], cluster),
+			})
+		}
+	}
+
+	atomic.StoreUint64(&c.numMissMatched, 0)
+	d := differ.HandlerFuncs{}
+	d.AddFunc = func(vObj differ.ClusterObject) {
+		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+			klog.Errorf("error requeue %s %v/%v in cluster %s: %v", c.name(), vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues(fmt.Sprintf("RequeuedTenant%s", c.gvk.Kind)).Inc()
+		}
+	}
+	d.UpdateFunc = func(vObj, pObj differ.ClusterObject) {
+		v := vObj.Object.(*unstructured.Unstructured)
+		p := pObj.Object.(*unstructured.Unstructured)
+
+		if p.GetAnnotations()[constants.LabelUID] != string(v.GetUID()) {
+			klog.Errorf("Found super master %s %s delegated UID is different from tenant object.", c.name(), pObj.Key)
+			d.OnDelete(pObj)
+			return
+		}
+		vc, err := util.GetVirtualClusterObject(c.MultiClusterController, vObj.GetOwnerCluster())
+		if err != nil {
+			klog.Errorf("fail to get cluster spec : %s", vObj.GetOwnerCluster())
+			return
+		}
+		if updated := conversion.Equality(c.Config, vc).CheckUnstructuredEquality(p, v); updated != nil {
+			atomic.AddUint64(&c.numMissMatched, 1)
+			klog.Warningf("%s %s diff in super&tenant master", c.name(), pObj.Key)
+			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, v); err != nil {
+				klog.Errorf("error requeue %s %s in cluster %s: %v", c.name(), vObj.Key, vObj.GetOwnerCluster(), err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues(fmt.Sprintf("RequeuedTenant%s", c.gvk.Kind)).Inc()
+			}
+		}
+	}
+	d.DeleteFunc = func(pObj differ.ClusterObject) {
+		deleteOptions := &metav1.DeleteOptions{}
+		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pObj.GetUID()))
+		if err := c.resource.Namespace(pObj.GetNamespace()).Delete(ctx, pObj.GetName(), *deleteOptions); err != nil {
+			klog.Errorf("error deleting super master %s %s: %v", c.name(), pObj.Key, err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues(fmt.Sprintf("DeletedOrphanSuperMaster%s", c.gvk.Kind)).Inc()
+		}
+	}
+
+	vSet.Difference(pSet, differ.FilteringHandler{
+		Handler:    d,
+		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
+	})
+
+	metrics.CheckerMissMatchStats.WithLabelValues(fmt.Sprintf("MissMatched%s", c.gvk.Kind)).Set(float64(atomic.LoadUint64(&c.numMissMatched)))
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generic implements a resource syncer of the namespaced custom resources based on the dynamic client,
+// so that a custom resource can be synced without a resource syncer of its own.
+package generic
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// gvr and gvk are the resource and the kind of the synced custom resource.
+	gvr schema.GroupVersionResource
+	gvk schema.GroupVersionKind
+	// resource is the super master client of the synced custom resource.
+	resource dynamic.NamespaceableResourceInterface
+	// super master informer/lister/synced functions of the synced custom resource
+	informer informers.GenericInformer
+	lister   cache.GenericLister
+	synced   cache.InformerSynced
+	// numMissMatched is the number of mismatched objects found in the last patrol.
+	numMissMatched uint64
+}
+
+// NewGenericController creates a resource syncer of the namespaced custom resource. The tenant objects are synced to
+// the super master namespaces of their tenant namespaces, their status is neither synced nor checked.
+func NewGenericController(config *config.SyncerConfiguration,
+	resource config.GenericResource,
+	client dynamic.Interface,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	if resource.Version == "" || resource.Resource == "" || resource.Kind == "" {
+		return nil, fmt.Errorf("generic resource syncer: version, resource and kind of %+v must be specified", resource)
+	}
+	gvr := schema.GroupVersionResource{Group: resource.Group, Version: resource.Version, Resource: resource.Resource}
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		gvr:      gvr,
+		gvk:      gvr.GroupVersion().WithKind(resource.Kind),
+		resource: client.Resource(gvr),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(c.newObject(), c.newList(), c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.informer = dynamicinformer.NewFilteredDynamicInformer(client, gvr, metav1.NamespaceAll, 0, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, nil)
+	c.lister = c.informer.Lister()
+	if options.IsFake {
+		c.synced = func() bool { return true }
+	} else {
+		c.synced = c.informer.Informer().HasSynced
+	}
+
+	c.Patroller, err = pa.NewPatroller(c.newObject(), c, pa.WithResourcePeriod(config, c.name()), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// name returns the resource name used by the logs and the patrol periods, e.g., certificates.cert-manager.io.
+func (c *controller) name() string {
+	return c.gvr.GroupResource().String()
+}
+
+// newObject returns an empty object of the synced custom resource.
+func (c *controller) newObject() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(c.gvk)
+	return obj
+}
+
+// newList returns an empty list of the synced custom resource.
+func (c *controller) newList() *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(c.gvk.GroupVersion().WithKind(c.gvk.Kind + "List"))
+	return list
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// StartDWS runs the super master informer of the custom resource, which is not shared with other resource syncers,
+// and starts the downward syncer.
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	go c.informer.Informer().Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, c.synced) {
+		return fmt.Errorf("failed to wait for caches to sync %s", c.name())
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+// The reconcile logic for tenant master custom resource informer
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	klog.V(4).Infof("reconcile %s %s/%s event for cluster %s", c.name(), request.Namespace, request.Name, request.ClusterName)
+
+	targetNamespace := conversion.ToSuperMasterNamespace(request.ClusterName, request.Namespace)
+	var pObj *unstructured.Unstructured
+	obj, err := c.lister.ByNamespace(targetNamespace).Get(request.Name)
+	pExists := true
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		pExists = false
+	} else {
+		pObj = obj.(*unstructured.Unstructured)
+	}
+	vExists := true
+	vObj := c.newObject()
+	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vObj); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		vExists = false
+	}
+
+	if vExists && !pExists {
+		err := c.reconcileCreate(request.ClusterName, targetNamespace, request.UID, vObj)
+		if err != nil {
+			klog.Errorf("failed reconcile %s %s/%s CREATE of cluster %s %v", c.name(), request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if !vExists && pExists {
+		err := c.reconcileRemove(request.ClusterName, targetNamespace, request.UID, request.Name, pObj)
+		if err != nil {
+			klog.Errorf("failed reconcile %s %s/%s DELETE of cluster %s %v", c.name(), request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if vExists && pExists {
+		err := c.reconcileUpdate(request.ClusterName, targetNamespace, request.UID, pObj, vObj)
+		if err != nil {
+			klog.Errorf("failed reconcile %s %s/%s UPDATE of cluster %s %v", c.name(), request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else {
+		// object is gone.
+	}
+	return reconciler.Result{}, nil
+}
+
+func (c *controller) reconcileCreate(clusterName, targetNamespace, requestUID string, vObj *unstructured.Unstructured) error {
+	vcName, vcNS, _, err := c.MultiClusterController.GetOwnerInfo(clusterName)
+	if err != nil {
+		return err
+	}
+	newObj, err := conversion.BuildMetadata(clusterName, vcNS, vcName, targetNamespace, vObj)
+	if err != nil {
+		return err
+	}
+	pObj := newObj.(*unstructured.Unstructured)
+	// the status is populated by the super master controllers of the custom resource, if any.
+	unstructured.RemoveNestedField(pObj.Object, "status")
+
+	_, err = c.resource.Namespace(targetNamespace).Create(context.TODO(), pObj, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		existing, getErr := c.resource.Namespace(targetNamespace).Get(context.TODO(), pObj.GetName(), metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		if existing.GetAnnotations()[constants.LabelUID] == requestUID {
+			klog.Infof("%s %s/%s of cluster %s already exist in super master", c.name(), targetNamespace, pObj.GetName(), clusterName)
+			return nil
+		}
+		return fmt.Errorf("%s %s/%s exists but its delegated object UID is different", c.name(), targetNamespace, pObj.GetName())
+	}
+	return err
+}
+
+func (c *controller) reconcileUpdate(clusterName, targetNamespace, requestUID string, pObj, vObj *unstructured.Unstructured) error {
+	if pObj.GetAnnotations()[constants.LabelUID] != requestUID {
+		return fmt.Errorf("%s %s/%s delegated UID is different from updated object", c.name(), targetNamespace, pObj.GetName())
+	}
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	updated := conversion.Equality(c.Config, vc).CheckUnstructuredEquality(pObj, vObj)
+	if updated != nil {
+		_, err = c.resource.Namespace(targetNamespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) reconcileRemove(clusterName, targetNamespace, requestUID, name string, pObj *unstructured.Unstructured) error {
+	if pObj.GetAnnotations()[constants.LabelUID] != requestUID {
+		return fmt.Errorf("to be deleted %s %s/%s delegated UID is different from deleted object", c.name(), targetNamespace, name)
+	}
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+	}
+	err := c.resource.Namespace(targetNamespace).Delete(context.TODO(), name, *opts)
+	if errors.IsNotFound(err) {
+		klog.Warningf("%s %s/%s of cluster %s not found in super master", c.name(), targetNamespace, name, clusterName)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	core "k8s.io/client-go/testing"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// The tests sync configmaps as a custom resource, since the fake tenant client only serves the registered types.
+var configMapResource = config.GenericResource{Version: "v1", Resource: "configmaps", Kind: "ConfigMap"}
+
+func tenantObject(name, namespace, uid string, data map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+		Data: data,
+	}
+}
+
+func superObject(name, namespace, uid, clusterKey string, data map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetAnnotations(map[string]string{
+		constants.LabelUID:       uid,
+		constants.LabelCluster:   clusterKey,
+		constants.LabelNamespace: "default",
+	})
+	if data != nil {
+		unstructured.SetNestedStringMap(obj.Object, data, "data")
+	}
+	return obj
+}
+
+func TestGenericReconcile(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	clusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(clusterKey, "default")
+	request := reconciler.Request{ClusterName: clusterKey, UID: "12345"}
+	request.Namespace = "default"
+	request.Name = "cert"
+
+	testcases := map[string]struct {
+		existingObjectInSuper  []runtime.Object
+		existingObjectInTenant []runtime.Object
+		request                reconciler.Request
+		expectedVerb           string
+		expectedData           map[string]string
+	}{
+		"tenant object created": {
+			existingObjectInTenant: []runtime.Object{tenantObject("cert", "default", "12345", map[string]string{"a": "1"})},
+			request:                request,
+			expectedVerb:           "create",
+			expectedData:           map[string]string{"a": "1"},
+		},
+		"tenant object updated": {
+			existingObjectInSuper:  []runtime.Object{superObject("cert", superDefaultNSName, "12345", clusterKey, map[string]string{"a": "1"})},
+			existingObjectInTenant: []runtime.Object{tenantObject("cert", "default", "12345", map[string]string{"a": "2"})},
+			request:                request,
+			expectedVerb:           "update",
+			expectedData:           map[string]string{"a": "2"},
+		},
+		"tenant object unchanged": {
+			existingObjectInSuper:  []runtime.Object{superObject("cert", superDefaultNSName, "12345", clusterKey, map[string]string{"a": "1"})},
+			existingObjectInTenant: []runtime.Object{tenantObject("cert", "default", "12345", map[string]string{"a": "1"})},
+			request:                request,
+		},
+		"tenant object deleted": {
+			existingObjectInSuper: []runtime.Object{superObject("cert", superDefaultNSName, "12345", clusterKey, nil)},
+			request:               request,
+			expectedVerb:          "delete",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			superClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, tc.existingObjectInSuper...)
+			s, err := NewGenericController(&config.SyncerConfiguration{}, configMapResource, superClient, manager.ResourceSyncerOptions{IsFake: true})
+			if err != nil {
+				t.Fatalf("error creating controller: %v", err)
+			}
+			c := s.(*controller)
+			for _, obj := range tc.existingObjectInSuper {
+				c.informer.Informer().GetStore().Add(obj)
+			}
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient(tc.existingObjectInTenant...))
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			c.GetListener().AddCluster(tenantCluster)
+			superClient.ClearActions()
+
+			if _, err := c.Reconcile(tc.request); err != nil {
+				t.Fatalf("unexpected reconcile error: %v", err)
+			}
+
+			actions := superClient.Actions()
+			if tc.expectedVerb == "" {
+				if len(actions) != 0 {
+					t.Errorf("expected no operation, got %v", actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches(tc.expectedVerb, "configmaps") {
+				t.Fatalf("expected a %s of configmaps, got %v", tc.expectedVerb, actions)
+			}
+			if actions[0].GetNamespace() != superDefaultNSName {
+				t.Errorf("expected the object in namespace %s, got %s", superDefaultNSName, actions[0].GetNamespace())
+			}
+			if tc.expectedData == nil {
+				return
+			}
+			obj := actions[0].(core.CreateAction).GetObject().(*unstructured.Unstructured)
+			data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+			if len(data) != len(tc.expectedData) || data["a"] != tc.expectedData["a"] {
+				t.Errorf("expected data %v, got %v", tc.expectedData, data)
+			}
+			if obj.GetAnnotations()[constants.LabelUID] != tc.request.UID {
+				t.Errorf("expected delegated UID %s, got %s", tc.request.UID, obj.GetAnnotations()[constants.LabelUID])
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+
+	if !cache.WaitForCacheSync(stopCh, c.hpaSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting HorizontalPodAutoscaler checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo checks to see if horizontalpodautoscalers in super master informer cache and tenant master
+// keep consistency.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "horizontalpodautoscaler")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	atomic.StoreUint64(&c.numMissMatchedHorizontalPodAutoscalers, 0)
+
+	pHorizontalPodAutoscalers, err := c.hpaLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing horizontalpodautoscalers from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+	pSet := differ.NewDiffSet()
+	for _, pHPA := range pHorizontalPodAutoscalers {
+		pSet.Insert(differ.ClusterObject{Object: pHPA, Key: differ.DefaultClusterObjectKey(pHPA, "")})
+	}
+
+	knownClusterSet := sets.NewString(clusterNames...)
+	vSet := differ.NewDiffSet()
+	for _, cluster := range clusterNames {
+		hpaList := &v2beta2.HorizontalPodAutoscalerList{}
+		if err := c.MultiClusterController.List(cluster, hpaList); err != nil {
+			klog.Errorf("error listing horizontalpodautoscalers from cluster %s informer cache: %v", cluster, err)
+			knownClusterSet.Delete(cluster)
+			continue
+		}
+
+		for i := range hpaList.Items {
+			vSet.Insert(differ.ClusterObject{
+				Object:       &hpaList.Items[i],
+				OwnerCluster: cluster,
+				Key:          differ.DefaultClusterObjectKey(&hpaList.Items[i], cluster),
+			})
+		}
+	}
+
+	hpaDiffer := differ.HandlerFuncs{}
+	hpaDiffer.AddFunc = func(vObj differ.ClusterObject) {
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
+		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+			klog.Errorf("error requeue vHPA %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantHorizontalPodAutoscalers").Inc()
+		}
+	}
+	hpaDiffer.UpdateFunc = func(vObj, pObj differ.ClusterObject) {
+		vHPA := vObj.Object.(*v2beta2.HorizontalPodAutoscaler)
+		pHPA := pObj.Object.(*v2beta2.HorizontalPodAutoscaler)
+
+		if pHPA.Annotations[constants.LabelUID] != string(vHPA.UID) {
+			klog.Errorf("Found pHPA %s delegated UID is different from tenant object.", pObj.Key)
+			hpaDiffer.OnDelete(pObj)
+			return
+		}
+		vc, err := util.GetVirtualClusterObject(c.MultiClusterController, vObj.GetOwnerCluster())
+		if err != nil {
+			klog.Errorf("fail to get cluster spec : %s", vObj.GetOwnerCluster())
+			return
+		}
+		updated := conversion.Equality(c.Config, vc).CheckHorizontalPodAutoscalerEquality(pHPA, vHPA)
+		if updated != nil {
+			atomic.AddUint64(&c.numMissMatchedHorizontalPodAutoscalers, 1)
+			klog.Warningf("HorizontalPodAutoscaler %s diff in super&tenant master", pObj.Key)
+			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+				klog.Errorf("error requeue vHPA %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantHorizontalPodAutoscalers").Inc()
+			}
+		}
+		if !equality.Semantic.DeepEqual(vHPA.Status, pHPA.Status) {
+			klog.Warningf("status of HorizontalPodAutoscaler %s diff in super&tenant master", pObj.Key)
+			c.UpwardController.AddToQueue(pHPA.Namespace + "/" + pHPA.Name)
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterHorizontalPodAutoscalers").Inc()
+		}
+	}
+	hpaDiffer.DeleteFunc = func(pObj differ.ClusterObject) {
+		deleteOptions := &metav1.DeleteOptions{}
+		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pObj.GetUID()))
+		if err := c.hpaClient.HorizontalPodAutoscalers(pObj.GetNamespace()).Delete(ctx, pObj.GetName(), *deleteOptions); err != nil {
+			klog.Errorf("error deleting pHPA %s in super master: %v", pObj.Key, err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperMasterHorizontalPodAutoscalers").Inc()
+		}
+	}
+
+	vSet.Difference(pSet, differ.FilteringHandler{
+		Handler:    hpaDiffer,
+		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
+	})
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedHorizontalPodAutoscalers").Set(float64(atomic.LoadUint64(&c.numMissMatchedHorizontalPodAutoscalers)))
+}
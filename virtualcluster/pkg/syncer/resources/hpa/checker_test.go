@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"testing"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func TestHorizontalPodAutoscalerPatrol(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedDeletedPObject []string
+		ExpectedCreatedPObject []string
+		ExpectedUpdatedPObject []runtime.Object
+		ExpectedUpdatedVObject []runtime.Object
+		ExpectedNoOperation    bool
+		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
+		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
+	}{
+		"pHorizontalPodAutoscaler not created by vc": {
+			ExistingObjectInSuper: []runtime.Object{
+				tenantHorizontalPodAutoscaler("hpa-1", superDefaultNSName, "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pHorizontalPodAutoscaler exists, vHorizontalPodAutoscaler does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superHorizontalPodAutoscaler("hpa-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExpectedDeletedPObject: []string{
+				superDefaultNSName + "/hpa-2",
+			},
+		},
+		"pHorizontalPodAutoscaler exists, vHorizontalPodAutoscaler exists with different uid": {
+			ExistingObjectInSuper: []runtime.Object{
+				superHorizontalPodAutoscaler("hpa-3", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantHorizontalPodAutoscaler("hpa-3", "default", "123456"),
+			},
+			ExpectedDeletedPObject: []string{
+				superDefaultNSName + "/hpa-3",
+			},
+		},
+		"pHorizontalPodAutoscaler exists, vHorizontalPodAutoscaler exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				superHorizontalPodAutoscaler("hpa-4", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyReplicas(tenantHorizontalPodAutoscaler("hpa-4", "default", "12345"), 1, 3),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyReplicas(superHorizontalPodAutoscaler("hpa-4", superDefaultNSName, "12345", defaultClusterKey), 1, 3),
+			},
+			WaitDWS: true,
+		},
+		"pHorizontalPodAutoscaler exists, vHorizontalPodAutoscaler exists with different status": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatus(superHorizontalPodAutoscaler("hpa-5", superDefaultNSName, "12345", defaultClusterKey), 2, 3),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantHorizontalPodAutoscaler("hpa-5", "default", "12345"),
+			},
+			ExpectedUpdatedVObject: []runtime.Object{
+				applyStatus(tenantHorizontalPodAutoscaler("hpa-5", "default", "12345"), 2, 3),
+			},
+			WaitUWS: true,
+		},
+		"vHorizontalPodAutoscaler exists, pHorizontalPodAutoscaler does not exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantHorizontalPodAutoscaler("hpa-6", "default", "12345"),
+			},
+			ExpectedCreatedPObject: []string{
+				superDefaultNSName + "/hpa-6",
+			},
+			WaitDWS: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenantActions, superActions, err := util.RunPatrol(NewHorizontalPodAutoscalerController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
+			if err != nil {
+				t.Errorf("%s: error running patrol: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(superActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v in super cluster", k, superActions)
+					return
+				}
+				if len(tenantActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v tenant cluster", k, tenantActions)
+					return
+				}
+				return
+			}
+
+			if tc.ExpectedDeletedPObject != nil {
+				if len(tc.ExpectedDeletedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to delete pHorizontalPodAutoscaler %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPObject, superActions)
+					return
+				}
+				for i, expectedName := range tc.ExpectedDeletedPObject {
+					action := superActions[i]
+					if !action.Matches("delete", "horizontalpodautoscalers") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+						continue
+					}
+					fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+					if fullName != expectedName {
+						t.Errorf("%s: Expect to delete pHorizontalPodAutoscaler %s, got %s", k, expectedName, fullName)
+					}
+				}
+			}
+			if tc.ExpectedCreatedPObject != nil {
+				if len(tc.ExpectedCreatedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to create pHorizontalPodAutoscaler %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPObject, superActions)
+					return
+				}
+				for i, expectedName := range tc.ExpectedCreatedPObject {
+					action := superActions[i]
+					if !action.Matches("create", "horizontalpodautoscalers") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v2beta2.HorizontalPodAutoscaler)
+					fullName := created.Namespace + "/" + created.Name
+					if fullName != expectedName {
+						t.Errorf("%s: Expect to create pHorizontalPodAutoscaler %s, got %s", k, expectedName, fullName)
+					}
+				}
+			}
+			if tc.ExpectedUpdatedPObject != nil {
+				if len(tc.ExpectedUpdatedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to update pHorizontalPodAutoscaler %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPObject, superActions)
+					return
+				}
+				for i, obj := range tc.ExpectedUpdatedPObject {
+					action := superActions[i]
+					if !action.Matches("update", "horizontalpodautoscalers") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+					}
+					actionObj := action.(core.UpdateAction).GetObject()
+					if !equality.Semantic.DeepEqual(obj, actionObj) {
+						t.Errorf("%s: Expected updated pHorizontalPodAutoscaler is %v, got %v", k, obj, actionObj)
+					}
+				}
+			}
+			if tc.ExpectedUpdatedVObject != nil {
+				if len(tc.ExpectedUpdatedVObject) != len(tenantActions) {
+					t.Errorf("%s: Expected to update vHorizontalPodAutoscaler %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedVObject, tenantActions)
+					return
+				}
+				for i, obj := range tc.ExpectedUpdatedVObject {
+					action := tenantActions[i]
+					if !action.Matches("update", "horizontalpodautoscalers") || action.GetSubresource() != "status" {
+						t.Errorf("%s: Unexpected action %s", k, action)
+					}
+					actionObj := action.(core.UpdateAction).GetObject()
+					accessor, _ := meta.Accessor(obj)
+					accessor.SetResourceVersion("999")
+					if !equality.Semantic.DeepEqual(obj, actionObj) {
+						t.Errorf("%s: Expected updated vHorizontalPodAutoscaler is %v, got %v", k, obj, actionObj)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"fmt"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	v2beta2autoscaling "k8s.io/client-go/kubernetes/typed/autoscaling/v2beta2"
+	listersv2beta2 "k8s.io/client-go/listers/autoscaling/v2beta2"
+	"k8s.io/client-go/tools/cache"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "horizontalpodautoscaler",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewHorizontalPodAutoscalerController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+		Disable: true,
+	})
+}
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
+	// super master horizontalpodautoscaler client
+	hpaClient v2beta2autoscaling.HorizontalPodAutoscalersGetter
+	// super master horizontalpodautoscaler informer lister/synced function
+	hpaLister listersv2beta2.HorizontalPodAutoscalerLister
+	hpaSynced cache.InformerSynced
+	// numMissMatchedHorizontalPodAutoscalers is the number of mismatched horizontalpodautoscalers found in the last patrol.
+	numMissMatchedHorizontalPodAutoscalers uint64
+}
+
+func NewHorizontalPodAutoscalerController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		hpaClient: client.AutoscalingV2beta2(),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v2beta2.HorizontalPodAutoscaler{}, &v2beta2.HorizontalPodAutoscalerList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.hpaLister = informer.Autoscaling().V2beta2().HorizontalPodAutoscalers().Lister()
+	if options.IsFake {
+		c.hpaSynced = func() bool { return true }
+	} else {
+		c.hpaSynced = informer.Autoscaling().V2beta2().HorizontalPodAutoscalers().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&v2beta2.HorizontalPodAutoscaler{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.superNamespaces = pa.NewSuperNamespaceGuard("horizontalpodautoscaler", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v2beta2.HorizontalPodAutoscaler{}, c, pa.WithResourcePeriod(config, "horizontalpodautoscaler"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	informer.Autoscaling().V2beta2().HorizontalPodAutoscalers().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v2beta2.HorizontalPodAutoscaler:
+					return true
+				case cache.DeletedFinalStateUnknown:
+					if _, ok := t.Obj.(*v2beta2.HorizontalPodAutoscaler); ok {
+						return true
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v2beta2.HorizontalPodAutoscaler", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super master horizontalpodautoscaler controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueHorizontalPodAutoscaler,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newHPA := newObj.(*v2beta2.HorizontalPodAutoscaler)
+					oldHPA := oldObj.(*v2beta2.HorizontalPodAutoscaler)
+					if newHPA.ResourceVersion != oldHPA.ResourceVersion {
+						c.enqueueHorizontalPodAutoscaler(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueHorizontalPodAutoscaler,
+			},
+		})
+	return c, nil
+}
+
+func (c *controller) enqueueHorizontalPodAutoscaler(obj interface{}) {
+	hpa, ok := obj.(*v2beta2.HorizontalPodAutoscaler)
+	if !ok {
+		return
+	}
+
+	clusterName, _ := conversion.GetVirtualOwner(hpa)
+	if clusterName == "" {
+		return
+	}
+
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+	c.UpwardController.AddToQueue(key)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"context"
+	"fmt"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.hpaSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+// The reconcile logic for tenant master horizontalpodautoscaler informer
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	klog.V(4).Infof("reconcile horizontalpodautoscaler %s/%s event for cluster %s", request.Namespace, request.Name, request.ClusterName)
+
+	targetNamespace := conversion.ToSuperMasterNamespace(request.ClusterName, request.Namespace)
+	pHPA, err := c.hpaLister.HorizontalPodAutoscalers(targetNamespace).Get(request.Name)
+	pExists := true
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		pExists = false
+	}
+	vExists := true
+	vHPA := &v2beta2.HorizontalPodAutoscaler{}
+	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vHPA); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		vExists = false
+	}
+
+	if vExists && !pExists {
+		err := c.reconcileHorizontalPodAutoscalerCreate(request.ClusterName, targetNamespace, request.UID, vHPA)
+		if err != nil {
+			klog.Errorf("failed reconcile horizontalpodautoscaler %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if !vExists && pExists {
+		err := c.reconcileHorizontalPodAutoscalerRemove(request.ClusterName, targetNamespace, request.UID, request.Name, pHPA)
+		if err != nil {
+			klog.Errorf("failed reconcile horizontalpodautoscaler %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if vExists && pExists {
+		err := c.reconcileHorizontalPodAutoscalerUpdate(request.ClusterName, targetNamespace, request.UID, pHPA, vHPA)
+		if err != nil {
+			klog.Errorf("failed reconcile horizontalpodautoscaler %s/%s UPDATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else {
+		// object is gone.
+	}
+	return reconciler.Result{}, nil
+}
+
+func (c *controller) reconcileHorizontalPodAutoscalerCreate(clusterName, targetNamespace, requestUID string, hpa *v2beta2.HorizontalPodAutoscaler) error {
+	vcName, vcNS, _, err := c.MultiClusterController.GetOwnerInfo(clusterName)
+	if err != nil {
+		return err
+	}
+	newObj, err := conversion.BuildMetadata(clusterName, vcNS, vcName, targetNamespace, hpa)
+	if err != nil {
+		return err
+	}
+
+	// The scale target keeps its name in super master namespace, hence the scaleTargetRef is kept as is.
+	pHPA, err := c.hpaClient.HorizontalPodAutoscalers(targetNamespace).Create(context.TODO(), newObj.(*v2beta2.HorizontalPodAutoscaler), metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		if pHPA.Annotations[constants.LabelUID] == requestUID {
+			klog.Infof("horizontalpodautoscaler %s/%s of cluster %s already exist in super master", targetNamespace, hpa.Name, clusterName)
+			return nil
+		} else {
+			return fmt.Errorf("pHPA %s/%s exists but its delegated object UID is different.", targetNamespace, pHPA.Name)
+		}
+	}
+	return err
+}
+
+func (c *controller) reconcileHorizontalPodAutoscalerUpdate(clusterName, targetNamespace, requestUID string, pHPA, vHPA *v2beta2.HorizontalPodAutoscaler) error {
+	if pHPA.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("pHPA %s/%s delegated UID is different from updated object.", targetNamespace, pHPA.Name)
+	}
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	updatedHPA := conversion.Equality(c.Config, vc).CheckHorizontalPodAutoscalerEquality(pHPA, vHPA)
+	if updatedHPA != nil {
+		_, err = c.hpaClient.HorizontalPodAutoscalers(targetNamespace).Update(context.TODO(), updatedHPA, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) reconcileHorizontalPodAutoscalerRemove(clusterName, targetNamespace, requestUID, name string, pHPA *v2beta2.HorizontalPodAutoscaler) error {
+	if pHPA.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("To be deleted pHPA %s/%s delegated UID is different from deleted object.", targetNamespace, name)
+	}
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+	}
+	err := c.hpaClient.HorizontalPodAutoscalers(targetNamespace).Delete(context.TODO(), name, *opts)
+	if errors.IsNotFound(err) {
+		klog.Warningf("horizontalpodautoscaler %s/%s of cluster %s not found in super master", targetNamespace, name, clusterName)
+		return nil
+	}
+	return err
+}
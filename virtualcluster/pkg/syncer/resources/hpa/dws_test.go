@@ -0,0 +1,415 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"strings"
+	"testing"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func tenantHorizontalPodAutoscaler(name, namespace, uid string) *v2beta2.HorizontalPodAutoscaler {
+	return &v2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v2beta2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+func superHorizontalPodAutoscaler(name, namespace, uid, clusterKey string) *v2beta2.HorizontalPodAutoscaler {
+	return &v2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: "autoscaling/v2beta2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				constants.LabelUID:       uid,
+				constants.LabelCluster:   clusterKey,
+				constants.LabelNamespace: "default",
+			},
+		},
+	}
+}
+
+func applyScaleTarget(hpa *v2beta2.HorizontalPodAutoscaler, name string) *v2beta2.HorizontalPodAutoscaler {
+	hpa.Spec.ScaleTargetRef = v2beta2.CrossVersionObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       name,
+	}
+	return hpa
+}
+
+func applyReplicas(hpa *v2beta2.HorizontalPodAutoscaler, min, max int32) *v2beta2.HorizontalPodAutoscaler {
+	hpa.Spec.MinReplicas = &min
+	hpa.Spec.MaxReplicas = max
+	return hpa
+}
+
+func applyStatus(hpa *v2beta2.HorizontalPodAutoscaler, current, desired int32) *v2beta2.HorizontalPodAutoscaler {
+	hpa.Status.CurrentReplicas = current
+	hpa.Status.DesiredReplicas = desired
+	return hpa
+}
+
+func applyCPUUtilization(hpa *v2beta2.HorizontalPodAutoscaler, utilization int32) *v2beta2.HorizontalPodAutoscaler {
+	hpa.Spec.Metrics = []v2beta2.MetricSpec{
+		{
+			Type: v2beta2.ResourceMetricSourceType,
+			Resource: &v2beta2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: v2beta2.MetricTarget{
+					Type:               v2beta2.UtilizationMetricType,
+					AverageUtilization: &utilization,
+				},
+			},
+		},
+	}
+	return hpa
+}
+
+func TestDWHorizontalPodAutoscalerCreation(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedCreatedPObject []string
+		ExpectedCreatedSpec    *v2beta2.HorizontalPodAutoscalerSpec
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"new hpa": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantHorizontalPodAutoscaler("hpa-1", "default", "12345"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/hpa-1"},
+		},
+		"new hpa with scale target": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				applyScaleTarget(tenantHorizontalPodAutoscaler("hpa-1", "default", "12345"), "deploy"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/hpa-1"},
+			ExpectedCreatedSpec:    &applyScaleTarget(tenantHorizontalPodAutoscaler("hpa-1", "default", "12345"), "deploy").Spec,
+		},
+		"new hpa but already exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superHorizontalPodAutoscaler("hpa-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantHorizontalPodAutoscaler("hpa-2", "default", "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"new hpa but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superHorizontalPodAutoscaler("hpa-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantHorizontalPodAutoscaler("hpa-3", "default", "12345"),
+			},
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewHorizontalPodAutoscalerController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedCreatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to create hpa %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedCreatedPObject {
+				action := actions[i]
+				if !action.Matches("create", "horizontalpodautoscalers") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				created := action.(core.CreateAction).GetObject().(*v2beta2.HorizontalPodAutoscaler)
+				fullName := created.Namespace + "/" + created.Name
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
+				}
+				if tc.ExpectedCreatedSpec != nil && !equality.Semantic.DeepEqual(*tc.ExpectedCreatedSpec, created.Spec) {
+					t.Errorf("%s: Expected created spec %v, got %v", k, *tc.ExpectedCreatedSpec, created.Spec)
+				}
+			}
+		})
+	}
+}
+
+func TestDWHorizontalPodAutoscalerDeletion(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueueObject          *v2beta2.HorizontalPodAutoscaler
+		ExpectedDeletedPObject []string
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"delete hpa": {
+			ExistingObjectInSuper: []runtime.Object{
+				superHorizontalPodAutoscaler("hpa-1", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			EnqueueObject:          tenantHorizontalPodAutoscaler("hpa-1", "default", "12345"),
+			ExpectedDeletedPObject: []string{superDefaultNSName + "/hpa-1"},
+		},
+		"delete hpa but already gone": {
+			ExistingObjectInSuper: []runtime.Object{},
+			EnqueueObject:         tenantHorizontalPodAutoscaler("hpa-2", "default", "12345"),
+			ExpectedNoOperation:   true,
+		},
+		"delete hpa but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superHorizontalPodAutoscaler("hpa-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			EnqueueObject: tenantHorizontalPodAutoscaler("hpa-3", "default", "12345"),
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewHorizontalPodAutoscalerController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedDeletedPObject) != len(actions) {
+				t.Errorf("%s: Expected to delete hpa %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedDeletedPObject {
+				action := actions[i]
+				if !action.Matches("delete", "horizontalpodautoscalers") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be deleted, got %s", k, expectedName, fullName)
+				}
+			}
+		})
+	}
+}
+
+func TestDWHorizontalPodAutoscalerUpdate(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedUpdatedPObject []runtime.Object
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"no diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyReplicas(superHorizontalPodAutoscaler("hpa-1", superDefaultNSName, "12345", defaultClusterKey), 1, 3),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyReplicas(tenantHorizontalPodAutoscaler("hpa-1", "default", "12345"), 1, 3),
+			},
+			ExpectedNoOperation: true,
+		},
+		"diff in replicas": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyReplicas(superHorizontalPodAutoscaler("hpa-2", superDefaultNSName, "12345", defaultClusterKey), 1, 3),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyReplicas(tenantHorizontalPodAutoscaler("hpa-2", "default", "12345"), 2, 5),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyReplicas(superHorizontalPodAutoscaler("hpa-2", superDefaultNSName, "12345", defaultClusterKey), 2, 5),
+			},
+		},
+		"diff in metrics": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyCPUUtilization(superHorizontalPodAutoscaler("hpa-3", superDefaultNSName, "12345", defaultClusterKey), 50),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyCPUUtilization(tenantHorizontalPodAutoscaler("hpa-3", "default", "12345"), 80),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyCPUUtilization(superHorizontalPodAutoscaler("hpa-3", superDefaultNSName, "12345", defaultClusterKey), 80),
+			},
+		},
+		"diff exists but uid is wrong": {
+			ExistingObjectInSuper: []runtime.Object{
+				superHorizontalPodAutoscaler("hpa-4", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyReplicas(tenantHorizontalPodAutoscaler("hpa-4", "default", "123456"), 2, 5),
+			},
+			ExpectedError:       "delegated UID is different",
+			ExpectedNoOperation: true,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewHorizontalPodAutoscalerController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedUpdatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to update hpa %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPObject, actions)
+				return
+			}
+			for i, obj := range tc.ExpectedUpdatedPObject {
+				action := actions[i]
+				if !action.Matches("update", "horizontalpodautoscalers") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				actionObj := action.(core.UpdateAction).GetObject()
+				if !equality.Semantic.DeepEqual(obj, actionObj) {
+					t.Errorf("%s: Expected updated hpa is %v, got %v", k, obj, actionObj)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"context"
+	"fmt"
+
+	pkgerr "github.com/pkg/errors"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.hpaSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+// BackPopulate reflects the replicas and metrics observed by super master autoscaler to the tenant HorizontalPodAutoscaler.
+func (c *controller) BackPopulate(key string) error {
+	pNamespace, pName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key %v: %v", key, err))
+		return nil
+	}
+
+	pHPA, err := c.hpaLister.HorizontalPodAutoscalers(pNamespace).Get(pName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	clusterName, vNamespace := conversion.GetVirtualOwner(pHPA)
+	if clusterName == "" || vNamespace == "" {
+		klog.Infof("drop horizontalpodautoscaler %s/%s which is not belongs to any tenant", pNamespace, pName)
+		return nil
+	}
+
+	vHPA := &v2beta2.HorizontalPodAutoscaler{}
+	if err := c.MultiClusterController.Get(clusterName, vNamespace, pName, vHPA); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return pkgerr.Wrapf(err, "could not find pHPA %s/%s's vHPA in controller cache", vNamespace, pName)
+	}
+	if pHPA.Annotations[constants.LabelUID] != string(vHPA.UID) {
+		return fmt.Errorf("BackPopulated pHPA %s/%s delegated UID is different from updated object.", pHPA.Namespace, pHPA.Name)
+	}
+
+	if equality.Semantic.DeepEqual(vHPA.Status, pHPA.Status) {
+		return nil
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed to create client from cluster %s config", clusterName)
+	}
+
+	newHPA := vHPA.DeepCopy()
+	newHPA.Status = pHPA.Status
+	if _, err = tenantClient.AutoscalingV2beta2().HorizontalPodAutoscalers(vHPA.Namespace).UpdateStatus(context.TODO(), newHPA, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to back populate horizontalpodautoscaler %s/%s status update for cluster %s: %v", vHPA.Namespace, vHPA.Name, clusterName, err)
+	}
+	return nil
+}
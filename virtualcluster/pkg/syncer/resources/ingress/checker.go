@@ -22,7 +22,7 @@ import (
 	"sync"
 	"sync/atomic"
 
-	v1beta1 "k8s.io/api/extensions/v1beta1"
+	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
@@ -50,12 +51,14 @@ func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 
 // PatrollerDo check if ingresss keep consistency between super
 // master and tenant masters.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "ingress")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	wg := sync.WaitGroup{}
 	numSpecMissMatchedIngresses = 0
@@ -74,6 +77,7 @@ func (c *controller) PatrollerDo() {
 	pIngresses, err := c.ingressLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing ingresss from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 
@@ -83,7 +87,7 @@ func (c *controller) PatrollerDo() {
 			continue
 		}
 		shouldDelete := false
-		vIngress := &v1beta1.Ingress{}
+		vIngress := &v1.Ingress{}
 		err := c.MultiClusterController.Get(clusterName, vNamespace, pIngress.Name, vIngress)
 		if errors.IsNotFound(err) {
 			shouldDelete = true
@@ -110,7 +114,7 @@ func (c *controller) PatrollerDo() {
 }
 
 func (c *controller) checkIngressesOfTenantCluster(clusterName string) {
-	ingList := &v1beta1.IngressList{}
+	ingList := &v1.IngressList{}
 	if err := c.MultiClusterController.List(clusterName, ingList); err != nil {
 		klog.Errorf("error listing ingresss from cluster %s informer cache: %v", clusterName, err)
 		return
@@ -121,6 +125,9 @@ func (c *controller) checkIngressesOfTenantCluster(clusterName string) {
 		targetNamespace := conversion.ToSuperMasterNamespace(clusterName, vIngress.Namespace)
 		pIngress, err := c.ingressLister.Ingresses(targetNamespace).Get(vIngress.Name)
 		if errors.IsNotFound(err) {
+			if !c.superNamespaces.Validate(clusterName, vIngress.Namespace) {
+				continue
+			}
 			if err := c.MultiClusterController.RequeueObject(clusterName, &ingList.Items[i]); err != nil {
 				klog.Errorf("error requeue vingress %v/%v in cluster %s: %v", vIngress.Namespace, vIngress.Name, clusterName, err)
 			} else {
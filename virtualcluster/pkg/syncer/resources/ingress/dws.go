@@ -20,7 +20,7 @@ import (
 	"context"
 	"fmt"
 
-	v1beta1 "k8s.io/api/extensions/v1beta1"
+	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
@@ -51,7 +51,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 		pExists = false
 	}
 	vExists := true
-	vIngress := &v1beta1.Ingress{}
+	vIngress := &v1.Ingress{}
 	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vIngress); err != nil {
 		if !errors.IsNotFound(err) {
 			return reconciler.Result{Requeue: true}, err
@@ -83,7 +83,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	return reconciler.Result{}, nil
 }
 
-func (c *controller) reconcileIngressCreate(clusterName, targetNamespace, requestUID string, ingress *v1beta1.Ingress) error {
+func (c *controller) reconcileIngressCreate(clusterName, targetNamespace, requestUID string, ingress *v1.Ingress) error {
 	vcName, vcNS, _, err := c.MultiClusterController.GetOwnerInfo(clusterName)
 	if err != nil {
 		return err
@@ -93,7 +93,7 @@ func (c *controller) reconcileIngressCreate(clusterName, targetNamespace, reques
 		return err
 	}
 
-	pIngress := newObj.(*v1beta1.Ingress)
+	pIngress := newObj.(*v1.Ingress)
 
 	pIngress, err = c.ingressClient.Ingresses(targetNamespace).Create(context.TODO(), pIngress, metav1.CreateOptions{})
 	if errors.IsAlreadyExists(err) {
@@ -107,7 +107,7 @@ func (c *controller) reconcileIngressCreate(clusterName, targetNamespace, reques
 	return err
 }
 
-func (c *controller) reconcileIngressUpdate(clusterName, targetNamespace, requestUID string, pIngress, vIngress *v1beta1.Ingress) error {
+func (c *controller) reconcileIngressUpdate(clusterName, targetNamespace, requestUID string, pIngress, vIngress *v1.Ingress) error {
 	if pIngress.Annotations[constants.LabelUID] != requestUID {
 		return fmt.Errorf("pIngress %s/%s delegated UID is different from updated object.", targetNamespace, pIngress.Name)
 	}
@@ -126,7 +126,7 @@ func (c *controller) reconcileIngressUpdate(clusterName, targetNamespace, reques
 	return nil
 }
 
-func (c *controller) reconcileIngressRemove(clusterName, targetNamespace, requestUID, name string, pIngress *v1beta1.Ingress) error {
+func (c *controller) reconcileIngressRemove(clusterName, targetNamespace, requestUID, name string, pIngress *v1.Ingress) error {
 	if pIngress.Annotations[constants.LabelUID] != requestUID {
 		return fmt.Errorf("To be deleted pIngress %s/%s delegated UID is different from deleted object.", targetNamespace, name)
 	}
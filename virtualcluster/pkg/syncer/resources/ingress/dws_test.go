@@ -20,7 +20,7 @@ import (
 	"strings"
 	"testing"
 
-	v1beta1 "k8s.io/api/extensions/v1beta1"
+	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,11 +33,11 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 )
 
-func tenantIngress(name, namespace, uid string) *v1beta1.Ingress {
-	return &v1beta1.Ingress{
+func tenantIngress(name, namespace, uid string) *v1.Ingress {
+	return &v1.Ingress{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Ingress",
-			APIVersion: "v1beta1",
+			APIVersion: "networking.k8s.io/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -47,8 +47,8 @@ func tenantIngress(name, namespace, uid string) *v1beta1.Ingress {
 	}
 }
 
-func superIngress(name, namespace, uid, clusterKey string) *v1beta1.Ingress {
-	return &v1beta1.Ingress{
+func superIngress(name, namespace, uid, clusterKey string) *v1.Ingress {
+	return &v1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
@@ -79,9 +79,10 @@ func TestDWIngressCreation(t *testing.T) {
 
 	testcases := map[string]struct {
 		ExistingObjectInSuper  []runtime.Object
-		ExistingObjectInTenant *v1beta1.Ingress
+		ExistingObjectInTenant *v1.Ingress
 
 		ExpectedCreatedIngresses []string
+		ExpectedCreatedSpec      *v1.IngressSpec
 		ExpectedError            string
 	}{
 		"new ingress": {
@@ -89,6 +90,12 @@ func TestDWIngressCreation(t *testing.T) {
 			ExistingObjectInTenant:   tenantIngress("ing-1", "default", "12345"),
 			ExpectedCreatedIngresses: []string{superDefaultNSName + "/ing-1"},
 		},
+		"new ingress with backend and tls": {
+			ExistingObjectInSuper:    []runtime.Object{},
+			ExistingObjectInTenant:   applySpecToIngress(tenantIngress("ing-1", "default", "12345"), ingressSpec("nginx", "nginx", "nginx-tls")),
+			ExpectedCreatedIngresses: []string{superDefaultNSName + "/ing-1"},
+			ExpectedCreatedSpec:      ingressSpec("nginx", "nginx", "nginx-tls"),
+		},
 		"new ingress but already exists": {
 			ExistingObjectInSuper: []runtime.Object{
 				superIngress("ing-1", superDefaultNSName, "12345", defaultClusterKey),
@@ -141,11 +148,14 @@ func TestDWIngressCreation(t *testing.T) {
 				if !action.Matches("create", "ingresses") {
 					t.Errorf("%s: Unexpected action %s", k, action)
 				}
-				createdSVC := action.(core.CreateAction).GetObject().(*v1beta1.Ingress)
+				createdSVC := action.(core.CreateAction).GetObject().(*v1.Ingress)
 				fullName := createdSVC.Namespace + "/" + createdSVC.Name
 				if fullName != expectedName {
 					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
 				}
+				if tc.ExpectedCreatedSpec != nil && !equality.Semantic.DeepEqual(&createdSVC.Spec, tc.ExpectedCreatedSpec) {
+					t.Errorf("%s: Expected created spec %+v, got %+v", k, tc.ExpectedCreatedSpec, createdSVC.Spec)
+				}
 			}
 		})
 	}
@@ -169,7 +179,7 @@ func TestDWIngressDeletion(t *testing.T) {
 
 	testcases := map[string]struct {
 		ExistingObjectInSuper []runtime.Object
-		EnqueueObject         *v1beta1.Ingress
+		EnqueueObject         *v1.Ingress
 
 		ExpectedDeletedIngresses []string
 		ExpectedError            string
@@ -235,7 +245,46 @@ func TestDWIngressDeletion(t *testing.T) {
 	}
 }
 
-func applySpecToIngress(ing *v1beta1.Ingress, spec *v1beta1.IngressSpec) *v1beta1.Ingress {
+// ingressSpec returns an IngressSpec routing to the backend service with the tls secret.
+func ingressSpec(className, serviceName, secretName string) *v1.IngressSpec {
+	backend := v1.IngressBackend{
+		Service: &v1.IngressServiceBackend{
+			Name: serviceName,
+			Port: v1.ServiceBackendPort{
+				Number: 80,
+			},
+		},
+	}
+	pathType := v1.PathTypePrefix
+	return &v1.IngressSpec{
+		IngressClassName: &className,
+		DefaultBackend:   &backend,
+		TLS: []v1.IngressTLS{
+			{
+				Hosts:      []string{"example.com"},
+				SecretName: secretName,
+			},
+		},
+		Rules: []v1.IngressRule{
+			{
+				Host: "example.com",
+				IngressRuleValue: v1.IngressRuleValue{
+					HTTP: &v1.HTTPIngressRuleValue{
+						Paths: []v1.HTTPIngressPath{
+							{
+								Path:     "/",
+								PathType: &pathType,
+								Backend:  backend,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func applySpecToIngress(ing *v1.Ingress, spec *v1.IngressSpec) *v1.Ingress {
 	ing.Spec = *spec.DeepCopy()
 	return ing
 }
@@ -257,31 +306,15 @@ func TestDWIngressUpdate(t *testing.T) {
 	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
 
 	nginx := "nginx"
-	spec1 := &v1beta1.IngressSpec{
-		IngressClassName: &nginx,
-		Backend: &v1beta1.IngressBackend{
-			ServiceName: "nginx",
-		},
-	}
-
-	spec2 := &v1beta1.IngressSpec{
-		IngressClassName: &nginx,
-		Backend: &v1beta1.IngressBackend{
-			ServiceName: "nginx",
-		},
-	}
+	spec1 := ingressSpec(nginx, "nginx", "nginx-tls")
+	spec2 := ingressSpec(nginx, "nginx", "nginx-tls")
 
 	haproxy := "haproxy"
-	spec3 := &v1beta1.IngressSpec{
-		IngressClassName: &haproxy,
-		Backend: &v1beta1.IngressBackend{
-			ServiceName: "haproxy",
-		},
-	}
+	spec3 := ingressSpec(haproxy, "haproxy", "haproxy-tls")
 
 	testcases := map[string]struct {
 		ExistingObjectInSuper  []runtime.Object
-		ExistingObjectInTenant *v1beta1.Ingress
+		ExistingObjectInTenant *v1.Ingress
 
 		ExpectedUpdatedIngresses []runtime.Object
 		ExpectedError            string
@@ -293,6 +326,15 @@ func TestDWIngressUpdate(t *testing.T) {
 			ExistingObjectInTenant:   applySpecToIngress(tenantIngress("ing-1", "default", "12345"), spec2),
 			ExpectedUpdatedIngresses: []runtime.Object{},
 		},
+		"diff in backend and tls": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySpecToIngress(superIngress("ing-1", superDefaultNSName, "12345", defaultClusterKey), spec1),
+			},
+			ExistingObjectInTenant: applySpecToIngress(tenantIngress("ing-1", "default", "12345"), spec3),
+			ExpectedUpdatedIngresses: []runtime.Object{
+				applySpecToIngress(superIngress("ing-1", superDefaultNSName, "12345", defaultClusterKey), spec3),
+			},
+		},
 		"diff exists but uid is wrong": {
 			ExistingObjectInSuper: []runtime.Object{
 				applySpecToIngress(superIngress("ing-1", superDefaultNSName, "12345", defaultClusterKey), spec1),
@@ -21,9 +21,10 @@ import (
 	"strings"
 	"testing"
 
-	v1 "k8s.io/api/core/v1"
-	v1beta1 "k8s.io/api/extensions/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	core "k8s.io/client-go/testing"
@@ -33,8 +34,8 @@ import (
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
-func applyLoadBalancerToIngress(ing *v1beta1.Ingress, ip string) *v1beta1.Ingress {
-	ing.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{
+func applyLoadBalancerToIngress(ing *v1.Ingress, ip string) *v1.Ingress {
+	ing.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
 		{
 			IP: ip,
 		},
@@ -106,6 +107,18 @@ func TestUWIngress(t *testing.T) {
 			EnqueuedKey:         superDefaultNSName + "/ing",
 			ExpectedNoOperation: true,
 		},
+		"pIngress exists, vIngress exists with different loadbalancer status": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyLoadBalancerToIngress(superIngress("ing", superDefaultNSName, "12345", defaultClusterKey), "10.0.0.1"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantIngress("ing", "default", "12345"),
+			},
+			EnqueuedKey: superDefaultNSName + "/ing",
+			ExpectedUpdatedObject: []runtime.Object{
+				applyLoadBalancerToIngress(tenantIngress("ing", "default", "12345"), "10.0.0.1"),
+			},
+		},
 	}
 
 	for k, tc := range testcases {
@@ -143,6 +156,8 @@ func TestUWIngress(t *testing.T) {
 						continue
 					}
 					actionObj := action.(core.UpdateAction).GetObject()
+					accessor, _ := meta.Accessor(obj)
+					accessor.SetResourceVersion("999")
 					if !equality.Semantic.DeepEqual(obj, actionObj) {
 						exp, _ := json.Marshal(obj)
 						got, _ := json.Marshal(actionObj)
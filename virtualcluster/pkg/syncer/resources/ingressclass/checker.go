@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressclass
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.ingressClassSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting IngressClass checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if IngressClass keeps consistency between super master and tenant masters.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "ingressclass")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	wg := sync.WaitGroup{}
+	atomic.StoreUint64(&c.numMissMatchedIngressClasses, 0)
+
+	// sem bounds the number of tenant clusters being checked at the same time.
+	sem := make(chan struct{}, c.patrolConcurrency)
+	for _, clusterName := range clusterNames {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(clusterName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.checkIngressClassOfTenantCluster(ctx, clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		klog.Infof("ingressclass patrol is cancelled: %v", ctx.Err())
+		return
+	}
+
+	pIngressClassList, err := c.ingressClassLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing ingressclass from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+
+	for _, pIngressClass := range pIngressClassList {
+		if !publicIngressClass(pIngressClass) {
+			continue
+		}
+		for _, clusterName := range clusterNames {
+			if err := c.MultiClusterController.Get(clusterName, "", pIngressClass.Name, &v1.IngressClass{}); err != nil {
+				if errors.IsNotFound(err) {
+					if c.patrollerDryRun {
+						klog.Infof("[dry-run] would requeue ingressclass %s for cluster %s", pIngressClass.Name, clusterName)
+						metrics.CheckerDryRunStats.WithLabelValues("RequeuedSuperMasterIngressClasses").Inc()
+						continue
+					}
+					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterIngressClasses").Inc()
+					c.UpwardController.AddToQueue(clusterName + "/" + pIngressClass.Name)
+					continue
+				}
+				klog.Errorf("fail to get ingressclass from cluster %s: %v", clusterName, err)
+			}
+		}
+	}
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedIngressClasses").Set(float64(atomic.LoadUint64(&c.numMissMatchedIngressClasses)))
+}
+
+func (c *controller) checkIngressClassOfTenantCluster(ctx context.Context, clusterName string) {
+	defer metrics.RecordCheckerClusterScanDuration("IngressClass", clusterName, time.Now())
+	ingressClassList := &v1.IngressClassList{}
+	if err := c.MultiClusterController.List(clusterName, ingressClassList); err != nil {
+		klog.Errorf("error listing ingressclass from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check ingressclass consistency in cluster %s", clusterName)
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		klog.Errorf("fail to get cluster spec : %s", clusterName)
+		return
+	}
+
+	for i, vIngressClass := range ingressClassList.Items {
+		if ctx.Err() != nil {
+			klog.V(4).Infof("stop checking ingressclass in cluster %s: %v", clusterName, ctx.Err())
+			return
+		}
+		pIngressClass, err := c.ingressClassLister.Get(vIngressClass.Name)
+		// ingressclass which is no longer public is treated as orphan.
+		if errors.IsNotFound(err) || (err == nil && !publicIngressClass(pIngressClass)) {
+			// ingressclass created by tenant is left alone.
+			if !conversion.IsSyncerManaged(&ingressClassList.Items[i]) {
+				klog.V(4).Infof("orphan ingressclass %s in cluster %s is not managed by syncer, skip it", vIngressClass.Name, clusterName)
+				continue
+			}
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would delete orphan ingressclass %s in cluster %s", vIngressClass.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("DeletedOrphanTenantIngressClasses").Inc()
+				continue
+			}
+			// super master is the source of the truth for ingressclass object, delete tenant master obj
+			deleteCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+			err = c.deleteIngressClass(deleteCtx, clusterName, &ingressClassList.Items[i])
+			cancel()
+			if err != nil {
+				klog.Errorf("error deleting ingressclass %v in cluster %s: %v", vIngressClass.Name, clusterName, err)
+			} else {
+				metrics.RecordCheckerRemedy("DeletedOrphanTenantIngressClasses", clusterName)
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pIngressClass %s from super master cache: %v", vIngressClass.Name, err)
+			continue
+		}
+
+		updatedIngressClass := conversion.Equality(c.Config, vc).CheckIngressClassEquality(pIngressClass, &ingressClassList.Items[i])
+		if updatedIngressClass != nil {
+			atomic.AddUint64(&c.numMissMatchedIngressClasses, 1)
+			klog.Warningf("spec of ingressclass %v diff in super&tenant master", vIngressClass.Name)
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would requeue ingressclass %s for cluster %s", pIngressClass.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("RequeuedDiffIngressClasses").Inc()
+				continue
+			}
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedDiffIngressClasses").Inc()
+			c.UpwardController.AddToQueue(clusterName + "/" + pIngressClass.Name)
+		}
+	}
+}
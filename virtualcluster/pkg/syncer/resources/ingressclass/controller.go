@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressclass
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/networking/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "ingressclass",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewIngressClassController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptionsFromConfig(ctx.Config.(*config.SyncerConfiguration)))
+		},
+		Disable: true,
+	})
+}
+
+// controller populates the public super master ingressclasses to tenant masters, so that the ingressClassName
+// of the tenant ingresses refers to a class known to the tenant.
+type controller struct {
+	manager.BaseResourceSyncer
+	// super master ingressclasses lister/synced functions
+	ingressClassLister listersv1.IngressClassLister
+	ingressClassSynced cache.InformerSynced
+	// patrollerDryRun indicates that the patroller only logs the remediation it would take.
+	patrollerDryRun bool
+	// patrolConcurrency is the max number of tenant clusters checked in parallel.
+	patrolConcurrency int
+	// patrolOpTimeout is the timeout of each tenant operation issued by the patroller.
+	patrolOpTimeout time.Duration
+	// numMissMatchedIngressClasses is the number of mismatched ingressclasses found in the last patrol.
+	numMissMatchedIngressClasses uint64
+}
+
+func NewIngressClassController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		patrollerDryRun:   options.PatrollerDryRun,
+		patrolConcurrency: constants.DefaultPatrolConcurrency,
+		patrolOpTimeout:   constants.DefaultPatrolOpTimeout,
+	}
+	if options.PatrolConcurrency > 0 {
+		c.patrolConcurrency = options.PatrolConcurrency
+	}
+	if options.PatrolOpTimeout > 0 {
+		c.patrolOpTimeout = options.PatrolOpTimeout
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.IngressClass{}, &v1.IngressClassList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.ingressClassLister = informer.Networking().V1().IngressClasses().Lister()
+	if options.IsFake {
+		c.ingressClassSynced = func() bool { return true }
+	} else {
+		c.ingressClassSynced = informer.Networking().V1().IngressClasses().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&v1.IngressClass{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&v1.IngressClass{}, c, pa.WithResourcePeriod(config, "ingressclass"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	informer.Networking().V1().IngressClasses().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.IngressClass:
+					return publicIngressClass(t)
+				case cache.DeletedFinalStateUnknown:
+					if e, ok := t.Obj.(*v1.IngressClass); ok {
+						return publicIngressClass(e)
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.IngressClass", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super master ingressclass controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueIngressClass,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newIngressClass := newObj.(*v1.IngressClass)
+					oldIngressClass := oldObj.(*v1.IngressClass)
+					if newIngressClass.ResourceVersion != oldIngressClass.ResourceVersion {
+						c.enqueueIngressClass(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueIngressClass,
+			},
+		})
+	return c, nil
+}
+
+func publicIngressClass(e *v1.IngressClass) bool {
+	// We only backpopulate specific ingressclass to tenant masters
+	return e.Labels[constants.PublicObjectKey] == "true"
+}
+
+// PublicNameTaken returns true if the ingressclass of the name is synced from super master to tenant masters.
+func (c *controller) PublicNameTaken(name string) bool {
+	pIngressClass, err := c.ingressClassLister.Get(name)
+	if err != nil {
+		return false
+	}
+	return publicIngressClass(pIngressClass)
+}
+
+func (c *controller) enqueueIngressClass(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("No tenant masters, stop backpopulate ingressclass %v", key)
+		return
+	}
+
+	for _, clusterName := range clusterNames {
+		c.UpwardController.AddToQueue(clusterName + "/" + key)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressclass
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.ingressClassSynced) {
+		return fmt.Errorf("failed to wait for caches to sync ingressclass")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+func (c *controller) BackPopulate(key string) error {
+	// The key format is clustername/ingressClassName.
+	clusterName, name, _ := cache.SplitMetaNamespaceKey(key)
+
+	op := reconciler.AddEvent
+	pIngressClass, err := c.ingressClassLister.Get(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		op = reconciler.DeleteEvent
+	} else if !publicIngressClass(pIngressClass) {
+		op = reconciler.DeleteEvent
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+
+	vIngressClass := &v1.IngressClass{}
+	if err := c.MultiClusterController.Get(clusterName, "", name, vIngressClass); err != nil {
+		if errors.IsNotFound(err) {
+			if op == reconciler.AddEvent {
+				// Available in super, hence create a new in tenant master
+				vIngressClass := conversion.BuildVirtualIngressClass(clusterName, pIngressClass)
+				conversion.SetTenantDefaultIngressClass(vc, vIngressClass)
+				_, err := tenantClient.NetworkingV1().IngressClasses().Create(context.TODO(), vIngressClass, metav1.CreateOptions{})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return err
+	}
+
+	if op == reconciler.DeleteEvent {
+		if !conversion.IsSyncerManaged(vIngressClass) {
+			klog.Infof("ingressclass %s in cluster %s is not managed by syncer, leave it alone", name, clusterName)
+			return nil
+		}
+		return c.deleteIngressClass(context.TODO(), clusterName, vIngressClass)
+	}
+
+	updatedIngressClass := conversion.Equality(c.Config, vc).CheckIngressClassEquality(pIngressClass, vIngressClass)
+	if updatedIngressClass != nil && updatedIngressClass.Spec.Controller != vIngressClass.Spec.Controller {
+		// The ingressclass controller is immutable, hence the tenant ingressclass is deleted and created again.
+		if err := c.deleteIngressClass(context.TODO(), clusterName, vIngressClass); err != nil {
+			return err
+		}
+		vIngressClass := conversion.BuildVirtualIngressClass(clusterName, pIngressClass)
+		conversion.SetTenantDefaultIngressClass(vc, vIngressClass)
+		_, err := tenantClient.NetworkingV1().IngressClasses().Create(context.TODO(), vIngressClass, metav1.CreateOptions{})
+		return err
+	}
+	// ingressclasses created by tenants with the same name are taken over.
+	if !conversion.IsSyncerManaged(vIngressClass) {
+		if updatedIngressClass == nil {
+			updatedIngressClass = vIngressClass.DeepCopy()
+		}
+		conversion.SetSyncerManaged(updatedIngressClass)
+	}
+	if updatedIngressClass != nil {
+		_, err := tenantClient.NetworkingV1().IngressClasses().Update(context.TODO(), updatedIngressClass, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteIngressClass deletes the tenant ingressclass if it has not been recreated since it was observed.
+func (c *controller) deleteIngressClass(ctx context.Context, clusterName string, vIngressClass *v1.IngressClass) error {
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+		Preconditions:     metav1.NewUIDPreconditions(string(vIngressClass.UID)),
+	}
+	if err := tenantClient.NetworkingV1().IngressClasses().Delete(ctx, vIngressClass.Name, *opts); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressclass
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func makeIngressClass(name, uid string, mFuncs ...func(*v1.IngressClass)) *v1.IngressClass {
+	class := &v1.IngressClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "IngressClass",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(uid),
+		},
+		Spec: v1.IngressClassSpec{
+			Controller: "example.com/ingress-controller",
+		},
+	}
+
+	for _, f := range mFuncs {
+		f(class)
+	}
+	return class
+}
+
+func public(class *v1.IngressClass) {
+	if class.Labels == nil {
+		class.Labels = map[string]string{}
+	}
+	class.Labels[constants.PublicObjectKey] = "true"
+}
+
+func managed(class *v1.IngressClass) {
+	conversion.SetSyncerManaged(class)
+}
+
+func isDefault(value string) func(*v1.IngressClass) {
+	return func(class *v1.IngressClass) {
+		class.Annotations = map[string]string{
+			constants.AnnotationIsDefaultIngressClass: value,
+		}
+	}
+}
+
+func TestUWIngressClass(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	defaultTenant := testTenant.DeepCopy()
+	defaultTenant.Annotations = map[string]string{
+		constants.LabelTenantDefaultIngressClass: "nginx",
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+
+	testcases := map[string]struct {
+		Tenant                 *v1alpha1.VirtualCluster
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueuedKey            string
+		ExpectedCreatedObject  []string
+		ExpectedUpdatedObject  []string
+		ExpectedDeletedObject  []string
+		ExpectedDefaultClass   string
+		ExpectedError          string
+		ExpectedNoOperation    bool
+	}{
+		"pIngressClass exists but vIngressClass not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("nginx", "12345", public),
+			},
+			EnqueuedKey:           defaultClusterKey + "/nginx",
+			ExpectedCreatedObject: []string{"nginx"},
+		},
+		"pIngressClass not public, vIngressClass not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("nginx", "12345"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/nginx",
+			ExpectedNoOperation: true,
+		},
+		"pIngressClass exists, vIngressClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("nginx", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("nginx", "123456", public, managed),
+			},
+			EnqueuedKey:         defaultClusterKey + "/nginx",
+			ExpectedNoOperation: true,
+		},
+		"pIngressClass exists, vIngressClass exists with different parameters": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("nginx", "12345", public, func(class *v1.IngressClass) {
+					class.Spec.Parameters = &v1.IngressClassParametersReference{Kind: "IngressParameters", Name: "external"}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("nginx", "123456", public, managed),
+			},
+			EnqueuedKey:           defaultClusterKey + "/nginx",
+			ExpectedUpdatedObject: []string{"nginx"},
+		},
+		"pIngressClass exists, vIngressClass exists with different controller": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("nginx", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("nginx", "123456", public, managed, func(class *v1.IngressClass) {
+					class.Spec.Controller = "example.com/other-controller"
+				}),
+			},
+			EnqueuedKey:           defaultClusterKey + "/nginx",
+			ExpectedDeletedObject: []string{"nginx"},
+			ExpectedCreatedObject: []string{"nginx"},
+		},
+		"pIngressClass exists, vIngressClass not managed by syncer": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("nginx", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("nginx", "123456", public),
+			},
+			EnqueuedKey:           defaultClusterKey + "/nginx",
+			ExpectedUpdatedObject: []string{"nginx"},
+		},
+		"pIngressClass not found, vIngressClass exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("nginx", "123456", managed),
+			},
+			EnqueuedKey:           defaultClusterKey + "/nginx",
+			ExpectedDeletedObject: []string{"nginx"},
+		},
+		"pIngressClass not public, vIngressClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("nginx", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("nginx", "123456", managed),
+			},
+			EnqueuedKey:           defaultClusterKey + "/nginx",
+			ExpectedDeletedObject: []string{"nginx"},
+		},
+		"pIngressClass not found, vIngressClass created by tenant": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("nginx", "123456"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/nginx",
+			ExpectedNoOperation: true,
+		},
+		"pIngressClass is the tenant default, vIngressClass not found": {
+			Tenant: defaultTenant,
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("nginx", "12345", public),
+			},
+			EnqueuedKey:           defaultClusterKey + "/nginx",
+			ExpectedCreatedObject: []string{"nginx"},
+			ExpectedDefaultClass:  "true",
+		},
+		"pIngressClass is the super default, vIngressClass is not the tenant default": {
+			Tenant: defaultTenant,
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("haproxy", "12345", public, isDefault("true")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("haproxy", "123456", public, managed, isDefault("true")),
+			},
+			EnqueuedKey:           defaultClusterKey + "/haproxy",
+			ExpectedUpdatedObject: []string{"haproxy"},
+			ExpectedDefaultClass:  "false",
+		},
+		"pIngressClass is the super default, no tenant default": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeIngressClass("haproxy", "12345", public, isDefault("true")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeIngressClass("haproxy", "123456", public, managed, isDefault("false")),
+			},
+			EnqueuedKey:         defaultClusterKey + "/haproxy",
+			ExpectedNoOperation: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenant := testTenant
+			if tc.Tenant != nil {
+				tenant = tc.Tenant
+			}
+			actions, reconcileErr, err := util.RunUpwardSync(NewIngressClassController, tenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedDeletedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("delete", "ingressclasses") {
+						continue
+					}
+					name := action.(core.DeleteAction).GetName()
+					if name != expectedName {
+						t.Errorf("%s: Expected deleted vIngressClass %s, got %s", k, expectedName, name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect deleted vIngressClass %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedCreatedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("create", "ingressclasses") {
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.IngressClass)
+					if created.Name != expectedName {
+						t.Errorf("%s: Expected created vIngressClass %s, got %s", k, expectedName, created.Name)
+					}
+					if !conversion.IsSyncerManaged(created) {
+						t.Errorf("%s: Expected created vIngressClass %s to be managed by syncer", k, created.Name)
+					}
+					if got := created.Annotations[constants.AnnotationIsDefaultIngressClass]; got != tc.ExpectedDefaultClass {
+						t.Errorf("%s: Expected created vIngressClass %s default class %q, got %q", k, created.Name, tc.ExpectedDefaultClass, got)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect created vIngressClass %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedUpdatedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("update", "ingressclasses") {
+						continue
+					}
+					updated := action.(core.UpdateAction).GetObject().(*v1.IngressClass)
+					if updated.Name != expectedName {
+						t.Errorf("%s: Expected updated vIngressClass %s, got %s", k, expectedName, updated.Name)
+					}
+					if !conversion.IsSyncerManaged(updated) {
+						t.Errorf("%s: Expected updated vIngressClass %s to be managed by syncer", k, updated.Name)
+					}
+					if tc.ExpectedDefaultClass != "" && updated.Annotations[constants.AnnotationIsDefaultIngressClass] != tc.ExpectedDefaultClass {
+						t.Errorf("%s: Expected updated vIngressClass %s default class %q, got %q", k, updated.Name, tc.ExpectedDefaultClass, updated.Annotations[constants.AnnotationIsDefaultIngressClass])
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect updated vIngressClass %s but not found", k, expectedName)
+				}
+			}
+		})
+	}
+}
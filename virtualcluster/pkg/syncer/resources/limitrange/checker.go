@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limitrange
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+
+	if !cache.WaitForCacheSync(stopCh, c.limitRangeSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting LimitRange checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo checks to see if limitranges in super master informer cache and tenant master
+// keep consistency.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "limitrange")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	atomic.StoreUint64(&c.numMissMatchedLimitRanges, 0)
+
+	pLimitRanges, err := c.limitRangeLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing limitranges from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+	pSet := differ.NewDiffSet()
+	for _, pLR := range pLimitRanges {
+		pSet.Insert(differ.ClusterObject{Object: pLR, Key: differ.DefaultClusterObjectKey(pLR, "")})
+	}
+
+	knownClusterSet := sets.NewString(clusterNames...)
+	vSet := differ.NewDiffSet()
+	for _, cluster := range clusterNames {
+		lrList := &v1.LimitRangeList{}
+		if err := c.MultiClusterController.List(cluster, lrList); err != nil {
+			klog.Errorf("error listing limitranges from cluster %s informer cache: %v", cluster, err)
+			knownClusterSet.Delete(cluster)
+			continue
+		}
+
+		for i := range lrList.Items {
+			vSet.Insert(differ.ClusterObject{
+				Object:       &lrList.Items[i],
+				OwnerCluster: cluster,
+				Key:          differ.DefaultClusterObjectKey(&lrList.Items[i], cluster),
+			})
+		}
+	}
+
+	limitRangeDiffer := differ.HandlerFuncs{}
+	limitRangeDiffer.AddFunc = func(vObj differ.ClusterObject) {
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
+		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+			klog.Errorf("error requeue vLimitRange %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantLimitRanges").Inc()
+		}
+	}
+	limitRangeDiffer.UpdateFunc = func(vObj, pObj differ.ClusterObject) {
+		vLR := vObj.Object.(*v1.LimitRange)
+		pLR := pObj.Object.(*v1.LimitRange)
+
+		if pLR.Annotations[constants.LabelUID] != string(vLR.UID) {
+			klog.Errorf("Found pLimitRange %s delegated UID is different from tenant object.", pObj.Key)
+			limitRangeDiffer.OnDelete(pObj)
+			return
+		}
+		vc, err := util.GetVirtualClusterObject(c.MultiClusterController, vObj.GetOwnerCluster())
+		if err != nil {
+			klog.Errorf("fail to get cluster spec : %s", vObj.GetOwnerCluster())
+			return
+		}
+		updated := conversion.Equality(c.Config, vc).CheckLimitRangeEquality(pLR, vLR)
+		if updated != nil {
+			atomic.AddUint64(&c.numMissMatchedLimitRanges, 1)
+			klog.Warningf("LimitRange %s diff in super&tenant master", pObj.Key)
+			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+				klog.Errorf("error requeue vLimitRange %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantLimitRanges").Inc()
+			}
+		}
+	}
+	limitRangeDiffer.DeleteFunc = func(pObj differ.ClusterObject) {
+		deleteOptions := &metav1.DeleteOptions{}
+		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pObj.GetUID()))
+		if err := c.limitRangeClient.LimitRanges(pObj.GetNamespace()).Delete(ctx, pObj.GetName(), *deleteOptions); err != nil {
+			klog.Errorf("error deleting pLimitRange %s in super master: %v", pObj.Key, err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperMasterLimitRanges").Inc()
+		}
+	}
+
+	vSet.Difference(pSet, differ.FilteringHandler{
+		Handler:    limitRangeDiffer,
+		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
+	})
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedLimitRanges").Set(float64(atomic.LoadUint64(&c.numMissMatchedLimitRanges)))
+}
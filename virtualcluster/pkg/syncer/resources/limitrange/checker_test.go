@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limitrange
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func TestLimitRangePatrol(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedDeletedPObject []string
+		ExpectedCreatedPObject []string
+		ExpectedUpdatedPObject []runtime.Object
+		ExpectedNoOperation    bool
+		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
+		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
+	}{
+		"pLimitRange not created by vc": {
+			ExistingObjectInSuper: []runtime.Object{
+				tenantLimitRange("lr-1", superDefaultNSName, "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pLimitRange exists, vLimitRange does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExpectedDeletedPObject: []string{
+				superDefaultNSName + "/lr-2",
+			},
+		},
+		"pLimitRange exists, vLimitRange exists with different uid": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-3", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantLimitRange("lr-3", "default", "123456"),
+			},
+			ExpectedDeletedPObject: []string{
+				superDefaultNSName + "/lr-3",
+			},
+		},
+		"pLimitRange exists, vLimitRange exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-4", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyContainerLimit(tenantLimitRange("lr-4", "default", "12345"), "1", "2"),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyContainerLimit(superLimitRange("lr-4", superDefaultNSName, "12345", defaultClusterKey), "1", "2"),
+			},
+			WaitDWS: true,
+		},
+		"pLimitRange exists, vLimitRange exists with same spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyContainerLimit(superLimitRange("lr-5", superDefaultNSName, "12345", defaultClusterKey), "1", "2"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyContainerLimit(tenantLimitRange("lr-5", "default", "12345"), "1", "2"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"vLimitRange exists, pLimitRange does not exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantLimitRange("lr-6", "default", "12345"),
+			},
+			ExpectedCreatedPObject: []string{
+				superDefaultNSName + "/lr-6",
+			},
+			WaitDWS: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenantActions, superActions, err := util.RunPatrol(NewLimitRangeController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
+			if err != nil {
+				t.Errorf("%s: error running patrol: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(superActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v in super cluster", k, superActions)
+					return
+				}
+				if len(tenantActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v tenant cluster", k, tenantActions)
+					return
+				}
+				return
+			}
+
+			if tc.ExpectedDeletedPObject != nil {
+				if len(tc.ExpectedDeletedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to delete pLimitRange %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPObject, superActions)
+					return
+				}
+				for i, expectedName := range tc.ExpectedDeletedPObject {
+					action := superActions[i]
+					if !action.Matches("delete", "limitranges") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+						continue
+					}
+					fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+					if fullName != expectedName {
+						t.Errorf("%s: Expect to delete pLimitRange %s, got %s", k, expectedName, fullName)
+					}
+				}
+			}
+			if tc.ExpectedCreatedPObject != nil {
+				if len(tc.ExpectedCreatedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to create pLimitRange %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPObject, superActions)
+					return
+				}
+				for i, expectedName := range tc.ExpectedCreatedPObject {
+					action := superActions[i]
+					if !action.Matches("create", "limitranges") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.LimitRange)
+					fullName := created.Namespace + "/" + created.Name
+					if fullName != expectedName {
+						t.Errorf("%s: Expect to create pLimitRange %s, got %s", k, expectedName, fullName)
+					}
+				}
+			}
+			if tc.ExpectedUpdatedPObject != nil {
+				if len(tc.ExpectedUpdatedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to update pLimitRange %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPObject, superActions)
+					return
+				}
+				for i, obj := range tc.ExpectedUpdatedPObject {
+					action := superActions[i]
+					if !action.Matches("update", "limitranges") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+					}
+					actionObj := action.(core.UpdateAction).GetObject()
+					if !equality.Semantic.DeepEqual(obj, actionObj) {
+						t.Errorf("%s: Expected updated pLimitRange is %v, got %v", k, obj, actionObj)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limitrange
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "limitrange",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewLimitRangeController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+		Disable: true,
+	})
+}
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
+	// super master limitrange client
+	limitRangeClient v1core.LimitRangesGetter
+	// super master limitrange informer lister/synced function
+	limitRangeLister listersv1.LimitRangeLister
+	limitRangeSynced cache.InformerSynced
+	// numMissMatchedLimitRanges is the number of mismatched limitranges found in the last patrol.
+	numMissMatchedLimitRanges uint64
+}
+
+func NewLimitRangeController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		limitRangeClient: client.CoreV1(),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.LimitRange{}, &v1.LimitRangeList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.limitRangeLister = informer.Core().V1().LimitRanges().Lister()
+	if options.IsFake {
+		c.limitRangeSynced = func() bool { return true }
+	} else {
+		c.limitRangeSynced = informer.Core().V1().LimitRanges().Informer().HasSynced
+	}
+
+	c.superNamespaces = pa.NewSuperNamespaceGuard("limitrange", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.LimitRange{}, c, pa.WithResourcePeriod(config, "limitrange"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,392 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limitrange
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func tenantLimitRange(name, namespace, uid string) *v1.LimitRange {
+	return &v1.LimitRange{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "LimitRange",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+func superLimitRange(name, namespace, uid, clusterKey string) *v1.LimitRange {
+	return &v1.LimitRange{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "LimitRange",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				constants.LabelUID:       uid,
+				constants.LabelCluster:   clusterKey,
+				constants.LabelNamespace: "default",
+			},
+		},
+	}
+}
+
+func applyContainerLimit(lr *v1.LimitRange, max, ratio string) *v1.LimitRange {
+	lr.Spec.Limits = []v1.LimitRangeItem{
+		{
+			Type:                 v1.LimitTypeContainer,
+			Min:                  v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+			Max:                  v1.ResourceList{v1.ResourceCPU: resource.MustParse(max)},
+			Default:              v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			DefaultRequest:       v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m")},
+			MaxLimitRequestRatio: v1.ResourceList{v1.ResourceCPU: resource.MustParse(ratio)},
+		},
+	}
+	return lr
+}
+
+func TestDWLimitRangeCreation(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedCreatedPObject []string
+		ExpectedCreatedSpec    *v1.LimitRangeSpec
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"new lr": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantLimitRange("lr-1", "default", "12345"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/lr-1"},
+		},
+		"new lr with container limit": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				applyContainerLimit(tenantLimitRange("lr-1", "default", "12345"), "1", "2"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/lr-1"},
+			ExpectedCreatedSpec:    &applyContainerLimit(tenantLimitRange("lr-1", "default", "12345"), "1", "2").Spec,
+		},
+		"new lr but already exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantLimitRange("lr-2", "default", "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"new lr but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantLimitRange("lr-3", "default", "12345"),
+			},
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewLimitRangeController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedCreatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to create lr %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedCreatedPObject {
+				action := actions[i]
+				if !action.Matches("create", "limitranges") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				created := action.(core.CreateAction).GetObject().(*v1.LimitRange)
+				fullName := created.Namespace + "/" + created.Name
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
+				}
+				if tc.ExpectedCreatedSpec != nil && !equality.Semantic.DeepEqual(*tc.ExpectedCreatedSpec, created.Spec) {
+					t.Errorf("%s: Expected created spec %v, got %v", k, *tc.ExpectedCreatedSpec, created.Spec)
+				}
+			}
+		})
+	}
+}
+
+func TestDWLimitRangeDeletion(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueueObject          *v1.LimitRange
+		ExpectedDeletedPObject []string
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"delete lr": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-1", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			EnqueueObject:          tenantLimitRange("lr-1", "default", "12345"),
+			ExpectedDeletedPObject: []string{superDefaultNSName + "/lr-1"},
+		},
+		"delete lr but already gone": {
+			ExistingObjectInSuper: []runtime.Object{},
+			EnqueueObject:         tenantLimitRange("lr-2", "default", "12345"),
+			ExpectedNoOperation:   true,
+		},
+		"delete lr but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			EnqueueObject: tenantLimitRange("lr-3", "default", "12345"),
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewLimitRangeController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedDeletedPObject) != len(actions) {
+				t.Errorf("%s: Expected to delete lr %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedDeletedPObject {
+				action := actions[i]
+				if !action.Matches("delete", "limitranges") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be deleted, got %s", k, expectedName, fullName)
+				}
+			}
+		})
+	}
+}
+
+func TestDWLimitRangeUpdate(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedUpdatedPObject []runtime.Object
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"no diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyContainerLimit(superLimitRange("lr-1", superDefaultNSName, "12345", defaultClusterKey), "1", "2"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyContainerLimit(tenantLimitRange("lr-1", "default", "12345"), "1", "2"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"diff in spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyContainerLimit(tenantLimitRange("lr-2", "default", "12345"), "1", "2"),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyContainerLimit(superLimitRange("lr-2", superDefaultNSName, "12345", defaultClusterKey), "1", "2"),
+			},
+		},
+		"diff in max limit request ratio": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyContainerLimit(superLimitRange("lr-4", superDefaultNSName, "12345", defaultClusterKey), "1", "2"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyContainerLimit(tenantLimitRange("lr-4", "default", "12345"), "1", "4"),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyContainerLimit(superLimitRange("lr-4", superDefaultNSName, "12345", defaultClusterKey), "1", "4"),
+			},
+		},
+		"diff exists but uid is wrong": {
+			ExistingObjectInSuper: []runtime.Object{
+				superLimitRange("lr-3", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyContainerLimit(tenantLimitRange("lr-3", "default", "123456"), "1", "2"),
+			},
+			ExpectedError:       "delegated UID is different",
+			ExpectedNoOperation: true,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewLimitRangeController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedUpdatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to update lr %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPObject, actions)
+				return
+			}
+			for i, obj := range tc.ExpectedUpdatedPObject {
+				action := actions[i]
+				if !action.Matches("update", "limitranges") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				actionObj := action.(core.UpdateAction).GetObject()
+				if !equality.Semantic.DeepEqual(obj, actionObj) {
+					t.Errorf("%s: Expected updated lr is %v, got %v", k, obj, actionObj)
+				}
+			}
+		})
+	}
+}
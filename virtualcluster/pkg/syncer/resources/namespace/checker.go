@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
@@ -81,15 +82,17 @@ func (c *controller) shouldBeGarbageCollected(ns *v1.Namespace) bool {
 	return false
 }
 
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.V(4).Infof("super cluster has no tenant control planes, still check %s for gc purpose", "namespace")
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	pList, err := c.nsLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing namespaces from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 	pSet := differ.NewDiffSet()
@@ -86,7 +86,7 @@ func NewNamespaceController(config *config.SyncerConfiguration,
 		c.vcSynced = vcInformer.Informer().HasSynced
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.Namespace{}, c, pa.WithOptions(options.PatrolOptions))
+	c.Patroller, err = pa.NewPatroller(&v1.Namespace{}, c, pa.WithResourcePeriod(config, "namespace"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+
+	if !cache.WaitForCacheSync(stopCh, c.networkPolicySynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting NetworkPolicy checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo checks to see if networkpolicies in super master informer cache and tenant master
+// keep consistency.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "networkpolicy")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	atomic.StoreUint64(&c.numMissMatchedNetworkPolicies, 0)
+
+	pNetworkPolicies, err := c.networkPolicyLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing networkpolicies from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+	pSet := differ.NewDiffSet()
+	for _, pNP := range pNetworkPolicies {
+		pSet.Insert(differ.ClusterObject{Object: pNP, Key: differ.DefaultClusterObjectKey(pNP, "")})
+	}
+
+	knownClusterSet := sets.NewString(clusterNames...)
+	vSet := differ.NewDiffSet()
+	for _, cluster := range clusterNames {
+		npList := &v1.NetworkPolicyList{}
+		if err := c.MultiClusterController.List(cluster, npList); err != nil {
+			klog.Errorf("error listing networkpolicies from cluster %s informer cache: %v", cluster, err)
+			knownClusterSet.Delete(cluster)
+			continue
+		}
+
+		for i := range npList.Items {
+			vSet.Insert(differ.ClusterObject{
+				Object:       &npList.Items[i],
+				OwnerCluster: cluster,
+				Key:          differ.DefaultClusterObjectKey(&npList.Items[i], cluster),
+			})
+		}
+	}
+
+	networkPolicyDiffer := differ.HandlerFuncs{}
+	networkPolicyDiffer.AddFunc = func(vObj differ.ClusterObject) {
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
+		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+			klog.Errorf("error requeue vNetworkPolicy %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantNetworkPolicies").Inc()
+		}
+	}
+	networkPolicyDiffer.UpdateFunc = func(vObj, pObj differ.ClusterObject) {
+		vNP := vObj.Object.(*v1.NetworkPolicy)
+		pNP := pObj.Object.(*v1.NetworkPolicy)
+
+		if pNP.Annotations[constants.LabelUID] != string(vNP.UID) {
+			klog.Errorf("Found pNetworkPolicy %s delegated UID is different from tenant object.", pObj.Key)
+			networkPolicyDiffer.OnDelete(pObj)
+			return
+		}
+		vc, err := util.GetVirtualClusterObject(c.MultiClusterController, vObj.GetOwnerCluster())
+		if err != nil {
+			klog.Errorf("fail to get cluster spec : %s", vObj.GetOwnerCluster())
+			return
+		}
+		updated := conversion.Equality(c.Config, vc).CheckNetworkPolicyEquality(pNP, vNP)
+		if updated != nil {
+			atomic.AddUint64(&c.numMissMatchedNetworkPolicies, 1)
+			klog.Warningf("NetworkPolicy %s diff in super&tenant master", pObj.Key)
+			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+				klog.Errorf("error requeue vNetworkPolicy %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantNetworkPolicies").Inc()
+			}
+		}
+	}
+	networkPolicyDiffer.DeleteFunc = func(pObj differ.ClusterObject) {
+		deleteOptions := &metav1.DeleteOptions{}
+		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pObj.GetUID()))
+		if err := c.networkPolicyClient.NetworkPolicies(pObj.GetNamespace()).Delete(ctx, pObj.GetName(), *deleteOptions); err != nil {
+			klog.Errorf("error deleting pNetworkPolicy %s in super master: %v", pObj.Key, err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperMasterNetworkPolicies").Inc()
+		}
+	}
+
+	vSet.Difference(pSet, differ.FilteringHandler{
+		Handler:    networkPolicyDiffer,
+		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
+	})
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedNetworkPolicies").Set(float64(atomic.LoadUint64(&c.numMissMatchedNetworkPolicies)))
+}
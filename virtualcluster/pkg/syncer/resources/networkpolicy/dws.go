@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.networkPolicySynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+// The reconcile logic for tenant master networkpolicy informer
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	klog.V(4).Infof("reconcile networkpolicy %s/%s event for cluster %s", request.Namespace, request.Name, request.ClusterName)
+
+	targetNamespace := conversion.ToSuperMasterNamespace(request.ClusterName, request.Namespace)
+	pNetworkPolicy, err := c.networkPolicyLister.NetworkPolicies(targetNamespace).Get(request.Name)
+	pExists := true
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		pExists = false
+	}
+	vExists := true
+	vNetworkPolicy := &v1.NetworkPolicy{}
+	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vNetworkPolicy); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		vExists = false
+	}
+
+	if vExists && !pExists {
+		err := c.reconcileNetworkPolicyCreate(request.ClusterName, targetNamespace, request.UID, vNetworkPolicy)
+		if err != nil {
+			klog.Errorf("failed reconcile networkpolicy %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if !vExists && pExists {
+		err := c.reconcileNetworkPolicyRemove(request.ClusterName, targetNamespace, request.UID, request.Name, pNetworkPolicy)
+		if err != nil {
+			klog.Errorf("failed reconcile networkpolicy %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if vExists && pExists {
+		err := c.reconcileNetworkPolicyUpdate(request.ClusterName, targetNamespace, request.UID, pNetworkPolicy, vNetworkPolicy)
+		if err != nil {
+			klog.Errorf("failed reconcile networkpolicy %s/%s UPDATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else {
+		// object is gone.
+	}
+	return reconciler.Result{}, nil
+}
+
+func (c *controller) reconcileNetworkPolicyCreate(clusterName, targetNamespace, requestUID string, networkPolicy *v1.NetworkPolicy) error {
+	vcName, vcNS, _, err := c.MultiClusterController.GetOwnerInfo(clusterName)
+	if err != nil {
+		return err
+	}
+	newObj, err := conversion.BuildMetadata(clusterName, vcNS, vcName, targetNamespace, networkPolicy)
+	if err != nil {
+		return err
+	}
+
+	pNetworkPolicy := newObj.(*v1.NetworkPolicy)
+	conversion.MutateNetworkPolicySpec(&pNetworkPolicy.Spec, clusterName)
+
+	pNetworkPolicy, err = c.networkPolicyClient.NetworkPolicies(targetNamespace).Create(context.TODO(), pNetworkPolicy, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		if pNetworkPolicy.Annotations[constants.LabelUID] == requestUID {
+			klog.Infof("networkpolicy %s/%s of cluster %s already exist in super master", targetNamespace, networkPolicy.Name, clusterName)
+			return nil
+		} else {
+			return fmt.Errorf("pNetworkPolicy %s/%s exists but its delegated object UID is different.", targetNamespace, pNetworkPolicy.Name)
+		}
+	}
+	return err
+}
+
+func (c *controller) reconcileNetworkPolicyUpdate(clusterName, targetNamespace, requestUID string, pNetworkPolicy, vNetworkPolicy *v1.NetworkPolicy) error {
+	if pNetworkPolicy.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("pNetworkPolicy %s/%s delegated UID is different from updated object.", targetNamespace, pNetworkPolicy.Name)
+	}
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	updatedNetworkPolicy := conversion.Equality(c.Config, vc).CheckNetworkPolicyEquality(pNetworkPolicy, vNetworkPolicy)
+	if updatedNetworkPolicy != nil {
+		_, err = c.networkPolicyClient.NetworkPolicies(targetNamespace).Update(context.TODO(), updatedNetworkPolicy, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) reconcileNetworkPolicyRemove(clusterName, targetNamespace, requestUID, name string, pNetworkPolicy *v1.NetworkPolicy) error {
+	if pNetworkPolicy.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("To be deleted pNetworkPolicy %s/%s delegated UID is different from deleted object.", targetNamespace, name)
+	}
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+	}
+	err := c.networkPolicyClient.NetworkPolicies(targetNamespace).Delete(context.TODO(), name, *opts)
+	if errors.IsNotFound(err) {
+		klog.Warningf("networkpolicy %s/%s of cluster %s not found in super master", targetNamespace, name, clusterName)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func tenantNetworkPolicy(name, namespace, uid string) *v1.NetworkPolicy {
+	return &v1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+func superNetworkPolicy(name, namespace, uid, clusterKey string) *v1.NetworkPolicy {
+	return &v1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				constants.LabelUID:       uid,
+				constants.LabelCluster:   clusterKey,
+				constants.LabelNamespace: "default",
+			},
+		},
+	}
+}
+
+func applyIngressFromNamespace(np *v1.NetworkPolicy, namespace string) *v1.NetworkPolicy {
+	np.Spec.Ingress = []v1.NetworkPolicyIngressRule{
+		{
+			From: []v1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							corev1.LabelMetadataName: namespace,
+						},
+					},
+				},
+			},
+		},
+	}
+	return np
+}
+
+func applyIngressFromTenant(np *v1.NetworkPolicy, namespace, clusterKey string) *v1.NetworkPolicy {
+	np = applyIngressFromNamespace(np, namespace)
+	np.Spec.Ingress[0].From[0].PodSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			constants.LabelCluster: clusterKey,
+		},
+	}
+	return np
+}
+
+func TestDWNetworkPolicyCreation(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+	superKubeSystemNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "kube-system")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedCreatedPObject []string
+		ExpectedCreatedSpec    *v1.NetworkPolicySpec
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"new np": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantNetworkPolicy("np-1", "default", "12345"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/np-1"},
+		},
+		"new np with namespace selector": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				applyIngressFromNamespace(tenantNetworkPolicy("np-1", "default", "12345"), "kube-system"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/np-1"},
+			ExpectedCreatedSpec:    &applyIngressFromTenant(tenantNetworkPolicy("np-1", "default", "12345"), superKubeSystemNSName, defaultClusterKey).Spec,
+		},
+		"new np but already exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNetworkPolicy("np-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantNetworkPolicy("np-2", "default", "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"new np but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNetworkPolicy("np-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantNetworkPolicy("np-3", "default", "12345"),
+			},
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewNetworkPolicyController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedCreatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to create np %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedCreatedPObject {
+				action := actions[i]
+				if !action.Matches("create", "networkpolicies") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				created := action.(core.CreateAction).GetObject().(*v1.NetworkPolicy)
+				fullName := created.Namespace + "/" + created.Name
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
+				}
+				if tc.ExpectedCreatedSpec != nil && !equality.Semantic.DeepEqual(*tc.ExpectedCreatedSpec, created.Spec) {
+					t.Errorf("%s: Expected created spec %v, got %v", k, *tc.ExpectedCreatedSpec, created.Spec)
+				}
+			}
+		})
+	}
+}
+
+func TestDWNetworkPolicyDeletion(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueueObject          *v1.NetworkPolicy
+		ExpectedDeletedPObject []string
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"delete np": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNetworkPolicy("np-1", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			EnqueueObject:          tenantNetworkPolicy("np-1", "default", "12345"),
+			ExpectedDeletedPObject: []string{superDefaultNSName + "/np-1"},
+		},
+		"delete np but already gone": {
+			ExistingObjectInSuper: []runtime.Object{},
+			EnqueueObject:         tenantNetworkPolicy("np-2", "default", "12345"),
+			ExpectedNoOperation:   true,
+		},
+		"delete np but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNetworkPolicy("np-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			EnqueueObject: tenantNetworkPolicy("np-3", "default", "12345"),
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewNetworkPolicyController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedDeletedPObject) != len(actions) {
+				t.Errorf("%s: Expected to delete np %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedDeletedPObject {
+				action := actions[i]
+				if !action.Matches("delete", "networkpolicies") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be deleted, got %s", k, expectedName, fullName)
+				}
+			}
+		})
+	}
+}
+
+func TestDWNetworkPolicyUpdate(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+	superKubeSystemNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "kube-system")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedUpdatedPObject []runtime.Object
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"no diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyIngressFromTenant(superNetworkPolicy("np-1", superDefaultNSName, "12345", defaultClusterKey), superKubeSystemNSName, defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyIngressFromNamespace(tenantNetworkPolicy("np-1", "default", "12345"), "kube-system"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"diff in spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNetworkPolicy("np-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyIngressFromNamespace(tenantNetworkPolicy("np-2", "default", "12345"), "kube-system"),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyIngressFromTenant(superNetworkPolicy("np-2", superDefaultNSName, "12345", defaultClusterKey), superKubeSystemNSName, defaultClusterKey),
+			},
+		},
+		"diff exists but uid is wrong": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNetworkPolicy("np-3", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyIngressFromNamespace(tenantNetworkPolicy("np-3", "default", "123456"), "kube-system"),
+			},
+			ExpectedError:       "delegated UID is different",
+			ExpectedNoOperation: true,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewNetworkPolicyController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedUpdatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to update np %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPObject, actions)
+				return
+			}
+			for i, obj := range tc.ExpectedUpdatedPObject {
+				action := actions[i]
+				if !action.Matches("update", "networkpolicies") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				actionObj := action.(core.UpdateAction).GetObject()
+				if !equality.Semantic.DeepEqual(obj, actionObj) {
+					t.Errorf("%s: Expected updated np is %v, got %v", k, obj, actionObj)
+				}
+			}
+		})
+	}
+}
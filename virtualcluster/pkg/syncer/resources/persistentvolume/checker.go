@@ -20,17 +20,20 @@ import (
 	"context"
 	"fmt"
 	"sync/atomic"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 )
 
@@ -38,7 +41,7 @@ var numClaimMissMatchedPVs uint64
 var numSpecMissMatchedPVs uint64
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
-	if !cache.WaitForCacheSync(stopCh, c.pvSynced, c.pvcSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.pvSynced, c.pvcSynced, c.nsSynced) {
 		return fmt.Errorf("failed to wait for caches to sync before starting Service checker")
 	}
 	c.Patroller.Start(stopCh)
@@ -46,12 +49,14 @@ func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 }
 
 // PatrollerDo check if persistent volumes keep consistency between super master and tenant masters.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "persistentvolume")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	numClaimMissMatchedPVs = 0
 	numSpecMissMatchedPVs = 0
@@ -59,6 +64,7 @@ func (c *controller) PatrollerDo() {
 	pList, err := c.pvLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing pv from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 	pSet := differ.NewDiffSet()
@@ -113,7 +119,7 @@ func (c *controller) PatrollerDo() {
 			return
 		}
 
-		updatedPVSpec := conversion.Equality(c.Config, nil).CheckPVSpecEquality(&pPV.Spec, &vPV.Spec)
+		updatedPVSpec := conversion.ConfigEquality(c.Config).CheckPVSpecEquality(&pPV.Spec, &vPV.Spec)
 		if updatedPVSpec != nil {
 			atomic.AddUint64(&numSpecMissMatchedPVs, 1)
 			klog.Warningf("spec of pv %v diff in super&tenant master %s", vPV.Name, clusterName)
@@ -175,11 +181,91 @@ func (c *controller) PatrollerDo() {
 				// Bound PVC does not belong to any tenant.
 				return false
 			}
-			return true
+			// the pv of a paused cluster is not requeued.
+			return !pa.PatrolPaused(clusterName)
 		},
 	})
 
 	metrics.CheckerMissMatchStats.WithLabelValues("ClaimMissMatchedPVs").Set(float64(numClaimMissMatchedPVs))
 	metrics.CheckerMissMatchStats.WithLabelValues("SpecMissMatchedPVs").Set(float64(numSpecMissMatchedPVs))
 
+	c.reportLeakedPVs(pList, clusterNames)
+}
+
+// reportLeakedPVs finds the super master PVs released by the deletion of their tenant PVCs. The PVs retained or
+// recycled are left to super master. A PV with the Delete reclaim policy is removed along with its volume by the
+// super master pv controller or the external provisioner, if it is still released after the grace period, the
+// volume most likely failed to be deleted. The PV is reported as leaked but never deleted by the patroller, it is
+// the only handle left to the volume.
+func (c *controller) reportLeakedPVs(pList []*v1.PersistentVolume, clusterNames []string) {
+	var leaked uint64
+	released := make(map[string]time.Time)
+	defer c.setReleasedPVs(released)
+
+	activeClusters := sets.NewString(clusterNames...)
+	for _, pPV := range pList {
+		clusterName := c.releasedByTenant(pPV)
+		if clusterName == "" || !activeClusters.Has(clusterName) {
+			continue
+		}
+		if pPV.Spec.PersistentVolumeReclaimPolicy != v1.PersistentVolumeReclaimDelete {
+			klog.V(4).Infof("pv %s released by cluster %s has reclaim policy %s, leave it to super master", pPV.Name, clusterName, pPV.Spec.PersistentVolumeReclaimPolicy)
+			continue
+		}
+		firstSeen := c.releasedFirstSeenTime(pPV.Name)
+		released[pPV.Name] = firstSeen
+		if time.Since(firstSeen) < c.reclaimGracePeriod {
+			continue
+		}
+
+		leaked++
+		klog.Warningf("pv %s released by pvc %s/%s of cluster %s is not reclaimed since %v, its volume may be leaked", pPV.Name,
+			pPV.Spec.ClaimRef.Namespace, pPV.Spec.ClaimRef.Name, clusterName, firstSeen)
+	}
+
+	metrics.CheckerMissMatchStats.WithLabelValues("LeakedPVs").Set(float64(leaked))
+}
+
+// releasedByTenant returns the tenant cluster whose deleted PVC released the super master PV, or empty if the PV
+// is not released or not bound to a tenant PVC. The cluster is resolved from the super master namespace of the
+// claim, hence the PVs whose super master namespace is gone are not attributed to any tenant.
+func (c *controller) releasedByTenant(pPV *v1.PersistentVolume) string {
+	if !boundPersistentVolume(pPV) || pPV.Status.Phase != v1.VolumeReleased {
+		return ""
+	}
+	claimRef := pPV.Spec.ClaimRef
+	pPVC, err := c.pvcLister.PersistentVolumeClaims(claimRef.Namespace).Get(claimRef.Name)
+	if err == nil && pPVC.UID == claimRef.UID {
+		// The claim is still there, the pv controller has not caught up yet.
+		return ""
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("fail to get pPVC %s/%s in super master: %v", claimRef.Namespace, claimRef.Name, err)
+		return ""
+	}
+	clusterName, _, err := conversion.GetVirtualNamespace(c.nsLister, claimRef.Namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("fail to get namespace %s in super master: %v", claimRef.Namespace, err)
+		}
+		return ""
+	}
+	return clusterName
+}
+
+// releasedFirstSeenTime returns the time when the released super master PV was first observed.
+func (c *controller) releasedFirstSeenTime(name string) time.Time {
+	c.Lock()
+	defer c.Unlock()
+	if t, ok := c.releasedPVs[name]; ok {
+		return t
+	}
+	return time.Now()
+}
+
+// setReleasedPVs replaces the released PVs with the ones observed in the last patrol.
+func (c *controller) setReleasedPVs(released map[string]time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	c.releasedPVs = released
 }
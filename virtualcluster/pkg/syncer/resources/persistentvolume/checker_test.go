@@ -15,7 +15,9 @@ package persistentvolume
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -29,6 +31,8 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
 )
 
 func superPV(name, uid string) *v1.PersistentVolume {
@@ -350,3 +354,109 @@ func TestPVPatrol(t *testing.T) {
 		})
 	}
 }
+
+func superNamespace(name, clusterKey string) *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				constants.LabelCluster:   clusterKey,
+				constants.LabelNamespace: "default",
+			},
+		},
+	}
+}
+
+func TestPVPatrolReportLeaked(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	// A namespace of another tenant whose name also starts with the cluster key of the test tenant.
+	otherClusterKey := defaultClusterKey + "-x"
+	superOtherNSName := conversion.ToSuperMasterNamespace(otherClusterKey, "default")
+
+	releasedPV := func(namespace string, policy v1.PersistentVolumeReclaimPolicy) *v1.PersistentVolume {
+		pv := boundPV(superPVC("pvc", namespace, "23456", defaultClusterKey), superPV("pv", "12345"))
+		pv.Spec.PersistentVolumeReclaimPolicy = policy
+		pv.Status.Phase = v1.VolumeReleased
+		return pv
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper []runtime.Object
+		GracePeriod           time.Duration
+		ExpectedLeaked        float64
+	}{
+		"released pv with delete policy is reported": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(superDefaultNSName, defaultClusterKey),
+				releasedPV(superDefaultNSName, v1.PersistentVolumeReclaimDelete),
+			},
+			ExpectedLeaked: 1,
+		},
+		"released pv with delete policy within grace period": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(superDefaultNSName, defaultClusterKey),
+				releasedPV(superDefaultNSName, v1.PersistentVolumeReclaimDelete),
+			},
+			GracePeriod: time.Hour,
+		},
+		"released pv with retain policy": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(superDefaultNSName, defaultClusterKey),
+				releasedPV(superDefaultNSName, v1.PersistentVolumeReclaimRetain),
+			},
+		},
+		"released pv whose claim is recreated": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(superDefaultNSName, defaultClusterKey),
+				superPVC("pvc", superDefaultNSName, "23456", defaultClusterKey),
+				releasedPV(superDefaultNSName, v1.PersistentVolumeReclaimDelete),
+			},
+		},
+		"released pv not bound to a tenant claim": {
+			ExistingObjectInSuper: []runtime.Object{
+				releasedPV("default", v1.PersistentVolumeReclaimDelete),
+			},
+		},
+		"released pv in the namespace of another tenant": {
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(superOtherNSName, otherClusterKey),
+				releasedPV(superOtherNSName, v1.PersistentVolumeReclaimDelete),
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			_, superActions, err := util.RunPatrol(NewPVController, testTenant, tc.ExistingObjectInSuper, nil, nil, false, false, func(rs manager.ResourceSyncer) {
+				rs.(*controller).reclaimGracePeriod = tc.GracePeriod
+			})
+			if err != nil {
+				t.Errorf("%s: error running patrol: %v", k, err)
+				return
+			}
+
+			for _, action := range superActions {
+				if action.Matches("delete", "persistentvolumes") {
+					t.Errorf("%s: unexpected deletion of pPV %s", k, action.(core.DeleteAction).GetName())
+				}
+			}
+			if v := testutil.ToFloat64(metrics.CheckerMissMatchStats.WithLabelValues("LeakedPVs")); v != tc.ExpectedLeaked {
+				t.Errorf("%s: expected %v leaked pPVs, got %v", k, tc.ExpectedLeaked, v)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ package persistentvolume
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -31,6 +33,7 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
@@ -42,7 +45,7 @@ func init() {
 	plugin.SyncerResourceRegister.Register(&plugin.Registration{
 		ID: "persistentvolume",
 		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
-			return NewPVController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+			return NewPVController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptionsFromConfig(ctx.Config.(*config.SyncerConfiguration)))
 		},
 	})
 }
@@ -57,6 +60,16 @@ type controller struct {
 	pvSynced  cache.InformerSynced
 	pvcLister listersv1.PersistentVolumeClaimLister
 	pvcSynced cache.InformerSynced
+	// super master namespace lister/synced functions, the tenant cluster of a released PV is resolved from
+	// the super master namespace of its claim.
+	nsLister listersv1.NamespaceLister
+	nsSynced cache.InformerSynced
+	// reclaimGracePeriod is how long a released super master PV with the Delete reclaim policy is given to
+	// be reclaimed by super master before the patroller reports it as leaked.
+	reclaimGracePeriod time.Duration
+	// releasedPVs records when each super master PV released by a tenant PVC deletion was first observed.
+	sync.Mutex
+	releasedPVs map[string]time.Time
 }
 
 func NewPVController(config *config.SyncerConfiguration,
@@ -69,8 +82,13 @@ func NewPVController(config *config.SyncerConfiguration,
 		BaseResourceSyncer: manager.BaseResourceSyncer{
 			Config: config,
 		},
-		client:   client.CoreV1(),
-		informer: informer.Core().V1(),
+		client:             client.CoreV1(),
+		informer:           informer.Core().V1(),
+		reclaimGracePeriod: constants.DefaultPVReclaimGracePeriod,
+		releasedPVs:        make(map[string]time.Time),
+	}
+	if options.PVReclaimGracePeriod > 0 {
+		c.reclaimGracePeriod = options.PVReclaimGracePeriod
 	}
 
 	var err error
@@ -81,13 +99,16 @@ func NewPVController(config *config.SyncerConfiguration,
 
 	c.pvLister = c.informer.PersistentVolumes().Lister()
 	c.pvcLister = c.informer.PersistentVolumeClaims().Lister()
+	c.nsLister = c.informer.Namespaces().Lister()
 
 	if options.IsFake {
 		c.pvSynced = func() bool { return true }
 		c.pvcSynced = func() bool { return true }
+		c.nsSynced = func() bool { return true }
 	} else {
 		c.pvSynced = c.informer.PersistentVolumes().Informer().HasSynced
 		c.pvcSynced = c.informer.PersistentVolumeClaims().Informer().HasSynced
+		c.nsSynced = c.informer.Namespaces().Informer().HasSynced
 	}
 
 	c.UpwardController, err = uw.NewUWController(&v1.PersistentVolume{}, c, uw.WithOptions(options.UWOptions))
@@ -95,7 +116,7 @@ func NewPVController(config *config.SyncerConfiguration,
 		return nil, err
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.PersistentVolume{}, c, pa.WithOptions(options.PatrolOptions))
+	c.Patroller, err = pa.NewPatroller(&v1.PersistentVolume{}, c, pa.WithResourcePeriod(config, "persistentvolume"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
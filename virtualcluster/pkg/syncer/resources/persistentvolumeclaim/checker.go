@@ -31,11 +31,14 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
 var numMissMatchedPVCs uint64
+var numStatusMissMatchedPVCs uint64
+var numCapacityMissMatchedPVCs uint64
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.pvcSynced) {
@@ -47,18 +50,23 @@ func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 
 // PatrollerDo check if persistent volume claims keep consistency between super
 // master and tenant masters.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "persistentvolumeclaim")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	numMissMatchedPVCs = 0
+	numStatusMissMatchedPVCs = 0
+	numCapacityMissMatchedPVCs = 0
 
 	pList, err := c.pvcLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing pvc from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 	pSet := differ.NewDiffSet()
@@ -87,6 +95,9 @@ func (c *controller) PatrollerDo() {
 
 	d := differ.HandlerFuncs{}
 	d.AddFunc = func(vObj differ.ClusterObject) {
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
 		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
 			klog.Errorf("error requeue vPVC %s in cluster %s: %v", vObj.Key, vObj.GetOwnerCluster(), err)
 		} else {
@@ -111,6 +122,36 @@ func (c *controller) PatrollerDo() {
 		if updatedPVC != nil {
 			atomic.AddUint64(&numMissMatchedPVCs, 1)
 			klog.Warningf("spec of pvc %s diff in super&tenant master", pObj.Key)
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would requeue pvc %s for cluster %s", vObj.Key, vObj.GetOwnerCluster())
+				metrics.CheckerDryRunStats.WithLabelValues("RequeuedTenantPVCs").Inc()
+				return
+			}
+			d.OnAdd(vObj)
+			return
+		}
+
+		if pa.StatusUpsyncEnabled(c.Config, "persistentvolumeclaim") {
+			fields := pa.Fields{Resource: "pvc", Cluster: vObj.GetOwnerCluster(), Object: v.Namespace + "/" + v.Name}
+			if pa.UpsyncStatus(fields, "UpsyncedTenantPVCStatus", p.Status, v.Status, c.patrollerDryRun, func() error {
+				return c.upsyncStatus(vObj.GetOwnerCluster(), p, v)
+			}) {
+				atomic.AddUint64(&numStatusMissMatchedPVCs, 1)
+			}
+			return
+		}
+
+		// the capacity is reflected back once super master expands the volume.
+		if conversion.Equality(c.Config, vc).CheckPVCCapacityEquality(p, v) != nil {
+			atomic.AddUint64(&numCapacityMissMatchedPVCs, 1)
+			klog.Warningf("capacity of pvc %s diff in super&tenant master", pObj.Key)
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would requeue pPVC %s", pObj.Key)
+				metrics.CheckerDryRunStats.WithLabelValues("RequeuedSuperMasterPVCs").Inc()
+				return
+			}
+			c.UpwardController.AddToQueue(p.Namespace + "/" + p.Name)
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterPVCs").Inc()
 		}
 	}
 	d.DeleteFunc = func(pObj differ.ClusterObject) {
@@ -129,4 +170,18 @@ func (c *controller) PatrollerDo() {
 	})
 
 	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedPVCs").Set(float64(numMissMatchedPVCs))
+	metrics.CheckerMissMatchStats.WithLabelValues("StatusMissMatchedPVCs").Set(float64(numStatusMissMatchedPVCs))
+	metrics.CheckerMissMatchStats.WithLabelValues("CapacityMissMatchedPVCs").Set(float64(numCapacityMissMatchedPVCs))
+}
+
+// upsyncStatus copies the status of the super master pvc to the tenant pvc.
+func (c *controller) upsyncStatus(clusterName string, pPVC, vPVC *v1.PersistentVolumeClaim) error {
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	newPVC := vPVC.DeepCopy()
+	newPVC.Status = pPVC.Status
+	_, err = tenantClient.CoreV1().PersistentVolumeClaims(vPVC.Namespace).UpdateStatus(context.TODO(), newPVC, metav1.UpdateOptions{})
+	return err
 }
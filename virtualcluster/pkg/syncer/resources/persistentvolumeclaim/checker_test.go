@@ -21,15 +21,22 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 )
 
 func TestPVCPatrol(t *testing.T) {
@@ -71,6 +78,13 @@ func TestPVCPatrol(t *testing.T) {
 		VolumeName:       "volume-1",
 	}
 
+	bound := &v1.PersistentVolumeClaimStatus{
+		Phase: v1.ClaimBound,
+		Capacity: v1.ResourceList{
+			v1.ResourceStorage: resource.MustParse("20Gi"),
+		},
+	}
+
 	defaultClusterKey := conversion.ToClusterKey(testTenant)
 	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
 
@@ -85,6 +99,7 @@ func TestPVCPatrol(t *testing.T) {
 		ExpectedNoOperation    bool
 		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
 		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
+		StatusUpsync           bool
 	}{
 		"pPVC not created by vc": {
 			ExistingObjectInSuper: []runtime.Object{
@@ -113,13 +128,61 @@ func TestPVCPatrol(t *testing.T) {
 		},
 		"pPVC exists, vPVC exists with different spec": {
 			ExistingObjectInSuper: []runtime.Object{
-				applySpecToPVC(superPVC("pvc-3", superDefaultNSName, "12345", defaultClusterKey), spec2),
+				applySpecToPVC(superPVC("pvc-3", superDefaultNSName, "12345", defaultClusterKey), spec1),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applySpecToPVC(tenantPVC("pvc-3", "default", "12345"), spec2),
+			},
+			ExpectedNoOperation: true,
+			// notes: the vPVC is requeued to DWS, which does not run here.
+		},
+		"pPVC exists, vPVC exists with shrunk storage request": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySpecToPVC(superPVC("pvc-7", superDefaultNSName, "12345", defaultClusterKey), spec2),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applySpecToPVC(tenantPVC("pvc-7", "default", "12345"), spec1),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pPVC exists, vPVC exists with different status": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPVC(superPVC("pvc-5", superDefaultNSName, "12345", defaultClusterKey), bound),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPVC("pvc-5", "default", "12345"),
+			},
+			// only the capacity is reflected back, the vPVC is read from the tenant cache with its type meta.
+			ExpectedUpdatedVObject: []runtime.Object{
+				func() *v1.PersistentVolumeClaim {
+					pvc := applyStatusToPVC(tenantPVC("pvc-5", "default", "12345"), &v1.PersistentVolumeClaimStatus{Capacity: bound.Capacity})
+					pvc.TypeMeta = metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"}
+					return pvc
+				}(),
+			},
+			WaitUWS: true,
+		},
+		"pPVC exists, vPVC exists with different status, status upsync enabled": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPVC(superPVC("pvc-5", superDefaultNSName, "12345", defaultClusterKey), bound),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPVC("pvc-5", "default", "12345"),
+			},
+			ExpectedUpdatedVObject: []runtime.Object{
+				applyStatusToPVC(tenantPVC("pvc-5", "default", "12345"), bound),
+			},
+			StatusUpsync: true,
+		},
+		"pPVC exists, vPVC exists with different spec and status, status upsync enabled": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPVC(applySpecToPVC(superPVC("pvc-6", superDefaultNSName, "12345", defaultClusterKey), spec1), bound),
 			},
 			ExistingObjectInTenant: []runtime.Object{
-				applySpecToPVC(tenantPVC("pvc-3", "default", "12345"), spec1),
+				applySpecToPVC(tenantPVC("pvc-6", "default", "12345"), spec2),
 			},
 			ExpectedNoOperation: true,
-			// notes: have not updated the different pPVC in patrol now.
+			StatusUpsync:        true,
 		},
 		"vPVC exists, pPVC does not exists": {
 			ExistingObjectInTenant: []runtime.Object{
@@ -134,7 +197,14 @@ func TestPVCPatrol(t *testing.T) {
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			tenantActions, superActions, err := util.RunPatrol(NewPVCController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
+			newControllerFunc := NewPVCController
+			if tc.StatusUpsync {
+				newControllerFunc = func(config *config.SyncerConfiguration, client clientset.Interface, informer informers.SharedInformerFactory, vcClient vcclient.Interface, vcInformer vcinformers.VirtualClusterInformer, options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+					config.StatusUpsyncResources = []string{"persistentvolumeclaim"}
+					return NewPVCController(config, client, informer, vcClient, vcInformer, options)
+				}
+			}
+			tenantActions, superActions, err := util.RunPatrol(newControllerFunc, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
 			if err != nil {
 				t.Errorf("%s: error running patrol: %v", k, err)
 				return
@@ -227,10 +297,12 @@ func TestPVCPatrol(t *testing.T) {
 				}
 				for i, obj := range tc.ExpectedUpdatedVObject {
 					action := tenantActions[i]
-					if !action.Matches("update", "persistentvolumeclaims") {
+					if !action.Matches("update", "persistentvolumeclaims") || action.GetSubresource() != "status" {
 						t.Errorf("%s: Unexpected action %s", k, action)
 					}
 					actionObj := action.(core.UpdateAction).GetObject()
+					accessor, _ := meta.Accessor(obj)
+					accessor.SetResourceVersion("999")
 					if !equality.Semantic.DeepEqual(obj, actionObj) {
 						t.Errorf("%s: Expected updated vPVC is %v, got %v", k, obj, actionObj)
 					}
@@ -239,3 +311,8 @@ func TestPVCPatrol(t *testing.T) {
 		})
 	}
 }
+
+func applyStatusToPVC(pvc *v1.PersistentVolumeClaim, status *v1.PersistentVolumeClaimStatus) *v1.PersistentVolumeClaim {
+	pvc.Status = *status.DeepCopy()
+	return pvc
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package persistentvolumeclaim
 
 import (
+	"fmt"
+
 	v1 "k8s.io/api/core/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -27,8 +30,10 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -37,18 +42,22 @@ func init() {
 	plugin.SyncerResourceRegister.Register(&plugin.Registration{
 		ID: "persistentvolumeclaim",
 		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
-			return NewPVCController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+			return NewPVCController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptionsFromConfig(ctx.Config.(*config.SyncerConfiguration)))
 		},
 	})
 }
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
 	// super master pvc client
 	pvcClient v1core.PersistentVolumeClaimsGetter
 	// super master pvc lister
 	pvcLister listersv1.PersistentVolumeClaimLister
 	pvcSynced cache.InformerSynced
+	// patrollerDryRun indicates that the patroller only logs the remediation it would take.
+	patrollerDryRun bool
 }
 
 func NewPVCController(config *config.SyncerConfiguration,
@@ -61,7 +70,8 @@ func NewPVCController(config *config.SyncerConfiguration,
 		BaseResourceSyncer: manager.BaseResourceSyncer{
 			Config: config,
 		},
-		pvcClient: client.CoreV1(),
+		pvcClient:       client.CoreV1(),
+		patrollerDryRun: options.PatrollerDryRun,
 	}
 
 	var err error
@@ -77,10 +87,54 @@ func NewPVCController(config *config.SyncerConfiguration,
 		c.pvcSynced = informer.Core().V1().PersistentVolumeClaims().Informer().HasSynced
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.PersistentVolumeClaim{}, c, pa.WithOptions(options.PatrolOptions))
+	c.UpwardController, err = uw.NewUWController(&v1.PersistentVolumeClaim{}, c, uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
 
+	c.superNamespaces = pa.NewSuperNamespaceGuard("persistentvolumeclaim", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.PersistentVolumeClaim{}, c, pa.WithResourcePeriod(config, "persistentvolumeclaim"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	// The capacity of a pvc grows once super master expands its volume, which is reflected back to the tenant pvc.
+	informer.Core().V1().PersistentVolumeClaims().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.PersistentVolumeClaim:
+					return syncedPVC(t)
+				default:
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newPVC := newObj.(*v1.PersistentVolumeClaim)
+					oldPVC := oldObj.(*v1.PersistentVolumeClaim)
+					if conversion.ConfigEquality(c.Config).CheckPVCCapacityEquality(newPVC, oldPVC) != nil {
+						c.enqueuePVC(newPVC)
+					}
+				},
+			},
+		})
+
 	return c, nil
 }
+
+// syncedPVC returns true if the super master pvc is synced from a tenant master.
+func syncedPVC(pvc *v1.PersistentVolumeClaim) bool {
+	clusterName, _ := conversion.GetVirtualOwner(pvc)
+	return clusterName != ""
+}
+
+func (c *controller) enqueuePVC(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+	c.UpwardController.AddToQueue(key)
+}
@@ -118,6 +118,9 @@ func (c *controller) reconcilePVCUpdate(clusterName, targetNamespace, requestUID
 	if err != nil {
 		return err
 	}
+	if conversion.PVCStorageShrunk(pPVC, vPVC) {
+		klog.Warningf("pvc %s/%s of cluster %s requests less storage than pPVC, shrinking a pvc is not supported", vPVC.Namespace, vPVC.Name, clusterName)
+	}
 	updatedPVC := conversion.Equality(c.Config, vc).CheckPVCEquality(pPVC, vPVC)
 	if updatedPVC != nil {
 		pPVC, err = c.pvcClient.PersistentVolumeClaims(targetNamespace).Update(context.TODO(), updatedPVC, metav1.UpdateOptions{})
@@ -34,6 +34,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
@@ -131,12 +132,14 @@ func (c *controller) deleteClusterVNode(cluster, nodeName string) {
 
 // PatrollerDo checks to see if pods in super master informer cache and tenant master
 // keep consistency.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "pod")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	wg := sync.WaitGroup{}
 
@@ -147,6 +150,7 @@ func (c *controller) PatrollerDo() {
 	pList, err := c.podLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing pod from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 	pSet := differ.NewDiffSet()
@@ -197,6 +201,9 @@ func (c *controller) PatrollerDo() {
 			metrics.CheckerRemedyStats.WithLabelValues("DeletedTenantPodsDueToSuperEviction").Inc()
 			return
 		}
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
 		c.requeuePod(vObj.GetOwnerCluster(), vPod)
 	}
 	d.UpdateFunc = func(vObj, pObj differ.ClusterObject) {
@@ -256,7 +263,7 @@ func (c *controller) PatrollerDo() {
 			}
 		}
 
-		if conversion.Equality(c.Config, nil).CheckUWPodStatusEquality(pPod, vPod) != nil {
+		if conversion.ConfigEquality(c.Config).CheckUWPodStatusEquality(pPod, vPod) != nil {
 			atomic.AddUint64(&numStatusMissMatchedPods, 1)
 			klog.Warningf("status of pod %v/%v diff in super&tenant master", pPod.Namespace, pPod.Name)
 			if assignedPod(pPod) {
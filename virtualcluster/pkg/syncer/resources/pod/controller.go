@@ -58,6 +58,8 @@ func init() {
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
 	// super master pod client
 	client v1core.CoreV1Interface
 	// super master informer/listers/synced functions
@@ -133,7 +135,9 @@ func NewPodController(config *config.SyncerConfiguration,
 		return nil, err
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.Pod{}, c, pa.WithOptions(options.PatrolOptions))
+	c.superNamespaces = pa.NewSuperNamespaceGuard("pod", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.Pod{}, c, pa.WithResourcePeriod(config, "pod"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
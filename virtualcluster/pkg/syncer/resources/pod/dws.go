@@ -202,6 +202,7 @@ func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID
 		conversion.PodMutateServiceLink(c.Config.DisablePodServiceLinks),
 		conversion.PodMutateDefault(vPod, pSecretMap, services, nameServer),
 		conversion.PodMutateAutoMountServiceAccountToken(c.Config.DisableServiceAccountToken),
+		conversion.PodMutatePriority(vPod, c.Config.MaxTenantPriority),
 		// TODO: make extension configurable
 		//conversion.PodAddExtensionMeta(vPod),
 	}
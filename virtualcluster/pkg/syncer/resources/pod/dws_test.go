@@ -28,12 +28,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
@@ -418,6 +424,106 @@ func TestDWPodCreation(t *testing.T) {
 	}
 }
 
+func TestDWPodPriority(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	defaultVCName, defaultVCNamespace := testTenant.Name, testTenant.Namespace
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+	preemptNever := v1.PreemptNever
+	applyPriority := func(pod *v1.Pod, className string, priority *int32) *v1.Pod {
+		pod.Spec.PriorityClassName = className
+		pod.Spec.Priority = priority
+		if priority != nil {
+			pod.Spec.PreemptionPolicy = &preemptNever
+		}
+		return pod
+	}
+
+	testcases := map[string]struct {
+		MaxTenantPriority  int32
+		PriorityClassName  string
+		Priority           *int32
+		ExpectedClassName  string
+		ExpectedPriority   *int32
+		ExpectedPreemption bool
+	}{
+		"priority not capped": {
+			PriorityClassName:  "high",
+			Priority:           pointer.Int32Ptr(20000),
+			ExpectedClassName:  "high",
+			ExpectedPriority:   pointer.Int32Ptr(20000),
+			ExpectedPreemption: true,
+		},
+		"priority below max tenant priority": {
+			MaxTenantPriority: 10000,
+			PriorityClassName: "low",
+			Priority:          pointer.Int32Ptr(5000),
+			ExpectedClassName: "low",
+		},
+		"priority above max tenant priority": {
+			MaxTenantPriority: 10000,
+			PriorityClassName: "system-cluster-critical",
+			Priority:          pointer.Int32Ptr(2000000000),
+		},
+		"priority unset": {
+			MaxTenantPriority: 10000,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			existingObjectInSuper := []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			}
+			existingObjectInTenant := []runtime.Object{
+				applyPriority(tenantPod("pod-1", "default", "12345"), tc.PriorityClassName, tc.Priority),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			}
+			newPodController := func(config *config.SyncerConfiguration, client clientset.Interface, informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface, vcInformer vcinformers.VirtualClusterInformer, options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.MaxTenantPriority = tc.MaxTenantPriority
+				return NewPodController(config, client, informer, vcClient, vcInformer, options)
+			}
+			actions, reconcileErr, err := util.RunDownwardSync(newPodController, testTenant, existingObjectInSuper, existingObjectInTenant, existingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("%s: expected no error, but got \"%v\"", k, reconcileErr)
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("create", "pods") {
+				t.Errorf("%s: Expected to create a Pod. Actual actions were: %#v", k, actions)
+				return
+			}
+
+			expectedPod := superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345")
+			applyPriority(expectedPod, tc.ExpectedClassName, tc.ExpectedPriority)
+			if !tc.ExpectedPreemption {
+				expectedPod.Spec.PreemptionPolicy = nil
+			}
+			createdPod := actions[0].(core.CreateAction).GetObject().(*v1.Pod)
+			if !equality.Semantic.DeepEqual(createdPod, expectedPod) {
+				t.Errorf("%s: Expected %+v to be created, got %+v", k, expectedPod.Spec, createdPod.Spec)
+			}
+		})
+	}
+}
+
 func TestDWPodDeletion(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
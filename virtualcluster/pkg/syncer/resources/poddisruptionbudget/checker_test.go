@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func TestPodDisruptionBudgetPatrol(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedDeletedPObject []string
+		ExpectedCreatedPObject []string
+		ExpectedUpdatedPObject []runtime.Object
+		ExpectedNoOperation    bool
+		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
+		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
+	}{
+		"pPodDisruptionBudget not created by vc": {
+			ExistingObjectInSuper: []runtime.Object{
+				tenantPodDisruptionBudget("pdb-1", superDefaultNSName, "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pPodDisruptionBudget exists, vPodDisruptionBudget does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPodDisruptionBudget("pdb-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExpectedDeletedPObject: []string{
+				superDefaultNSName + "/pdb-2",
+			},
+		},
+		"pPodDisruptionBudget exists, vPodDisruptionBudget exists with different uid": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPodDisruptionBudget("pdb-3", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPodDisruptionBudget("pdb-3", "default", "123456"),
+			},
+			ExpectedDeletedPObject: []string{
+				superDefaultNSName + "/pdb-3",
+			},
+		},
+		"pPodDisruptionBudget exists, vPodDisruptionBudget exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPodDisruptionBudget("pdb-4", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyMinAvailable(tenantPodDisruptionBudget("pdb-4", "default", "12345"), 1),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyMinAvailable(superPodDisruptionBudget("pdb-4", superDefaultNSName, "12345", defaultClusterKey), 1),
+			},
+			WaitDWS: true,
+		},
+		"pPodDisruptionBudget exists, vPodDisruptionBudget exists with converted spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySelector(superPodDisruptionBudget("pdb-5", superDefaultNSName, "12345", defaultClusterKey), map[string]string{"app": "a", constants.LabelCluster: defaultClusterKey}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applySelector(tenantPodDisruptionBudget("pdb-5", "default", "12345"), map[string]string{"app": "a"}),
+			},
+			ExpectedNoOperation: true,
+		},
+		"vPodDisruptionBudget exists, pPodDisruptionBudget does not exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPodDisruptionBudget("pdb-6", "default", "12345"),
+			},
+			ExpectedCreatedPObject: []string{
+				superDefaultNSName + "/pdb-6",
+			},
+			WaitDWS: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenantActions, superActions, err := util.RunPatrol(NewPodDisruptionBudgetController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
+			if err != nil {
+				t.Errorf("%s: error running patrol: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(superActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v in super cluster", k, superActions)
+					return
+				}
+				if len(tenantActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v tenant cluster", k, tenantActions)
+					return
+				}
+				return
+			}
+
+			if tc.ExpectedDeletedPObject != nil {
+				if len(tc.ExpectedDeletedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to delete pPodDisruptionBudget %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPObject, superActions)
+					return
+				}
+				for i, expectedName := range tc.ExpectedDeletedPObject {
+					action := superActions[i]
+					if !action.Matches("delete", "poddisruptionbudgets") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+						continue
+					}
+					fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+					if fullName != expectedName {
+						t.Errorf("%s: Expect to delete pPodDisruptionBudget %s, got %s", k, expectedName, fullName)
+					}
+				}
+			}
+			if tc.ExpectedCreatedPObject != nil {
+				if len(tc.ExpectedCreatedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to create pPodDisruptionBudget %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPObject, superActions)
+					return
+				}
+				for i, expectedName := range tc.ExpectedCreatedPObject {
+					action := superActions[i]
+					if !action.Matches("create", "poddisruptionbudgets") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.PodDisruptionBudget)
+					fullName := created.Namespace + "/" + created.Name
+					if fullName != expectedName {
+						t.Errorf("%s: Expect to create pPodDisruptionBudget %s, got %s", k, expectedName, fullName)
+					}
+				}
+			}
+			if tc.ExpectedUpdatedPObject != nil {
+				if len(tc.ExpectedUpdatedPObject) != len(superActions) {
+					t.Errorf("%s: Expected to update pPodDisruptionBudget %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPObject, superActions)
+					return
+				}
+				for i, obj := range tc.ExpectedUpdatedPObject {
+					action := superActions[i]
+					if !action.Matches("update", "poddisruptionbudgets") {
+						t.Errorf("%s: Unexpected action %s", k, action)
+					}
+					actionObj := action.(core.UpdateAction).GetObject()
+					if !equality.Semantic.DeepEqual(obj, actionObj) {
+						t.Errorf("%s: Expected updated pPodDisruptionBudget is %v, got %v", k, obj, actionObj)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.podDisruptionBudgetSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+// The reconcile logic for tenant master poddisruptionbudget informer
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	klog.V(4).Infof("reconcile poddisruptionbudget %s/%s event for cluster %s", request.Namespace, request.Name, request.ClusterName)
+
+	targetNamespace := conversion.ToSuperMasterNamespace(request.ClusterName, request.Namespace)
+	pPodDisruptionBudget, err := c.podDisruptionBudgetLister.PodDisruptionBudgets(targetNamespace).Get(request.Name)
+	pExists := true
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		pExists = false
+	}
+	vExists := true
+	vPodDisruptionBudget := &v1.PodDisruptionBudget{}
+	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vPodDisruptionBudget); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		vExists = false
+	}
+
+	if vExists && !pExists {
+		err := c.reconcilePodDisruptionBudgetCreate(request.ClusterName, targetNamespace, request.UID, vPodDisruptionBudget)
+		if err != nil {
+			klog.Errorf("failed reconcile poddisruptionbudget %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if !vExists && pExists {
+		err := c.reconcilePodDisruptionBudgetRemove(request.ClusterName, targetNamespace, request.UID, request.Name, pPodDisruptionBudget)
+		if err != nil {
+			klog.Errorf("failed reconcile poddisruptionbudget %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if vExists && pExists {
+		err := c.reconcilePodDisruptionBudgetUpdate(request.ClusterName, targetNamespace, request.UID, pPodDisruptionBudget, vPodDisruptionBudget)
+		if err != nil {
+			klog.Errorf("failed reconcile poddisruptionbudget %s/%s UPDATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else {
+		// object is gone.
+	}
+	return reconciler.Result{}, nil
+}
+
+func (c *controller) reconcilePodDisruptionBudgetCreate(clusterName, targetNamespace, requestUID string, podDisruptionBudget *v1.PodDisruptionBudget) error {
+	vcName, vcNS, _, err := c.MultiClusterController.GetOwnerInfo(clusterName)
+	if err != nil {
+		return err
+	}
+	newObj, err := conversion.BuildMetadata(clusterName, vcNS, vcName, targetNamespace, podDisruptionBudget)
+	if err != nil {
+		return err
+	}
+
+	pPodDisruptionBudget := newObj.(*v1.PodDisruptionBudget)
+	conversion.MutatePodDisruptionBudgetSpec(&pPodDisruptionBudget.Spec, clusterName)
+
+	pPodDisruptionBudget, err = c.podDisruptionBudgetClient.PodDisruptionBudgets(targetNamespace).Create(context.TODO(), pPodDisruptionBudget, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		if pPodDisruptionBudget.Annotations[constants.LabelUID] == requestUID {
+			klog.Infof("poddisruptionbudget %s/%s of cluster %s already exist in super master", targetNamespace, podDisruptionBudget.Name, clusterName)
+			return nil
+		} else {
+			return fmt.Errorf("pPodDisruptionBudget %s/%s exists but its delegated object UID is different.", targetNamespace, pPodDisruptionBudget.Name)
+		}
+	}
+	return err
+}
+
+func (c *controller) reconcilePodDisruptionBudgetUpdate(clusterName, targetNamespace, requestUID string, pPodDisruptionBudget, vPodDisruptionBudget *v1.PodDisruptionBudget) error {
+	if pPodDisruptionBudget.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("pPodDisruptionBudget %s/%s delegated UID is different from updated object.", targetNamespace, pPodDisruptionBudget.Name)
+	}
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	updatedPodDisruptionBudget := conversion.Equality(c.Config, vc).CheckPodDisruptionBudgetEquality(pPodDisruptionBudget, vPodDisruptionBudget)
+	if updatedPodDisruptionBudget != nil {
+		_, err = c.podDisruptionBudgetClient.PodDisruptionBudgets(targetNamespace).Update(context.TODO(), updatedPodDisruptionBudget, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) reconcilePodDisruptionBudgetRemove(clusterName, targetNamespace, requestUID, name string, pPodDisruptionBudget *v1.PodDisruptionBudget) error {
+	if pPodDisruptionBudget.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("To be deleted pPodDisruptionBudget %s/%s delegated UID is different from deleted object.", targetNamespace, name)
+	}
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+	}
+	err := c.podDisruptionBudgetClient.PodDisruptionBudgets(targetNamespace).Delete(context.TODO(), name, *opts)
+	if errors.IsNotFound(err) {
+		klog.Warningf("poddisruptionbudget %s/%s of cluster %s not found in super master", targetNamespace, name, clusterName)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,394 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poddisruptionbudget
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func tenantPodDisruptionBudget(name, namespace, uid string) *v1.PodDisruptionBudget {
+	return &v1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+func superPodDisruptionBudget(name, namespace, uid, clusterKey string) *v1.PodDisruptionBudget {
+	return &v1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				constants.LabelUID:       uid,
+				constants.LabelCluster:   clusterKey,
+				constants.LabelNamespace: "default",
+			},
+		},
+	}
+}
+
+func applySelector(pdb *v1.PodDisruptionBudget, labels map[string]string) *v1.PodDisruptionBudget {
+	pdb.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: labels,
+	}
+	return pdb
+}
+
+func applyMinAvailable(pdb *v1.PodDisruptionBudget, minAvailable int) *v1.PodDisruptionBudget {
+	value := intstr.FromInt(minAvailable)
+	pdb.Spec.MinAvailable = &value
+	return pdb
+}
+
+func TestDWPodDisruptionBudgetCreation(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedCreatedPObject []string
+		ExpectedCreatedSpec    *v1.PodDisruptionBudgetSpec
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"new pdb": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPodDisruptionBudget("pdb-1", "default", "12345"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/pdb-1"},
+		},
+		"new pdb with selector": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				applySelector(tenantPodDisruptionBudget("pdb-1", "default", "12345"), map[string]string{"app": "a"}),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/pdb-1"},
+			ExpectedCreatedSpec: &applySelector(tenantPodDisruptionBudget("pdb-1", "default", "12345"), map[string]string{
+				"app":                  "a",
+				constants.LabelCluster: defaultClusterKey,
+			}).Spec,
+		},
+		"new pdb but already exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPodDisruptionBudget("pdb-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPodDisruptionBudget("pdb-2", "default", "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"new pdb but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPodDisruptionBudget("pdb-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPodDisruptionBudget("pdb-3", "default", "12345"),
+			},
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewPodDisruptionBudgetController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedCreatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to create pdb %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedCreatedPObject {
+				action := actions[i]
+				if !action.Matches("create", "poddisruptionbudgets") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				created := action.(core.CreateAction).GetObject().(*v1.PodDisruptionBudget)
+				fullName := created.Namespace + "/" + created.Name
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
+				}
+				if tc.ExpectedCreatedSpec != nil && !equality.Semantic.DeepEqual(*tc.ExpectedCreatedSpec, created.Spec) {
+					t.Errorf("%s: Expected created spec %v, got %v", k, *tc.ExpectedCreatedSpec, created.Spec)
+				}
+			}
+		})
+	}
+}
+
+func TestDWPodDisruptionBudgetDeletion(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueueObject          *v1.PodDisruptionBudget
+		ExpectedDeletedPObject []string
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"delete pdb": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPodDisruptionBudget("pdb-1", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			EnqueueObject:          tenantPodDisruptionBudget("pdb-1", "default", "12345"),
+			ExpectedDeletedPObject: []string{superDefaultNSName + "/pdb-1"},
+		},
+		"delete pdb but already gone": {
+			ExistingObjectInSuper: []runtime.Object{},
+			EnqueueObject:         tenantPodDisruptionBudget("pdb-2", "default", "12345"),
+			ExpectedNoOperation:   true,
+		},
+		"delete pdb but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPodDisruptionBudget("pdb-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			EnqueueObject: tenantPodDisruptionBudget("pdb-3", "default", "12345"),
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewPodDisruptionBudgetController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedDeletedPObject) != len(actions) {
+				t.Errorf("%s: Expected to delete pdb %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedDeletedPObject {
+				action := actions[i]
+				if !action.Matches("delete", "poddisruptionbudgets") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be deleted, got %s", k, expectedName, fullName)
+				}
+			}
+		})
+	}
+}
+
+func TestDWPodDisruptionBudgetUpdate(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedUpdatedPObject []runtime.Object
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"no diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySelector(superPodDisruptionBudget("pdb-1", superDefaultNSName, "12345", defaultClusterKey), map[string]string{"app": "a", constants.LabelCluster: defaultClusterKey}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applySelector(tenantPodDisruptionBudget("pdb-1", "default", "12345"), map[string]string{"app": "a"}),
+			},
+			ExpectedNoOperation: true,
+		},
+		"diff in selector": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySelector(superPodDisruptionBudget("pdb-2", superDefaultNSName, "12345", defaultClusterKey), map[string]string{"app": "a", constants.LabelCluster: defaultClusterKey}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applySelector(tenantPodDisruptionBudget("pdb-2", "default", "12345"), map[string]string{"app": "b"}),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applySelector(superPodDisruptionBudget("pdb-2", superDefaultNSName, "12345", defaultClusterKey), map[string]string{"app": "b", constants.LabelCluster: defaultClusterKey}),
+			},
+		},
+		"diff in minAvailable": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyMinAvailable(superPodDisruptionBudget("pdb-3", superDefaultNSName, "12345", defaultClusterKey), 1),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyMinAvailable(tenantPodDisruptionBudget("pdb-3", "default", "12345"), 2),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyMinAvailable(superPodDisruptionBudget("pdb-3", superDefaultNSName, "12345", defaultClusterKey), 2),
+			},
+		},
+		"diff exists but uid is wrong": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPodDisruptionBudget("pdb-4", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyMinAvailable(tenantPodDisruptionBudget("pdb-4", "default", "123456"), 2),
+			},
+			ExpectedError:       "delegated UID is different",
+			ExpectedNoOperation: true,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewPodDisruptionBudgetController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedUpdatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to update pdb %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPObject, actions)
+				return
+			}
+			for i, obj := range tc.ExpectedUpdatedPObject {
+				action := actions[i]
+				if !action.Matches("update", "poddisruptionbudgets") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				actionObj := action.(core.UpdateAction).GetObject()
+				if !equality.Semantic.DeepEqual(obj, actionObj) {
+					t.Errorf("%s: Expected updated pdb is %v, got %v", k, obj, actionObj)
+				}
+			}
+		})
+	}
+}
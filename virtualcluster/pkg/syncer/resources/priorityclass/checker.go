@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	v1 "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -32,46 +33,58 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 )
 
 var numMissMatchedPriorityClasses uint64
 
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.priorityclassSynced) {
-		return fmt.Errorf("failed to wait for caches to sync before starting Service checker")
+		return fmt.Errorf("failed to wait for caches to sync before starting PriorityClass checker")
 	}
 	c.Patroller.Start(stopCh)
 	return nil
 }
 
-// ParollerDo check if PriorityClass keeps consistency between super master and tenant masters.
-func (c *controller) PatrollerDo() {
+// PatrollerDo check if PriorityClass keeps consistency between super master and tenant masters.
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "priorityclass")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	wg := sync.WaitGroup{}
-	numMissMatchedPriorityClasses = 0
+	atomic.StoreUint64(&numMissMatchedPriorityClasses, 0)
 
 	for _, clusterName := range clusterNames {
+		if ctx.Err() != nil {
+			break
+		}
 		wg.Add(1)
 		go func(clusterName string) {
 			defer wg.Done()
-			c.checkPriorityClassOfTenantCluster(clusterName)
+			c.checkPriorityClassOfTenantCluster(ctx, clusterName)
 		}(clusterName)
 	}
 	wg.Wait()
 
+	if ctx.Err() != nil {
+		klog.Infof("priorityclass patrol is cancelled: %v", ctx.Err())
+		return
+	}
+
 	pPriorityClassList, err := c.priorityclassLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing priorityclass from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 
 	for _, pPriorityClass := range pPriorityClassList {
-		if !publicPriorityClass(pPriorityClass) {
+		if !c.publicPriorityClass(pPriorityClass) {
 			continue
 		}
 		for _, clusterName := range clusterNames {
@@ -85,22 +98,30 @@ func (c *controller) PatrollerDo() {
 		}
 	}
 
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedPriorityClasses").Set(float64(numMissMatchedPriorityClasses))
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedPriorityClasses").Set(float64(atomic.LoadUint64(&numMissMatchedPriorityClasses)))
 }
 
-func (c *controller) checkPriorityClassOfTenantCluster(clusterName string) {
-	scList := &v1.PriorityClassList{}
-	if err := c.MultiClusterController.List(clusterName, scList); err != nil {
+func (c *controller) checkPriorityClassOfTenantCluster(ctx context.Context, clusterName string) {
+	defer metrics.RecordCheckerClusterScanDuration("PriorityClass", clusterName, time.Now())
+	pcList := &v1.PriorityClassList{}
+	if err := c.MultiClusterController.List(clusterName, pcList); err != nil {
 		klog.Errorf("error listing priorityclass from cluster %s informer cache: %v", clusterName, err)
 		return
 	}
+	klog.V(4).Infof("check priorityclass consistency in cluster %s", clusterName)
 
-	for i, vPriorityClass := range scList.Items {
-		if !publicPriorityClass(&vPriorityClass) {
+	for i, vPriorityClass := range pcList.Items {
+		if ctx.Err() != nil {
+			klog.V(4).Infof("stop checking priorityclass in cluster %s: %v", clusterName, ctx.Err())
+			return
+		}
+		// tenant masters may own priorityclasses of their own, only the synced public ones are checked.
+		if vPriorityClass.Labels[constants.PublicObjectKey] != "true" {
 			continue
 		}
 		pPriorityClass, err := c.priorityclassLister.Get(vPriorityClass.Name)
-		if errors.IsNotFound(err) {
+		// priorityclass which is no longer public, or exceeds MaxTenantPriority, is treated as orphan.
+		if errors.IsNotFound(err) || (err == nil && !c.publicPriorityClass(pPriorityClass)) {
 			// super master is the source of the truth for priorityclass object, delete tenant master obj
 			tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
 			if err != nil {
@@ -108,9 +129,9 @@ func (c *controller) checkPriorityClassOfTenantCluster(clusterName string) {
 				continue
 			}
 			opts := &metav1.DeleteOptions{
-				PropagationPolicy: &constants.DefaultDeletionPolicy,
+				PropagationPolicy: &c.deletionPropagationPolicy,
 			}
-			if err := tenantClient.SchedulingV1().PriorityClasses().Delete(context.TODO(), vPriorityClass.Name, *opts); err != nil {
+			if err := tenantClient.SchedulingV1().PriorityClasses().Delete(ctx, vPriorityClass.Name, *opts); err != nil {
 				klog.Errorf("error deleting priorityclass %v in cluster %s: %v", vPriorityClass.Name, clusterName, err)
 			} else {
 				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanTenantPriorityClasses").Inc()
@@ -123,13 +144,11 @@ func (c *controller) checkPriorityClassOfTenantCluster(clusterName string) {
 			continue
 		}
 
-		updatedPriorityClass := conversion.Equality(nil, nil).CheckPriorityClassEquality(pPriorityClass, &scList.Items[i])
+		updatedPriorityClass := conversion.ConfigEquality(c.Config).CheckPriorityClassEquality(pPriorityClass, &pcList.Items[i])
 		if updatedPriorityClass != nil {
 			atomic.AddUint64(&numMissMatchedPriorityClasses, 1)
 			klog.Warningf("spec of priorityClass %v diff in super&tenant master", vPriorityClass.Name)
-			if publicPriorityClass(pPriorityClass) {
-				c.UpwardController.AddToQueue(clusterName + "/" + pPriorityClass.Name)
-			}
+			c.UpwardController.AddToQueue(clusterName + "/" + pPriorityClass.Name)
 		}
 	}
 }
@@ -21,10 +21,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
@@ -48,6 +52,7 @@ func TestPriorityClassPatrol(t *testing.T) {
 		ExpectedCreatedVObject []string
 		ExpectedUpdatedVObject []runtime.Object
 		ExpectedNoOperation    bool
+		MaxTenantPriority      int32
 		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
 		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
 	}{
@@ -121,11 +126,83 @@ func TestPriorityClassPatrol(t *testing.T) {
 			},
 			WaitUWS: true,
 		},
+		"pPriorityClass no longer public, vPriorityClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makePriorityClass("pc", "123456", func(class *v1.PriorityClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExpectedDeletedVObject: []string{
+				"pc",
+			},
+		},
+		"pPriorityClass exists below max tenant priority, vPriorityClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", func(class *v1.PriorityClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+					class.Value = 10000
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makePriorityClass("pc", "123456", func(class *v1.PriorityClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+					class.Value = 10000
+				}),
+			},
+			MaxTenantPriority:   10000,
+			ExpectedNoOperation: true,
+		},
+		"pPriorityClass exists above max tenant priority, vPriorityClass does not exist": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", func(class *v1.PriorityClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+					class.Value = 20000
+				}),
+			},
+			MaxTenantPriority:   10000,
+			ExpectedNoOperation: true,
+		},
+		"pPriorityClass exists above max tenant priority, vPriorityClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", func(class *v1.PriorityClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+					class.Value = 20000
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makePriorityClass("pc", "123456", func(class *v1.PriorityClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+					class.Value = 10000
+				}),
+			},
+			ExpectedDeletedVObject: []string{
+				"pc",
+			},
+			MaxTenantPriority: 10000,
+		},
 	}
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			tenantActions, superActions, err := util.RunPatrol(NewPriorityClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
+			setMaxTenantPriority := func(s manager.ResourceSyncer) {
+				s.(*controller).Config.MaxTenantPriority = tc.MaxTenantPriority
+			}
+			tenantActions, superActions, err := util.RunPatrol(NewPriorityClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, setMaxTenantPriority)
 			if err != nil {
 				t.Errorf("%s: error running patrol: %v", k, err)
 				return
@@ -201,3 +278,32 @@ func TestPriorityClassPatrol(t *testing.T) {
 		})
 	}
 }
+
+func TestPriorityClassDeletionPropagationPolicy(t *testing.T) {
+	testcases := map[string]struct {
+		policy   metav1.DeletionPropagation
+		expected metav1.DeletionPropagation
+	}{
+		"default policy": {
+			expected: constants.DefaultDeletionPolicy,
+		},
+		"orphan policy": {
+			policy:   metav1.DeletePropagationOrphan,
+			expected: metav1.DeletePropagationOrphan,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			superClient := fake.NewSimpleClientset()
+			r, err := NewPriorityClassController(&config.SyncerConfiguration{}, superClient, informers.NewSharedInformerFactory(superClient, 0), nil, nil,
+				manager.ResourceSyncerOptions{IsFake: true, DeletionPropagationPolicy: tc.policy})
+			if err != nil {
+				t.Fatalf("error creating controller: %v", err)
+			}
+			if policy := r.(*controller).deletionPropagationPolicy; policy != tc.expected {
+				t.Errorf("expected deletion propagation policy %s, got %s", tc.expected, policy)
+			}
+		})
+	}
+}
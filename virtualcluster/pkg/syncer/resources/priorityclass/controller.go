@@ -18,7 +18,9 @@ package priorityclass
 
 import (
 	"fmt"
+
 	v1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	priorityclassinformers "k8s.io/client-go/informers/scheduling/v1"
@@ -57,6 +59,8 @@ type controller struct {
 	informer            priorityclassinformers.Interface
 	priorityclassLister listersv1.PriorityClassLister
 	priorityclassSynced cache.InformerSynced
+	// deletionPropagationPolicy is the propagation policy of the tenant priorityclasses deleted by syncer.
+	deletionPropagationPolicy metav1.DeletionPropagation
 }
 
 func NewPriorityClassController(config *config.SyncerConfiguration,
@@ -69,8 +73,12 @@ func NewPriorityClassController(config *config.SyncerConfiguration,
 		BaseResourceSyncer: manager.BaseResourceSyncer{
 			Config: config,
 		},
-		client:   client.SchedulingV1(),
-		informer: informer.Scheduling().V1(),
+		client:                    client.SchedulingV1(),
+		informer:                  informer.Scheduling().V1(),
+		deletionPropagationPolicy: constants.DefaultDeletionPolicy,
+	}
+	if options.DeletionPropagationPolicy != "" {
+		c.deletionPropagationPolicy = options.DeletionPropagationPolicy
 	}
 
 	var err error
@@ -91,7 +99,7 @@ func NewPriorityClassController(config *config.SyncerConfiguration,
 		return nil, err
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.PriorityClass{}, c, pa.WithOptions(options.PatrolOptions))
+	c.Patroller, err = pa.NewPatroller(&v1.PriorityClass{}, c, pa.WithResourcePeriod(config, "priorityclass"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -101,10 +109,10 @@ func NewPriorityClassController(config *config.SyncerConfiguration,
 			FilterFunc: func(obj interface{}) bool {
 				switch t := obj.(type) {
 				case *v1.PriorityClass:
-					return publicPriorityClass(t)
+					return c.publicPriorityClass(t)
 				case cache.DeletedFinalStateUnknown:
 					if e, ok := t.Obj.(*v1.PriorityClass); ok {
-						return publicPriorityClass(e)
+						return c.publicPriorityClass(e)
 					}
 					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.PriorityClass", obj))
 					return false
@@ -128,9 +136,28 @@ func NewPriorityClassController(config *config.SyncerConfiguration,
 	return c, nil
 }
 
-func publicPriorityClass(e *v1.PriorityClass) bool {
+func (c *controller) publicPriorityClass(e *v1.PriorityClass) bool {
+	return publicPriorityClass(c.Config, e)
+}
+
+func publicPriorityClass(config *config.SyncerConfiguration, e *v1.PriorityClass) bool {
 	// We only backpopulate specific priorityclass to tenant masters
-	return e.Labels[constants.PublicObjectKey] == "true"
+	return e.Labels[constants.PublicObjectKey] == "true" && withinMaxTenantPriority(config, e)
+}
+
+// withinMaxTenantPriority returns false if the value of the priorityclass exceeds MaxTenantPriority. Such
+// priorityclasses are kept from tenants, the pods using them would preempt super master critical workloads.
+func withinMaxTenantPriority(config *config.SyncerConfiguration, e *v1.PriorityClass) bool {
+	return config == nil || config.MaxTenantPriority == 0 || e.Value <= config.MaxTenantPriority
+}
+
+// PublicNameTaken returns true if the priorityclass of the name is synced from super master to tenant masters.
+func (c *controller) PublicNameTaken(name string) bool {
+	pPriorityClass, err := c.priorityclassLister.Get(name)
+	if err != nil {
+		return false
+	}
+	return c.publicPriorityClass(pPriorityClass)
 }
 
 func (c *controller) enqueuePriorityClass(obj interface{}) {
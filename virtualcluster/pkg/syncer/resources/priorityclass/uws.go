@@ -25,7 +25,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 
-	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
@@ -50,6 +49,9 @@ func (c *controller) BackPopulate(key string) error {
 			return err
 		}
 		op = reconciler.DeleteEvent
+	} else if !withinMaxTenantPriority(c.Config, pPriorityClass) {
+		// the tenant copy of a priorityclass above MaxTenantPriority is removed.
+		op = reconciler.DeleteEvent
 	}
 
 	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
@@ -75,14 +77,15 @@ func (c *controller) BackPopulate(key string) error {
 
 	if op == reconciler.DeleteEvent {
 		opts := &metav1.DeleteOptions{
-			PropagationPolicy: &constants.DefaultDeletionPolicy,
+			PropagationPolicy: &c.deletionPropagationPolicy,
+			Preconditions:     metav1.NewUIDPreconditions(string(vPriorityClass.UID)),
 		}
 		err := tenantClient.SchedulingV1().PriorityClasses().Delete(context.TODO(), scName, *opts)
-		if err != nil {
+		if err != nil && !errors.IsNotFound(err) {
 			return err
 		}
 	} else {
-		updatedPriorityClass := conversion.Equality(c.Config, nil).CheckPriorityClassEquality(pPriorityClass, vPriorityClass)
+		updatedPriorityClass := conversion.ConfigEquality(c.Config).CheckPriorityClassEquality(pPriorityClass, vPriorityClass)
 		if updatedPriorityClass != nil {
 			_, err := tenantClient.SchedulingV1().PriorityClasses().Update(context.TODO(), updatedPriorityClass, metav1.UpdateOptions{})
 			if err != nil {
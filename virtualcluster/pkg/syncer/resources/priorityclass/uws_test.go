@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
@@ -75,8 +76,10 @@ func TestUWPCCreation(t *testing.T) {
 		ExistingObjectInTenant []runtime.Object
 		EnqueuedKey            string
 		ExpectedCreatedObject  []string
+		ExpectedCreatedValue   int32
 		ExpectedError          string
 		ExpectedNoOperation    bool
+		MaxTenantPriority      int32
 	}{
 		"pPC exists but vPC not found": {
 			ExistingObjectInSuper: []runtime.Object{
@@ -97,11 +100,37 @@ func TestUWPCCreation(t *testing.T) {
 			EnqueuedKey:         defaultClusterKey + "/pc",
 			ExpectedNoOperation: true,
 		},
+		"pPC exists but vPC not found, value above max tenant priority": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", func(class *v1.PriorityClass) {
+					class.Value = 20000
+				}),
+			},
+			EnqueuedKey:         defaultClusterKey + "/pc",
+			ExpectedNoOperation: true,
+			MaxTenantPriority:   10000,
+		},
+		"pPC exists but vPC not found, value below max tenant priority": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", func(class *v1.PriorityClass) {
+					class.Value = 5000
+				}),
+			},
+			EnqueuedKey: defaultClusterKey + "/pc",
+			ExpectedCreatedObject: []string{
+				"pc",
+			},
+			ExpectedCreatedValue: 5000,
+			MaxTenantPriority:    10000,
+		},
 	}
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			actions, reconcileErr, err := util.RunUpwardSync(NewPriorityClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, nil)
+			setMaxTenantPriority := func(s manager.ResourceSyncer) {
+				s.(*controller).Config.MaxTenantPriority = tc.MaxTenantPriority
+			}
+			actions, reconcileErr, err := util.RunUpwardSync(NewPriorityClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, setMaxTenantPriority)
 			if err != nil {
 				t.Errorf("%s: error running upward sync: %v", k, err)
 				return
@@ -137,6 +166,9 @@ func TestUWPCCreation(t *testing.T) {
 					if created.Name != expectedName {
 						t.Errorf("%s: Expected created vPC %s, got %s", k, expectedName, created.Name)
 					}
+					if tc.ExpectedCreatedValue != 0 && created.Value != tc.ExpectedCreatedValue {
+						t.Errorf("%s: Expected created vPC value %d, got %d", k, tc.ExpectedCreatedValue, created.Value)
+					}
 					matched = true
 					break
 				}
@@ -265,6 +297,7 @@ func TestUWPCDeletion(t *testing.T) {
 		ExpectedDeletedObject  []string
 		ExpectedError          string
 		ExpectedNoOperation    bool
+		MaxTenantPriority      int32
 	}{
 		"pPC not found, vPC exists": {
 			ExistingObjectInTenant: []runtime.Object{
@@ -279,11 +312,31 @@ func TestUWPCDeletion(t *testing.T) {
 				"pc",
 			},
 		},
+		"pPC exists above max tenant priority, vPC exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makePriorityClass("pc", "12345", func(class *v1.PriorityClass) {
+					class.Value = 20000
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makePriorityClass("pc", "123456", func(class *v1.PriorityClass) {
+					class.Value = 20000
+				}),
+			},
+			EnqueuedKey: defaultClusterKey + "/pc",
+			ExpectedDeletedObject: []string{
+				"pc",
+			},
+			MaxTenantPriority: 10000,
+		},
 	}
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			actions, reconcileErr, err := util.RunUpwardSync(NewPriorityClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, nil)
+			setMaxTenantPriority := func(s manager.ResourceSyncer) {
+				s.(*controller).Config.MaxTenantPriority = tc.MaxTenantPriority
+			}
+			actions, reconcileErr, err := util.RunUpwardSync(NewPriorityClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, setMaxTenantPriority)
 			if err != nil {
 				t.Errorf("%s: error running upward sync: %v", k, err)
 				return
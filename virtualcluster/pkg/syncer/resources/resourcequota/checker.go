@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+
+	if !cache.WaitForCacheSync(stopCh, c.quotaSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting ResourceQuota checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo checks to see if resourcequotas in super master informer cache and tenant master
+// keep consistency.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "resourcequota")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	atomic.StoreUint64(&c.numMissMatchedResourceQuotas, 0)
+
+	pResourceQuotas, err := c.quotaLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing resourcequotas from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+	pSet := differ.NewDiffSet()
+	for _, pQuota := range pResourceQuotas {
+		pSet.Insert(differ.ClusterObject{Object: pQuota, Key: differ.DefaultClusterObjectKey(pQuota, "")})
+	}
+
+	knownClusterSet := sets.NewString(clusterNames...)
+	vSet := differ.NewDiffSet()
+	for _, cluster := range clusterNames {
+		quotaList := &v1.ResourceQuotaList{}
+		if err := c.MultiClusterController.List(cluster, quotaList); err != nil {
+			klog.Errorf("error listing resourcequotas from cluster %s informer cache: %v", cluster, err)
+			knownClusterSet.Delete(cluster)
+			continue
+		}
+
+		for i := range quotaList.Items {
+			vSet.Insert(differ.ClusterObject{
+				Object:       &quotaList.Items[i],
+				OwnerCluster: cluster,
+				Key:          differ.DefaultClusterObjectKey(&quotaList.Items[i], cluster),
+			})
+		}
+	}
+
+	quotaDiffer := differ.HandlerFuncs{}
+	quotaDiffer.AddFunc = func(vObj differ.ClusterObject) {
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
+		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+			klog.Errorf("error requeue vQuota %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantResourceQuotas").Inc()
+		}
+	}
+	quotaDiffer.UpdateFunc = func(vObj, pObj differ.ClusterObject) {
+		vQuota := vObj.Object.(*v1.ResourceQuota)
+		pQuota := pObj.Object.(*v1.ResourceQuota)
+
+		if pQuota.Annotations[constants.LabelUID] != string(vQuota.UID) {
+			klog.Errorf("Found pQuota %s delegated UID is different from tenant object.", pObj.Key)
+			quotaDiffer.OnDelete(pObj)
+			return
+		}
+		vc, err := util.GetVirtualClusterObject(c.MultiClusterController, vObj.GetOwnerCluster())
+		if err != nil {
+			klog.Errorf("fail to get cluster spec : %s", vObj.GetOwnerCluster())
+			return
+		}
+		updated := conversion.Equality(c.Config, vc).CheckResourceQuotaEquality(pQuota, vQuota)
+		if updated != nil {
+			atomic.AddUint64(&c.numMissMatchedResourceQuotas, 1)
+			klog.Warningf("ResourceQuota %s diff in super&tenant master", pObj.Key)
+			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+				klog.Errorf("error requeue vQuota %v/%v in cluster %s: %v", vObj.GetNamespace(), vObj.GetName(), vObj.GetOwnerCluster(), err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantResourceQuotas").Inc()
+			}
+		}
+		if !equality.Semantic.DeepEqual(vQuota.Status.Used, pQuota.Status.Used) {
+			klog.Warningf("used of ResourceQuota %s diff in super&tenant master", pObj.Key)
+			c.UpwardController.AddToQueue(pQuota.Namespace + "/" + pQuota.Name)
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterResourceQuotas").Inc()
+		}
+	}
+	quotaDiffer.DeleteFunc = func(pObj differ.ClusterObject) {
+		deleteOptions := &metav1.DeleteOptions{}
+		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pObj.GetUID()))
+		if err := c.quotaClient.ResourceQuotas(pObj.GetNamespace()).Delete(ctx, pObj.GetName(), *deleteOptions); err != nil {
+			klog.Errorf("error deleting pQuota %s in super master: %v", pObj.Key, err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperMasterResourceQuotas").Inc()
+		}
+	}
+
+	vSet.Difference(pSet, differ.FilteringHandler{
+		Handler:    quotaDiffer,
+		FilterFunc: differ.DefaultDifferFilter(knownClusterSet),
+	})
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedResourceQuotas").Set(float64(atomic.LoadUint64(&c.numMissMatchedResourceQuotas)))
+}
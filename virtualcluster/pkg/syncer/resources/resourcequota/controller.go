@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "resourcequota",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewResourceQuotaController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+		Disable: true,
+	})
+}
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
+	// super master resourcequota client
+	quotaClient v1core.ResourceQuotasGetter
+	// super master resourcequota informer lister/synced function
+	quotaLister listersv1.ResourceQuotaLister
+	quotaSynced cache.InformerSynced
+	// numMissMatchedResourceQuotas is the number of mismatched resourcequotas found in the last patrol.
+	numMissMatchedResourceQuotas uint64
+}
+
+func NewResourceQuotaController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		quotaClient: client.CoreV1(),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.ResourceQuota{}, &v1.ResourceQuotaList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.quotaLister = informer.Core().V1().ResourceQuotas().Lister()
+	if options.IsFake {
+		c.quotaSynced = func() bool { return true }
+	} else {
+		c.quotaSynced = informer.Core().V1().ResourceQuotas().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&v1.ResourceQuota{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.superNamespaces = pa.NewSuperNamespaceGuard("resourcequota", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.ResourceQuota{}, c, pa.WithResourcePeriod(config, "resourcequota"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	informer.Core().V1().ResourceQuotas().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.ResourceQuota:
+					return true
+				case cache.DeletedFinalStateUnknown:
+					if _, ok := t.Obj.(*v1.ResourceQuota); ok {
+						return true
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.ResourceQuota", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super master resourcequota controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueResourceQuota,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newQuota := newObj.(*v1.ResourceQuota)
+					oldQuota := oldObj.(*v1.ResourceQuota)
+					if newQuota.ResourceVersion != oldQuota.ResourceVersion {
+						c.enqueueResourceQuota(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueResourceQuota,
+			},
+		})
+	return c, nil
+}
+
+func (c *controller) enqueueResourceQuota(obj interface{}) {
+	quota, ok := obj.(*v1.ResourceQuota)
+	if !ok {
+		return
+	}
+
+	clusterName, _ := conversion.GetVirtualOwner(quota)
+	if clusterName == "" {
+		return
+	}
+
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+	c.UpwardController.AddToQueue(key)
+}
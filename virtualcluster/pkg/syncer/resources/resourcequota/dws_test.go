@@ -0,0 +1,407 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func tenantResourceQuota(name, namespace, uid string) *v1.ResourceQuota {
+	return &v1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ResourceQuota",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+	}
+}
+
+func superResourceQuota(name, namespace, uid, clusterKey string) *v1.ResourceQuota {
+	return &v1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ResourceQuota",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				constants.LabelUID:       uid,
+				constants.LabelCluster:   clusterKey,
+				constants.LabelNamespace: "default",
+			},
+		},
+	}
+}
+
+func applyHard(quota *v1.ResourceQuota, cpu, pods string) *v1.ResourceQuota {
+	quota.Spec.Hard = v1.ResourceList{
+		v1.ResourceCPU:  resource.MustParse(cpu),
+		v1.ResourcePods: resource.MustParse(pods),
+	}
+	return quota
+}
+
+func applyUsed(quota *v1.ResourceQuota, cpu, pods string) *v1.ResourceQuota {
+	quota.Status.Used = v1.ResourceList{
+		v1.ResourceCPU:  resource.MustParse(cpu),
+		v1.ResourcePods: resource.MustParse(pods),
+	}
+	return quota
+}
+
+func applyPriorityClassScope(quota *v1.ResourceQuota, priorityClasses ...string) *v1.ResourceQuota {
+	quota.Spec.ScopeSelector = &v1.ScopeSelector{
+		MatchExpressions: []v1.ScopedResourceSelectorRequirement{
+			{
+				ScopeName: v1.ResourceQuotaScopePriorityClass,
+				Operator:  v1.ScopeSelectorOpIn,
+				Values:    priorityClasses,
+			},
+		},
+	}
+	return quota
+}
+
+func TestDWResourceQuotaCreation(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedCreatedPObject []string
+		ExpectedCreatedSpec    *v1.ResourceQuotaSpec
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"new quota": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantResourceQuota("rq-1", "default", "12345"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/rq-1"},
+		},
+		"new quota with priorityclass scope": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				applyPriorityClassScope(tenantResourceQuota("rq-1", "default", "12345"), "high"),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/rq-1"},
+			ExpectedCreatedSpec:    &applyPriorityClassScope(tenantResourceQuota("rq-1", "default", "12345"), "high").Spec,
+		},
+		"new quota but already exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superResourceQuota("rq-2", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantResourceQuota("rq-2", "default", "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"new quota but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superResourceQuota("rq-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantResourceQuota("rq-3", "default", "12345"),
+			},
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewResourceQuotaController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedCreatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to create quota %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedCreatedPObject {
+				action := actions[i]
+				if !action.Matches("create", "resourcequotas") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				created := action.(core.CreateAction).GetObject().(*v1.ResourceQuota)
+				fullName := created.Namespace + "/" + created.Name
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
+				}
+				if tc.ExpectedCreatedSpec != nil && !equality.Semantic.DeepEqual(*tc.ExpectedCreatedSpec, created.Spec) {
+					t.Errorf("%s: Expected created spec %v, got %v", k, *tc.ExpectedCreatedSpec, created.Spec)
+				}
+			}
+		})
+	}
+}
+
+func TestDWResourceQuotaDeletion(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueueObject          *v1.ResourceQuota
+		ExpectedDeletedPObject []string
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"delete quota": {
+			ExistingObjectInSuper: []runtime.Object{
+				superResourceQuota("rq-1", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			EnqueueObject:          tenantResourceQuota("rq-1", "default", "12345"),
+			ExpectedDeletedPObject: []string{superDefaultNSName + "/rq-1"},
+		},
+		"delete quota but already gone": {
+			ExistingObjectInSuper: []runtime.Object{},
+			EnqueueObject:         tenantResourceQuota("rq-2", "default", "12345"),
+			ExpectedNoOperation:   true,
+		},
+		"delete quota but existing different uid one": {
+			ExistingObjectInSuper: []runtime.Object{
+				superResourceQuota("rq-3", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			EnqueueObject: tenantResourceQuota("rq-3", "default", "12345"),
+			ExpectedError: "delegated UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewResourceQuotaController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedDeletedPObject) != len(actions) {
+				t.Errorf("%s: Expected to delete quota %#v. Actual actions were: %#v", k, tc.ExpectedDeletedPObject, actions)
+				return
+			}
+			for i, expectedName := range tc.ExpectedDeletedPObject {
+				action := actions[i]
+				if !action.Matches("delete", "resourcequotas") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				fullName := action.(core.DeleteAction).GetNamespace() + "/" + action.(core.DeleteAction).GetName()
+				if fullName != expectedName {
+					t.Errorf("%s: Expected %s to be deleted, got %s", k, expectedName, fullName)
+				}
+			}
+		})
+	}
+}
+
+func TestDWResourceQuotaUpdate(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedUpdatedPObject []runtime.Object
+		ExpectedNoOperation    bool
+		ExpectedError          string
+	}{
+		"no diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyHard(superResourceQuota("rq-1", superDefaultNSName, "12345", defaultClusterKey), "1", "10"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyHard(tenantResourceQuota("rq-1", "default", "12345"), "1", "10"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"diff in hard": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyHard(superResourceQuota("rq-2", superDefaultNSName, "12345", defaultClusterKey), "1", "10"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyHard(tenantResourceQuota("rq-2", "default", "12345"), "2", "20"),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyHard(superResourceQuota("rq-2", superDefaultNSName, "12345", defaultClusterKey), "2", "20"),
+			},
+		},
+		"diff in scope selector": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyPriorityClassScope(superResourceQuota("rq-3", superDefaultNSName, "12345", defaultClusterKey), "low"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyPriorityClassScope(tenantResourceQuota("rq-3", "default", "12345"), "high"),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyPriorityClassScope(superResourceQuota("rq-3", superDefaultNSName, "12345", defaultClusterKey), "high"),
+			},
+		},
+		"diff exists but uid is wrong": {
+			ExistingObjectInSuper: []runtime.Object{
+				superResourceQuota("rq-4", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyHard(tenantResourceQuota("rq-4", "default", "123456"), "2", "20"),
+			},
+			ExpectedError:       "delegated UID is different",
+			ExpectedNoOperation: true,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewResourceQuotaController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			if len(tc.ExpectedUpdatedPObject) != len(actions) {
+				t.Errorf("%s: Expected to update quota %#v. Actual actions were: %#v", k, tc.ExpectedUpdatedPObject, actions)
+				return
+			}
+			for i, obj := range tc.ExpectedUpdatedPObject {
+				action := actions[i]
+				if !action.Matches("update", "resourcequotas") {
+					t.Errorf("%s: Unexpected action %s", k, action)
+				}
+				actionObj := action.(core.UpdateAction).GetObject()
+				if !equality.Semantic.DeepEqual(obj, actionObj) {
+					t.Errorf("%s: Expected updated quota is %v, got %v", k, obj, actionObj)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"fmt"
+
+	pkgerr "github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.quotaSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+// BackPopulate reflects the usage observed by super master quota controller to the tenant ResourceQuota.
+func (c *controller) BackPopulate(key string) error {
+	pNamespace, pName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key %v: %v", key, err))
+		return nil
+	}
+
+	pQuota, err := c.quotaLister.ResourceQuotas(pNamespace).Get(pName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	clusterName, vNamespace := conversion.GetVirtualOwner(pQuota)
+	if clusterName == "" || vNamespace == "" {
+		klog.Infof("drop resourcequota %s/%s which is not belongs to any tenant", pNamespace, pName)
+		return nil
+	}
+
+	vQuota := &v1.ResourceQuota{}
+	if err := c.MultiClusterController.Get(clusterName, vNamespace, pName, vQuota); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return pkgerr.Wrapf(err, "could not find pQuota %s/%s's vQuota in controller cache", vNamespace, pName)
+	}
+	if pQuota.Annotations[constants.LabelUID] != string(vQuota.UID) {
+		return fmt.Errorf("BackPopulated pQuota %s/%s delegated UID is different from updated object.", pQuota.Namespace, pQuota.Name)
+	}
+
+	if equality.Semantic.DeepEqual(vQuota.Status.Used, pQuota.Status.Used) {
+		return nil
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed to create client from cluster %s config", clusterName)
+	}
+
+	newQuota := vQuota.DeepCopy()
+	newQuota.Status.Used = pQuota.Status.Used
+	if _, err = tenantClient.CoreV1().ResourceQuotas(vQuota.Namespace).UpdateStatus(context.TODO(), newQuota, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to back populate resourcequota %s/%s status update for cluster %s: %v", vQuota.Namespace, vQuota.Name, clusterName, err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.runtimeClassSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting RuntimeClass checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if RuntimeClass keeps consistency between super master and tenant masters.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "runtimeclass")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	wg := sync.WaitGroup{}
+	atomic.StoreUint64(&c.numMissMatchedRuntimeClasses, 0)
+
+	// sem bounds the number of tenant clusters being checked at the same time.
+	sem := make(chan struct{}, c.patrolConcurrency)
+	for _, clusterName := range clusterNames {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(clusterName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.checkRuntimeClassOfTenantCluster(ctx, clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		klog.Infof("runtimeclass patrol is cancelled: %v", ctx.Err())
+		return
+	}
+
+	pRuntimeClassList, err := c.runtimeClassLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("error listing runtimeclass from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+
+	for _, pRuntimeClass := range pRuntimeClassList {
+		if !c.publicRuntimeClass(pRuntimeClass) {
+			continue
+		}
+		for _, clusterName := range clusterNames {
+			if err := c.MultiClusterController.Get(clusterName, "", pRuntimeClass.Name, &v1.RuntimeClass{}); err != nil {
+				if errors.IsNotFound(err) {
+					if c.patrollerDryRun {
+						klog.Infof("[dry-run] would requeue runtimeclass %s for cluster %s", pRuntimeClass.Name, clusterName)
+						metrics.CheckerDryRunStats.WithLabelValues("RequeuedSuperMasterRuntimeClasses").Inc()
+						continue
+					}
+					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterRuntimeClasses").Inc()
+					c.UpwardController.AddToQueue(clusterName + "/" + pRuntimeClass.Name)
+					continue
+				}
+				klog.Errorf("fail to get runtimeclass from cluster %s: %v", clusterName, err)
+			}
+		}
+	}
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedRuntimeClasses").Set(float64(atomic.LoadUint64(&c.numMissMatchedRuntimeClasses)))
+}
+
+func (c *controller) checkRuntimeClassOfTenantCluster(ctx context.Context, clusterName string) {
+	defer metrics.RecordCheckerClusterScanDuration("RuntimeClass", clusterName, time.Now())
+	runtimeClassList := &v1.RuntimeClassList{}
+	if err := c.MultiClusterController.List(clusterName, runtimeClassList); err != nil {
+		klog.Errorf("error listing runtimeclass from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check runtimeclass consistency in cluster %s", clusterName)
+
+	for i, vRuntimeClass := range runtimeClassList.Items {
+		if ctx.Err() != nil {
+			klog.V(4).Infof("stop checking runtimeclass in cluster %s: %v", clusterName, ctx.Err())
+			return
+		}
+		pRuntimeClass, err := c.runtimeClassLister.Get(vRuntimeClass.Name)
+		// runtimeclass which is no longer public or allowed is treated as orphan.
+		if errors.IsNotFound(err) || (err == nil && !c.publicRuntimeClass(pRuntimeClass)) {
+			// runtimeclass created by tenant is left alone.
+			if !conversion.IsSyncerManaged(&runtimeClassList.Items[i]) {
+				klog.V(4).Infof("orphan runtimeclass %s in cluster %s is not managed by syncer, skip it", vRuntimeClass.Name, clusterName)
+				continue
+			}
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would delete orphan runtimeclass %s in cluster %s", vRuntimeClass.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("DeletedOrphanTenantRuntimeClasses").Inc()
+				continue
+			}
+			// super master is the source of the truth for runtimeclass object, delete tenant master obj
+			deleteCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+			err = c.deleteRuntimeClass(deleteCtx, clusterName, &runtimeClassList.Items[i])
+			cancel()
+			if err != nil {
+				klog.Errorf("error deleting runtimeclass %v in cluster %s: %v", vRuntimeClass.Name, clusterName, err)
+			} else {
+				metrics.RecordCheckerRemedy("DeletedOrphanTenantRuntimeClasses", clusterName)
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pRuntimeClass %s from super master cache: %v", vRuntimeClass.Name, err)
+			continue
+		}
+
+		updatedRuntimeClass := conversion.ConfigEquality(c.Config).CheckRuntimeClassEquality(pRuntimeClass, &runtimeClassList.Items[i])
+		if updatedRuntimeClass != nil {
+			atomic.AddUint64(&c.numMissMatchedRuntimeClasses, 1)
+			klog.Warningf("spec of runtimeclass %v diff in super&tenant master", vRuntimeClass.Name)
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would requeue runtimeclass %s for cluster %s", pRuntimeClass.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("RequeuedDiffRuntimeClasses").Inc()
+				continue
+			}
+			metrics.CheckerRemedyStats.WithLabelValues("RequeuedDiffRuntimeClasses").Inc()
+			c.UpwardController.AddToQueue(clusterName + "/" + pRuntimeClass.Name)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func TestRuntimeClassPatrol(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	dryRun := func(r manager.ResourceSyncer) {
+		r.(*controller).patrollerDryRun = true
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedDeletedVObject []string
+		ExpectedCreatedVObject []string
+		ExpectedUpdatedVObject []string
+		ExpectedNoOperation    bool
+		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
+		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
+		StateModifyFunc        func(manager.ResourceSyncer)
+	}{
+		"pRuntimeClass not public": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pRuntimeClass not allowed": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     allowList("kata*"),
+		},
+		"pRuntimeClass exists, vRuntimeClass does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			WaitUWS: true,
+			ExpectedCreatedVObject: []string{
+				"gvisor",
+			},
+		},
+		"pRuntimeClass not found, vRuntimeClass exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", managed),
+			},
+			ExpectedDeletedVObject: []string{
+				"gvisor",
+			},
+		},
+		"pRuntimeClass not public, vRuntimeClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", managed),
+			},
+			ExpectedDeletedVObject: []string{
+				"gvisor",
+			},
+		},
+		"pRuntimeClass not allowed, vRuntimeClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", public, managed),
+			},
+			ExpectedDeletedVObject: []string{
+				"gvisor",
+			},
+			StateModifyFunc: allowList("kata*"),
+		},
+		"pRuntimeClass not found, vRuntimeClass created by tenant": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345"),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pRuntimeClass exists, vRuntimeClass exists with different overhead": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public, func(class *v1.RuntimeClass) {
+					class.Overhead = &v1.Overhead{PodFixed: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")}}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", public, managed),
+			},
+			ExpectedUpdatedVObject: []string{
+				"gvisor",
+			},
+			WaitUWS: true,
+		},
+		"dry run, pRuntimeClass exists, vRuntimeClass does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
+		"dry run, pRuntimeClass not found, vRuntimeClass exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", managed),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenantActions, superActions, err := util.RunPatrol(NewRuntimeClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, tc.StateModifyFunc)
+			if err != nil {
+				t.Errorf("%s: error running patrol: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(superActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v in super cluster", k, superActions)
+					return
+				}
+				if len(tenantActions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v tenant cluster", k, tenantActions)
+					return
+				}
+				return
+			}
+
+			for _, expectedName := range tc.ExpectedDeletedVObject {
+				matched := false
+				for _, action := range tenantActions {
+					if !action.Matches("delete", "runtimeclasses") {
+						continue
+					}
+					fullName := action.(core.DeleteAction).GetName()
+					if fullName != expectedName {
+						t.Errorf("%s: Expect to delete vRuntimeClass %s, got %s", k, expectedName, fullName)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect to delete vRuntimeClass %s, but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedCreatedVObject {
+				matched := false
+				for _, action := range tenantActions {
+					if !action.Matches("create", "runtimeclasses") {
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.RuntimeClass)
+					if created.Name != expectedName {
+						t.Errorf("%s: Expect to create vRuntimeClass %s, got %s", k, expectedName, created.Name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect to create vRuntimeClass %s, but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedUpdatedVObject {
+				matched := false
+				for _, action := range tenantActions {
+					if !action.Matches("update", "runtimeclasses") {
+						continue
+					}
+					updated := action.(core.UpdateAction).GetObject().(*v1.RuntimeClass)
+					if updated.Name != expectedName {
+						t.Errorf("%s: Expect to update vRuntimeClass %s, got %s", k, expectedName, updated.Name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect to update vRuntimeClass %s, but not found", k, expectedName)
+				}
+			}
+		})
+	}
+}
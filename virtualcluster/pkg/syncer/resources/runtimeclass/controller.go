@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	v1 "k8s.io/api/node/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/node/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "runtimeclass",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewRuntimeClassController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptionsFromConfig(ctx.Config.(*config.SyncerConfiguration)))
+		},
+		Disable: true,
+	})
+}
+
+// controller populates the public super master runtimeclasses to tenant masters, so that the tenant pods
+// referring to a runtimeclass, e.g., gvisor or kata, pass the validation of the tenant master. The pods are
+// run by the super master runtimeclass of the same name.
+type controller struct {
+	manager.BaseResourceSyncer
+	// super master runtimeclasses lister/synced functions
+	runtimeClassLister listersv1.RuntimeClassLister
+	runtimeClassSynced cache.InformerSynced
+	// patrollerDryRun indicates that the patroller only logs the remediation it would take.
+	patrollerDryRun bool
+	// patrolConcurrency is the max number of tenant clusters checked in parallel.
+	patrolConcurrency int
+	// patrolOpTimeout is the timeout of each tenant operation issued by the patroller.
+	patrolOpTimeout time.Duration
+	// numMissMatchedRuntimeClasses is the number of mismatched runtimeclasses found in the last patrol.
+	numMissMatchedRuntimeClasses uint64
+}
+
+func NewRuntimeClassController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		patrollerDryRun:   options.PatrollerDryRun,
+		patrolConcurrency: constants.DefaultPatrolConcurrency,
+		patrolOpTimeout:   constants.DefaultPatrolOpTimeout,
+	}
+	if options.PatrolConcurrency > 0 {
+		c.patrolConcurrency = options.PatrolConcurrency
+	}
+	if options.PatrolOpTimeout > 0 {
+		c.patrolOpTimeout = options.PatrolOpTimeout
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.RuntimeClass{}, &v1.RuntimeClassList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.runtimeClassLister = informer.Node().V1().RuntimeClasses().Lister()
+	if options.IsFake {
+		c.runtimeClassSynced = func() bool { return true }
+	} else {
+		c.runtimeClassSynced = informer.Node().V1().RuntimeClasses().Informer().HasSynced
+	}
+
+	c.UpwardController, err = uw.NewUWController(&v1.RuntimeClass{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&v1.RuntimeClass{}, c, pa.WithResourcePeriod(config, "runtimeclass"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	// the runtimeclasses which are no longer public or allowed are enqueued as well, so that their tenant
+	// copies are removed.
+	informer.Node().V1().RuntimeClasses().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.RuntimeClass:
+					return t.Labels[constants.PublicObjectKey] == "true"
+				case cache.DeletedFinalStateUnknown:
+					if e, ok := t.Obj.(*v1.RuntimeClass); ok {
+						return e.Labels[constants.PublicObjectKey] == "true"
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.RuntimeClass", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super master runtimeclass controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueRuntimeClass,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newRuntimeClass := newObj.(*v1.RuntimeClass)
+					oldRuntimeClass := oldObj.(*v1.RuntimeClass)
+					if newRuntimeClass.ResourceVersion != oldRuntimeClass.ResourceVersion {
+						c.enqueueRuntimeClass(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueRuntimeClass,
+			},
+		})
+	return c, nil
+}
+
+func (c *controller) publicRuntimeClass(e *v1.RuntimeClass) bool {
+	return publicRuntimeClass(c.Config, e)
+}
+
+// publicRuntimeClass returns true if the super master runtimeclass is synced to tenant masters.
+func publicRuntimeClass(config *config.SyncerConfiguration, e *v1.RuntimeClass) bool {
+	// We only backpopulate specific runtimeclass to tenant masters
+	if e.Labels[constants.PublicObjectKey] != "true" {
+		return false
+	}
+	return runtimeClassNameAllowed(config, e.Name)
+}
+
+// runtimeClassNameAllowed checks the runtimeclass name against the allow list of the config.
+func runtimeClassNameAllowed(config *config.SyncerConfiguration, name string) bool {
+	if config == nil || len(config.SyncRuntimeClassAllowList) == 0 {
+		return true
+	}
+	for _, pattern := range config.SyncRuntimeClassAllowList {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicNameTaken returns true if the runtimeclass of the name is synced from super master to tenant masters.
+func (c *controller) PublicNameTaken(name string) bool {
+	pRuntimeClass, err := c.runtimeClassLister.Get(name)
+	if err != nil {
+		return false
+	}
+	return c.publicRuntimeClass(pRuntimeClass)
+}
+
+func (c *controller) enqueueRuntimeClass(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("No tenant masters, stop backpopulate runtimeclass %v", key)
+		return
+	}
+
+	for _, clusterName := range clusterNames {
+		c.UpwardController.AddToQueue(clusterName + "/" + key)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.runtimeClassSynced) {
+		return fmt.Errorf("failed to wait for caches to sync runtimeclass")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+func (c *controller) BackPopulate(key string) error {
+	// The key format is clustername/runtimeClassName.
+	clusterName, name, _ := cache.SplitMetaNamespaceKey(key)
+
+	op := reconciler.AddEvent
+	pRuntimeClass, err := c.runtimeClassLister.Get(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		op = reconciler.DeleteEvent
+	} else if !c.publicRuntimeClass(pRuntimeClass) {
+		// runtimeclass which is not public or not allowed should not exist in tenant masters.
+		op = reconciler.DeleteEvent
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+
+	vRuntimeClass := &v1.RuntimeClass{}
+	if err := c.MultiClusterController.Get(clusterName, "", name, vRuntimeClass); err != nil {
+		if errors.IsNotFound(err) {
+			if op == reconciler.AddEvent {
+				// Available in super, hence create a new in tenant master
+				vRuntimeClass := conversion.BuildVirtualRuntimeClass(clusterName, pRuntimeClass)
+				_, err := tenantClient.NodeV1().RuntimeClasses().Create(context.TODO(), vRuntimeClass, metav1.CreateOptions{})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return err
+	}
+
+	if op == reconciler.DeleteEvent {
+		if !conversion.IsSyncerManaged(vRuntimeClass) {
+			klog.Infof("runtimeclass %s in cluster %s is not managed by syncer, leave it alone", name, clusterName)
+			return nil
+		}
+		return c.deleteRuntimeClass(context.TODO(), clusterName, vRuntimeClass)
+	}
+
+	updatedRuntimeClass := conversion.ConfigEquality(c.Config).CheckRuntimeClassEquality(pRuntimeClass, vRuntimeClass)
+	if updatedRuntimeClass != nil && updatedRuntimeClass.Handler != vRuntimeClass.Handler {
+		// The runtimeclass handler is immutable, hence the tenant runtimeclass is deleted and created again.
+		if err := c.deleteRuntimeClass(context.TODO(), clusterName, vRuntimeClass); err != nil {
+			return err
+		}
+		_, err := tenantClient.NodeV1().RuntimeClasses().Create(context.TODO(), conversion.BuildVirtualRuntimeClass(clusterName, pRuntimeClass), metav1.CreateOptions{})
+		return err
+	}
+	// runtimeclasses created by tenants with the same name are taken over.
+	if !conversion.IsSyncerManaged(vRuntimeClass) {
+		if updatedRuntimeClass == nil {
+			updatedRuntimeClass = vRuntimeClass.DeepCopy()
+		}
+		conversion.SetSyncerManaged(updatedRuntimeClass)
+	}
+	if updatedRuntimeClass != nil {
+		_, err := tenantClient.NodeV1().RuntimeClasses().Update(context.TODO(), updatedRuntimeClass, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRuntimeClass deletes the tenant runtimeclass if it has not been recreated since it was observed.
+func (c *controller) deleteRuntimeClass(ctx context.Context, clusterName string, vRuntimeClass *v1.RuntimeClass) error {
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+		Preconditions:     metav1.NewUIDPreconditions(string(vRuntimeClass.UID)),
+	}
+	if err := tenantClient.NodeV1().RuntimeClasses().Delete(ctx, vRuntimeClass.Name, *opts); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
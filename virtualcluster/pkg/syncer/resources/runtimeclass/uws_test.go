@@ -0,0 +1,312 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func makeRuntimeClass(name, uid string, mFuncs ...func(*v1.RuntimeClass)) *v1.RuntimeClass {
+	class := &v1.RuntimeClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "RuntimeClass",
+			APIVersion: "node.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(uid),
+		},
+		Handler: "runsc",
+	}
+
+	for _, f := range mFuncs {
+		f(class)
+	}
+	return class
+}
+
+func public(class *v1.RuntimeClass) {
+	if class.Labels == nil {
+		class.Labels = map[string]string{}
+	}
+	class.Labels[constants.PublicObjectKey] = "true"
+}
+
+func managed(class *v1.RuntimeClass) {
+	conversion.SetSyncerManaged(class)
+}
+
+func allowList(patterns ...string) func(manager.ResourceSyncer) {
+	return func(r manager.ResourceSyncer) {
+		r.(*controller).Config.SyncRuntimeClassAllowList = patterns
+	}
+}
+
+func TestUWRuntimeClass(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueuedKey            string
+		StateModifyFunc        func(manager.ResourceSyncer)
+		ExpectedCreatedObject  []string
+		ExpectedUpdatedObject  []string
+		ExpectedDeletedObject  []string
+		ExpectedError          string
+		ExpectedNoOperation    bool
+	}{
+		"pRuntimeClass exists but vRuntimeClass not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			ExpectedCreatedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass not public, vRuntimeClass not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/gvisor",
+			ExpectedNoOperation: true,
+		},
+		"pRuntimeClass allowed, vRuntimeClass not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			StateModifyFunc:       allowList("kata", "gvisor*"),
+			ExpectedCreatedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass not allowed, vRuntimeClass not found": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			EnqueuedKey:         defaultClusterKey + "/gvisor",
+			StateModifyFunc:     allowList("kata*"),
+			ExpectedNoOperation: true,
+		},
+		"pRuntimeClass not allowed, vRuntimeClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", public, managed),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			StateModifyFunc:       allowList("kata*"),
+			ExpectedDeletedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass exists, vRuntimeClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", public, managed),
+			},
+			EnqueuedKey:         defaultClusterKey + "/gvisor",
+			ExpectedNoOperation: true,
+		},
+		"pRuntimeClass exists, vRuntimeClass exists with different overhead": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public, func(class *v1.RuntimeClass) {
+					class.Overhead = &v1.Overhead{PodFixed: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")}}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", public, managed),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			ExpectedUpdatedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass exists, vRuntimeClass exists with different scheduling": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public, func(class *v1.RuntimeClass) {
+					class.Scheduling = &v1.Scheduling{NodeSelector: map[string]string{"sandbox": "gvisor"}}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", public, managed),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			ExpectedUpdatedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass exists, vRuntimeClass exists with different handler": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", public, managed, func(class *v1.RuntimeClass) {
+					class.Handler = "kata"
+				}),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			ExpectedDeletedObject: []string{"gvisor"},
+			ExpectedCreatedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass exists, vRuntimeClass not managed by syncer": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", public),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			ExpectedUpdatedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass not found, vRuntimeClass exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", managed),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			ExpectedDeletedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass not public, vRuntimeClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeRuntimeClass("gvisor", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456", managed),
+			},
+			EnqueuedKey:           defaultClusterKey + "/gvisor",
+			ExpectedDeletedObject: []string{"gvisor"},
+		},
+		"pRuntimeClass not found, vRuntimeClass created by tenant": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeRuntimeClass("gvisor", "123456"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/gvisor",
+			ExpectedNoOperation: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(NewRuntimeClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, tc.StateModifyFunc)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+					return
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else {
+				if tc.ExpectedError != "" {
+					t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedDeletedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("delete", "runtimeclasses") {
+						continue
+					}
+					name := action.(core.DeleteAction).GetName()
+					if name != expectedName {
+						t.Errorf("%s: Expected deleted vRuntimeClass %s, got %s", k, expectedName, name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect deleted vRuntimeClass %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedCreatedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("create", "runtimeclasses") {
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.RuntimeClass)
+					if created.Name != expectedName {
+						t.Errorf("%s: Expected created vRuntimeClass %s, got %s", k, expectedName, created.Name)
+					}
+					if !conversion.IsSyncerManaged(created) {
+						t.Errorf("%s: Expected created vRuntimeClass %s to be managed by syncer", k, created.Name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect created vRuntimeClass %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedUpdatedObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("update", "runtimeclasses") {
+						continue
+					}
+					updated := action.(core.UpdateAction).GetObject().(*v1.RuntimeClass)
+					if updated.Name != expectedName {
+						t.Errorf("%s: Expected updated vRuntimeClass %s, got %s", k, expectedName, updated.Name)
+					}
+					if !conversion.IsSyncerManaged(updated) {
+						t.Errorf("%s: Expected updated vRuntimeClass %s to be managed by syncer", k, updated.Name)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect updated vRuntimeClass %s but not found", k, expectedName)
+				}
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package secret
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -32,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
@@ -47,12 +49,14 @@ func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 }
 
 // PatrollerDo check if normal secrets and service account secrets keep consistency between super master and tenant masters.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "secret")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	var wg sync.WaitGroup
 	numMissMatchedOpaqueSecrets = 0
@@ -67,57 +71,59 @@ func (c *controller) PatrollerDo() {
 	}
 	wg.Wait()
 
-	secretList, err := c.secretLister.List(labels.Everything())
-	if err != nil {
-		klog.Errorf("error listing secret from super master informer cache: %v", err)
+	klog.V(4).Infof("check secrets consistency in super")
+	pa.ListInChunks(c.secretStore, c.patrolListChunkSize, func(objs []interface{}) bool {
+		for _, obj := range objs {
+			c.checkSuperMasterSecret(obj.(*v1.Secret))
+		}
+		return true
+	})
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedOpaqueSecrets").Set(float64(numMissMatchedOpaqueSecrets))
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedSASecrets").Set(float64(numMissMatchedSASecrets))
+}
+
+// checkSuperMasterSecret deletes the super master secret if its tenant secret is gone or replaced.
+func (c *controller) checkSuperMasterSecret(pSecret *v1.Secret) {
+	// service account token type secret are managed by super individually.
+	if pSecret.Type == v1.SecretTypeServiceAccountToken {
 		return
 	}
 
-	klog.V(4).Infof("check secrets consistency in super")
-	for _, pSecret := range secretList {
-		// service account token type secret are managed by super individually.
-		if pSecret.Type == v1.SecretTypeServiceAccountToken {
-			continue
-		}
+	clusterName, vNamespace := conversion.GetVirtualOwner(pSecret)
+	if len(clusterName) == 0 || len(vNamespace) == 0 {
+		return
+	}
 
-		clusterName, vNamespace := conversion.GetVirtualOwner(pSecret)
-		if len(clusterName) == 0 || len(vNamespace) == 0 {
-			continue
-		}
+	shouldDelete := false
 
-		shouldDelete := false
+	// virtual service account token type secret
+	vSecretName := pSecret.Name
+	if secretName := pSecret.GetAnnotations()[constants.LabelSecretName]; secretName != "" {
+		vSecretName = secretName
+	}
+	// check whether secret is exists in tenant.
+	vSecret := &v1.Secret{}
+	err := c.MultiClusterController.Get(clusterName, vNamespace, vSecretName, vSecret)
+	if errors.IsNotFound(err) {
+		shouldDelete = true
+	}
 
-		// virtual service account token type secret
-		vSecretName := pSecret.Name
-		if secretName := pSecret.GetAnnotations()[constants.LabelSecretName]; secretName != "" {
-			vSecretName = secretName
-		}
-		// check whether secret is exists in tenant.
-		vSecret := &v1.Secret{}
-		err := c.MultiClusterController.Get(clusterName, vNamespace, vSecretName, vSecret)
-		if errors.IsNotFound(err) {
+	if err == nil {
+		if pSecret.Annotations[constants.LabelUID] != string(vSecret.UID) {
 			shouldDelete = true
+			klog.Warningf("Found pSecret %s/%s delegated UID is different from tenant object.", pSecret.Namespace, pSecret.Name)
 		}
+	}
 
-		if err == nil {
-			if pSecret.Annotations[constants.LabelUID] != string(vSecret.UID) {
-				shouldDelete = true
-				klog.Warningf("Found pSecret %s/%s delegated UID is different from tenant object.", pSecret.Namespace, pSecret.Name)
-			}
-		}
-
-		if shouldDelete {
-			deleteOptions := metav1.NewPreconditionDeleteOptions(string(pSecret.UID))
-			if err := c.secretClient.Secrets(pSecret.Namespace).Delete(context.TODO(), pSecret.Name, *deleteOptions); err != nil {
-				klog.Errorf("error deleting pSecret %s/%s in super master: %v", pSecret.Namespace, pSecret.Name, err)
-			} else {
-				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperMasterSecrets").Inc()
-			}
+	if shouldDelete {
+		deleteOptions := metav1.NewPreconditionDeleteOptions(string(pSecret.UID))
+		if err := c.secretClient.Secrets(pSecret.Namespace).Delete(context.TODO(), pSecret.Name, *deleteOptions); err != nil {
+			klog.Errorf("error deleting pSecret %s/%s in super master: %v", pSecret.Namespace, pSecret.Name, err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperMasterSecrets").Inc()
 		}
 	}
-
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedOpaqueSecrets").Set(float64(numMissMatchedOpaqueSecrets))
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedSASecrets").Set(float64(numMissMatchedSASecrets))
 }
 
 func (c *controller) checkSecretOfTenantCluster(clusterName string) {
@@ -130,6 +136,10 @@ func (c *controller) checkSecretOfTenantCluster(clusterName string) {
 	klog.V(4).Infof("check secrets consistency in cluster %s", clusterName)
 
 	for i, vSecret := range secretList.Items {
+		// secrets of the types not synced are not expected in super master.
+		if !c.secretTypeSynced(vSecret.Type) {
+			continue
+		}
 		targetNamespace := conversion.ToSuperMasterNamespace(clusterName, vSecret.Namespace)
 
 		if vSecret.Type == v1.SecretTypeServiceAccountToken {
@@ -139,6 +149,9 @@ func (c *controller) checkSecretOfTenantCluster(clusterName string) {
 
 		pSecret, err := c.secretLister.Secrets(targetNamespace).Get(vSecret.Name)
 		if errors.IsNotFound(err) {
+			if !c.superNamespaces.Validate(clusterName, vSecret.Namespace) {
+				continue
+			}
 			if err := c.MultiClusterController.RequeueObject(clusterName, &secretList.Items[i]); err != nil {
 				klog.Errorf("error requeue vSecret %v/%v in cluster %s: %v", vSecret.Namespace, vSecret.Name, clusterName, err)
 			} else {
@@ -166,6 +179,12 @@ func (c *controller) checkSecretOfTenantCluster(clusterName string) {
 		if updatedSecret != nil {
 			atomic.AddUint64(&numMissMatchedOpaqueSecrets, 1)
 			klog.Warningf("spec of secret %v/%v diff in super&tenant master", vSecret.Namespace, vSecret.Name)
+			// never log the secret values, only the keys that differ.
+			if klog.V(2) {
+				if keys := conversion.SecretDataDiff(pSecret, &secretList.Items[i]); len(keys) > 0 {
+					klog.Infof("secret %v/%v in cluster %s differs in keys: %s", vSecret.Namespace, vSecret.Name, clusterName, strings.Join(keys, ", "))
+				}
+			}
 		}
 	}
 }
@@ -175,6 +194,9 @@ func (c *controller) checkServiceAccountTokenTypeSecretOfTenantCluster(clusterNa
 		constants.LabelSecretUID: string(vSecret.UID),
 	}))
 	if errors.IsNotFound(err) || len(secretList) == 0 {
+		if !c.superNamespaces.Validate(clusterName, vSecret.Namespace) {
+			return
+		}
 		if err := c.MultiClusterController.RequeueObject(clusterName, vSecret); err != nil {
 			klog.Errorf("error requeue service account type vSecret %v/%v in cluster %s: %v", vSecret.Namespace, vSecret.Name, clusterName, err)
 		} else {
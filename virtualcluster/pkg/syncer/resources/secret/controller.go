@@ -18,6 +18,7 @@ package secret
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -44,11 +45,18 @@ func init() {
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
 	// super master secret client
 	secretClient v1core.CoreV1Interface
-	// super master secret lister/synced function
+	// super master secret lister/store/synced function
 	secretLister listersv1.SecretLister
+	secretStore  cache.Store
 	secretSynced cache.InformerSynced
+	// patrolListChunkSize is the number of super master secrets the patroller fetches at a time.
+	patrolListChunkSize int
+	// syncSecretTypes are the types of the tenant secrets to sync, all types are synced if it is empty.
+	syncSecretTypes sets.String
 }
 
 func NewSecretController(config *config.SyncerConfiguration,
@@ -58,7 +66,9 @@ func NewSecretController(config *config.SyncerConfiguration,
 	vcInformer vcinformers.VirtualClusterInformer,
 	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
 	c := &controller{
-		secretClient: client.CoreV1(),
+		secretClient:        client.CoreV1(),
+		syncSecretTypes:     sets.NewString(config.SyncSecretTypes...),
+		patrolListChunkSize: options.PatrolListChunkSize,
 	}
 
 	var err error
@@ -68,16 +78,24 @@ func NewSecretController(config *config.SyncerConfiguration,
 	}
 
 	c.secretLister = informer.Core().V1().Secrets().Lister()
+	c.secretStore = informer.Core().V1().Secrets().Informer().GetStore()
 	if options.IsFake {
 		c.secretSynced = func() bool { return true }
 	} else {
 		c.secretSynced = informer.Core().V1().Secrets().Informer().HasSynced
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.Secret{}, c, pa.WithOptions(options.PatrolOptions))
+	c.superNamespaces = pa.NewSuperNamespaceGuard("secret", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.Secret{}, c, pa.WithResourcePeriod(config, "secret"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
 
 	return c, nil
 }
+
+// secretTypeSynced returns true if the tenant secrets of the type should be synced.
+func (c *controller) secretTypeSynced(secretType v1.SecretType) bool {
+	return c.syncSecretTypes.Len() == 0 || c.syncSecretTypes.Has(string(secretType))
+}
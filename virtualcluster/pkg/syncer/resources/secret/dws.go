@@ -48,7 +48,10 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	vSecret := &v1.Secret{}
 	err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vSecret)
 	if err == nil {
-
+		if !c.secretTypeSynced(vSecret.Type) {
+			klog.V(4).Infof("skip secret %s/%s of cluster %s, type %s is not synced", request.Namespace, request.Name, request.ClusterName, vSecret.Type)
+			return reconciler.Result{}, nil
+		}
 	} else if !errors.IsNotFound(err) {
 		return reconciler.Result{Requeue: true}, err
 	}
@@ -139,7 +142,7 @@ func (c *controller) reconcileServiceAccountSecretCreate(clusterName, targetName
 }
 
 func (c *controller) reconcileServiceAccountSecretUpdate(clusterName, targetNamespace string, pSecret, vSecret *v1.Secret) error {
-	updatedBinaryData, equal := conversion.Equality(c.Config, nil).CheckBinaryDataEquality(pSecret.Data, vSecret.Data)
+	updatedBinaryData, equal := conversion.ConfigEquality(c.Config).CheckBinaryDataEquality(pSecret.Data, vSecret.Data)
 	if equal {
 		return nil
 	}
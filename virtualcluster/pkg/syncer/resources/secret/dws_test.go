@@ -27,13 +27,19 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 )
 
 func superSecret(vcName, vcNamespace, name, namespace, uid, clusterKey string, secretType v1.SecretType) *v1.Secret {
@@ -257,6 +263,84 @@ func TestDWSecretCreation(t *testing.T) {
 	}
 }
 
+func TestDWSecretTypeFilter(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperMasterNamespace(defaultClusterKey, "default")
+
+	newController := func(config *config.SyncerConfiguration,
+		client clientset.Interface,
+		informer informers.SharedInformerFactory,
+		vcClient vcclient.Interface,
+		vcInformer vcinformers.VirtualClusterInformer,
+		options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+		config.SyncSecretTypes = []string{string(v1.SecretTypeOpaque)}
+		return NewSecretController(config, client, informer, vcClient, vcInformer, options)
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInTenant []runtime.Object
+		ExpectedCreated        bool
+	}{
+		"secret of synced type": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantSecret("normal-secret", "default", "12345", v1.SecretTypeOpaque),
+			},
+			ExpectedCreated: true,
+		},
+		"secret of type not synced": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantSecret("docker-secret", "default", "12345", v1.SecretTypeDockerConfigJson),
+			},
+		},
+		"service account secret not synced": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantSecret("sa-secret", "default", "12345", v1.SecretTypeServiceAccountToken),
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(newController, testTenant, nil, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], func(tenantClientset, superClientset *fake.Clientset) {
+				superClientset.PrependReactor("create", "secrets", generateNameReactor)
+			})
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("%s: expected no error, but got \"%v\"", k, reconcileErr)
+			}
+
+			if !tc.ExpectedCreated {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("create", "secrets") {
+				t.Errorf("%s: Expected to create secret in %s, got %v", k, superDefaultNSName, actions)
+				return
+			}
+			if ns := actions[0].(core.CreateAction).GetNamespace(); ns != superDefaultNSName {
+				t.Errorf("%s: Expected to create secret in %s, got %s", k, superDefaultNSName, ns)
+			}
+		})
+	}
+}
+
 func TestDWSecretDeletion(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
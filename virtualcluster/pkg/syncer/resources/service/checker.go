@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
@@ -50,12 +51,14 @@ func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
 
 // PatrollerDo check if services keep consistency between super
 // master and tenant masters.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
 		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "service")
+		pa.ReportFailure(ctx)
 		return
 	}
+	clusterNames = pa.ActiveClusters(clusterNames)
 
 	numSpecMissMatchedServices = 0
 	numStatusMissMatchedServices = 0
@@ -64,6 +67,7 @@ func (c *controller) PatrollerDo() {
 	pList, err := c.serviceLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("error listing service from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
 		return
 	}
 	pSet := differ.NewDiffSet()
@@ -92,6 +96,9 @@ func (c *controller) PatrollerDo() {
 
 	d := differ.HandlerFuncs{}
 	d.AddFunc = func(vObj differ.ClusterObject) {
+		if !c.superNamespaces.Validate(vObj.GetOwnerCluster(), vObj.GetNamespace()) {
+			return
+		}
 		if err := c.MultiClusterController.RequeueObject(vObj.GetOwnerCluster(), vObj.Object); err != nil {
 			klog.Errorf("error requeue vService %s in cluster %s: %v", vObj.Key, vObj.GetOwnerCluster(), err)
 		} else {
@@ -49,6 +49,8 @@ func init() {
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
 	// super master service client
 	serviceClient v1core.ServicesGetter
 	// super master informer/listers/synced functions
@@ -87,7 +89,9 @@ func NewServiceController(config *config.SyncerConfiguration,
 		return nil, err
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.Service{}, c, pa.WithOptions(options.PatrolOptions))
+	c.superNamespaces = pa.NewSuperNamespaceGuard("service", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.Service{}, c, pa.WithResourcePeriod(config, "service"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
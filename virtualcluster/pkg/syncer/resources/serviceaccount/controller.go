@@ -44,6 +44,8 @@ func init() {
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// superNamespaces validates the super master namespaces of the tenant objects requeued by the checker
+	superNamespaces *pa.SuperNamespaceGuard
 	// super master sa client
 	saClient v1core.CoreV1Interface
 	// super master sa lister/synced function
@@ -74,7 +76,9 @@ func NewServiceAccountController(config *config.SyncerConfiguration,
 		c.saSynced = informer.Core().V1().ServiceAccounts().Informer().HasSynced
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.ServiceAccount{}, c, pa.WithOptions(options.PatrolOptions))
+	c.superNamespaces = pa.NewSuperNamespaceGuard("serviceaccount", config, informer, c.MultiClusterController)
+
+	c.Patroller, err = pa.NewPatroller(&v1.ServiceAccount{}, c, pa.WithResourcePeriod(config, "serviceaccount"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
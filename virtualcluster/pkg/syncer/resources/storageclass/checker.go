@@ -19,116 +19,1252 @@ package storageclass
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	listersv1 "k8s.io/client-go/listers/storage/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	utilerrors "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
-var numMissMatchedStorageClasses uint64
-
 func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
-	if !cache.WaitForCacheSync(stopCh, c.storageclassSynced) {
+	if c.apiAbsent {
+		<-stopCh
+		return nil
+	}
+	if !cache.WaitForCacheSync(stopCh, c.superCachesSynced()...) {
 		return fmt.Errorf("failed to wait for caches to sync before starting Service checker")
 	}
+	go wait.Until(c.runOrphanCleanupWorker, time.Second, stopCh)
+	go func() {
+		<-stopCh
+		c.orphanCleanupQueue.ShutDown()
+	}()
 	c.Patroller.Start(stopCh)
 	return nil
 }
 
+// TriggerPatrolNow runs a patrol round immediately and returns the number of mismatched storageclasses.
+// The round is serialized with the periodic ones.
+func (c *controller) TriggerPatrolNow(ctx context.Context) (uint64, error) {
+	if c.apiAbsent {
+		return 0, fmt.Errorf("super master does not serve storageclasses, storageclass syncer is disabled")
+	}
+	if !c.storageclassSynced() {
+		return 0, fmt.Errorf("storageclass cache is not synced yet")
+	}
+	var mismatched uint64
+	c.Patroller.RunOnce(ctx, func() {
+		mismatched = atomic.LoadUint64(&c.numMissMatchedStorageClasses)
+	})
+	return mismatched, nil
+}
+
 // ParollerDo check if StorageClass keeps consistency between super master and tenant masters.
-func (c *controller) PatrollerDo() {
+func (c *controller) PatrollerDo(ctx context.Context) {
 	clusterNames := c.MultiClusterController.GetClusterNames()
 	if len(clusterNames) == 0 {
-		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "storageclass")
+		pa.Infof(pa.Fields{Resource: "storageclass"}, "super cluster has no tenant control planes, giving up periodic checker: %s", "storageclass")
+		pa.ReportFailure(ctx)
 		return
 	}
+	// the lag of the paused clusters keeps growing, they are only pruned once removed.
+	c.syncLag.Prune(clusterNames)
+	defer c.syncLag.Report()
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	c.pruneClusterOrphans(clusterNames)
+	c.pruneReconciled(clusterNames)
+	c.writeThrottle.Prune(clusterNames)
+	c.resetRequeued()
+	c.sweep.Start()
+
+	// clusters which are still bootstrapping are skipped, their caches cannot be trusted yet.
+	// So are the clusters which do not serve storage.k8s.io/v1 storageclasses.
+	var readyClusterNames []string
+	for _, clusterName := range clusterNames {
+		if !c.MultiClusterController.IsClusterReady(clusterName) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip"}, "cluster %s is not ready, skip checking storageclass", clusterName)
+			continue
+		}
+		if !c.storageClassServed(clusterName) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip"}, "cluster %s does not serve storage.k8s.io/v1 storageclass, skip checking storageclass", clusterName)
+			continue
+		}
+		readyClusterNames = append(readyClusterNames, clusterName)
+	}
+	metrics.CheckerSkippedClusters.WithLabelValues("StorageClass").Set(float64(len(clusterNames) - len(readyClusterNames)))
+	clusterNames = readyClusterNames
 
 	wg := sync.WaitGroup{}
-	numMissMatchedStorageClasses = 0
+	atomic.StoreUint64(&c.numMissMatchedStorageClasses, 0)
+	atomic.StoreUint64(&c.numUnmanagedStorageClasses, 0)
+	atomic.StoreUint64(&c.numStuckTerminatingStorageClasses, 0)
+	atomic.StoreUint64(&c.numCorruptStorageClasses, 0)
+	atomic.StoreUint64(&c.numProtectedStorageClasses, 0)
+	atomic.StoreUint64(&c.numSuperTopologyStorageClasses, 0)
+	atomic.StoreUint64(&c.numSyncedStorageClasses, 0)
+	atomic.StoreUint64(&c.numDivergedStorageClasses, 0)
+	atomic.StoreUint64(&c.numPublicStorageClasses, c.countPublicStorageClasses(c.storageclassStore))
+	for _, super := range c.extraSupers {
+		atomic.StoreUint64(&super.numPublic, c.countPublicStorageClasses(super.store))
+	}
+
+	// results records the number of mismatched storageclasses of each tenant cluster checked.
+	results := make(map[string]uint64)
+	var resultLock sync.Mutex
 
+	// sem bounds the number of tenant clusters being checked at the same time.
+	sem := make(chan struct{}, c.patrolConcurrency)
 	for _, clusterName := range clusterNames {
+		if ctx.Err() != nil {
+			break
+		}
 		wg.Add(1)
+		sem <- struct{}{}
 		go func(clusterName string) {
-			defer wg.Done()
-			c.checkStorageClassOfTenantCluster(clusterName)
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			defer c.recoverClusterPanic(ctx, clusterName)
+			if mismatched, ok := c.checkStorageClassOfTenantCluster(ctx, clusterName); ok {
+				resultLock.Lock()
+				results[clusterName] = mismatched
+				resultLock.Unlock()
+			}
 		}(clusterName)
 	}
 	wg.Wait()
 
-	pStorageClassList, err := c.storageclassLister.List(labels.Everything())
+	if ctx.Err() != nil {
+		pa.Infof(pa.Fields{Resource: "storageclass", Err: ctx.Err()}, "storageclass patrol is cancelled: %v", ctx.Err())
+		return
+	}
+
+	c.requeueMissingStorageClasses(clusterNames)
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedStorageClasses").Set(float64(atomic.LoadUint64(&c.numMissMatchedStorageClasses)))
+	metrics.CheckerUnmanagedTenantObjects.WithLabelValues("StorageClass").Set(float64(atomic.LoadUint64(&c.numUnmanagedStorageClasses)))
+	metrics.CheckerStuckTerminating.WithLabelValues("StorageClass").Set(float64(atomic.LoadUint64(&c.numStuckTerminatingStorageClasses)))
+	metrics.CheckerCorruptObjects.WithLabelValues("StorageClass").Set(float64(atomic.LoadUint64(&c.numCorruptStorageClasses)))
+	metrics.CheckerProtectedObjects.WithLabelValues("StorageClass").Set(float64(atomic.LoadUint64(&c.numProtectedStorageClasses)))
+	metrics.CheckerSuperTopology.WithLabelValues("StorageClass").Set(float64(atomic.LoadUint64(&c.numSuperTopologyStorageClasses)))
+	if !metrics.PerClusterLabels() {
+		metrics.SyncedObjectCount.WithLabelValues("StorageClass", "").Set(float64(atomic.LoadUint64(&c.numSyncedStorageClasses)))
+		metrics.DivergedObjectCount.WithLabelValues("StorageClass", "").Set(float64(atomic.LoadUint64(&c.numDivergedStorageClasses)))
+	}
+
+	c.updateSyncedConditions(results)
+	c.sweep.Finish(results)
+}
+
+// LastSweepSummary returns the remediations taken in the last completed patrol sweep aggregated across the tenant
+// clusters. It returns false if no sweep has completed yet.
+func (c *controller) LastSweepSummary() (pa.SweepSummary, bool) {
+	return c.sweep.Last()
+}
+
+// ClustersMissingObject returns the tenant clusters where the public object of the resource was found missing
+// in the last completed patrol sweep, e.g., the tenants which have not got a new storageclass yet. The clusters
+// unreachable during the sweep are not reported. It returns nil for the resources other than storageclass.
+func (c *controller) ClustersMissingObject(resource, name string) []string {
+	if resource != "storageclass" {
+		return nil
+	}
+	return c.sweep.ClustersMissing(name)
+}
+
+// countPublicStorageClasses returns the number of public storageclasses in the super master store, i.e., the ones
+// every tenant cluster mapped to the super master is expected to have.
+func (c *controller) countPublicStorageClasses(store cache.Store) uint64 {
+	var public uint64
+	pa.ListInChunks(store, c.patrolListChunkSize, func(objs []interface{}) bool {
+		for _, obj := range objs {
+			if c.publicStorageClass(obj.(*v1.StorageClass)) {
+				public++
+			}
+		}
+		return true
+	})
+	return public
+}
+
+// recoverClusterPanic recovers from the panic raised while checking a tenant cluster, so that it neither crashes
+// the syncer nor stops the other tenant clusters from being checked. The patrol round is reported as failed.
+func (c *controller) recoverClusterPanic(ctx context.Context, clusterName string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip", Err: fmt.Errorf("%v", r)}, "panic while checking storageclass of cluster %s: %v\n%s", clusterName, r, debug.Stack())
+	metrics.RecordCheckerPanic("StorageClass", clusterName)
+	pa.ReportFailure(ctx)
+}
+
+// updateSyncedConditions writes the StorageClassSynced condition back to the virtualclusters.
+func (c *controller) updateSyncedConditions(results map[string]uint64) {
+	if c.vcClient == nil {
+		return
+	}
+	now := metav1.Now()
+	for clusterName, mismatched := range results {
+		condition := v1alpha1.ClusterCondition{
+			Type:               v1alpha1.StorageClassSynced,
+			Status:             corev1.ConditionTrue,
+			LastProbeTime:      now,
+			LastTransitionTime: now,
+			Reason:             "Synced",
+			Message:            "all storageclasses are consistent with super master",
+		}
+		if mismatched > 0 {
+			condition.Status = corev1.ConditionFalse
+			condition.Reason = "Mismatched"
+			condition.Message = fmt.Sprintf("%d storageclasses are inconsistent with super master", mismatched)
+		}
+		if err := c.updateSyncedCondition(clusterName, condition); err != nil {
+			if c.clusterRemoved(clusterName, err) || errors.IsNotFound(err) {
+				pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip"}, "cluster %s is removed during the patrol, skip updating its condition", clusterName)
+				continue
+			}
+			pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "updateCondition", Err: err}, "failed to update %s condition of cluster %s: %v", v1alpha1.StorageClassSynced, clusterName, err)
+		}
+	}
+}
+
+func (c *controller) updateSyncedCondition(clusterName string, condition v1alpha1.ClusterCondition) error {
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	return c.setVirtualClusterCondition(vc, condition)
+}
+
+// markAPIAbsent marks the StorageClassSynced condition of the tenant cluster false, since super master does not
+// serve storageclasses. The tenant cluster is not registered in the multi cluster controller in that case, hence
+// the virtualcluster is got from the cluster itself.
+func (c *controller) markAPIAbsent(cluster mc.ClusterInterface) {
+	if c.vcClient == nil {
+		return
+	}
+	obj, err := cluster.GetObject()
 	if err != nil {
-		klog.Errorf("error listing storageclass from super master informer cache: %v", err)
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: cluster.GetClusterName(), Err: err}, "failed to get virtualcluster of cluster %s: %v", cluster.GetClusterName(), err)
 		return
 	}
+	vc, ok := obj.(*v1alpha1.VirtualCluster)
+	if !ok {
+		return
+	}
+	now := metav1.Now()
+	condition := v1alpha1.ClusterCondition{
+		Type:               v1alpha1.StorageClassSynced,
+		Status:             corev1.ConditionFalse,
+		LastProbeTime:      now,
+		LastTransitionTime: now,
+		Reason:             "APIUnavailable",
+		Message:            fmt.Sprintf("super master does not serve %s storageclasses", v1.SchemeGroupVersion),
+	}
+	if err := c.setVirtualClusterCondition(vc, condition); err != nil {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: cluster.GetClusterName(), Action: "updateCondition", Err: err}, "failed to update %s condition of cluster %s: %v", v1alpha1.StorageClassSynced, cluster.GetClusterName(), err)
+	}
+}
 
-	for _, pStorageClass := range pStorageClassList {
-		if !publicStorageClass(pStorageClass) {
-			continue
+func (c *controller) setVirtualClusterCondition(vc *v1alpha1.VirtualCluster, condition v1alpha1.ClusterCondition) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := c.vcClient.TenancyV1alpha1().VirtualClusters(vc.Namespace).Get(vc.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
 		}
-		for _, clusterName := range clusterNames {
+		util.SetVirtualClusterCondition(latest, condition)
+		_, err = c.vcClient.TenancyV1alpha1().VirtualClusters(vc.Namespace).Update(latest)
+		return err
+	})
+}
 
-			if err := c.MultiClusterController.Get(clusterName, "", pStorageClass.Name, &v1.StorageClass{}); err != nil {
-				if errors.IsNotFound(err) {
-					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterStorageClasses").Inc()
-					c.UpwardController.AddToQueue(clusterName + "/" + pStorageClass.Name)
-				}
-				klog.Errorf("fail to get storageclass from cluster %s: %v", clusterName, err)
+// requeueMissingStorageClasses requeues the public storageclasses missing in the tenant clusters, each cluster is
+// checked against the super master it is mapped to.
+func (c *controller) requeueMissingStorageClasses(clusterNames []string) {
+	// unreachable records the clusters which fail to be accessed, they are skipped for the rest of this pass
+	// so that no remediation is decided based on an unreachable cluster.
+	unreachable := sets.NewString()
+	supers, clustersOf := c.clustersBySuper(clusterNames)
+	for _, super := range supers {
+		if len(clustersOf[super]) == 0 {
+			continue
+		}
+		pa.ListInChunks(super.store, c.patrolListChunkSize, func(objs []interface{}) bool {
+			for _, obj := range objs {
+				c.requeueMissingStorageClass(obj.(*v1.StorageClass), clustersOf[super], unreachable)
 			}
+			return true
+		})
+	}
+}
+
+// requeueMissingStorageClass requeues the public storageclass for the tenant clusters missing it.
+func (c *controller) requeueMissingStorageClass(pStorageClass *v1.StorageClass, clusterNames []string, unreachable sets.String) {
+	if !c.publicStorageClass(pStorageClass) {
+		return
+	}
+	if conversion.SuperTopologyStorageClass(c.Config, pStorageClass) {
+		atomic.AddUint64(&c.numSuperTopologyStorageClasses, 1)
+		pa.V(4).Infof(pa.Fields{Resource: "storageclass", Object: pStorageClass.Name}, "storageclass %s is synced to tenants bound to super cluster topology", pStorageClass.Name)
+	}
+	for _, clusterName := range clusterNames {
+		if unreachable.Has(clusterName) {
+			continue
+		}
+		err := c.MultiClusterController.Get(clusterName, "", pStorageClass.Name, &v1.StorageClass{})
+		if err == nil {
+			continue
+		}
+		if c.clusterRemoved(clusterName, err) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip"}, "cluster %s is removed during the patrol, skip it", clusterName)
+			unreachable.Insert(clusterName)
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip", Err: err}, "fail to get storageclass from cluster %s, skip the cluster in this pass: %v", clusterName, err)
+			metrics.RecordCheckerConnectionError("StorageClass", clusterName)
+			unreachable.Insert(clusterName)
+			continue
+		}
+		c.sweep.Missing(clusterName, pStorageClass.Name)
+		if c.conflictPolicy == manager.DiffOnly {
+			pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: pStorageClass.Name, Action: "requeue"}, "storageclass %s is missing in cluster %s, conflict policy is %s", pStorageClass.Name, clusterName, c.conflictPolicy)
+			continue
+		}
+		key := clusterName + "/" + pStorageClass.Name
+		if !c.markRequeued(key) {
+			continue
+		}
+		if c.patrollerDryRun {
+			pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: pStorageClass.Name, Action: "requeue"}, "[dry-run] would requeue storageclass %s for cluster %s", pStorageClass.Name, clusterName)
+			metrics.CheckerDryRunStats.WithLabelValues("RequeuedSuperMasterStorageClasses").Inc()
+			continue
+		}
+		if !c.requeueFromPatrol(key) {
+			unreachable.Insert(clusterName)
+			continue
 		}
+		metrics.RecordCheckerRemedy("RequeuedSuperMasterStorageClasses", clusterName)
+		c.recordRemedy(clusterName, pStorageClass.Name, "", "requeue", "Requeued",
+			"StorageClass %s is missing in tenant master and is requeued to sync from super master", pStorageClass.Name)
 	}
+}
 
-	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedStorageClasses").Set(float64(numMissMatchedStorageClasses))
+// clusterRemoved returns true if the error is caused by the cluster being removed after the patrol started,
+// which is benign and should not be reported as a failure.
+func (c *controller) clusterRemoved(clusterName string, err error) bool {
+	return utilerrors.IsClusterNotFound(err) || c.MultiClusterController.GetCluster(clusterName) == nil
 }
 
-func (c *controller) checkStorageClassOfTenantCluster(clusterName string) {
+// checkStorageClassOfTenantCluster checks the storageclasses of a tenant cluster. It returns the number of
+// mismatched storageclasses and whether the check completed.
+func (c *controller) checkStorageClassOfTenantCluster(ctx context.Context, clusterName string) (uint64, bool) {
+	defer metrics.RecordCheckerClusterScanDuration("StorageClass", clusterName, time.Now())
+	// the tenant requests of the cluster are aborted once it is removed.
+	ctx, cancel := c.withClusterContext(ctx, clusterName)
+	defer cancel()
+	if ctx.Err() != nil {
+		pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip", Err: ctx.Err()}, "cluster %s is not watched, skip it", clusterName)
+		return 0, false
+	}
+	// Only the storageclasses managed by syncer are checked, the ones created by tenants are left alone.
 	scList := &v1.StorageClassList{}
-	if err := c.MultiClusterController.List(clusterName, scList); err != nil {
-		klog.Errorf("error listing storageclass from cluster %s informer cache: %v", clusterName, err)
-		return
+	if err := c.MultiClusterController.List(clusterName, scList, client.MatchingLabels{constants.LabelManagedBy: constants.ManagedBySyncer}); err != nil {
+		if c.clusterRemoved(clusterName, err) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip"}, "cluster %s is removed during the patrol, skip it", clusterName)
+			return 0, false
+		}
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Err: err}, "error listing storageclass from cluster %s informer cache: %v", clusterName, err)
+		return 0, false
+	}
+	pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName}, "check storageclass consistency in cluster %s", clusterName)
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		if c.clusterRemoved(clusterName, err) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip"}, "cluster %s is removed during the patrol, skip it", clusterName)
+			return 0, false
+		}
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Err: err}, "fail to get cluster spec : %s", clusterName)
+		return 0, false
+	}
+	super, err := c.superOfVirtualCluster(vc)
+	if err != nil {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip", Err: err}, "fail to get the super master of cluster %s, skip the cluster in this pass: %v", clusterName, err)
+		return 0, false
 	}
-	klog.V(4).Infof("check storageclass consistency in cluster %s", clusterName)
+	if _, err := conversion.TenantStorageClassParameterOverrides(vc); err != nil {
+		pa.Warningf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Err: err}, "ignore the storageclass parameter overrides of cluster %s: %v", clusterName, err)
+	}
+	qps, burst, err := conversion.TenantPatrolWriteLimit(vc, c.Config.PatrolWriteQPS, c.Config.PatrolWriteBurst)
+	if err != nil {
+		pa.Warningf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Err: err}, "ignore the patrol write limit of cluster %s: %v", clusterName, err)
+	}
+	c.writeThrottle.SetLimit(clusterName, qps, burst)
+
+	owner, err := c.storageClassOwner(ctx, clusterName)
+	if err != nil {
+		if c.clusterRemoved(clusterName, err) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip"}, "cluster %s is removed during the patrol, skip it", clusterName)
+			return 0, false
+		}
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip", Err: err}, "fail to get storageclass owner of cluster %s, skip the cluster in this pass: %v", clusterName, err)
+		metrics.RecordCheckerConnectionError("StorageClass", clusterName)
+		return 0, false
+	}
+	var ownerUID types.UID
+	if owner != nil {
+		ownerUID = owner.UID
+	}
+
+	// orphans records the orphan storageclasses still present in this cluster.
+	orphans := make(map[string]time.Time)
+	defer c.setClusterOrphans(clusterName, orphans)
+
+	// reconciled records the storageclasses found consistent in this pass, the deleted ones are dropped with it.
+	reconciled := make(map[string]reconciledVersions)
+	defer c.setClusterReconciled(clusterName, reconciled)
+
+	// synced and mismatched are the numbers of tenant storageclasses consistent and inconsistent with super master,
+	// syncedPublic are the synced ones which are public in super master.
+	var synced, syncedPublic, mismatched uint64
+	// managed is the number of tenant storageclasses managed by syncer, toDelete holds the orphans among them and
+	// toRecreate the ones whose immutable fields differ from super master.
+	managed := len(scList.Items)
+	var toDelete []*v1.StorageClass
+	var toRecreate []immutableDrift
+
+	c.countUnmanagedOrphans(clusterName, super.lister)
 
 	for i, vStorageClass := range scList.Items {
-		pStorageClass, err := c.storageclassLister.Get(vStorageClass.Name)
-		if errors.IsNotFound(err) {
-			// super master is the source of the truth for sc object, delete tenant master obj
-			tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
-			if err != nil {
-				klog.Errorf("error getting cluster %s clientset: %v", clusterName, err)
+		if ctx.Err() != nil {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Err: ctx.Err()}, "stop checking storageclass in cluster %s: %v", clusterName, ctx.Err())
+			return 0, false
+		}
+		// a terminating storageclass is held by its finalizers, deleting it again does not help.
+		if vStorageClass.DeletionTimestamp != nil {
+			atomic.AddUint64(&c.numStuckTerminatingStorageClasses, 1)
+			c.handleTerminatingStorageClass(ctx, clusterName, &scList.Items[i])
+			continue
+		}
+		pStorageClass, err := super.lister.Get(vStorageClass.Name)
+		// storageclass denied by allow list or deny list is treated as orphan.
+		if errors.IsNotFound(err) || (err == nil && !c.storageClassAllowed(vStorageClass.Name)) {
+			if conversion.IsProtected(&scList.Items[i]) {
+				atomic.AddUint64(&c.numProtectedStorageClasses, 1)
+				pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "skip"}, "orphan storageclass %s in cluster %s is protected, leave it alone", vStorageClass.Name, clusterName)
 				continue
 			}
-			opts := &metav1.DeleteOptions{
-				PropagationPolicy: &constants.DefaultDeletionPolicy,
+			firstSeen := c.orphanFirstSeenTime(clusterName, vStorageClass.Name)
+			orphans[vStorageClass.Name] = firstSeen
+			if time.Since(firstSeen) < c.orphanTTL {
+				pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "skip"}, "orphan storageclass %s in cluster %s is within grace period, first seen at %v", vStorageClass.Name, clusterName, firstSeen)
+				continue
 			}
-			if err := tenantClient.StorageV1().StorageClasses().Delete(context.TODO(), vStorageClass.Name, *opts); err != nil {
-				klog.Errorf("error deleting storageclass %v in cluster %s: %v", vStorageClass.Name, clusterName, err)
-			} else {
-				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanTenantStorageClasses").Inc()
+			if c.conflictPolicy == manager.DiffOnly {
+				pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "delete"}, "orphan storageclass %s found in cluster %s, conflict policy is %s", vStorageClass.Name, clusterName, c.conflictPolicy)
+				continue
+			}
+			if c.patrollerDryRun {
+				pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "delete"}, "[dry-run] would delete orphan storageclass %s in cluster %s", vStorageClass.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("DeletedOrphanTenantStorageClasses").Inc()
+				continue
 			}
+			// the orphans are deleted after the scan, once the pass is known not to be destructive.
+			toDelete = append(toDelete, &scList.Items[i])
 			continue
 		}
 
 		if err != nil {
-			klog.Errorf("failed to get pStorageClass %s from super master cache: %v", vStorageClass.Name, err)
+			pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Err: err}, "failed to get pStorageClass %s from super master cache: %v", vStorageClass.Name, err)
+			continue
+		}
+
+		// a corrupt storageclass cannot be updated in place since the provisioner is immutable, nor is it
+		// necessarily found by the equality check, hence it is recreated regardless of the reconciled versions.
+		if missing := missingRequiredFields(&scList.Items[i]); len(missing) > 0 {
+			atomic.AddUint64(&c.numCorruptStorageClasses, 1)
+			mismatched++
+			c.recreateCorruptStorageClass(ctx, clusterName, pStorageClass, &scList.Items[i], missing)
 			continue
 		}
 
-		updatedStorageClass := conversion.Equality(nil, nil).CheckStorageClassEquality(pStorageClass, &scList.Items[i])
-		if updatedStorageClass != nil {
-			atomic.AddUint64(&numMissMatchedStorageClasses, 1)
-			klog.Warningf("spec of storageClass %v diff in super&tenant master", vStorageClass.Name)
-			if publicStorageClass(pStorageClass) {
-				c.UpwardController.AddToQueue(clusterName + "/" + pStorageClass.Name)
+		key := clusterName + "/" + vStorageClass.Name
+		versions := reconciledVersions{superID: super.id, super: pStorageClass.ResourceVersion, tenant: vStorageClass.ResourceVersion, vc: vcFingerprint(vc), owner: ownerUID}
+		var updatedStorageClass *v1.StorageClass
+		// only the existence of the tenant copy of a mirror only storageclass is checked.
+		if !conversion.MirrorOnly(pStorageClass) && !c.reconciledUnchanged(clusterName, vStorageClass.Name, versions) {
+			updatedStorageClass = conversion.Equality(c.Config, vc).WithOwnerReference(owner).CheckStorageClassEquality(pStorageClass, &scList.Items[i])
+		}
+		if updatedStorageClass == nil {
+			reconciled[vStorageClass.Name] = versions
+			c.patrolRequeueLimiter.Forget(key)
+			c.syncLag.Synced(clusterName, key)
+			synced++
+			if c.publicStorageClass(pStorageClass) {
+				syncedPublic++
+			}
+		} else {
+			atomic.AddUint64(&c.numMissMatchedStorageClasses, 1)
+			mismatched++
+			pa.Warningf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name}, "spec of storageClass %v diff in super&tenant master", vStorageClass.Name)
+			if klog.V(2) {
+				pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name}, "storageClass %v in cluster %s diff: %s", vStorageClass.Name, clusterName,
+					strings.Join(conversion.StorageClassDiff(&scList.Items[i], updatedStorageClass), ", "))
+			}
+			if klog.V(5) {
+				pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name}, "storageClass %v in cluster %s mismatched, super master object: %s, tenant master object: %s", vStorageClass.Name, clusterName,
+					pa.DumpObject(pStorageClass), pa.DumpObject(&scList.Items[i]))
+			}
+			if c.conflictPolicy != manager.SuperWins {
+				pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "keep"}, "keep storageclass %s in cluster %s, conflict policy is %s", vStorageClass.Name, clusterName, c.conflictPolicy)
+				c.patrolRequeueLimiter.Forget(key)
+				continue
+			}
+			// the update to an immutable field is rejected, the storageclass is recreated after the scan instead.
+			if c.Config.RecreateStorageClassOnImmutableChange && c.publicStorageClass(pStorageClass) {
+				if fields := immutableFieldsChanged(&scList.Items[i], updatedStorageClass); len(fields) > 0 {
+					toRecreate = append(toRecreate, immutableDrift{vStorageClass: &scList.Items[i], fields: fields})
+					continue
+				}
+			}
+			if c.publicStorageClass(pStorageClass) && c.markRequeued(key) {
+				if c.patrollerDryRun {
+					pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: pStorageClass.Name, Action: "requeue"}, "[dry-run] would requeue storageclass %s for cluster %s", pStorageClass.Name, clusterName)
+					metrics.CheckerDryRunStats.WithLabelValues("RequeuedDiffStorageClasses").Inc()
+					continue
+				}
+				if c.requeueFromPatrol(key) {
+					c.audit(clusterName, vStorageClass.Name, vStorageClass.UID, "requeue", "Requeued",
+						fmt.Sprintf("StorageClass %s differs from super master and is requeued to sync from super master", vStorageClass.Name))
+				}
 			}
 		}
 	}
+	if len(toDelete) > 0 && !c.superCacheFresh(super) {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "delete"}, "super master storageclass cache has not observed a new resource version since %v, skip deleting %d orphan storageclasses in cluster %s",
+			c.observedTime(), len(toDelete), clusterName)
+		toDelete = nil
+	}
+	// the recreations delete tenant storageclasses as well, hence they count toward the delete limit.
+	if c.exceedsDeleteLimit(len(toDelete)+len(toRecreate), managed) {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "delete"}, "patrol would delete %d of %d storageclasses managed by syncer in cluster %s, %d of them to be recreated, more than %d%%, abort the deletions. "+
+			"Check whether the super master storageclass cache is broken", len(toDelete)+len(toRecreate), managed, clusterName, len(toRecreate), c.maxDeletePercentPerPass)
+		metrics.CheckerAbortedDestructive.WithLabelValues("StorageClass").Inc()
+	} else {
+		for _, drift := range toRecreate {
+			c.recreateDriftedStorageClass(ctx, clusterName, drift)
+		}
+		if !c.deleteOrphanStorageClasses(ctx, clusterName, toDelete, orphans) {
+			return 0, false
+		}
+	}
+
+	// the public storageclasses created in super master during the patrol may be synced already.
+	var diverged uint64
+	if public := c.publicStorageClassesOf(super); public > syncedPublic {
+		diverged = public - syncedPublic
+	}
+	atomic.AddUint64(&c.numSyncedStorageClasses, synced)
+	atomic.AddUint64(&c.numDivergedStorageClasses, diverged)
+	if metrics.PerClusterLabels() {
+		metrics.SyncedObjectCount.WithLabelValues("StorageClass", clusterName).Set(float64(synced))
+		metrics.DivergedObjectCount.WithLabelValues("StorageClass", clusterName).Set(float64(diverged))
+		metrics.SetCheckerClusterMissMatch("MissMatchedStorageClasses", clusterName, float64(mismatched))
+	}
+	return mismatched, true
+}
+
+// countUnmanagedOrphans counts the orphan storageclasses of the tenant cluster which are not managed by syncer.
+// They are created by the tenant and are never removed, only reported.
+func (c *controller) countUnmanagedOrphans(clusterName string, superLister listersv1.StorageClassLister) {
+	unmanaged, err := labels.NewRequirement(constants.LabelManagedBy, selection.NotEquals, []string{constants.ManagedBySyncer})
+	if err != nil {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Err: err}, "failed to build unmanaged storageclass selector: %v", err)
+		return
+	}
+	scList := &v1.StorageClassList{}
+	if err := c.MultiClusterController.List(clusterName, scList, client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*unmanaged)}); err != nil {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Err: err}, "error listing unmanaged storageclass from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	for _, vStorageClass := range scList.Items {
+		_, err := superLister.Get(vStorageClass.Name)
+		if errors.IsNotFound(err) || (err == nil && !c.storageClassAllowed(vStorageClass.Name)) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "skip"}, "orphan storageclass %s in cluster %s is not managed by syncer, skip it", vStorageClass.Name, clusterName)
+			atomic.AddUint64(&c.numUnmanagedStorageClasses, 1)
+		}
+	}
+}
+
+// observeSuperCache records the time when the super master storageclass cache observes a new resource version.
+// The resource version advances with storageclass events as well as watch bookmarks and relists.
+func (c *controller) observeSuperCache() {
+	rv := c.lastSyncResourceVersion()
+	c.superCacheLock.Lock()
+	defer c.superCacheLock.Unlock()
+	if rv != c.observedResourceVersion || c.observedAt.IsZero() {
+		c.observedResourceVersion = rv
+		c.observedAt = time.Now()
+	}
+}
+
+func (c *controller) observedTime() time.Time {
+	c.superCacheLock.Lock()
+	defer c.superCacheLock.Unlock()
+	return c.observedAt
+}
+
+// superCacheFresh returns false if the super master storageclass cache has not synced, or has not observed a new
+// resource version for longer than SuperCacheMaxStaleness, in which case it cannot be trusted to declare
+// tenant storageclasses orphaned. The staleness is only tracked for the default super master.
+func (c *controller) superCacheFresh(super *superSource) bool {
+	if !super.synced() {
+		return false
+	}
+	if super.id != "" {
+		return true
+	}
+	if c.Config.SuperCacheMaxStaleness <= 0 {
+		return true
+	}
+	c.observeSuperCache()
+	return time.Since(c.observedTime()) <= c.Config.SuperCacheMaxStaleness
+}
+
+// exceedsDeleteLimit returns true if deleting n of the managed tenant storageclasses of a cluster in a single pass
+// is beyond maxDeletePercentPerPass. Up to PatrolDeleteLimitFloor deletions are always allowed.
+func (c *controller) exceedsDeleteLimit(n, managed int) bool {
+	if c.maxDeletePercentPerPass >= 100 || n <= constants.PatrolDeleteLimitFloor {
+		return false
+	}
+	return n*100 > c.maxDeletePercentPerPass*managed
+}
+
+// tenantStorageClassClient is the part of the tenant storageclass client the patroller writes with, it is
+// faked in tests to exercise the failures of the tenant apiserver.
+type tenantStorageClassClient interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.StorageClassList, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*v1.StorageClass, error)
+}
+
+// clusterStorageClasses returns the storageclass client of the tenant cluster.
+func (c *controller) clusterStorageClasses(clusterName string) (tenantStorageClassClient, error) {
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return tenantClient.StorageV1().StorageClasses(), nil
+}
+
+// deleteOrphanStorageClasses deletes the orphan tenant storageclasses of a cluster, the ones failed to be deleted
+// are retried in the cleanup queue. It returns false if the cluster is removed.
+func (c *controller) deleteOrphanStorageClasses(ctx context.Context, clusterName string, toDelete []*v1.StorageClass, orphans map[string]time.Time) bool {
+	if len(toDelete) == 0 {
+		return true
+	}
+	// super master is the source of the truth for sc object, delete tenant master obj
+	tenantClient, err := c.tenantStorageClasses(clusterName)
+	if err != nil {
+		if c.clusterRemoved(clusterName, err) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip"}, "cluster %s is removed during the patrol, skip it", clusterName)
+			return false
+		}
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "delete", Err: err}, "error getting cluster %s clientset: %v", clusterName, err)
+		return true
+	}
+	deleted := func(vStorageClass *v1.StorageClass) {
+		delete(orphans, vStorageClass.Name)
+		metrics.RecordCheckerRemedy("DeletedOrphanTenantStorageClasses", clusterName)
+		c.recordRemedy(clusterName, vStorageClass.Name, vStorageClass.UID, "delete", "DeletedOrphan",
+			"StorageClass %s is deleted because it is not synced from super master", vStorageClass.Name)
+	}
+	if c.Config.StorageClassBulkOrphanDeletion && len(toDelete) > 1 && c.bulkDeleteOrphanStorageClasses(ctx, clusterName, tenantClient, toDelete) {
+		for _, vStorageClass := range toDelete {
+			deleted(vStorageClass)
+		}
+		return true
+	}
+	for _, vStorageClass := range toDelete {
+		if ctx.Err() != nil {
+			return true
+		}
+		if err := c.deleteOrphanStorageClass(ctx, clusterName, tenantClient, vStorageClass); err != nil {
+			pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "delete", Err: err}, "error deleting storageclass %v in cluster %s, retry it in the cleanup queue: %v", vStorageClass.Name, clusterName, err)
+			if ctx.Err() == nil {
+				c.orphanCleanupQueue.AddRateLimited(clusterName + "/" + vStorageClass.Name)
+			}
+			continue
+		}
+		deleted(vStorageClass)
+	}
+	return true
+}
+
+// maxBulkDeleteExclusions bounds the storageclasses excluded by the field selector of a bulk orphan deletion,
+// each of them is a term of the request URL.
+const maxBulkDeleteExclusions = 100
+
+// bulkDeleteOrphanStorageClasses deletes the orphans of the cluster with a single DeleteCollection request. A field
+// selector cannot select a set of names, hence the request selects the syncer managed storageclasses except the
+// ones to keep, i.e., the other managed tenant storageclasses and the super master storageclasses. Such a selector
+// matches more than intended if a managed storageclass shows up after the exclusions are collected, so it is
+// verified by a list right before the deletion and nothing is deleted in bulk unless it matches the orphans only.
+// It returns false if the orphans are left to be deleted one by one.
+func (c *controller) bulkDeleteOrphanStorageClasses(ctx context.Context, clusterName string, tenantClient tenantStorageClassClient, toDelete []*v1.StorageClass) bool {
+	logFields := pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "delete"}
+	for _, vStorageClass := range toDelete {
+		if _, overridden := vStorageClass.GetAnnotations()[constants.AnnotationDeletionPropagationPolicy]; overridden {
+			pa.V(4).Infof(logFields, "storageclass %s in cluster %s overrides its deletion propagation policy, delete the orphans one by one", vStorageClass.Name, clusterName)
+			return false
+		}
+	}
+	orphanNames := sets.NewString()
+	for _, vStorageClass := range toDelete {
+		orphanNames.Insert(vStorageClass.Name)
+	}
+	managedSelector := labels.SelectorFromSet(labels.Set{constants.LabelManagedBy: constants.ManagedBySyncer}).String()
+	list := func(opts metav1.ListOptions) (*v1.StorageClassList, error) {
+		listCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+		defer cancel()
+		return tenantClient.List(listCtx, opts)
+	}
+
+	vList, err := list(metav1.ListOptions{LabelSelector: managedSelector})
+	if err != nil {
+		logFields.Err = err
+		pa.Errorf(logFields, "error listing storageclasses of cluster %s for bulk deletion, delete the orphans one by one: %v", clusterName, err)
+		return false
+	}
+	super, err := c.superOf(clusterName)
+	if err != nil {
+		logFields.Err = err
+		pa.Errorf(logFields, "error getting the super master of cluster %s, delete the orphans one by one: %v", clusterName, err)
+		return false
+	}
+	pStorageClasses, err := super.lister.List(labels.Everything())
+	if err != nil {
+		logFields.Err = err
+		pa.Errorf(logFields, "error listing storageclasses from super master informer cache, delete the orphans of cluster %s one by one: %v", clusterName, err)
+		return false
+	}
+	keep := sets.NewString()
+	for i := range vList.Items {
+		keep.Insert(vList.Items[i].Name)
+	}
+	for _, pStorageClass := range pStorageClasses {
+		keep.Insert(pStorageClass.Name)
+	}
+	keep = keep.Difference(orphanNames)
+	if keep.Len() > maxBulkDeleteExclusions {
+		pa.V(4).Infof(logFields, "%d storageclasses of cluster %s would be excluded from bulk deletion, delete the orphans one by one", keep.Len(), clusterName)
+		return false
+	}
+	var exclusions []fields.Selector
+	for _, name := range keep.List() {
+		exclusions = append(exclusions, fields.OneTermNotEqualSelector("metadata.name", name))
+	}
+	opts := metav1.ListOptions{LabelSelector: managedSelector, FieldSelector: fields.AndSelectors(exclusions...).String()}
+
+	matched, err := list(opts)
+	if err != nil {
+		logFields.Err = err
+		pa.Errorf(logFields, "error verifying the bulk deletion of cluster %s, delete the orphans one by one: %v", clusterName, err)
+		return false
+	}
+	for i := range matched.Items {
+		if !orphanNames.Has(matched.Items[i].Name) {
+			pa.Warningf(logFields, "bulk deletion of cluster %s would delete storageclass %s which is not an orphan, delete the orphans one by one", clusterName, matched.Items[i].Name)
+			return false
+		}
+	}
+
+	if err := c.writeThrottle.Wait(ctx, clusterName); err != nil {
+		logFields.Err = err
+		pa.Errorf(logFields, "error waiting to delete %d orphan storageclasses of cluster %s in bulk: %v", len(toDelete), clusterName, err)
+		return false
+	}
+	deleteCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+	defer cancel()
+	if err := tenantClient.DeleteCollection(deleteCtx, metav1.DeleteOptions{PropagationPolicy: &c.deletionPropagationPolicy}, opts); err != nil {
+		logFields.Err = err
+		pa.Errorf(logFields, "error deleting %d orphan storageclasses of cluster %s in bulk, delete them one by one: %v", len(toDelete), clusterName, err)
+		return false
+	}
+	pa.V(4).Infof(logFields, "deleted %d orphan storageclasses of cluster %s in bulk", len(toDelete), clusterName)
+	return true
+}
+
+// missingRequiredFields returns the required fields of the storageclass which are empty, e.g., the provisioner of
+// a storageclass left behind by a partial create. The apiserver rejects such storageclasses, so they are corrupt.
+func missingRequiredFields(sc *v1.StorageClass) []string {
+	var missing []string
+	if sc.Provisioner == "" {
+		missing = append(missing, "provisioner")
+	}
+	return missing
+}
+
+// recreateCorruptStorageClass deletes the corrupt tenant storageclass and requeues it, so that it is created again
+// from super master. It is left alone if the super master storageclass is not public or is corrupt as well, or if
+// the tenant storageclass is protected.
+func (c *controller) recreateCorruptStorageClass(ctx context.Context, clusterName string, pStorageClass, vStorageClass *v1.StorageClass, missing []string) {
+	logFields := pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "recreate"}
+	pa.Warningf(logFields, "storageclass %s in cluster %s is corrupt, its %v are empty", vStorageClass.Name, clusterName, missing)
+	if !c.publicStorageClass(pStorageClass) {
+		pa.V(4).Infof(logFields, "storageclass %s is not public in super master, leave the corrupt copy in cluster %s alone", pStorageClass.Name, clusterName)
+		return
+	}
+	if superMissing := missingRequiredFields(pStorageClass); len(superMissing) > 0 {
+		pa.Warningf(logFields, "storageclass %s is corrupt in super master as well, its %v are empty, leave the copy in cluster %s alone", pStorageClass.Name, superMissing, clusterName)
+		return
+	}
+	if conversion.IsProtected(vStorageClass) {
+		pa.Infof(logFields, "corrupt storageclass %s in cluster %s is protected, leave it alone", vStorageClass.Name, clusterName)
+		return
+	}
+	if c.conflictPolicy == manager.DiffOnly {
+		pa.Infof(logFields, "corrupt storageclass %s found in cluster %s, conflict policy is %s", vStorageClass.Name, clusterName, c.conflictPolicy)
+		return
+	}
+	key := clusterName + "/" + vStorageClass.Name
+	if !c.markRequeued(key) {
+		return
+	}
+	if c.patrollerDryRun {
+		pa.Infof(logFields, "[dry-run] would recreate corrupt storageclass %s in cluster %s", vStorageClass.Name, clusterName)
+		metrics.CheckerDryRunStats.WithLabelValues("RecreatedCorruptTenantStorageClasses").Inc()
+		return
+	}
+	if !c.deleteForRecreate(ctx, clusterName, vStorageClass, logFields) {
+		return
+	}
+	metrics.RecordCheckerRemedy("RecreatedCorruptTenantStorageClasses", clusterName)
+	c.recordRemedy(clusterName, vStorageClass.Name, vStorageClass.UID, "recreate", "RecreatedCorrupt",
+		"StorageClass %s is recreated from super master because its %v are empty", vStorageClass.Name, missing)
+}
+
+// immutableDrift is a tenant storageclass whose immutable fields differ from super master.
+type immutableDrift struct {
+	vStorageClass *v1.StorageClass
+	fields        []string
+}
+
+// immutableFieldsChanged returns the immutable fields of the tenant storageclass which differ in the update
+// returned by the equality check.
+func immutableFieldsChanged(vStorageClass, updated *v1.StorageClass) []string {
+	var fields []string
+	if vStorageClass.Provisioner != updated.Provisioner {
+		fields = append(fields, "provisioner")
+	}
+	if !equality.Semantic.DeepEqual(vStorageClass.Parameters, updated.Parameters) {
+		fields = append(fields, "parameters")
+	}
+	if !equality.Semantic.DeepEqual(vStorageClass.ReclaimPolicy, updated.ReclaimPolicy) {
+		fields = append(fields, "reclaimPolicy")
+	}
+	return fields
+}
+
+// recreateDriftedStorageClass deletes the tenant storageclass whose immutable fields differ from super master and
+// requeues it, so that it is created again from super master rather than updated in vain. It is left alone if
+// the tenant storageclass is protected.
+func (c *controller) recreateDriftedStorageClass(ctx context.Context, clusterName string, drift immutableDrift) {
+	vStorageClass := drift.vStorageClass
+	logFields := pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "recreate"}
+	if conversion.IsProtected(vStorageClass) {
+		pa.Infof(logFields, "immutable %v of storageclass %s in cluster %s differ from super master, but it is protected, leave it alone", drift.fields, vStorageClass.Name, clusterName)
+		return
+	}
+	key := clusterName + "/" + vStorageClass.Name
+	if !c.markRequeued(key) {
+		return
+	}
+	if c.patrollerDryRun {
+		pa.Infof(logFields, "[dry-run] would recreate storageclass %s in cluster %s, its immutable %v differ from super master", vStorageClass.Name, clusterName, drift.fields)
+		metrics.CheckerDryRunStats.WithLabelValues("RecreatedImmutableDriftTenantStorageClasses").Inc()
+		return
+	}
+	if !c.deleteForRecreate(ctx, clusterName, vStorageClass, logFields) {
+		return
+	}
+	metrics.CheckerRecreatedObjects.WithLabelValues("StorageClass").Inc()
+	metrics.RecordCheckerRemedy("RecreatedImmutableDriftTenantStorageClasses", clusterName)
+	c.recordRemedy(clusterName, vStorageClass.Name, vStorageClass.UID, "recreate", "RecreatedImmutableDrift",
+		"StorageClass %s is recreated from super master because its immutable %v differ", vStorageClass.Name, drift.fields)
+}
+
+// deleteForRecreate deletes the tenant storageclass, guarded by its uid, and requeues it to be created again from
+// super master. It returns false if the storageclass is not deleted or not requeued.
+func (c *controller) deleteForRecreate(ctx context.Context, clusterName string, vStorageClass *v1.StorageClass, logFields pa.Fields) bool {
+	tenantClient, err := c.tenantStorageClasses(clusterName)
+	if err != nil {
+		logFields.Err = err
+		pa.Errorf(logFields, "error getting cluster %s clientset: %v", clusterName, err)
+		return false
+	}
+	policy := c.deletionPolicyOf(clusterName, vStorageClass)
+	opts := metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+		Preconditions:     metav1.NewUIDPreconditions(string(vStorageClass.UID)),
+	}
+	if err := c.writeThrottle.Wait(ctx, clusterName); err != nil {
+		logFields.Err = err
+		pa.Errorf(logFields, "error waiting to delete storageclass %s in cluster %s: %v", vStorageClass.Name, clusterName, err)
+		return false
+	}
+	deleteCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+	defer cancel()
+	if err := tenantClient.Delete(deleteCtx, vStorageClass.Name, opts); err != nil && !errors.IsNotFound(err) {
+		logFields.Err = err
+		pa.Errorf(logFields, "error deleting storageclass %s in cluster %s: %v", vStorageClass.Name, clusterName, err)
+		return false
+	}
+	return c.requeueFromPatrol(clusterName + "/" + vStorageClass.Name)
+}
+
+// requeueFromPatrol requeues the key with exponential backoff, so that a storageclass which repeatedly
+// fails to be reconciled does not hot-loop with the patrol period. The tenant cluster may be removed after
+// it is checked, it returns false without requeuing the key if the cluster is gone.
+func (c *controller) requeueFromPatrol(key string) bool {
+	clusterName, name, _ := cache.SplitMetaNamespaceKey(key)
+	if c.MultiClusterController.GetCluster(clusterName) == nil {
+		pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: key, Action: "skip"}, "cluster %s is removed during the patrol, skip requeuing %s", clusterName, key)
+		return false
+	}
+	c.syncLag.Changed(clusterName, key, c.superChangedTime(clusterName, name))
+	c.UpwardController.AddToQueueAfter(key, c.patrolRequeueLimiter.When(key))
+	return true
+}
+
+// superChangedTime returns the last change time of the super master storageclass of the tenant cluster, which the
+// sync lag of a key requeued by the patroller is measured from. The deletion time of a storageclass gone from super
+// master is unknown, the current time is returned instead.
+func (c *controller) superChangedTime(clusterName, name string) time.Time {
+	super, err := c.superOf(clusterName)
+	if err != nil {
+		return time.Now()
+	}
+	pStorageClass, err := super.lister.Get(name)
+	if err != nil {
+		return time.Now()
+	}
+	return pa.LastChanged(pStorageClass)
+}
+
+// resetRequeued starts a new patrol sweep, in which each key is requeued at most once.
+func (c *controller) resetRequeued() {
+	c.requeuedLock.Lock()
+	defer c.requeuedLock.Unlock()
+	c.requeuedKeys = sets.NewString()
+}
+
+// markRequeued records the key as requeued in the current patrol sweep. It returns false if the key has been
+// requeued in the sweep already, e.g., by the tenant cluster check, in which case it is neither requeued nor
+// counted again.
+func (c *controller) markRequeued(key string) bool {
+	c.requeuedLock.Lock()
+	defer c.requeuedLock.Unlock()
+	if c.requeuedKeys.Has(key) {
+		return false
+	}
+	c.requeuedKeys.Insert(key)
+	return true
+}
+
+// recordRemedy records an event in tenant master describing the remediation done to the storageclass, and
+// hands the remediation to the audit sink.
+func (c *controller) recordRemedy(clusterName, name string, uid types.UID, action, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	err := c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+		Kind:       "StorageClass",
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Name:       name,
+		UID:        uid,
+	}, corev1.EventTypeNormal, reason, "%s", message)
+	if err != nil {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: name, Action: action, Err: err}, "failed to record event for storageclass %s in cluster %s: %v", name, clusterName, err)
+	}
+	c.audit(clusterName, name, uid, action, reason, message)
+}
+
+// audit hands the remediation done to the storageclass to the audit sink, it is counted in the sweep summary as well.
+func (c *controller) audit(clusterName, name string, uid types.UID, action, reason, message string) {
+	record := pa.AuditRecord{
+		Time:     time.Now(),
+		Resource: "storageclass",
+		Cluster:  clusterName,
+		Object:   name,
+		UID:      uid,
+		Action:   action,
+		Reason:   reason,
+		Message:  message,
+	}
+	c.auditSink.Record(record)
+	c.sweep.Record(record)
+}
+
+// orphanDeleteBackoff bounds the attempts the patroller makes to delete an orphan tenant storageclass.
+// The jitter spreads the retries of the clusters checked in parallel.
+var orphanDeleteBackoff = wait.Backoff{
+	Steps:    constants.DefaultPatrolDeleteAttempts,
+	Duration: constants.DefaultPatrolDeleteRetryDelay,
+	Factor:   2,
+	Jitter:   0.5,
+}
+
+// handleTerminatingStorageClass handles a syncer managed tenant storageclass whose deletion is pending on its
+// finalizers. It is not deleted again, and once it has been terminating for longer than stuckFinalizerGracePeriod
+// its finalizers are removed so that the deletion completes.
+func (c *controller) handleTerminatingStorageClass(ctx context.Context, clusterName string, vStorageClass *v1.StorageClass) {
+	terminating := time.Since(vStorageClass.DeletionTimestamp.Time)
+	if c.stuckFinalizerGracePeriod <= 0 || len(vStorageClass.Finalizers) == 0 || terminating < c.stuckFinalizerGracePeriod {
+		pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "skip"}, "storageclass %s in cluster %s has been terminating for %v, skip it", vStorageClass.Name, clusterName, terminating)
+		return
+	}
+	if c.conflictPolicy == manager.DiffOnly {
+		pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "removeFinalizers"}, "storageclass %s is stuck terminating in cluster %s, conflict policy is %s", vStorageClass.Name, clusterName, c.conflictPolicy)
+		return
+	}
+	if c.patrollerDryRun {
+		pa.Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "removeFinalizers"}, "[dry-run] would remove finalizers %v of storageclass %s stuck terminating in cluster %s", vStorageClass.Finalizers, vStorageClass.Name, clusterName)
+		metrics.CheckerDryRunStats.WithLabelValues("RemovedFinalizersTenantStorageClasses").Inc()
+		return
+	}
+	tenantClient, err := c.tenantStorageClasses(clusterName)
+	if err != nil {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Err: err}, "error getting cluster %s clientset: %v", clusterName, err)
+		return
+	}
+	// the patch is guarded by the uid, so that a storageclass recreated under the same name is left alone.
+	patch := []byte(fmt.Sprintf(`{"metadata":{"uid":%q,"finalizers":null}}`, vStorageClass.UID))
+	if err := c.writeThrottle.Wait(ctx, clusterName); err != nil {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "removeFinalizers", Err: err}, "error waiting to remove finalizers of storageclass %s in cluster %s: %v", vStorageClass.Name, clusterName, err)
+		return
+	}
+	patchCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+	defer cancel()
+	if _, err := tenantClient.Patch(patchCtx, vStorageClass.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil && !errors.IsNotFound(err) {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "removeFinalizers", Err: err}, "error removing finalizers of storageclass %s in cluster %s: %v", vStorageClass.Name, clusterName, err)
+		return
+	}
+	metrics.RecordCheckerRemedy("RemovedFinalizersTenantStorageClasses", clusterName)
+	c.recordRemedy(clusterName, vStorageClass.Name, vStorageClass.UID, "removeFinalizers", "RemovedFinalizers",
+		"Finalizers %v of StorageClass %s are removed because it has been terminating for %v", vStorageClass.Finalizers, vStorageClass.Name, terminating.Round(time.Second))
+}
+
+// deleteOrphanStorageClass deletes the tenant storageclass, retrying the failures so that a briefly flaky
+// tenant apiserver does not leave the orphan until the next patrol. A storageclass already gone is not an error.
+func (c *controller) deleteOrphanStorageClass(ctx context.Context, clusterName string, tenantClient tenantStorageClassClient, vStorageClass *v1.StorageClass) error {
+	policy := c.deletionPolicyOf(clusterName, vStorageClass)
+	opts := metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+	}
+	return retry.OnError(orphanDeleteBackoff, func(error) bool { return ctx.Err() == nil }, func() error {
+		if err := c.writeThrottle.Wait(ctx, clusterName); err != nil {
+			return err
+		}
+		deleteCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+		defer cancel()
+		err := tenantClient.Delete(deleteCtx, vStorageClass.Name, opts)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// deletionPolicyOf returns the propagation policy the tenant storageclass is deleted with, which may be overridden
+// by its AnnotationDeletionPropagationPolicy annotation. An invalid annotation falls back to the syncer policy.
+func (c *controller) deletionPolicyOf(clusterName string, vStorageClass *v1.StorageClass) metav1.DeletionPropagation {
+	policy, err := conversion.DeletionPropagationPolicy(vStorageClass, c.deletionPropagationPolicy)
+	if err != nil {
+		pa.Warningf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: vStorageClass.Name, Action: "delete", Err: err}, "delete storageclass %s in cluster %s with propagation policy %s: %v", vStorageClass.Name, clusterName, policy, err)
+	}
+	return policy
+}
+
+func (c *controller) runOrphanCleanupWorker() {
+	for c.processNextOrphanCleanup() {
+	}
+}
+
+func (c *controller) processNextOrphanCleanup() bool {
+	obj, quit := c.orphanCleanupQueue.Get()
+	if quit {
+		return false
+	}
+	defer c.orphanCleanupQueue.Done(obj)
+
+	key := obj.(string)
+	clusterName, name, _ := cache.SplitMetaNamespaceKey(key)
+	err := c.cleanupOrphanStorageClass(key)
+	if err == nil || utilerrors.IsClusterNotFound(err) {
+		c.orphanCleanupQueue.Forget(obj)
+		return true
+	}
+	if c.orphanCleanupQueue.NumRequeues(obj) >= utilconstants.MaxReconcileRetryAttempts {
+		pa.Warningf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: name, Action: "delete", Err: err}, "cleanup of orphan storageclass %s is dropped due to reaching max retry limit, left to the next patrol: %v", key, err)
+		c.orphanCleanupQueue.Forget(obj)
+		return true
+	}
+	pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: name, Action: "delete", Err: err}, "error cleaning up orphan storageclass %s (will retry): %v", key, err)
+	c.orphanCleanupQueue.AddRateLimited(obj)
+	return true
+}
+
+// cleanupOrphanStorageClass deletes the orphan tenant storageclass of the cluster/name key if it is still an
+// orphan managed by syncer, the super master storageclass may have been created or allowed since it was queued.
+func (c *controller) cleanupOrphanStorageClass(key string) error {
+	clusterName, name, _ := cache.SplitMetaNamespaceKey(key)
+	super, err := c.superOf(clusterName)
+	if err != nil {
+		return err
+	}
+	if _, err := super.lister.Get(name); err == nil {
+		if c.storageClassAllowed(name) {
+			return nil
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	vStorageClass := &v1.StorageClass{}
+	if err := c.MultiClusterController.Get(clusterName, "", name, vStorageClass); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !conversion.IsSyncerManaged(vStorageClass) || conversion.IsProtected(vStorageClass) {
+		return nil
+	}
+
+	tenantClient, err := c.tenantStorageClasses(clusterName)
+	if err != nil {
+		return err
+	}
+	if err := c.writeThrottle.Wait(c.getClusterContext(clusterName), clusterName); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(c.getClusterContext(clusterName), c.patrolOpTimeout)
+	defer cancel()
+	policy := c.deletionPolicyOf(clusterName, vStorageClass)
+	opts := metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+		Preconditions:     metav1.NewUIDPreconditions(string(vStorageClass.UID)),
+	}
+	if err := tenantClient.Delete(ctx, name, opts); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	metrics.RecordCheckerRemedy("DeletedOrphanTenantStorageClasses", clusterName)
+	c.recordRemedy(clusterName, name, vStorageClass.UID, "delete", "DeletedOrphan",
+		"StorageClass %s is deleted because it is not synced from super master", name)
+	return nil
+}
+
+// orphanFirstSeenTime returns the time when the orphan tenant storageclass was first observed.
+func (c *controller) orphanFirstSeenTime(clusterName, name string) time.Time {
+	c.Lock()
+	defer c.Unlock()
+	if t, exist := c.clusterOrphanMap[clusterName][name]; exist {
+		return t
+	}
+	return time.Now()
+}
+
+// setClusterOrphans replaces the recorded orphan storageclasses of the cluster.
+func (c *controller) setClusterOrphans(clusterName string, orphans map[string]time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	c.clusterOrphanMap[clusterName] = orphans
+}
+
+// pruneClusterOrphans forgets the orphan records of clusters that are no longer managed.
+func (c *controller) pruneClusterOrphans(clusterNames []string) {
+	c.Lock()
+	defer c.Unlock()
+	active := sets.NewString(clusterNames...)
+	for cluster := range c.clusterOrphanMap {
+		if !active.Has(cluster) {
+			delete(c.clusterOrphanMap, cluster)
+		}
+	}
+}
+
+// reconciledUnchanged returns true if the tenant storageclass was found consistent with super master at the same
+// resource versions, in which case the equality check is skipped. The objects without resource version, e.g.,
+// the ones created by fake clients, are always checked.
+func (c *controller) reconciledUnchanged(clusterName, name string, versions reconciledVersions) bool {
+	if versions.super == "" || versions.tenant == "" {
+		return false
+	}
+	c.reconciledLock.Lock()
+	defer c.reconciledLock.Unlock()
+	last, exist := c.reconciled[clusterName][name]
+	return exist && last == versions
+}
+
+// vcFingerprint returns the fields of the virtualcluster the storageclass equality check depends on.
+func vcFingerprint(vc *v1alpha1.VirtualCluster) string {
+	return fmt.Sprintf("%v;%v;%s;%s", vc.Spec.TransparentMetaPrefixes, vc.Spec.OpaqueMetaPrefixes, vc.GetAnnotations()[constants.LabelTenantDefaultStorageClass], vc.GetAnnotations()[constants.LabelTenantStorageClassParameters])
+}
+
+// setClusterReconciled replaces the recorded consistent storageclasses of the cluster.
+func (c *controller) setClusterReconciled(clusterName string, reconciled map[string]reconciledVersions) {
+	c.reconciledLock.Lock()
+	defer c.reconciledLock.Unlock()
+	c.reconciled[clusterName] = reconciled
+}
+
+// resetReconciled forgets all the consistent storageclasses, so that they are compared in full by the next patrol.
+func (c *controller) resetReconciled() {
+	c.reconciledLock.Lock()
+	defer c.reconciledLock.Unlock()
+	c.reconciled = make(map[string]map[string]reconciledVersions)
+}
+
+// pruneReconciled forgets the consistent storageclasses of clusters that are no longer managed.
+func (c *controller) pruneReconciled(clusterNames []string) {
+	c.reconciledLock.Lock()
+	defer c.reconciledLock.Unlock()
+	active := sets.NewString(clusterNames...)
+	for cluster := range c.reconciled {
+		if !active.Has(cluster) {
+			delete(c.reconciled, cluster)
+		}
+	}
 }
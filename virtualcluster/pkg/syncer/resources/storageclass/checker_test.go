@@ -14,18 +14,47 @@ limitations under the License.
 package storageclass
 
 import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	storagev1client "k8s.io/client-go/kubernetes/typed/storage/v1"
 	core "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	fakevcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	utilerrors "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 func TestStorageClassPatrol(t *testing.T) {
@@ -41,15 +70,27 @@ func TestStorageClassPatrol(t *testing.T) {
 		},
 	}
 
+	dryRun := func(r manager.ResourceSyncer) {
+		r.(*controller).patrollerDryRun = true
+	}
+	conflictPolicy := func(policy manager.ConflictPolicy) func(manager.ResourceSyncer) {
+		return func(r manager.ResourceSyncer) {
+			r.(*controller).conflictPolicy = policy
+		}
+	}
+
 	testcases := map[string]struct {
 		ExistingObjectInSuper  []runtime.Object
 		ExistingObjectInTenant []runtime.Object
 		ExpectedDeletedVObject []string
 		ExpectedCreatedVObject []string
 		ExpectedUpdatedVObject []runtime.Object
+		ExpectedEventReasons   []string
+		ExpectedAuditActions   []string
 		ExpectedNoOperation    bool
 		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
 		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
+		StateModifyFunc        func(manager.ResourceSyncer)
 	}{
 		"pStorageClass not public": {
 			ExistingObjectInSuper: []runtime.Object{
@@ -69,14 +110,32 @@ func TestStorageClassPatrol(t *testing.T) {
 			ExpectedCreatedVObject: []string{
 				"sc",
 			},
+			ExpectedEventReasons: []string{
+				"Requeued",
+			},
+			ExpectedAuditActions: []string{
+				"requeue",
+			},
 		},
 		"pStorageClass not found, vStorageClass exists": {
 			ExistingObjectInTenant: []runtime.Object{
-				makeStorageClass("sc", "12345"),
+				makeStorageClass("sc", "12345", managed),
 			},
 			ExpectedDeletedVObject: []string{
 				"sc",
 			},
+			ExpectedEventReasons: []string{
+				"DeletedOrphan",
+			},
+			ExpectedAuditActions: []string{
+				"delete",
+			},
+		},
+		"pStorageClass not found, vStorageClass exists but not managed": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345"),
+			},
+			ExpectedNoOperation: true,
 		},
 		"pStorageClass exists, vStorageClass exists with different spec": {
 			ExistingObjectInSuper: []runtime.Object{
@@ -88,27 +147,322 @@ func TestStorageClassPatrol(t *testing.T) {
 				}),
 			},
 			ExistingObjectInTenant: []runtime.Object{
-				makeStorageClass("sc", "123456", func(class *v1.StorageClass) {
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
 					class.Provisioner = "b"
 				}),
 			},
 			ExpectedUpdatedVObject: []runtime.Object{
-				makeStorageClass("sc", "123456", func(class *v1.StorageClass) {
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = "a"
+				}),
+			},
+			ExpectedAuditActions: []string{
+				"requeue",
+			},
+			WaitUWS: true,
+		},
+		"pStorageClass mirror only, vStorageClass exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", mirrorOnly, func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
 					class.Provisioner = "a"
 				}),
 			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = "b"
+				}),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pStorageClass mirror only, vStorageClass does not exist": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", mirrorOnly, func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExpectedCreatedVObject: []string{
+				"sc",
+			},
+			ExpectedAuditActions: []string{
+				"requeue",
+			},
 			WaitUWS: true,
 		},
+		"pStorageClass exists, vStorageClass exists with different spec, tenant wins": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+					class.Provisioner = "a"
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = "b"
+				}),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     conflictPolicy(manager.TenantWins),
+		},
+		"pStorageClass exists, vStorageClass does not exists, diff only": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     conflictPolicy(manager.DiffOnly),
+		},
+		"pStorageClass not found, vStorageClass exists, diff only": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     conflictPolicy(manager.DiffOnly),
+		},
+		"pStorageClass not found, vStorageClass exists, tenant wins": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed),
+			},
+			ExpectedDeletedVObject: []string{
+				"sc",
+			},
+			ExpectedEventReasons: []string{
+				"DeletedOrphan",
+			},
+			ExpectedAuditActions: []string{
+				"delete",
+			},
+			StateModifyFunc: conflictPolicy(manager.TenantWins),
+		},
+		"pStorageClass not found, vStorageClass exists within orphan ttl": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc: func(r manager.ResourceSyncer) {
+				r.(*controller).orphanTTL = time.Hour
+			},
+		},
+		"pStorageClass not found, vStorageClass exists beyond orphan ttl": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed),
+			},
+			ExpectedDeletedVObject: []string{
+				"sc",
+			},
+			ExpectedAuditActions: []string{
+				"delete",
+			},
+			StateModifyFunc: func(r manager.ResourceSyncer) {
+				c := r.(*controller)
+				c.orphanTTL = time.Hour
+				c.clusterOrphanMap[conversion.ToClusterKey(testTenant)] = map[string]time.Time{
+					"sc": time.Now().Add(-2 * time.Hour),
+				}
+			},
+		},
+		"pStorageClass not found, vStorageClass protected": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed, protected),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pStorageClass not allowed, vStorageClass protected": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, protected),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc: func(r manager.ResourceSyncer) {
+				r.(*controller).Config.SyncStorageClassAllowList = []string{"gp*"}
+			},
+		},
+		"pStorageClass denied, vStorageClass does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc: func(r manager.ResourceSyncer) {
+				r.(*controller).Config.SyncStorageClassDenyList = []string{"s*"}
+			},
+		},
+		"pStorageClass not allowed, vStorageClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed),
+			},
+			ExpectedDeletedVObject: []string{
+				"sc",
+			},
+			ExpectedAuditActions: []string{
+				"delete",
+			},
+			StateModifyFunc: func(r manager.ResourceSyncer) {
+				r.(*controller).Config.SyncStorageClassAllowList = []string{"gp*"}
+			},
+		},
+		"pStorageClass exists, vStorageClass corrupt": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = ""
+				}),
+			},
+			ExpectedDeletedVObject: []string{
+				"sc",
+			},
+			ExpectedEventReasons: []string{
+				"RecreatedCorrupt",
+			},
+			ExpectedAuditActions: []string{
+				"recreate",
+			},
+		},
+		"pStorageClass exists, vStorageClass corrupt but protected": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, protected, func(class *v1.StorageClass) {
+					class.Provisioner = ""
+				}),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pStorageClass corrupt, vStorageClass corrupt": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+					class.Provisioner = ""
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = ""
+				}),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pStorageClass not public, vStorageClass corrupt": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = ""
+				}),
+			},
+			ExpectedNoOperation: true,
+		},
+		"dry run, pStorageClass exists, vStorageClass corrupt": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = ""
+				}),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
+		"dry run, pStorageClass exists, vStorageClass does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+				}),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
+		"dry run, pStorageClass not found, vStorageClass exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
+		"dry run, pStorageClass exists, vStorageClass exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Labels = map[string]string{
+						constants.PublicObjectKey: "true",
+					}
+					class.Provisioner = "a"
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = "b"
+				}),
+			},
+			ExpectedNoOperation: true,
+			StateModifyFunc:     dryRun,
+		},
 	}
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			tenantActions, superActions, err := util.RunPatrol(NewStorageClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
+			sink := &auditRecorder{}
+			modify := func(r manager.ResourceSyncer) {
+				r.(*controller).auditSink = sink
+				if tc.StateModifyFunc != nil {
+					tc.StateModifyFunc(r)
+				}
+			}
+			tenantActions, superActions, err := util.RunPatrol(NewStorageClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, modify)
 			if err != nil {
 				t.Errorf("%s: error running patrol: %v", k, err)
 				return
 			}
 
+			if actions := sink.actions(); !equality.Semantic.DeepEqual(actions, tc.ExpectedAuditActions) {
+				t.Errorf("%s: Expect audited actions %v, got %v", k, tc.ExpectedAuditActions, actions)
+			}
+
 			if tc.ExpectedNoOperation {
 				if len(superActions) != 0 {
 					t.Errorf("%s: Expect no operation, got %v in super cluster", k, superActions)
@@ -157,6 +511,27 @@ func TestStorageClassPatrol(t *testing.T) {
 				}
 			}
 
+			for _, expectedReason := range tc.ExpectedEventReasons {
+				matched := false
+				for _, action := range tenantActions {
+					if !action.Matches("create", "events") {
+						continue
+					}
+					event := action.(core.CreateAction).GetObject().(*corev1.Event)
+					if event.Reason != expectedReason {
+						continue
+					}
+					if event.InvolvedObject.Kind != "StorageClass" || event.InvolvedObject.Name != "sc" {
+						t.Errorf("%s: Expect event involving storageclass sc, got %v", k, event.InvolvedObject)
+					}
+					matched = true
+					break
+				}
+				if !matched {
+					t.Errorf("%s: Expect event with reason %s, but not found", k, expectedReason)
+				}
+			}
+
 			for _, obj := range tc.ExpectedUpdatedVObject {
 				matched := false
 				for _, action := range tenantActions {
@@ -179,3 +554,2045 @@ func TestStorageClassPatrol(t *testing.T) {
 		})
 	}
 }
+
+func TestStorageClassPatrolRequeueBackoff(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	key := conversion.ToClusterKey(testTenant) + "/sc"
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{
+			constants.PublicObjectKey: "true",
+		}
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		PreviousRequeues       int
+		ExpectedRequeues       int
+	}{
+		"vStorageClass missing, requeue is backed off": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", public),
+			},
+			PreviousRequeues: 2,
+			ExpectedRequeues: 3,
+		},
+		"vStorageClass diff, requeue is backed off": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", public, managed, func(class *v1.StorageClass) {
+					class.Provisioner = "p2"
+				}),
+			},
+			PreviousRequeues: 2,
+			ExpectedRequeues: 3,
+		},
+		"vStorageClass consistent, backoff is reset": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", public, managed),
+			},
+			PreviousRequeues: 2,
+			ExpectedRequeues: 0,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			var c *controller
+			seedRequeues := func(r manager.ResourceSyncer) {
+				c = r.(*controller)
+				for i := 0; i < tc.PreviousRequeues; i++ {
+					c.patrolRequeueLimiter.When(key)
+				}
+			}
+			_, _, err := util.RunPatrol(NewStorageClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, false, false, seedRequeues)
+			if err != nil {
+				t.Errorf("%s: error running patrol: %v", k, err)
+				return
+			}
+			if requeues := c.patrolRequeueLimiter.NumRequeues(key); requeues != tc.ExpectedRequeues {
+				t.Errorf("%s: expected %d requeues of %s, got %d", k, tc.ExpectedRequeues, key, requeues)
+			}
+		})
+	}
+}
+
+func TestStorageClassServedInVersion(t *testing.T) {
+	for _, tt := range []struct {
+		gitVersion string
+		served     bool
+	}{
+		{gitVersion: "v1.21.1", served: true},
+		{gitVersion: "v1.6.0", served: true},
+		{gitVersion: "v1.5.8", served: false},
+		{gitVersion: "unknown", served: true},
+	} {
+		if served := storageClassServedInVersion(&version.Info{GitVersion: tt.gitVersion}); served != tt.served {
+			t.Errorf("version %s: expected served %v, got %v", tt.gitVersion, tt.served, served)
+		}
+	}
+}
+
+func TestStorageClassPatrolSyncedObjectCount(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	provisioner := func(p string) func(*v1.StorageClass) {
+		return func(class *v1.StorageClass) {
+			class.Provisioner = p
+		}
+	}
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+
+	c, _ := newFakeController(t, testTenant,
+		makeStorageClass("sc1", "1", public, provisioner("a")),
+		makeStorageClass("sc2", "2", public, provisioner("a")),
+		makeStorageClass("sc3", "3", public, provisioner("a")),
+		makeStorageClass("private", "4", provisioner("a")))
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient(
+		makeStorageClass("sc1", "11", managed, provisioner("a")),
+		makeStorageClass("sc2", "22", managed, provisioner("b"))))
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	l := c.GetListener()
+	l.AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	metrics.SetPerClusterLabels(true)
+	defer metrics.SetPerClusterLabels(false)
+	atomic.StoreUint64(&c.numPublicStorageClasses, c.countPublicStorageClasses(c.storageclassStore))
+	c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	if v := testutil.ToFloat64(metrics.SyncedObjectCount.WithLabelValues("StorageClass", clusterName)); v != 1 {
+		t.Errorf("expected 1 synced storageclass, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.CheckerClusterMissMatchStats.WithLabelValues("MissMatchedStorageClasses", clusterName)); v != 1 {
+		t.Errorf("expected 1 mismatched storageclass, got %v", v)
+	}
+
+	// sc2 is mismatched and sc3 is missing in tenant.
+	if v := testutil.ToFloat64(metrics.DivergedObjectCount.WithLabelValues("StorageClass", clusterName)); v != 2 {
+		t.Errorf("expected 2 diverged storageclasses, got %v", v)
+	}
+
+	series := testutil.CollectAndCount(metrics.SyncedObjectCount)
+	divergedSeries := testutil.CollectAndCount(metrics.DivergedObjectCount)
+	l.RemoveCluster(tenantCluster)
+	if n := testutil.CollectAndCount(metrics.SyncedObjectCount); n != series-1 {
+		t.Errorf("expected synced storageclass count of the removed cluster to be deleted, got %d series, was %d", n, series)
+	}
+	if n := testutil.CollectAndCount(metrics.DivergedObjectCount); n != divergedSeries-1 {
+		t.Errorf("expected diverged storageclass count of the removed cluster to be deleted, got %d series, was %d", n, divergedSeries)
+	}
+}
+
+func TestStorageClassPatrolCorruptCount(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+	corrupt := func(class *v1.StorageClass) {
+		class.Provisioner = ""
+	}
+
+	c, _ := newFakeController(t, testTenant,
+		makeStorageClass("sc1", "1", public),
+		makeStorageClass("sc2", "2", public),
+		makeStorageClass("sc3", "3", public))
+	c.patrollerDryRun = true
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient(
+		makeStorageClass("sc1", "11", managed),
+		makeStorageClass("sc2", "22", managed, corrupt),
+		makeStorageClass("sc3", "33", managed, corrupt)))
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	mismatched, ok := c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	if !ok {
+		t.Fatalf("expected cluster %s to be checked", clusterName)
+	}
+	if n := atomic.LoadUint64(&c.numCorruptStorageClasses); n != 2 {
+		t.Errorf("expected 2 corrupt storageclasses, got %d", n)
+	}
+	if mismatched != 2 {
+		t.Errorf("expected the corrupt storageclasses to be mismatched, got %d mismatched", mismatched)
+	}
+
+	// the corrupt storageclasses are not cached as reconciled, they are checked again in the next pass.
+	c.resetRequeued()
+	atomic.StoreUint64(&c.numCorruptStorageClasses, 0)
+	c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	if n := atomic.LoadUint64(&c.numCorruptStorageClasses); n != 2 {
+		t.Errorf("expected 2 corrupt storageclasses in the second pass, got %d", n)
+	}
+}
+
+func TestStorageClassPatrolProtectedCount(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+
+	c, _ := newFakeController(t, testTenant)
+	c.maxDeletePercentPerPass = 100
+	tenantClient := fake.NewSimpleClientset()
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, tenantClient, fakeClient.NewFakeClient(
+		makeStorageClass("sc1", "11", managed, protected),
+		makeStorageClass("sc2", "22", managed, protected),
+		makeStorageClass("sc3", "33", managed)))
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	if n := atomic.LoadUint64(&c.numProtectedStorageClasses); n != 2 {
+		t.Errorf("expected 2 protected storageclasses, got %d", n)
+	}
+	var deleted []string
+	for _, action := range tenantClient.Actions() {
+		if action.Matches("delete", "storageclasses") {
+			deleted = append(deleted, action.(core.DeleteAction).GetName())
+		}
+	}
+	if !equality.Semantic.DeepEqual(deleted, []string{"sc3"}) {
+		t.Errorf("expected only the unprotected orphan to be deleted, got %v", deleted)
+	}
+}
+
+func TestStorageClassPatrolWriteThrottle(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+			// a tiny qps, so that the bucket is not refilled during the test.
+			Annotations: map[string]string{
+				constants.LabelTenantPatrolWriteQPS:   "0.001",
+				constants.LabelTenantPatrolWriteBurst: "2",
+			},
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+
+	c, _ := newFakeController(t, testTenant)
+	c.maxDeletePercentPerPass = 100
+	tenantClient := fake.NewSimpleClientset()
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, tenantClient, fakeClient.NewFakeClient(
+		makeStorageClass("sc1", "11", managed),
+		makeStorageClass("sc2", "22", managed),
+		makeStorageClass("sc3", "33", managed)))
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+	defer cancel()
+	c.checkStorageClassOfTenantCluster(ctx, clusterName)
+	deleted := 0
+	for _, action := range tenantClient.Actions() {
+		if action.Matches("delete", "storageclasses") {
+			deleted++
+		}
+	}
+	if deleted != 2 {
+		t.Errorf("expected the orphan deletions to be throttled to the burst of 2, got %d deletions", deleted)
+	}
+}
+
+func TestUpdateSyncedConditions(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+			Conditions: []v1alpha1.ClusterCondition{
+				{Status: corev1.ConditionTrue, Reason: "TenantMasterRunning"},
+			},
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	c, tenantCluster := newFakeController(t, testTenant)
+	vcClient := fakevcclient.NewSimpleClientset(testTenant)
+	c.vcClient = vcClient
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	getCondition := func() *v1alpha1.ClusterCondition {
+		vc, err := vcClient.TenancyV1alpha1().VirtualClusters(testTenant.Namespace).Get(testTenant.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("error getting virtualcluster: %v", err)
+		}
+		if len(vc.Status.Conditions) != 2 {
+			t.Fatalf("expected the phase condition and the %s condition, got %v", v1alpha1.StorageClassSynced, vc.Status.Conditions)
+		}
+		return &vc.Status.Conditions[1]
+	}
+
+	c.updateSyncedConditions(map[string]uint64{clusterName: 2})
+	condition := getCondition()
+	if condition.Type != v1alpha1.StorageClassSynced || condition.Status != corev1.ConditionFalse || condition.Reason != "Mismatched" {
+		t.Errorf("expected mismatched %s condition, got %+v", v1alpha1.StorageClassSynced, condition)
+	}
+	if condition.Message != "2 storageclasses are inconsistent with super master" {
+		t.Errorf("unexpected condition message %q", condition.Message)
+	}
+	if condition.LastProbeTime.IsZero() {
+		t.Errorf("expected last probe time to be set")
+	}
+
+	c.updateSyncedConditions(map[string]uint64{clusterName: 0})
+	if condition = getCondition(); condition.Status != corev1.ConditionTrue || condition.Reason != "Synced" {
+		t.Errorf("expected synced %s condition, got %+v", v1alpha1.StorageClassSynced, condition)
+	}
+}
+
+func TestStorageClassAPIAbsent(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	superClient := fake.NewSimpleClientset()
+	superClient.Resources = []*metav1.APIResourceList{
+		{GroupVersion: v1.SchemeGroupVersion.String(), APIResources: []metav1.APIResource{{Name: "csidrivers"}}},
+	}
+	vcClient := fakevcclient.NewSimpleClientset(testTenant)
+	r, err := NewStorageClassController(&config.SyncerConfiguration{}, superClient, informers.NewSharedInformerFactory(superClient, 0), vcClient, nil, manager.ResourceSyncerOptions{})
+	if err != nil {
+		t.Fatalf("expected the controller to disable itself, got error: %v", err)
+	}
+	c := r.(*controller)
+	if !c.apiAbsent {
+		t.Fatalf("expected the storageclass API to be absent")
+	}
+	if v := testutil.ToFloat64(metrics.SyncerDisabledResource.WithLabelValues("storageclass")); v != 1 {
+		t.Errorf("expected storageclass to be reported as disabled, got %v", v)
+	}
+
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient())
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	l := c.GetListener()
+	l.AddCluster(tenantCluster)
+	l.WatchCluster(tenantCluster)
+	vc, err := vcClient.TenancyV1alpha1().VirtualClusters(testTenant.Namespace).Get(testTenant.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error getting virtualcluster: %v", err)
+	}
+	if len(vc.Status.Conditions) != 1 || vc.Status.Conditions[0].Status != corev1.ConditionFalse || vc.Status.Conditions[0].Reason != "APIUnavailable" {
+		t.Errorf("expected unavailable %s condition, got %+v", v1alpha1.StorageClassSynced, vc.Status.Conditions)
+	}
+	if c.MultiClusterController.GetCluster(conversion.ToClusterKey(testTenant)) != nil {
+		t.Errorf("expected the tenant cluster not to be watched")
+	}
+
+	if _, err := c.TriggerPatrolNow(context.TODO()); err == nil {
+		t.Errorf("expected the patrol on demand to fail")
+	}
+	stopCh := make(chan struct{})
+	close(stopCh)
+	if err := c.StartPatrol(stopCh); err != nil {
+		t.Errorf("expected the patroller to stop cleanly, got %v", err)
+	}
+	if err := c.StartUWS(stopCh); err != nil {
+		t.Errorf("expected the upward syncer to stop cleanly, got %v", err)
+	}
+}
+
+func TestStorageClassAPIServed(t *testing.T) {
+	testcases := map[string]struct {
+		resources []*metav1.APIResourceList
+		served    bool
+	}{
+		"served": {
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: v1.SchemeGroupVersion.String(), APIResources: []metav1.APIResource{{Name: "storageclasses"}}},
+			},
+			served: true,
+		},
+		"group version served without storageclasses": {
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: v1.SchemeGroupVersion.String(), APIResources: []metav1.APIResource{{Name: "csidrivers"}}},
+			},
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.Resources = tc.resources
+			served, err := storageClassAPIServed(client.Discovery())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if served != tc.served {
+				t.Errorf("expected served %v, got %v", tc.served, served)
+			}
+		})
+	}
+}
+
+func TestStorageClassPatrolClusterRemoved(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	c, tenantCluster := newFakeController(t, testTenant, makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}))
+	l := c.GetListener()
+	l.AddCluster(tenantCluster)
+	l.WatchCluster(tenantCluster)
+	// drain the storageclass enqueued when the cluster is watched.
+	key, _ := c.UpwardController.Queue.Get()
+	c.UpwardController.Queue.Done(key)
+
+	// the cluster names are snapshotted when the patrol starts, then the cluster is removed
+	// before the missing storageclasses are requeued.
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	l.RemoveCluster(tenantCluster)
+
+	connectionErrors := testutil.ToFloat64(metrics.CheckerConnectionErrors.WithLabelValues("StorageClass", clusterName))
+	requeued := testutil.ToFloat64(metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterStorageClasses"))
+	c.requeueMissingStorageClasses(clusterNames)
+
+	if c.UpwardController.Queue.Len() != 0 {
+		t.Errorf("expected nothing requeued for the removed cluster, got %d keys", c.UpwardController.Queue.Len())
+	}
+	if v := testutil.ToFloat64(metrics.CheckerConnectionErrors.WithLabelValues("StorageClass", clusterName)); v != connectionErrors {
+		t.Errorf("expected no connection error recorded for the removed cluster, got %v", v-connectionErrors)
+	}
+	if v := testutil.ToFloat64(metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterStorageClasses")); v != requeued {
+		t.Errorf("expected no remedy recorded for the removed cluster, got %v", v-requeued)
+	}
+}
+
+func TestStorageClassPatrolOrphanDeleteRetry(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	defer func(backoff wait.Backoff) { orphanDeleteBackoff = backoff }(orphanDeleteBackoff)
+	orphanDeleteBackoff.Duration = time.Millisecond
+
+	testcases := map[string]struct {
+		failures          int
+		expectedDeletes   int
+		expectedRequeues  int
+		expectedRemaining bool
+	}{
+		"delete succeeds after transient failures": {
+			failures:        2,
+			expectedDeletes: 3,
+		},
+		"delete keeps failing": {
+			failures:          constants.DefaultPatrolDeleteAttempts,
+			expectedDeletes:   constants.DefaultPatrolDeleteAttempts,
+			expectedRequeues:  1,
+			expectedRemaining: true,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c, _ := newFakeController(t, testTenant)
+			tenantClientset := fake.NewSimpleClientset(makeStorageClass("sc", "12345", managed))
+			failures := tc.failures
+			tenantClientset.PrependReactor("delete", "storageclasses", func(action core.Action) (bool, runtime.Object, error) {
+				if failures > 0 {
+					failures--
+					return true, nil, fmt.Errorf("tenant apiserver is unavailable")
+				}
+				return false, nil, nil
+			})
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, tenantClientset, fakeClient.NewFakeClient(makeStorageClass("sc", "12345", managed)))
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+
+			c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+
+			deletes := 0
+			for _, action := range tenantClientset.Actions() {
+				if action.Matches("delete", "storageclasses") {
+					deletes++
+				}
+			}
+			if deletes != tc.expectedDeletes {
+				t.Errorf("expected %d delete attempts, got %d", tc.expectedDeletes, deletes)
+			}
+			key := clusterName + "/sc"
+			if requeues := c.orphanCleanupQueue.NumRequeues(key); requeues != tc.expectedRequeues {
+				t.Errorf("expected %d requeues to the cleanup queue, got %d", tc.expectedRequeues, requeues)
+			}
+			if !tc.expectedRemaining {
+				return
+			}
+
+			// the cleanup queue deletes the orphan once the tenant apiserver recovers.
+			if err := c.cleanupOrphanStorageClass(key); err != nil {
+				t.Errorf("expected orphan to be cleaned up, got %v", err)
+			}
+			if _, err := tenantClientset.StorageV1().StorageClasses().Get(context.TODO(), "sc", metav1.GetOptions{}); !errors.IsNotFound(err) {
+				t.Errorf("expected orphan storageclass to be deleted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStorageClassPatrolDeleteLimit(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+
+	testcases := map[string]struct {
+		maxDeletePercent int
+		orphans          int
+		expectedDeletes  int
+		expectedAborted  float64
+	}{
+		"orphans beyond the limit are kept": {
+			orphans:         4,
+			expectedAborted: 1,
+		},
+		"orphans within the floor are deleted": {
+			orphans:         constants.PatrolDeleteLimitFloor,
+			expectedDeletes: constants.PatrolDeleteLimitFloor,
+		},
+		"limit disabled": {
+			maxDeletePercent: 100,
+			orphans:          4,
+			expectedDeletes:  4,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c, _ := newFakeController(t, testTenant)
+			if tc.maxDeletePercent > 0 {
+				c.maxDeletePercentPerPass = tc.maxDeletePercent
+			}
+			var objs []runtime.Object
+			for i := 0; i < tc.orphans; i++ {
+				objs = append(objs, makeStorageClass(fmt.Sprintf("sc%d", i), fmt.Sprintf("%d", i), managed))
+			}
+			tenantClientset := fake.NewSimpleClientset(objs...)
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, tenantClientset, fakeClient.NewFakeClient(objs...))
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+
+			aborted := testutil.ToFloat64(metrics.CheckerAbortedDestructive.WithLabelValues("StorageClass"))
+			c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+
+			deletes := 0
+			for _, action := range tenantClientset.Actions() {
+				if action.Matches("delete", "storageclasses") {
+					deletes++
+				}
+			}
+			if deletes != tc.expectedDeletes {
+				t.Errorf("expected %d deletions, got %d", tc.expectedDeletes, deletes)
+			}
+			if v := testutil.ToFloat64(metrics.CheckerAbortedDestructive.WithLabelValues("StorageClass")) - aborted; v != tc.expectedAborted {
+				t.Errorf("expected %v aborted passes, got %v", tc.expectedAborted, v)
+			}
+		})
+	}
+}
+
+func TestStorageClassPatrolStaleSuperCache(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+
+	c, _ := newFakeController(t, testTenant)
+	c.Config.SuperCacheMaxStaleness = time.Minute
+	resourceVersion := "10"
+	c.lastSyncResourceVersion = func() string { return resourceVersion }
+	c.observedResourceVersion = resourceVersion
+	c.observedAt = time.Now().Add(-2 * time.Minute)
+
+	tenantClientset := fake.NewSimpleClientset(makeStorageClass("sc", "12345", managed))
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, tenantClientset, fakeClient.NewFakeClient(makeStorageClass("sc", "12345", managed)))
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	deletes := func() int {
+		n := 0
+		for _, action := range tenantClientset.Actions() {
+			if action.Matches("delete", "storageclasses") {
+				n++
+			}
+		}
+		return n
+	}
+
+	c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	if n := deletes(); n != 0 {
+		t.Errorf("expected no orphan deleted with a stale super master cache, got %d deletions", n)
+	}
+
+	// a new resource version proves the cache is fresh again.
+	resourceVersion = "11"
+	c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	if n := deletes(); n != 1 {
+		t.Errorf("expected the orphan deleted with a fresh super master cache, got %d deletions", n)
+	}
+}
+
+func TestStorageClassPatrolRequeueOncePerSweep(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+	c, tenantCluster := newFakeController(t, testTenant, makeStorageClass("sc", "12345", public))
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+	key := clusterName + "/sc"
+	requeued := func() float64 {
+		return testutil.ToFloat64(metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterStorageClasses"))
+	}
+
+	// the key has been requeued by the tenant cluster check of this sweep.
+	c.resetRequeued()
+	c.markRequeued(key)
+	before := requeued()
+	c.requeueMissingStorageClasses([]string{clusterName})
+	if n := c.patrolRequeueLimiter.NumRequeues(key); n != 0 {
+		t.Errorf("expected %s not to be requeued twice in a sweep, got %d requeues", key, n)
+	}
+	if requeued() != before {
+		t.Errorf("expected the requeue counter not to be inflated")
+	}
+
+	c.resetRequeued()
+	c.requeueMissingStorageClasses([]string{clusterName})
+	if n := c.patrolRequeueLimiter.NumRequeues(key); n != 1 {
+		t.Errorf("expected %s to be requeued in the next sweep, got %d requeues", key, n)
+	}
+	if requeued() != before+1 {
+		t.Errorf("expected the requeue to be counted once")
+	}
+}
+
+func TestStorageClassReconciledFastPath(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	c, _ := newFakeController(t, testTenant)
+	versions := reconciledVersions{super: "1", tenant: "2", vc: vcFingerprint(testTenant)}
+
+	if c.reconciledUnchanged(clusterName, "sc", versions) {
+		t.Errorf("expected storageclass never reconciled to be checked")
+	}
+	c.setClusterReconciled(clusterName, map[string]reconciledVersions{"sc": versions})
+	if !c.reconciledUnchanged(clusterName, "sc", versions) {
+		t.Errorf("expected unchanged storageclass to skip the equality check")
+	}
+	changed := versions
+	changed.tenant = "3"
+	if c.reconciledUnchanged(clusterName, "sc", changed) {
+		t.Errorf("expected changed tenant storageclass to be checked")
+	}
+	if c.reconciledUnchanged(clusterName, "sc", reconciledVersions{vc: versions.vc}) {
+		t.Errorf("expected storageclass without resource version to be checked")
+	}
+
+	c.resetReconciled()
+	if c.reconciledUnchanged(clusterName, "sc", versions) {
+		t.Errorf("expected storageclass to be checked after relist")
+	}
+
+	c.setClusterReconciled(clusterName, map[string]reconciledVersions{"sc": versions})
+	c.pruneReconciled(nil)
+	if c.reconciledUnchanged(clusterName, "sc", versions) {
+		t.Errorf("expected storageclass of removed cluster to be forgotten")
+	}
+}
+
+// auditRecorder records the remediations handed to the audit sink.
+type auditRecorder struct {
+	sync.Mutex
+	records []pa.AuditRecord
+}
+
+func (r *auditRecorder) Record(record pa.AuditRecord) {
+	r.Lock()
+	defer r.Unlock()
+	r.records = append(r.records, record)
+}
+
+func (r *auditRecorder) actions() []string {
+	r.Lock()
+	defer r.Unlock()
+	var actions []string
+	for _, record := range r.records {
+		if record.Resource != "storageclass" || record.Object != "sc" {
+			continue
+		}
+		actions = append(actions, record.Action)
+	}
+	return actions
+}
+
+// deletePolicyRecorder records the propagation policies of the storageclass deletions, which the fake clientset drops.
+type deletePolicyRecorder struct {
+	*fake.Clientset
+	policies []metav1.DeletionPropagation
+}
+
+func (r *deletePolicyRecorder) StorageV1() storagev1client.StorageV1Interface {
+	return &recordingStorageV1{StorageV1Interface: r.Clientset.StorageV1(), recorder: r}
+}
+
+type recordingStorageV1 struct {
+	storagev1client.StorageV1Interface
+	recorder *deletePolicyRecorder
+}
+
+func (s *recordingStorageV1) StorageClasses() storagev1client.StorageClassInterface {
+	return &recordingStorageClasses{StorageClassInterface: s.StorageV1Interface.StorageClasses(), recorder: s.recorder}
+}
+
+type recordingStorageClasses struct {
+	storagev1client.StorageClassInterface
+	recorder *deletePolicyRecorder
+}
+
+func (s *recordingStorageClasses) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if opts.PropagationPolicy != nil {
+		s.recorder.policies = append(s.recorder.policies, *opts.PropagationPolicy)
+	}
+	return s.StorageClassInterface.Delete(ctx, name, opts)
+}
+
+func TestStorageClassDeletionPropagationPolicy(t *testing.T) {
+	annotated := func(policy string) func(*v1.StorageClass) {
+		return func(class *v1.StorageClass) {
+			if class.Annotations == nil {
+				class.Annotations = map[string]string{}
+			}
+			class.Annotations[constants.AnnotationDeletionPropagationPolicy] = policy
+		}
+	}
+
+	testcases := map[string]struct {
+		policy     metav1.DeletionPropagation
+		annotation func(*v1.StorageClass)
+		expected   metav1.DeletionPropagation
+	}{
+		"default policy": {
+			expected: constants.DefaultDeletionPolicy,
+		},
+		"foreground policy": {
+			policy:   metav1.DeletePropagationForeground,
+			expected: metav1.DeletePropagationForeground,
+		},
+		"orphan annotation": {
+			policy:     metav1.DeletePropagationForeground,
+			annotation: annotated("Orphan"),
+			expected:   metav1.DeletePropagationOrphan,
+		},
+		"background annotation": {
+			policy:     metav1.DeletePropagationForeground,
+			annotation: annotated("Background"),
+			expected:   metav1.DeletePropagationBackground,
+		},
+		"foreground annotation": {
+			annotation: annotated("Foreground"),
+			expected:   metav1.DeletePropagationForeground,
+		},
+		"invalid annotation": {
+			policy:     metav1.DeletePropagationForeground,
+			annotation: annotated("Cascade"),
+			expected:   metav1.DeletePropagationForeground,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			superClient := fake.NewSimpleClientset()
+			r, err := NewStorageClassController(&config.SyncerConfiguration{}, superClient, informers.NewSharedInformerFactory(superClient, 0), nil, nil,
+				manager.ResourceSyncerOptions{IsFake: true, DeletionPropagationPolicy: tc.policy})
+			if err != nil {
+				t.Fatalf("error creating controller: %v", err)
+			}
+			mutators := []func(*v1.StorageClass){managed}
+			if tc.annotation != nil {
+				mutators = append(mutators, tc.annotation)
+			}
+			vStorageClass := makeStorageClass("sc", "12345", mutators...)
+			tenantClient := &deletePolicyRecorder{Clientset: fake.NewSimpleClientset(vStorageClass)}
+			if err := r.(*controller).deleteOrphanStorageClass(context.TODO(), "test", tenantClient.StorageV1().StorageClasses(), vStorageClass); err != nil {
+				t.Fatalf("error deleting orphan storageclass: %v", err)
+			}
+			if len(tenantClient.policies) != 1 || tenantClient.policies[0] != tc.expected {
+				t.Errorf("expected deletion with policy %s, got %v", tc.expected, tenantClient.policies)
+			}
+		})
+	}
+}
+
+func TestStorageClassPatrolRecoverPanic(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	c, _ := newFakeController(t, testTenant)
+
+	panics := testutil.ToFloat64(metrics.CheckerPanics.WithLabelValues("StorageClass", metrics.ClusterLabelValue(clusterName)))
+	func() {
+		defer c.recoverClusterPanic(context.TODO(), clusterName)
+		panic("unexpected object")
+	}()
+	if v := testutil.ToFloat64(metrics.CheckerPanics.WithLabelValues("StorageClass", metrics.ClusterLabelValue(clusterName))); v != panics+1 {
+		t.Errorf("expected 1 panic recorded for cluster %s, got %v", clusterName, v-panics)
+	}
+}
+
+func TestStorageClassPatrolOwnerReference(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	anchor := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "anchor", UID: "anchor-uid"}}
+	owned := func(class *v1.StorageClass) {
+		class.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "Namespace", Name: "anchor", UID: "anchor-uid"}}
+	}
+
+	testcases := map[string]struct {
+		tenantObjects []runtime.Object
+		mismatched    uint64
+	}{
+		"owner does not exist": {
+			tenantObjects: []runtime.Object{makeStorageClass("sc", "12345", managed)},
+		},
+		"owner reference missing": {
+			tenantObjects: []runtime.Object{anchor, makeStorageClass("sc", "12345", managed)},
+			mismatched:    1,
+		},
+		"owner reference stamped": {
+			tenantObjects: []runtime.Object{anchor, makeStorageClass("sc", "12345", managed, owned)},
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeController(t, testTenant, makeStorageClass("sc", "1"))
+			c.Config.StorageClassOwner = &config.OwnerAnchor{APIVersion: "v1", Kind: "Namespace", Name: "anchor"}
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient(tc.tenantObjects...))
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+
+			mismatched, ok := c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+			if !ok {
+				t.Fatalf("expected the cluster to be checked")
+			}
+			if mismatched != tc.mismatched {
+				t.Errorf("expected %d mismatched storageclasses, got %d", tc.mismatched, mismatched)
+			}
+		})
+	}
+}
+
+func TestStorageClassPatrolStuckTerminating(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	terminating := func(class *v1.StorageClass) {
+		deletionTimestamp := metav1.NewTime(time.Now().Add(-time.Hour))
+		class.DeletionTimestamp = &deletionTimestamp
+		class.Finalizers = []string{"example.com/protection"}
+	}
+
+	testcases := map[string]struct {
+		gracePeriod     time.Duration
+		dryRun          bool
+		expectedActions []string
+	}{
+		"finalizers kept": {},
+		"within grace period": {
+			gracePeriod: 2 * time.Hour,
+		},
+		"finalizers removed": {
+			gracePeriod:     time.Minute,
+			expectedActions: []string{"patch"},
+		},
+		"finalizers removed in dry run": {
+			gracePeriod: time.Minute,
+			dryRun:      true,
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newFakeController(t, testTenant)
+			c.stuckFinalizerGracePeriod = tc.gracePeriod
+			c.patrollerDryRun = tc.dryRun
+			orphan := makeStorageClass("sc", "12345", managed, terminating)
+			tenantClientset := fake.NewSimpleClientset(orphan)
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, tenantClientset, fakeClient.NewFakeClient(orphan))
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+
+			if _, ok := c.checkStorageClassOfTenantCluster(context.TODO(), clusterName); !ok {
+				t.Fatalf("expected the cluster to be checked")
+			}
+			if c.numStuckTerminatingStorageClasses != 1 {
+				t.Errorf("expected 1 stuck terminating storageclass, got %d", c.numStuckTerminatingStorageClasses)
+			}
+			var verbs []string
+			for _, action := range tenantClientset.Actions() {
+				if action.GetResource().Resource == "storageclasses" {
+					verbs = append(verbs, action.GetVerb())
+				}
+			}
+			if !equality.Semantic.DeepEqual(verbs, tc.expectedActions) {
+				t.Errorf("expected tenant storageclass actions %v, got %v", tc.expectedActions, verbs)
+			}
+		})
+	}
+}
+
+func TestStorageClassPatrolSweepSummary(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+
+	c, _ := newFakeController(t, testTenant,
+		makeStorageClass("sc1", "1", public),
+		makeStorageClass("sc2", "2", public))
+	c.maxDeletePercentPerPass = 100
+	if _, ok := c.LastSweepSummary(); ok {
+		t.Fatalf("expected no sweep summary before the first sweep")
+	}
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient(
+		makeStorageClass("sc2", "22", managed, func(class *v1.StorageClass) {
+			class.Provisioner = "p2"
+		}),
+		makeStorageClass("sc3", "33", managed)))
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	c.PatrollerDo(context.TODO())
+	summary, ok := c.LastSweepSummary()
+	if !ok {
+		t.Fatalf("expected a sweep summary once the sweep completes")
+	}
+	// sc1 is missing and sc2 differs, both are requeued. sc3 is an orphan.
+	expected := pa.SweepSummary{
+		Resource:           "storageclass",
+		Clusters:           1,
+		MismatchedClusters: 1,
+		Mismatched:         1,
+		DeletedOrphans:     1,
+		Requeued:           2,
+	}
+	summary.StartTime, summary.EndTime = time.Time{}, time.Time{}
+	if summary != expected {
+		t.Errorf("expected sweep summary %+v, got %+v", expected, summary)
+	}
+
+	clusterName := conversion.ToClusterKey(testTenant)
+	if clusters := c.ClustersMissingObject("storageclass", "sc1"); !equality.Semantic.DeepEqual(clusters, []string{clusterName}) {
+		t.Errorf("expected sc1 to be missing in cluster %s, got %v", clusterName, clusters)
+	}
+	if clusters := c.ClustersMissingObject("storageclass", "sc2"); len(clusters) != 0 {
+		t.Errorf("expected sc2 not to be missing, got %v", clusters)
+	}
+	if clusters := c.ClustersMissingObject("ingressclass", "sc1"); clusters != nil {
+		t.Errorf("expected no clusters reported for other resources, got %v", clusters)
+	}
+}
+
+func TestStorageClassPatrolPaused(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	c, tenantCluster := newFakeController(t, testTenant, makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}))
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	requeued := func() float64 {
+		return testutil.ToFloat64(metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterStorageClasses"))
+	}
+
+	pa.PausePatrol(clusterName)
+	defer pa.ResumePatrol(clusterName)
+	before := requeued()
+	c.PatrollerDo(context.TODO())
+	if v := requeued() - before; v != 0 {
+		t.Errorf("expected nothing requeued for the paused cluster, got %v", v)
+	}
+
+	pa.ResumePatrol(clusterName)
+	c.PatrollerDo(context.TODO())
+	if v := requeued() - before; v != 1 {
+		t.Errorf("expected the missing storageclass requeued once the cluster is resumed, got %v", v)
+	}
+}
+
+func TestStorageClassPatrolSyncLag(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+	changed := metav1.NewTime(time.Now().Add(-time.Hour))
+	c, tenantCluster := newFakeController(t, testTenant, makeStorageClass("sc", "12345", public, func(class *v1.StorageClass) {
+		class.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl", Time: &changed}}
+	}))
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+	key := clusterName + "/sc"
+	metrics.SyncLagSeconds.Reset()
+
+	// the storageclass found missing by the patroller lags since its last change in super master.
+	c.resetRequeued()
+	c.requeueMissingStorageClasses([]string{clusterName})
+	if lag := c.syncLag.Lag(clusterName); lag < time.Hour {
+		t.Errorf("expected the sync lag measured from the super master change an hour ago, got %v", lag)
+	}
+	c.syncLag.Report()
+	if n := testutil.CollectAndCount(metrics.SyncLagSeconds); n != 1 {
+		t.Fatalf("expected the sync lag of the requeued storageclass to be reported, got %d series", n)
+	}
+
+	if err := c.BackPopulate(key); err != nil {
+		t.Fatalf("unexpected error back populating %s: %v", key, err)
+	}
+	if lag := c.syncLag.Lag(clusterName); lag != 0 {
+		t.Errorf("expected no sync lag once %s is back populated, got %v", key, lag)
+	}
+	c.syncLag.Report()
+	if n := testutil.CollectAndCount(metrics.SyncLagSeconds); n != 0 {
+		t.Errorf("expected the sync lag to be reset, got %d series", n)
+	}
+
+	// the storageclass fanned out by a super master event lags since the event.
+	c.fanOutStorageClass([]string{key})
+	if lag := c.syncLag.Lag(clusterName); lag >= time.Hour {
+		t.Errorf("expected the sync lag measured from the super master event, got %v", lag)
+	}
+	c.syncLag.Report()
+	if n := testutil.CollectAndCount(metrics.SyncLagSeconds); n != 1 {
+		t.Errorf("expected the sync lag of the fanned out storageclass to be reported, got %d series", n)
+	}
+}
+
+func TestStorageClassPatrolSuperTopology(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+	zoned := func(class *v1.StorageClass) {
+		class.AllowedTopologies = []corev1.TopologySelectorTerm{{
+			MatchLabelExpressions: []corev1.TopologySelectorLabelRequirement{{Key: "topology.kubernetes.io/zone", Values: []string{"zone-a"}}},
+		}}
+	}
+	waitForFirstConsumer := func(class *v1.StorageClass) {
+		mode := v1.VolumeBindingWaitForFirstConsumer
+		class.VolumeBindingMode = &mode
+	}
+
+	for _, tc := range []struct {
+		name     string
+		policy   config.TopologyPolicy
+		expected float64
+	}{
+		{name: "keep topologies", policy: config.TopologyKeep, expected: 2},
+		{name: "drop topologies", policy: config.TopologyDrop, expected: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, tenantCluster := newFakeController(t, testTenant,
+				makeStorageClass("plain", "1", public),
+				makeStorageClass("zoned", "2", public, zoned),
+				makeStorageClass("late", "3", public, waitForFirstConsumer),
+			)
+			c.Config.StorageClassTopologyPolicy = tc.policy
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+
+			c.PatrollerDo(context.TODO())
+			if v := testutil.ToFloat64(metrics.CheckerSuperTopology.WithLabelValues("StorageClass")); v != tc.expected {
+				t.Errorf("expected %v storageclasses bound to super cluster topologies, got %v", tc.expected, v)
+			}
+		})
+	}
+}
+
+func TestStorageClassWatchFanOut(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+
+	superClient := fake.NewSimpleClientset()
+	superInformer := informers.NewSharedInformerFactory(superClient, 0)
+	r, err := NewStorageClassController(&config.SyncerConfiguration{}, superClient, superInformer, nil, nil, manager.ResourceSyncerOptions{IsFake: true})
+	if err != nil {
+		t.Fatalf("error creating controller: %v", err)
+	}
+	c := r.(*controller)
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient())
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	c.GetListener().AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	stop := make(chan struct{})
+	defer close(stop)
+	superInformer.Start(stop)
+	superInformer.WaitForCacheSync(stop)
+
+	c.resetRequeued()
+	if _, err := superClient.StorageV1().StorageClasses().Create(context.TODO(), makeStorageClass("sc", "12345", public), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unexpected error creating storageclass: %v", err)
+	}
+	if _, err := superClient.StorageV1().StorageClasses().Create(context.TODO(), makeStorageClass("private", "23456"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unexpected error creating storageclass: %v", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		_, err := c.storageclassLister.Get("private")
+		return err == nil && c.UpwardController.Queue.Len() > 0, nil
+	}); err != nil {
+		t.Fatalf("storageclasses not fanned out: %v", err)
+	}
+
+	if n := c.UpwardController.Queue.Len(); n != 1 {
+		t.Fatalf("expected the public storageclass to be fanned out to the tenant cluster, got %d queued keys", n)
+	}
+	key, _ := c.UpwardController.Queue.Get()
+	if key != clusterName+"/sc" {
+		t.Errorf("expected key %s/sc to be queued, got %v", clusterName, key)
+	}
+
+	// the checker does not requeue the key fanned out in the same sweep.
+	c.requeueMissingStorageClasses([]string{clusterName})
+	if n := c.patrolRequeueLimiter.NumRequeues(clusterName + "/sc"); n != 0 {
+		t.Errorf("expected the fanned out storageclass not to be requeued by the checker, got %d requeues", n)
+	}
+}
+
+// removingClient removes the tenant cluster from the controller while a storageclass is read from it.
+type removingClient struct {
+	client.Client
+	remove func()
+}
+
+func (r *removingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	r.remove()
+	return r.Client.Get(ctx, key, obj)
+}
+
+func TestStorageClassPatrolClusterRemovedBeforeRequeue(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	c, _ := newFakeController(t, testTenant, makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}))
+	l := c.GetListener()
+	tenantClient := &removingClient{Client: fakeClient.NewFakeClient()}
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), tenantClient)
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	tenantClient.remove = func() { l.RemoveCluster(tenantCluster) }
+	l.AddCluster(tenantCluster)
+	c.addClusterContext(tenantCluster.GetClusterName())
+
+	// the storageclass is found missing in the cluster, which is removed right before it is requeued.
+	requeued := testutil.ToFloat64(metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterStorageClasses"))
+	c.resetRequeued()
+	c.requeueMissingStorageClasses([]string{clusterName})
+
+	if n := c.patrolRequeueLimiter.NumRequeues(clusterName + "/sc"); n != 0 {
+		t.Errorf("expected nothing requeued for the removed cluster, got %d requeues", n)
+	}
+	if v := testutil.ToFloat64(metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterStorageClasses")); v != requeued {
+		t.Errorf("expected no remedy recorded for the removed cluster, got %v", v-requeued)
+	}
+	if lag := c.syncLag.Lag(clusterName); lag != 0 {
+		t.Errorf("expected no sync lag tracked for the removed cluster, got %v", lag)
+	}
+
+	// a key queued before the cluster is removed is dropped by the upward controller.
+	if err := c.BackPopulate(clusterName + "/sc"); !utilerrors.IsClusterNotFound(err) {
+		t.Errorf("expected a cluster not found error back populating for the removed cluster, got %v", err)
+	}
+}
+
+// listGate tracks the storageclass lists issued to the tenant clusters, which are blocked until it is released.
+type listGate struct {
+	inflight int32
+	peak     int32
+	release  chan struct{}
+}
+
+// gatedClient blocks the storageclass lists of a tenant cluster on the gate.
+type gatedClient struct {
+	client.Client
+	gate   *listGate
+	listed int32
+}
+
+func (g *gatedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*v1.StorageClassList); ok {
+		n := atomic.AddInt32(&g.gate.inflight, 1)
+		for {
+			peak := atomic.LoadInt32(&g.gate.peak)
+			if n <= peak || atomic.CompareAndSwapInt32(&g.gate.peak, peak, n) {
+				break
+			}
+		}
+		<-g.gate.release
+		atomic.AddInt32(&g.gate.inflight, -1)
+		atomic.AddInt32(&g.listed, 1)
+	}
+	return g.Client.List(ctx, list, opts...)
+}
+
+func TestStorageClassPatrolConcurrency(t *testing.T) {
+	newTenant := func(i int) *v1alpha1.VirtualCluster {
+		return &v1alpha1.VirtualCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("test-%d", i),
+				Namespace: "tenant-1",
+				UID:       types.UID(fmt.Sprintf("7374a172-c35d-45b1-9c8e-bf5c5b61493%d", i)),
+			},
+		}
+	}
+	const numClusters, concurrency = 5, 2
+
+	c, _ := newFakeController(t, newTenant(0))
+	c.patrolConcurrency = concurrency
+	gate := &listGate{release: make(chan struct{})}
+	var tenantClients []*gatedClient
+	for i := 0; i < numClusters; i++ {
+		tenantClient := &gatedClient{Client: fakeClient.NewFakeClient(), gate: gate}
+		tenantClients = append(tenantClients, tenantClient)
+		tenantCluster, err := cluster.NewFakeTenantCluster(newTenant(i), fake.NewSimpleClientset(), tenantClient)
+		if err != nil {
+			t.Fatalf("error creating tenant cluster: %v", err)
+		}
+		c.GetListener().AddCluster(tenantCluster)
+		c.addClusterContext(tenantCluster.GetClusterName())
+	}
+	atomic.StoreUint64(&c.numMissMatchedStorageClasses, 3)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.PatrollerDo(context.TODO())
+	}()
+
+	if err := wait.PollImmediate(time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return atomic.LoadInt32(&gate.inflight) == concurrency, nil
+	}); err != nil {
+		t.Fatalf("expected %d clusters checked in parallel, got %d", concurrency, atomic.LoadInt32(&gate.inflight))
+	}
+	if n := atomic.LoadUint64(&c.numMissMatchedStorageClasses); n != 0 {
+		t.Errorf("expected the mismatched storageclasses reset before the clusters are checked, got %d", n)
+	}
+
+	// no more clusters are checked until the ones in flight are done, nor does the patrol return.
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&gate.inflight); n != concurrency {
+		t.Errorf("expected %d clusters checked in parallel, got %d", concurrency, n)
+	}
+	select {
+	case <-done:
+		t.Fatalf("expected the patrol to wait for the clusters in flight")
+	default:
+	}
+
+	close(gate.release)
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("expected the patrol to return once the clusters are checked")
+	}
+	for i, tenantClient := range tenantClients {
+		if atomic.LoadInt32(&tenantClient.listed) == 0 {
+			t.Errorf("expected cluster %d checked before the patrol returns", i)
+		}
+	}
+	if n := atomic.LoadInt32(&gate.peak); n != concurrency {
+		t.Errorf("expected at most %d clusters checked in parallel, got %d", concurrency, n)
+	}
+}
+
+// unsyncedCluster is a tenant cluster whose informer caches are still syncing.
+type unsyncedCluster struct {
+	mc.ClusterInterface
+}
+
+func (u *unsyncedCluster) Synced() bool {
+	return false
+}
+
+// countingClient counts the storageclass lists issued to a tenant cluster.
+type countingClient struct {
+	client.Client
+	listed int32
+}
+
+func (l *countingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*v1.StorageClassList); ok {
+		atomic.AddInt32(&l.listed, 1)
+	}
+	return l.Client.List(ctx, list, opts...)
+}
+
+func TestStorageClassPatrolClusterNotReady(t *testing.T) {
+	newTenant := func(name string) *v1alpha1.VirtualCluster {
+		return &v1alpha1.VirtualCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "tenant-1",
+				UID:       types.UID("7374a172-c35d-45b1-9c8e-bf5c5b61493" + name),
+			},
+		}
+	}
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+
+	c, _ := newFakeController(t, newTenant("a"), makeStorageClass("sc", "12345", public))
+	c.maxDeletePercentPerPass = 100
+
+	// both tenants have an orphan and miss the public storageclass, tenant b is still bootstrapping.
+	tenantClients := map[string]*fake.Clientset{}
+	listClients := map[string]*countingClient{}
+	clusterNames := map[string]string{}
+	for _, name := range []string{"a", "b"} {
+		vc := newTenant(name)
+		tenantClients[name] = fake.NewSimpleClientset(makeStorageClass("orphan", "11", managed))
+		listClients[name] = &countingClient{Client: fakeClient.NewFakeClient(makeStorageClass("orphan", "11", managed))}
+		tenantCluster, err := cluster.NewFakeTenantCluster(vc, tenantClients[name], listClients[name])
+		if err != nil {
+			t.Fatalf("error creating tenant cluster: %v", err)
+		}
+		if name == "b" {
+			c.GetListener().AddCluster(&unsyncedCluster{ClusterInterface: tenantCluster})
+			c.addClusterContext(tenantCluster.GetClusterName())
+		} else {
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+		}
+		clusterNames[name] = conversion.ToClusterKey(vc)
+	}
+
+	c.PatrollerDo(context.TODO())
+
+	deleted := func(name string) []string {
+		var names []string
+		for _, action := range tenantClients[name].Actions() {
+			if action.Matches("delete", "storageclasses") {
+				names = append(names, action.(core.DeleteAction).GetName())
+			}
+		}
+		return names
+	}
+	for name, expected := range map[string][]string{"a": {"orphan"}, "b": nil} {
+		if got := deleted(name); !equality.Semantic.DeepEqual(got, expected) {
+			t.Errorf("expected storageclasses %v deleted in tenant %s, got %v", expected, name, got)
+		}
+	}
+	if n := atomic.LoadInt32(&listClients["a"].listed); n == 0 {
+		t.Errorf("expected the storageclasses of the ready tenant a to be listed")
+	}
+	if n := atomic.LoadInt32(&listClients["b"].listed); n != 0 {
+		t.Errorf("expected the storageclasses of the bootstrapping tenant b not to be listed, got %d lists", n)
+	}
+	if n := c.patrolRequeueLimiter.NumRequeues(clusterNames["a"] + "/sc"); n != 1 {
+		t.Errorf("expected the missing storageclass of tenant a to be requeued once, got %d requeues", n)
+	}
+	if n := c.patrolRequeueLimiter.NumRequeues(clusterNames["b"] + "/sc"); n != 0 {
+		t.Errorf("expected the missing storageclass of tenant b not to be requeued, got %d requeues", n)
+	}
+	if v := testutil.ToFloat64(metrics.CheckerSkippedClusters.WithLabelValues("StorageClass")); v != 1 {
+		t.Errorf("expected 1 skipped cluster, got %v", v)
+	}
+}
+
+func TestStorageClassPatrolBulkOrphanDeletion(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+	gvr := v1.SchemeGroupVersion.WithResource("storageclasses")
+	gvk := v1.SchemeGroupVersion.WithKind("StorageClass")
+
+	testcases := map[string]struct {
+		// createdAfterCheck is a managed storageclass created in the tenant master after the orphans are found.
+		createdAfterCheck     *v1.StorageClass
+		expectedBulkDeletes   int
+		expectedSingleDeletes int
+	}{
+		"orphans deleted in bulk": {
+			expectedBulkDeletes: 1,
+		},
+		"selector matching a storageclass created after the check": {
+			createdAfterCheck:     makeStorageClass("new", "4", managed),
+			expectedSingleDeletes: 2,
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c, _ := newFakeController(t, testTenant, makeStorageClass("kept", "1", public))
+			c.Config.StorageClassBulkOrphanDeletion = true
+			c.maxDeletePercentPerPass = 100
+			objs := []runtime.Object{
+				makeStorageClass("kept", "1", managed),
+				makeStorageClass("orphan-1", "2", managed),
+				makeStorageClass("orphan-2", "3", managed),
+			}
+			tenantClientset := fake.NewSimpleClientset(objs...)
+			// the fake clientset ignores field selectors, filter the storageclasses like the apiserver.
+			matching := func(restrictions core.ListRestrictions) []v1.StorageClass {
+				obj, err := tenantClientset.Tracker().List(gvr, gvk, "")
+				if err != nil {
+					t.Fatalf("unexpected error listing storageclasses: %v", err)
+				}
+				var items []v1.StorageClass
+				for _, item := range obj.(*v1.StorageClassList).Items {
+					if restrictions.Labels.Matches(labels.Set(item.Labels)) && restrictions.Fields.Matches(fields.Set{"metadata.name": item.Name}) {
+						items = append(items, item)
+					}
+				}
+				return items
+			}
+			lists := 0
+			tenantClientset.PrependReactor("list", "storageclasses", func(action core.Action) (bool, runtime.Object, error) {
+				lists++
+				// the storageclass shows up after the exclusions are collected, right before the verification.
+				if lists == 2 && tc.createdAfterCheck != nil {
+					if err := tenantClientset.Tracker().Add(tc.createdAfterCheck); err != nil {
+						t.Fatalf("unexpected error creating storageclass: %v", err)
+					}
+				}
+				return true, &v1.StorageClassList{Items: matching(action.(core.ListAction).GetListRestrictions())}, nil
+			})
+			tenantClientset.PrependReactor("delete-collection", "storageclasses", func(action core.Action) (bool, runtime.Object, error) {
+				for _, item := range matching(action.(core.DeleteCollectionAction).GetListRestrictions()) {
+					if err := tenantClientset.Tracker().Delete(gvr, "", item.Name); err != nil {
+						return true, nil, err
+					}
+				}
+				return true, nil, nil
+			})
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, tenantClientset, fakeClient.NewFakeClient(objs...))
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+
+			c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+
+			bulkDeletes, singleDeletes := 0, 0
+			for _, action := range tenantClientset.Actions() {
+				switch {
+				case action.Matches("delete-collection", "storageclasses"):
+					bulkDeletes++
+				case action.Matches("delete", "storageclasses"):
+					singleDeletes++
+				}
+			}
+			if bulkDeletes != tc.expectedBulkDeletes || singleDeletes != tc.expectedSingleDeletes {
+				t.Errorf("expected %d bulk and %d single deletions, got %d and %d", tc.expectedBulkDeletes, tc.expectedSingleDeletes, bulkDeletes, singleDeletes)
+			}
+			remaining := sets.NewString()
+			for _, item := range matching(core.ListRestrictions{Labels: labels.Everything(), Fields: fields.Everything()}) {
+				remaining.Insert(item.Name)
+			}
+			expected := sets.NewString("kept")
+			if tc.createdAfterCheck != nil {
+				expected.Insert(tc.createdAfterCheck.Name)
+			}
+			if !remaining.Equal(expected) {
+				t.Errorf("expected storageclasses %v left in the tenant master, got %v", expected.List(), remaining.List())
+			}
+		})
+	}
+}
+
+// fakeTenantStorageClasses is a tenant storageclass client which records the deletions and fails them with deleteErr.
+type fakeTenantStorageClasses struct {
+	sync.Mutex
+	deleteErr error
+	deleted   []string
+}
+
+func (f *fakeTenantStorageClasses) List(ctx context.Context, opts metav1.ListOptions) (*v1.StorageClassList, error) {
+	return &v1.StorageClassList{}, nil
+}
+
+func (f *fakeTenantStorageClasses) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	f.Lock()
+	defer f.Unlock()
+	f.deleted = append(f.deleted, name)
+	return f.deleteErr
+}
+
+func (f *fakeTenantStorageClasses) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	return fmt.Errorf("bulk deletion is not supported")
+}
+
+func (f *fakeTenantStorageClasses) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*v1.StorageClass, error) {
+	return nil, fmt.Errorf("patch is not supported")
+}
+
+func TestStorageClassPatrolTenantClient(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	defer func(backoff wait.Backoff) { orphanDeleteBackoff = backoff }(orphanDeleteBackoff)
+	orphanDeleteBackoff.Duration = time.Millisecond
+	var retried []string
+	for i := 0; i < constants.DefaultPatrolDeleteAttempts; i++ {
+		retried = append(retried, "sc")
+	}
+
+	testcases := map[string]struct {
+		existingObjectInTenant []runtime.Object
+		clientErr              error
+		deleteErr              error
+		expectedDeleted        []string
+		expectedRemedies       float64
+		expectedRequeues       int
+	}{
+		"orphan is deleted": {
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "12345", managed)},
+			expectedDeleted:        []string{"sc"},
+			expectedRemedies:       1,
+		},
+		"orphan already gone": {
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "12345", managed)},
+			deleteErr:              errors.NewNotFound(v1.Resource("storageclasses"), "sc"),
+			expectedDeleted:        []string{"sc"},
+			expectedRemedies:       1,
+		},
+		"orphan fails to be deleted": {
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "12345", managed)},
+			deleteErr:              fmt.Errorf("tenant apiserver is unavailable"),
+			expectedDeleted:        retried,
+			expectedRequeues:       1,
+		},
+		"tenant client unavailable": {
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "12345", managed)},
+			clientErr:              fmt.Errorf("invalid kubeconfig"),
+		},
+		"orphan not managed by syncer": {
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "12345")},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c, _ := newFakeController(t, testTenant)
+			tenantClient := &fakeTenantStorageClasses{deleteErr: tc.deleteErr}
+			c.tenantStorageClasses = func(string) (tenantStorageClassClient, error) {
+				if tc.clientErr != nil {
+					return nil, tc.clientErr
+				}
+				return tenantClient, nil
+			}
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient(tc.existingObjectInTenant...))
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+			remedies := testutil.ToFloat64(metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanTenantStorageClasses"))
+
+			c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+
+			if !equality.Semantic.DeepEqual(tenantClient.deleted, tc.expectedDeleted) {
+				t.Errorf("expected deletions %v, got %v", tc.expectedDeleted, tenantClient.deleted)
+			}
+			if v := testutil.ToFloat64(metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanTenantStorageClasses")) - remedies; v != tc.expectedRemedies {
+				t.Errorf("expected %v orphan deletion remedies, got %v", tc.expectedRemedies, v)
+			}
+			if requeues := c.orphanCleanupQueue.NumRequeues(clusterName + "/sc"); requeues != tc.expectedRequeues {
+				t.Errorf("expected %d requeues to the cleanup queue, got %d", tc.expectedRequeues, requeues)
+			}
+		})
+	}
+}
+
+func TestStorageClassPatrolImmutableFieldChange(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+	retain := corev1.PersistentVolumeReclaimRetain
+	drifted := func(names ...string) []*v1.StorageClass {
+		var classes []*v1.StorageClass
+		for _, name := range names {
+			classes = append(classes, makeStorageClass(name, "12345", public, func(class *v1.StorageClass) {
+				class.Provisioner = "p2"
+			}))
+		}
+		return classes
+	}
+
+	testcases := map[string]struct {
+		existingObjectInSuper  []*v1.StorageClass
+		existingObjectInTenant []runtime.Object
+		disabled               bool
+		dryRun                 bool
+		expectedDeleted        []string
+		expectedRequeued       bool
+		expectedAborted        float64
+	}{
+		"provisioner changed": {
+			existingObjectInSuper:  drifted("sc"),
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "123456", managed)},
+			expectedDeleted:        []string{"sc"},
+			expectedRequeued:       true,
+		},
+		"parameters changed": {
+			existingObjectInSuper: []*v1.StorageClass{makeStorageClass("sc", "12345", public, func(class *v1.StorageClass) {
+				class.Parameters = map[string]string{"type": "b"}
+			})},
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "123456", managed)},
+			expectedDeleted:        []string{"sc"},
+			expectedRequeued:       true,
+		},
+		"reclaim policy changed": {
+			existingObjectInSuper: []*v1.StorageClass{makeStorageClass("sc", "12345", public, func(class *v1.StorageClass) {
+				class.ReclaimPolicy = &retain
+			})},
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "123456", managed)},
+			expectedDeleted:        []string{"sc"},
+			expectedRequeued:       true,
+		},
+		"mutable field changed": {
+			existingObjectInSuper: []*v1.StorageClass{makeStorageClass("sc", "12345", public, func(class *v1.StorageClass) {
+				class.MountOptions = []string{"debug"}
+			})},
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "123456", managed)},
+			expectedRequeued:       true,
+		},
+		"recreation disabled": {
+			existingObjectInSuper:  drifted("sc"),
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "123456", managed)},
+			disabled:               true,
+			expectedRequeued:       true,
+		},
+		"protected": {
+			existingObjectInSuper:  drifted("sc"),
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "123456", managed, protected)},
+		},
+		"dry run": {
+			existingObjectInSuper:  drifted("sc"),
+			existingObjectInTenant: []runtime.Object{makeStorageClass("sc", "123456", managed)},
+			dryRun:                 true,
+			expectedRequeued:       true,
+		},
+		"recreations beyond the delete limit": {
+			existingObjectInSuper: drifted("sc0", "sc1", "sc2", "sc3"),
+			existingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc0", "0", managed),
+				makeStorageClass("sc1", "1", managed),
+				makeStorageClass("sc2", "2", managed),
+				makeStorageClass("sc3", "3", managed),
+			},
+			expectedAborted: 1,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c, _ := newFakeController(t, testTenant, tc.existingObjectInSuper...)
+			c.Config.RecreateStorageClassOnImmutableChange = !tc.disabled
+			c.patrollerDryRun = tc.dryRun
+			tenantClient := &fakeTenantStorageClasses{}
+			c.tenantStorageClasses = func(string) (tenantStorageClassClient, error) {
+				return tenantClient, nil
+			}
+			tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient(tc.existingObjectInTenant...))
+			if err != nil {
+				t.Fatalf("error creating tenant cluster: %v", err)
+			}
+			c.GetListener().AddCluster(tenantCluster)
+			c.addClusterContext(tenantCluster.GetClusterName())
+			c.resetRequeued()
+			recreated := testutil.ToFloat64(metrics.CheckerRecreatedObjects.WithLabelValues("StorageClass"))
+			aborted := testutil.ToFloat64(metrics.CheckerAbortedDestructive.WithLabelValues("StorageClass"))
+
+			c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+
+			if !equality.Semantic.DeepEqual(tenantClient.deleted, tc.expectedDeleted) {
+				t.Errorf("expected deletions %v, got %v", tc.expectedDeleted, tenantClient.deleted)
+			}
+			if v := testutil.ToFloat64(metrics.CheckerRecreatedObjects.WithLabelValues("StorageClass")) - recreated; v != float64(len(tc.expectedDeleted)) {
+				t.Errorf("expected %d recreated storageclasses, got %v", len(tc.expectedDeleted), v)
+			}
+			if v := testutil.ToFloat64(metrics.CheckerAbortedDestructive.WithLabelValues("StorageClass")) - aborted; v != tc.expectedAborted {
+				t.Errorf("expected %v aborted passes, got %v", tc.expectedAborted, v)
+			}
+			if requeued := c.markRequeued(clusterName + "/sc"); requeued == tc.expectedRequeued {
+				t.Errorf("expected storageclass requeued to be %v", tc.expectedRequeued)
+			}
+		})
+	}
+}
+
+// patrolRounds is a patrol reconciler which records the rounds run.
+type patrolRounds chan struct{}
+
+func (r patrolRounds) PatrollerDo(context.Context) {
+	r <- struct{}{}
+}
+
+func TestStorageClassPatrolOnRelist(t *testing.T) {
+	withResourceVersion := func(rv string) func(*v1.StorageClass) {
+		return func(class *v1.StorageClass) {
+			class.ResourceVersion = rv
+		}
+	}
+
+	testcases := map[string]struct {
+		patrolOnRelist bool
+		expectedRounds int
+	}{
+		"relist triggers a round": {
+			patrolOnRelist: true,
+			expectedRounds: 1,
+		},
+		"relist is ignored": {
+			patrolOnRelist: false,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			superClient := fake.NewSimpleClientset(makeStorageClass("sc", "12345", withResourceVersion("1")))
+			watchers := make(chan *watch.FakeWatcher, 10)
+			superClient.PrependWatchReactor("storageclasses", func(core.Action) (bool, watch.Interface, error) {
+				w := watch.NewFake()
+				watchers <- w
+				return true, w, nil
+			})
+			superInformer := informers.NewSharedInformerFactory(superClient, 0)
+			r, err := NewStorageClassController(&config.SyncerConfiguration{}, superClient, superInformer, nil, nil, manager.ResourceSyncerOptions{
+				IsFake:         true,
+				PatrolOnRelist: tc.patrolOnRelist,
+			})
+			if err != nil {
+				t.Fatalf("error creating controller: %v", err)
+			}
+			c := r.(*controller)
+			rounds := make(patrolRounds, 10)
+			c.Patroller.Reconciler = rounds
+			c.Patroller.Period = time.Hour
+
+			stop := make(chan struct{})
+			defer close(stop)
+			superInformer.Start(stop)
+			superInformer.WaitForCacheSync(stop)
+			go c.Patroller.Start(stop)
+
+			expectRounds := func(expected int) {
+				t.Helper()
+				for i := 0; i < expected; i++ {
+					select {
+					case <-rounds:
+					case <-time.After(wait.ForeverTestTimeout):
+						t.Fatalf("expected %d rounds, got %d", expected, i)
+					}
+				}
+				select {
+				case <-rounds:
+					t.Errorf("expected no more than %d rounds", expected)
+				case <-time.After(100 * time.Millisecond):
+				}
+			}
+			expectRounds(1)
+
+			// an update observed by the watch does not trigger a round.
+			w := <-watchers
+			updated := makeStorageClass("sc", "12345", withResourceVersion("2"))
+			if err := superClient.Tracker().Update(v1.SchemeGroupVersion.WithResource("storageclasses"), updated, ""); err != nil {
+				t.Fatalf("error updating storageclass: %v", err)
+			}
+			w.Modify(updated)
+			if err := wait.PollImmediate(time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+				class, err := c.storageclassLister.Get("sc")
+				return err == nil && class.ResourceVersion == "2", nil
+			}); err != nil {
+				t.Fatalf("expected the update to be observed: %v", err)
+			}
+			expectRounds(0)
+
+			// the watch expires, the informer relists the storageclasses with the same resource version.
+			lists := make(chan struct{}, 10)
+			superClient.PrependReactor("list", "storageclasses", func(core.Action) (bool, runtime.Object, error) {
+				lists <- struct{}{}
+				return false, nil, nil
+			})
+			c.setClusterReconciled("cluster", map[string]reconciledVersions{"sc": {}})
+			w.Error(&metav1.Status{Status: metav1.StatusFailure, Code: 410, Reason: metav1.StatusReasonExpired})
+			select {
+			case <-lists:
+			case <-time.After(wait.ForeverTestTimeout):
+				t.Fatalf("expected the informer to relist")
+			}
+			// the relist is observed once the consistent storageclasses are forgotten.
+			if err := wait.PollImmediate(time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+				c.reconciledLock.Lock()
+				defer c.reconciledLock.Unlock()
+				return len(c.reconciled) == 0, nil
+			}); err != nil {
+				t.Fatalf("expected the relist to be observed: %v", err)
+			}
+			expectRounds(tc.expectedRounds)
+		})
+	}
+}
+
+// blockingTenantStorageClasses is a tenant storageclass client whose deletions block until their context is done.
+type blockingTenantStorageClasses struct {
+	fakeTenantStorageClasses
+	started chan struct{}
+	errs    chan error
+}
+
+func (b *blockingTenantStorageClasses) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	select {
+	case b.errs <- ctx.Err():
+	default:
+	}
+	return ctx.Err()
+}
+
+func TestStorageClassPatrolCancelledOnClusterRemoval(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+
+	c, _ := newFakeController(t, testTenant)
+	tenantClient := &blockingTenantStorageClasses{started: make(chan struct{}, 1), errs: make(chan error, 1)}
+	c.tenantStorageClasses = func(string) (tenantStorageClassClient, error) {
+		return tenantClient, nil
+	}
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient(makeStorageClass("sc", "12345", managed)))
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	l := c.GetListener()
+	l.AddCluster(tenantCluster)
+	l.WatchCluster(tenantCluster)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	}()
+
+	select {
+	case <-tenantClient.started:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("expected the orphan storageclass to be deleted")
+	}
+	l.RemoveCluster(tenantCluster)
+
+	select {
+	case err := <-tenantClient.errs:
+		if err != context.Canceled {
+			t.Errorf("expected the in-flight deletion to be cancelled, got %v", err)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("expected the in-flight deletion to be aborted once the cluster is removed")
+	}
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("expected the patrol of the removed cluster to return")
+	}
+
+	// the patrol of a cluster no longer watched issues no tenant request.
+	c.checkStorageClassOfTenantCluster(context.TODO(), clusterName)
+	select {
+	case <-tenantClient.started:
+		t.Errorf("expected no deletion in the removed cluster")
+	default:
+	}
+}
@@ -17,25 +17,43 @@ limitations under the License.
 package storageclass
 
 import (
+	"context"
 	"fmt"
+	"path"
+	"sync"
+	"time"
 
 	v1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/informers"
 	storageinformers "k8s.io/client-go/informers/storage/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	v1storage "k8s.io/client-go/kubernetes/typed/storage/v1"
 	listersv1 "k8s.io/client-go/listers/storage/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	utilerrors "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -44,19 +62,129 @@ func init() {
 	plugin.SyncerResourceRegister.Register(&plugin.Registration{
 		ID: "storageclass",
 		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
-			return NewStorageClassController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+			options := manager.ResourceSyncerOptionsFromConfig(ctx.Config.(*config.SyncerConfiguration))
+			options.ExtraSuperClusters = ctx.ExtraSuperClusters
+			return NewStorageClassController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, options)
 		},
 	})
 }
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// apiAbsent indicates that super master does not serve storage.k8s.io/v1 storageclasses, e.g., the storage
+	// API group is disabled, in which case the syncer disables itself rather than failing the whole syncer.
+	apiAbsent bool
 	// super master storageclasses client
 	client v1storage.StorageClassesGetter
 	// super master storageclasses informer/lister/synced functions
 	informer           storageinformers.Interface
 	storageclassLister listersv1.StorageClassLister
+	storageclassStore  cache.Store
 	storageclassSynced cache.InformerSynced
+	// extraSupers are the super masters other than the default one the tenants may be mapped to, keyed by id.
+	extraSupers map[string]*superSource
+	// vcClient updates the StorageClassSynced condition of the virtualclusters.
+	vcClient vcclient.Interface
+	// patrollerDryRun indicates that the patroller only logs the remediation it would take.
+	patrollerDryRun bool
+	// patrolConcurrency is the max number of tenant clusters checked in parallel.
+	patrolConcurrency int
+	// patrolOpTimeout is the timeout of each tenant operation issued by the patroller.
+	patrolOpTimeout time.Duration
+	// patrolListChunkSize is the number of super master storageclasses the patroller fetches at a time.
+	patrolListChunkSize int
+	// patrolRequeueLimiter backs off the keys requeued by the patroller, it is reset once the patroller
+	// finds the tenant storageclass consistent.
+	patrolRequeueLimiter workqueue.RateLimiter
+	// requeuedKeys records the keys requeued by the patroller in the current sweep, guarded by requeuedLock.
+	requeuedLock sync.Mutex
+	requeuedKeys sets.String
+	// syncLag tracks the keys changed in super master, by an event or found by the patroller, until they are back populated.
+	syncLag *pa.SyncLag
+	// numSuperTopologyStorageClasses is the number of public storageclasses synced bound to super cluster topologies
+	// found in the last patrol.
+	numSuperTopologyStorageClasses uint64
+	// numMissMatchedStorageClasses is the number of mismatched storageclasses found in the last patrol.
+	numMissMatchedStorageClasses uint64
+	// numUnmanagedStorageClasses is the number of orphan tenant storageclasses not managed by syncer found in the last patrol.
+	numUnmanagedStorageClasses uint64
+	// numStuckTerminatingStorageClasses is the number of syncer managed tenant storageclasses found terminating in the last patrol.
+	numStuckTerminatingStorageClasses uint64
+	// numCorruptStorageClasses is the number of syncer managed tenant storageclasses with empty required fields
+	// found in the last patrol.
+	numCorruptStorageClasses uint64
+	// numProtectedStorageClasses is the number of protected orphan tenant storageclasses found in the last patrol.
+	numProtectedStorageClasses uint64
+	// numSyncedStorageClasses is the number of tenant storageclasses consistent with super master found in the last patrol.
+	numSyncedStorageClasses uint64
+	// numPublicStorageClasses is the number of public super master storageclasses counted at the start of the
+	// last patrol, which each tenant cluster is expected to have.
+	numPublicStorageClasses uint64
+	// numDivergedStorageClasses is the number of public storageclasses without a consistent tenant copy summed over
+	// the tenant clusters in the last patrol.
+	numDivergedStorageClasses uint64
+	// patrolOnRelist indicates that a patrol round is triggered when the storageclass informer relists.
+	patrolOnRelist bool
+	// orphanTTL is the grace period before an orphan tenant storageclass is deleted.
+	orphanTTL time.Duration
+	// stuckFinalizerGracePeriod is how long a tenant storageclass may stay terminating before its finalizers
+	// are removed by the patroller, zero disables the removal.
+	stuckFinalizerGracePeriod time.Duration
+	// conflictPolicy decides how the patroller resolves the tenant storageclasses inconsistent with super master.
+	// The storageclasses of super master are shared by all tenants and mostly immutable, hence under TenantWins
+	// the tenant changes are kept in the tenant master rather than written to super master.
+	conflictPolicy manager.ConflictPolicy
+	// deletionPropagationPolicy is the propagation policy of the tenant storageclasses deleted by syncer.
+	deletionPropagationPolicy metav1.DeletionPropagation
+	// maxDeletePercentPerPass is the max percentage of the syncer managed tenant storageclasses of a cluster
+	// deleted in a single patrol pass.
+	maxDeletePercentPerPass int
+	// writeThrottle bounds the rate of the writes the patroller issues to each tenant cluster.
+	writeThrottle *pa.WriteThrottle
+	// auditSink receives the remediations taken by the patroller.
+	auditSink pa.AuditSink
+	// sweep aggregates the remediations taken by the patroller in each sweep.
+	sweep *pa.SweepRecorder
+	// orphanCleanupQueue holds the cluster/name keys of the orphan tenant storageclasses the patroller failed
+	// to delete, they are retried with backoff rather than waiting for the next patrol.
+	orphanCleanupQueue workqueue.RateLimitingInterface
+	// tenantStorageClasses returns the storageclass client the patroller writes to the tenant cluster with.
+	tenantStorageClasses func(clusterName string) (tenantStorageClassClient, error)
+	// lastSyncResourceVersion returns the resource version last observed by the super master storageclass informer.
+	lastSyncResourceVersion func() string
+	// superCacheLock guards observedResourceVersion and observedAt, which record when the super master cache
+	// last observed a new resource version.
+	superCacheLock          sync.Mutex
+	observedResourceVersion string
+	observedAt              time.Time
+	// clusterOrphanMap records when each orphan tenant storageclass was first observed, needed for delayed orphan deletion.
+	sync.Mutex
+	clusterOrphanMap map[string]map[string]time.Time
+	// clusterContexts holds a context per watched tenant cluster, which is cancelled when the cluster is removed
+	// so that the in-flight tenant requests of the removed cluster are aborted.
+	clusterContexts map[string]*clusterContext
+	// reconciled records the resource versions of the tenant storageclasses last found consistent with super
+	// master, keyed by cluster name and storageclass name, guarded by reconciledLock. The equality check of a
+	// storageclass is skipped while none of the versions changes. It is reset when the super master informer relists.
+	reconciledLock sync.Mutex
+	reconciled     map[string]map[string]reconciledVersions
+}
+
+// reconciledVersions are the versions of the objects the equality of a tenant storageclass depends on. The
+// virtualcluster is tracked by the fields used by the equality check, its resource version advances with every
+// condition update. The owner is the UID of the configured storageclass owner, if any. The versions of different
+// super masters are not comparable, hence the id of the super master the tenant is mapped to is tracked as well.
+type reconciledVersions struct {
+	superID string
+	super   string
+	tenant  string
+	vc      string
+	owner   types.UID
+}
+
+type clusterContext struct {
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewStorageClassController(config *config.SyncerConfiguration,
@@ -69,8 +197,51 @@ func NewStorageClassController(config *config.SyncerConfiguration,
 		BaseResourceSyncer: manager.BaseResourceSyncer{
 			Config: config,
 		},
-		client:   client.StorageV1(),
-		informer: informer.Storage().V1(),
+		client:                    client.StorageV1(),
+		vcClient:                  vcClient,
+		informer:                  informer.Storage().V1(),
+		patrollerDryRun:           options.PatrollerDryRun,
+		patrolConcurrency:         constants.DefaultPatrolConcurrency,
+		patrolOpTimeout:           constants.DefaultPatrolOpTimeout,
+		patrolListChunkSize:       options.PatrolListChunkSize,
+		patrolOnRelist:            options.PatrolOnRelist,
+		orphanTTL:                 options.OrphanTTL,
+		stuckFinalizerGracePeriod: options.StuckFinalizerGracePeriod,
+		conflictPolicy:            manager.SuperWins,
+		maxDeletePercentPerPass:   constants.DefaultMaxDeletePercentPerPass,
+		deletionPropagationPolicy: constants.DefaultDeletionPolicy,
+		auditSink:                 pa.NopAuditSink{},
+		writeThrottle:             pa.NewWriteThrottle(),
+		sweep:                     pa.NewSweepRecorder("storageclass"),
+		requeuedKeys:              sets.NewString(),
+		syncLag:                   pa.NewSyncLag("StorageClass"),
+		clusterOrphanMap:          make(map[string]map[string]time.Time),
+		clusterContexts:           make(map[string]*clusterContext),
+		reconciled:                make(map[string]map[string]reconciledVersions),
+		patrolRequeueLimiter: workqueue.NewItemExponentialFailureRateLimiter(
+			constants.DefaultPatrolRequeueBaseDelay, constants.DefaultPatrolRequeueMaxDelay),
+		orphanCleanupQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "storageclass-orphan-cleanup"),
+	}
+	c.tenantStorageClasses = c.clusterStorageClasses
+	if options.PatrolConcurrency > 0 {
+		c.patrolConcurrency = options.PatrolConcurrency
+	}
+	if options.PatrolOpTimeout > 0 {
+		c.patrolOpTimeout = options.PatrolOpTimeout
+	}
+	if options.ConflictPolicy != "" {
+		c.conflictPolicy = options.ConflictPolicy
+	}
+	if options.DeletionPropagationPolicy != "" {
+		c.deletionPropagationPolicy = options.DeletionPropagationPolicy
+	}
+	if options.MaxDeletePercentPerPass > 0 {
+		c.maxDeletePercentPerPass = options.MaxDeletePercentPerPass
+	}
+	if options.AuditSink != nil {
+		c.auditSink = options.AuditSink
+	} else if config.CheckerAuditLog {
+		c.auditSink = pa.LogAuditSink{}
 	}
 
 	var err error
@@ -79,74 +250,349 @@ func NewStorageClassController(config *config.SyncerConfiguration,
 		return nil, err
 	}
 
+	if !options.IsFake {
+		served, err := storageClassAPIServed(client.Discovery())
+		if err != nil {
+			return nil, err
+		}
+		if !served {
+			klog.Warningf("super master does not serve %s storageclasses, storageclass syncer is disabled", v1.SchemeGroupVersion)
+			metrics.SyncerDisabledResource.WithLabelValues("storageclass").Set(1)
+			c.apiAbsent = true
+			return c, nil
+		}
+	}
+
 	c.storageclassLister = informer.Storage().V1().StorageClasses().Lister()
+	c.storageclassStore = informer.Storage().V1().StorageClasses().Informer().GetStore()
 	if options.IsFake {
 		c.storageclassSynced = func() bool { return true }
 	} else {
 		c.storageclassSynced = informer.Storage().V1().StorageClasses().Informer().HasSynced
 	}
+	c.lastSyncResourceVersion = informer.Storage().V1().StorageClasses().Informer().LastSyncResourceVersion
 
 	c.UpwardController, err = uw.NewUWController(&v1.StorageClass{}, c, uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
 
-	c.Patroller, err = pa.NewPatroller(&v1.StorageClass{}, c, pa.WithOptions(options.PatrolOptions))
+	c.Patroller, err = pa.NewPatroller(&v1.StorageClass{}, c, pa.WithResourcePeriod(config, "storageclass"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
 	if err != nil {
 		return nil, err
 	}
 
-	c.informer.StorageClasses().Informer().AddEventHandler(
-		cache.FilteringResourceEventHandler{
-			FilterFunc: func(obj interface{}) bool {
-				switch t := obj.(type) {
-				case *v1.StorageClass:
-					return publicStorageClass(t)
-				case cache.DeletedFinalStateUnknown:
-					if e, ok := t.Obj.(*v1.StorageClass); ok {
-						return publicStorageClass(e)
-					}
-					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.StorageClass", obj))
-					return false
-				default:
-					utilruntime.HandleError(fmt.Errorf("unable to handle object in super master storageclass controller: %v", obj))
-					return false
-				}
-			},
-			Handler: cache.ResourceEventHandlerFuncs{
-				AddFunc: c.enqueueStorageClass,
-				UpdateFunc: func(oldObj, newObj interface{}) {
-					newStorageClass := newObj.(*v1.StorageClass)
-					oldStorageClass := oldObj.(*v1.StorageClass)
-					if newStorageClass.ResourceVersion != oldStorageClass.ResourceVersion {
-						c.enqueueStorageClass(newObj)
-					}
-				},
-				DeleteFunc: c.enqueueStorageClass,
-			},
+	// public storageclasses are synced to all tenant clusters as soon as they change in super master.
+	c.MultiClusterController.Watch(c.informer.StorageClasses().Informer(), c.filterStorageClass, c.fanOutStorageClassOf(""))
+	if len(options.ExtraSuperClusters) > 0 {
+		c.extraSupers = make(map[string]*superSource, len(options.ExtraSuperClusters))
+		for id, super := range options.ExtraSuperClusters {
+			storageClasses := super.Informer.Storage().V1().StorageClasses()
+			c.extraSupers[id] = c.newExtraSuper(id, storageClasses.Informer(), storageClasses.Lister(), options.IsFake)
+		}
+	}
+
+	if config.SuperCacheMaxStaleness > 0 {
+		// every event proves the super master watch is alive.
+		c.informer.StorageClasses().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { c.observeSuperCache() },
+			UpdateFunc: func(interface{}, interface{}) { c.observeSuperCache() },
+			DeleteFunc: func(interface{}) { c.observeSuperCache() },
 		})
+	}
+
+	c.informer.StorageClasses().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// The informer resync is disabled, hence an update without resource version change
+			// only happens when the informer relists, e.g., after the watch is broken.
+			if newObj.(*v1.StorageClass).ResourceVersion != oldObj.(*v1.StorageClass).ResourceVersion {
+				return
+			}
+			// the events may have been missed, the next patrol compares all storageclasses in full.
+			c.resetReconciled()
+			if c.patrolOnRelist {
+				c.Patroller.Trigger()
+			}
+		},
+	})
 	return c, nil
 }
 
-func publicStorageClass(e *v1.StorageClass) bool {
+func (c *controller) publicStorageClass(e *v1.StorageClass) bool {
+	return publicStorageClass(c.Config, e)
+}
+
+// PublicNameTaken returns true if the storageclass of the name is synced from super master to tenant masters.
+func (c *controller) PublicNameTaken(name string) bool {
+	if c.apiAbsent {
+		return false
+	}
+	pStorageClass, err := c.storageclassLister.Get(name)
+	if err != nil {
+		return false
+	}
+	return c.publicStorageClass(pStorageClass)
+}
+
+// storageClassAllowed checks the storageclass name against the configured allow list and deny list.
+func (c *controller) storageClassAllowed(name string) bool {
+	return storageClassNameAllowed(c.Config, name)
+}
+
+// publicStorageClass returns true if the super master storageclass is synced to tenant masters.
+func publicStorageClass(config *config.SyncerConfiguration, e *v1.StorageClass) bool {
 	// We only backpopulate specific storageclass to tenant masters
-	return e.Labels[constants.PublicObjectKey] == "true"
+	if e.Labels[constants.PublicObjectKey] != "true" {
+		return false
+	}
+	return storageClassNameAllowed(config, e.Name)
+}
+
+// storageClassNameAllowed checks the storageclass name against the allow list and deny list of the config.
+func storageClassNameAllowed(config *config.SyncerConfiguration, name string) bool {
+	if config == nil {
+		return true
+	}
+	for _, pattern := range config.SyncStorageClassDenyList {
+		if matched, _ := path.Match(pattern, name); matched {
+			return false
+		}
+	}
+	if len(config.SyncStorageClassAllowList) == 0 {
+		return true
+	}
+	for _, pattern := range config.SyncStorageClassAllowList {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// storageClassOwner returns the owner reference stamped onto the synced storageclasses of the tenant cluster.
+// It returns nil if no owner is configured, or if the owner does not exist in the tenant master yet, in which case
+// the storageclasses are synced without the reference and get it once the owner shows up. A reference to a missing
+// owner must never be stamped, the tenant garbage collector would delete the storageclasses right away.
+func (c *controller) storageClassOwner(ctx context.Context, clusterName string) (*metav1.OwnerReference, error) {
+	if c.Config == nil || c.Config.StorageClassOwner == nil {
+		return nil, nil
+	}
+	anchor := c.Config.StorageClassOwner
+	cluster := c.MultiClusterController.GetCluster(clusterName)
+	if cluster == nil {
+		return nil, utilerrors.NewClusterNotFound(clusterName)
+	}
+	tenantClient, err := cluster.GetDelegatingClient()
+	if err != nil {
+		return nil, err
+	}
+	owner := &unstructured.Unstructured{}
+	owner.SetAPIVersion(anchor.APIVersion)
+	owner.SetKind(anchor.Kind)
+	if err := tenantClient.Get(ctx, client.ObjectKey{Name: anchor.Name}, owner); err != nil {
+		if errors.IsNotFound(err) {
+			pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Object: anchor.Name}, "storageclass owner %s %s does not exist in cluster %s, sync storageclasses without it", anchor.Kind, anchor.Name, clusterName)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get storageclass owner %s %s from cluster %s: %v", anchor.Kind, anchor.Name, clusterName, err)
+	}
+	return &metav1.OwnerReference{
+		APIVersion: anchor.APIVersion,
+		Kind:       anchor.Kind,
+		Name:       owner.GetName(),
+		UID:        owner.GetUID(),
+	}, nil
+}
+
+// storageClassAPIServed checks whether super master serves storage.k8s.io/v1 storageclasses.
+func storageClassAPIServed(dc discovery.DiscoveryInterface) (bool, error) {
+	resources, err := dc.ServerResourcesForGroupVersion(v1.SchemeGroupVersion.String())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == "storageclasses" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// minStorageClassV1Version is the first kubernetes version which serves storage.k8s.io/v1 storageclasses.
+var minStorageClassV1Version = utilversion.MustParseGeneric("1.6.0")
+
+// storageClassServed returns false if the tenant cluster is too old to serve storage.k8s.io/v1 storageclasses,
+// the storageclasses of such a cluster are not synced. The storageclasses are synced if the version is unknown.
+func (c *controller) storageClassServed(clusterName string) bool {
+	info, err := c.MultiClusterController.GetClusterVersion(clusterName)
+	if err != nil {
+		pa.V(4).Infof(pa.Fields{Resource: "storageclass", Cluster: clusterName, Err: err}, "failed to get version of cluster %s: %v", clusterName, err)
+		return true
+	}
+	return storageClassServedInVersion(info)
 }
 
-func (c *controller) enqueueStorageClass(obj interface{}) {
-	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+func storageClassServedInVersion(info *version.Info) bool {
+	v, err := utilversion.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return true
+	}
+	return v.AtLeast(minStorageClassV1Version)
+}
+
+// GetListener returns a listener which populates the public storageclasses to a tenant cluster as soon as
+// the cluster is watched, instead of waiting for the next patrol, and aborts the in-flight requests of
+// a removed cluster.
+func (c *controller) GetListener() listener.ClusterChangeListener {
+	if c.apiAbsent {
+		return &apiAbsentListener{c: c}
+	}
+	return &clusterChangeListener{
+		ClusterChangeListener: listener.NewMCControllerListener(c.MultiClusterController, mc.WatchOptions{AttachUID: true}),
+		c:                     c,
+	}
+}
+
+type clusterChangeListener struct {
+	listener.ClusterChangeListener
+	c *controller
+}
+
+func (l *clusterChangeListener) WatchCluster(cluster mc.ClusterInterface) {
+	l.c.addClusterContext(cluster.GetClusterName())
+	l.ClusterChangeListener.WatchCluster(cluster)
+	l.c.enqueueClusterStorageClasses(cluster.GetClusterName())
+}
+
+func (l *clusterChangeListener) RemoveCluster(cluster mc.ClusterInterface) {
+	l.ClusterChangeListener.RemoveCluster(cluster)
+	l.c.cancelClusterContext(cluster.GetClusterName())
+	l.c.syncLag.Prune(l.c.MultiClusterController.GetClusterNames())
+	metrics.DeleteCheckerClusterStats("StorageClass", cluster.GetClusterName(),
+		"MissMatchedStorageClasses", "RequeuedSuperMasterStorageClasses", "DeletedOrphanTenantStorageClasses")
+}
+
+// enqueueClusterStorageClasses enqueues all public storageclasses of its super master for a single tenant cluster.
+func (c *controller) enqueueClusterStorageClasses(clusterName string) {
+	super, err := c.superOf(clusterName)
+	if err != nil {
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "requeue", Err: err}, "error getting the super master of cluster %s: %v", clusterName, err)
+		return
+	}
+	pStorageClassList, err := super.lister.List(labels.Everything())
 	if err != nil {
-		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "requeue", Err: err}, "error listing storageclass from super master informer cache: %v", err)
 		return
 	}
+	for _, pStorageClass := range pStorageClassList {
+		if c.publicStorageClass(pStorageClass) {
+			c.UpwardController.AddToQueue(clusterName + "/" + pStorageClass.Name)
+		}
+	}
+}
+
+// cancelledContext is the context of the tenant requests issued for a cluster which is not watched.
+var cancelledContext = func() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}()
 
-	clusterNames := c.MultiClusterController.GetClusterNames()
-	if len(clusterNames) == 0 {
-		klog.Infof("No tenant masters, stop backpopulate storageclass %v", key)
+// addClusterContext creates the context of the tenant requests issued for a watched cluster.
+func (c *controller) addClusterContext(clusterName string) {
+	c.Lock()
+	defer c.Unlock()
+	if _, ok := c.clusterContexts[clusterName]; ok {
 		return
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.clusterContexts[clusterName] = &clusterContext{ctx: ctx, cancel: cancel}
+}
+
+// getClusterContext returns the context of the tenant requests issued for the cluster. The context of
+// a cluster which is not watched, e.g., one already removed, is cancelled.
+func (c *controller) getClusterContext(clusterName string) context.Context {
+	c.Lock()
+	defer c.Unlock()
+	if cc, ok := c.clusterContexts[clusterName]; ok {
+		return cc.ctx
+	}
+	return cancelledContext
+}
+
+// withClusterContext returns a child of ctx which is cancelled as well once the cluster is removed.
+func (c *controller) withClusterContext(ctx context.Context, clusterName string) (context.Context, context.CancelFunc) {
+	clusterCtx := c.getClusterContext(clusterName)
+	ctx, cancel := context.WithCancel(ctx)
+	if clusterCtx.Err() != nil {
+		cancel()
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-clusterCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
 
-	for _, clusterName := range clusterNames {
-		c.UpwardController.AddToQueue(clusterName + "/" + key)
+func (c *controller) cancelClusterContext(clusterName string) {
+	c.Lock()
+	defer c.Unlock()
+	if cc, ok := c.clusterContexts[clusterName]; ok {
+		cc.cancel()
+		delete(c.clusterContexts, clusterName)
 	}
 }
+
+// filterStorageClass accepts the events of the public super master storageclasses.
+func (c *controller) filterStorageClass(obj interface{}) bool {
+	switch t := obj.(type) {
+	case *v1.StorageClass:
+		return c.publicStorageClass(t)
+	case cache.DeletedFinalStateUnknown:
+		if e, ok := t.Obj.(*v1.StorageClass); ok {
+			return c.publicStorageClass(e)
+		}
+		utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.StorageClass", obj))
+		return false
+	default:
+		utilruntime.HandleError(fmt.Errorf("unable to handle object in super master storageclass controller: %v", obj))
+		return false
+	}
+}
+
+// fanOutStorageClass enqueues the keys of a super master storageclass event for all tenant clusters. The keys
+// are marked requeued in the current patrol sweep, so that the checker does not requeue them once more with
+// backoff while the upward controller syncs them. A key still missing in the next sweep is requeued as usual.
+// The sync lag of the keys is measured from the event.
+func (c *controller) fanOutStorageClass(keys []string) {
+	now := time.Now()
+	for _, key := range keys {
+		clusterName, _, _ := cache.SplitMetaNamespaceKey(key)
+		c.syncLag.Changed(clusterName, key, now)
+		c.markRequeued(key)
+		c.UpwardController.AddToQueue(key)
+	}
+}
+
+// apiAbsentListener is used when super master does not serve storageclasses. The tenant clusters are never
+// watched, instead their StorageClassSynced condition is marked false.
+type apiAbsentListener struct {
+	c *controller
+}
+
+var _ listener.ClusterChangeListener = &apiAbsentListener{}
+
+func (l *apiAbsentListener) AddCluster(cluster mc.ClusterInterface) {}
+
+func (l *apiAbsentListener) WatchCluster(cluster mc.ClusterInterface) {
+	l.c.markAPIAbsent(cluster)
+}
+
+func (l *apiAbsentListener) RemoveCluster(cluster mc.ClusterInterface) {}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageclass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+// DiffReport is the difference between the public storageclasses of super master and the storageclasses of a
+// tenant master, as found by the storageclass patroller.
+type DiffReport struct {
+	// Cluster is the name of the tenant cluster.
+	Cluster string
+	// Missing are the public super master storageclasses absent from the tenant master.
+	Missing []string
+	// Orphans are the syncer managed tenant storageclasses which are not public in super master.
+	Orphans []string
+	// Mismatched are the tenant storageclasses inconsistent with super master, keyed by name, with the
+	// changes the syncer would make to them.
+	Mismatched map[string][]string
+	// Synced is the number of tenant storageclasses consistent with super master.
+	Synced int
+}
+
+// Empty returns true if the tenant master is consistent with super master.
+func (r *DiffReport) Empty() bool {
+	return len(r.Missing) == 0 && len(r.Orphans) == 0 && len(r.Mismatched) == 0
+}
+
+// Print writes the report in a human readable form.
+func (r *DiffReport) Print(w io.Writer) {
+	if r.Empty() {
+		fmt.Fprintf(w, "storageclasses of cluster %s are consistent with super master, %d synced\n", r.Cluster, r.Synced)
+		return
+	}
+	for _, name := range r.Missing {
+		fmt.Fprintf(w, "missing\t%s\n", name)
+	}
+	for _, name := range r.Orphans {
+		fmt.Fprintf(w, "orphan\t%s\n", name)
+	}
+	names := make([]string, 0, len(r.Mismatched))
+	for name := range r.Mismatched {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "diff\t%s\t%s\n", name, strings.Join(r.Mismatched[name], ", "))
+	}
+}
+
+// DiffStorageClasses compares the public storageclasses of super master with the storageclasses of the tenant
+// master of the virtualcluster the way the patroller does, and returns the difference. It only reads from both
+// masters, nothing is requeued, updated or deleted. The owner reference configured by StorageClassOwner is not
+// checked, since it can only be resolved against a running syncer's tenant cluster.
+func DiffStorageClasses(ctx context.Context, config *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster, superClient, tenantClient clientset.Interface) (*DiffReport, error) {
+	pList, err := superClient.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{LabelSelector: constants.PublicObjectKey + "=true"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storageclasses from super master: %v", err)
+	}
+	vList, err := tenantClient.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storageclasses from cluster %s: %v", conversion.ToClusterKey(vc), err)
+	}
+
+	pStorageClasses := make(map[string]*v1.StorageClass)
+	for i := range pList.Items {
+		if publicStorageClass(config, &pList.Items[i]) {
+			pStorageClasses[pList.Items[i].Name] = &pList.Items[i]
+		}
+	}
+	vStorageClasses := make(map[string]*v1.StorageClass)
+	for i := range vList.Items {
+		vStorageClasses[vList.Items[i].Name] = &vList.Items[i]
+	}
+
+	report := &DiffReport{Cluster: conversion.ToClusterKey(vc), Mismatched: make(map[string][]string)}
+	for name := range pStorageClasses {
+		if _, exists := vStorageClasses[name]; !exists {
+			report.Missing = append(report.Missing, name)
+		}
+	}
+	for name, vStorageClass := range vStorageClasses {
+		// Only the storageclasses managed by syncer are checked, the ones created by tenants are left alone.
+		if vStorageClass.Labels[constants.LabelManagedBy] != constants.ManagedBySyncer {
+			continue
+		}
+		pStorageClass, exists := pStorageClasses[name]
+		if !exists {
+			report.Orphans = append(report.Orphans, name)
+			continue
+		}
+		if updated := conversion.Equality(config, vc).CheckStorageClassEquality(pStorageClass, vStorageClass); updated != nil {
+			report.Mismatched[name] = conversion.StorageClassDiff(vStorageClass, updated)
+			continue
+		}
+		report.Synced++
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Orphans)
+	return report, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageclass
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+func TestDiffStorageClasses(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	storageClass := func(name, provisioner string, labels map[string]string) *v1.StorageClass {
+		return &v1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name, Labels: labels},
+			Provisioner: provisioner,
+		}
+	}
+	public := map[string]string{constants.PublicObjectKey: "true"}
+	managed := map[string]string{constants.LabelManagedBy: constants.ManagedBySyncer}
+
+	superClient := fake.NewSimpleClientset(
+		storageClass("synced", "a", public),
+		storageClass("mismatched", "a", public),
+		storageClass("missing", "a", public),
+		storageClass("denied", "a", public),
+		storageClass("private", "a", nil),
+	)
+	tenantClient := fake.NewSimpleClientset(
+		storageClass("synced", "a", managed),
+		storageClass("mismatched", "b", managed),
+		storageClass("denied", "a", managed),
+		storageClass("orphan", "a", managed),
+		storageClass("tenant-owned", "a", nil),
+	)
+
+	cfg := &config.SyncerConfiguration{SyncStorageClassDenyList: []string{"denied"}}
+	report, err := DiffStorageClasses(context.TODO(), cfg, testTenant, superClient, tenantClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(report.Missing, []string{"missing"}) {
+		t.Errorf("expected missing storageclasses [missing], got %v", report.Missing)
+	}
+	if !reflect.DeepEqual(report.Orphans, []string{"denied", "orphan"}) {
+		t.Errorf("expected orphan storageclasses [denied orphan], got %v", report.Orphans)
+	}
+	if len(report.Mismatched) != 1 || !reflect.DeepEqual(report.Mismatched["mismatched"], []string{"provisioner: b -> a"}) {
+		t.Errorf("expected storageclass mismatched to differ in provisioner, got %v", report.Mismatched)
+	}
+	if report.Synced != 1 {
+		t.Errorf("expected 1 synced storageclass, got %d", report.Synced)
+	}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	for _, line := range []string{"missing\tmissing", "orphan\torphan", "diff\tmismatched\tprovisioner: b -> a"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected report to contain %q, got %q", line, out.String())
+		}
+	}
+
+	for _, action := range append(superClient.Actions(), tenantClient.Actions()...) {
+		if action.GetVerb() != "list" {
+			t.Errorf("expected read-only access, got %v", action)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageclass
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	v1 "k8s.io/api/storage/v1"
+	listersv1 "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	utilerrors "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
+)
+
+// superSource is the super master a tenant cluster takes its public storageclasses from. The default super master,
+// i.e., the one syncer runs against, has an empty id.
+type superSource struct {
+	id     string
+	lister listersv1.StorageClassLister
+	store  cache.Store
+	synced cache.InformerSynced
+	// numPublic is the number of public storageclasses of an extra super master counted at the start of the last
+	// patrol, the count of the default super master is numPublicStorageClasses of the controller.
+	numPublic uint64
+}
+
+// defaultSuper returns the super master syncer runs against.
+func (c *controller) defaultSuper() *superSource {
+	return &superSource{lister: c.storageclassLister, store: c.storageclassStore, synced: c.storageclassSynced}
+}
+
+// supers returns the default super master followed by the extra ones sorted by id.
+func (c *controller) supers() []*superSource {
+	supers := []*superSource{c.defaultSuper()}
+	ids := make([]string, 0, len(c.extraSupers))
+	for id := range c.extraSupers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		supers = append(supers, c.extraSupers[id])
+	}
+	return supers
+}
+
+// superCachesSynced returns the synced functions of the storageclass caches of all super masters.
+func (c *controller) superCachesSynced() []cache.InformerSynced {
+	var synced []cache.InformerSynced
+	for _, super := range c.supers() {
+		synced = append(synced, super.synced)
+	}
+	return synced
+}
+
+// superOf returns the super master the tenant cluster is mapped to.
+func (c *controller) superOf(clusterName string) (*superSource, error) {
+	if len(c.extraSupers) == 0 {
+		return c.defaultSuper(), nil
+	}
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		if c.MultiClusterController.GetCluster(clusterName) == nil {
+			return nil, utilerrors.NewClusterNotFound(clusterName)
+		}
+		return nil, err
+	}
+	return c.superOfVirtualCluster(vc)
+}
+
+// superOfVirtualCluster returns the super master picked by the LabelTenantSuperCluster annotation of the
+// virtualcluster. The tenants without the annotation, as well as all tenants if no extra super master is given,
+// are mapped to the default super master. An unknown super master is an error rather than the default one, its
+// tenant storageclasses would be taken for orphans otherwise.
+func (c *controller) superOfVirtualCluster(vc *v1alpha1.VirtualCluster) (*superSource, error) {
+	if len(c.extraSupers) == 0 {
+		return c.defaultSuper(), nil
+	}
+	id := vc.GetAnnotations()[constants.LabelTenantSuperCluster]
+	if id == "" || id == utilconstants.SuperClusterID {
+		return c.defaultSuper(), nil
+	}
+	if super, exists := c.extraSupers[id]; exists {
+		return super, nil
+	}
+	return nil, fmt.Errorf("unknown super cluster %q", id)
+}
+
+// clustersBySuper groups the tenant clusters by the super masters they are mapped to, in the order of supers.
+// The clusters whose super master cannot be found are left out.
+func (c *controller) clustersBySuper(clusterNames []string) ([]*superSource, map[*superSource][]string) {
+	if len(c.extraSupers) == 0 {
+		super := c.defaultSuper()
+		return []*superSource{super}, map[*superSource][]string{super: clusterNames}
+	}
+	supers := c.supers()
+	byID := make(map[string]*superSource, len(supers))
+	for _, super := range supers {
+		byID[super.id] = super
+	}
+	groups := make(map[*superSource][]string)
+	for _, clusterName := range clusterNames {
+		super, err := c.superOf(clusterName)
+		if err != nil {
+			pa.Errorf(pa.Fields{Resource: "storageclass", Cluster: clusterName, Action: "skip", Err: err}, "fail to get the super master of cluster %s, skip it: %v", clusterName, err)
+			continue
+		}
+		groups[byID[super.id]] = append(groups[byID[super.id]], clusterName)
+	}
+	return supers, groups
+}
+
+// fanOutStorageClassOf returns the fan out of the events of the super master of the id, which only enqueues the
+// keys of the tenant clusters mapped to it.
+func (c *controller) fanOutStorageClassOf(id string) func(keys []string) {
+	return func(keys []string) {
+		if len(c.extraSupers) == 0 {
+			c.fanOutStorageClass(keys)
+			return
+		}
+		var mapped []string
+		for _, key := range keys {
+			clusterName, _, _ := cache.SplitMetaNamespaceKey(key)
+			if super, err := c.superOf(clusterName); err == nil && super.id == id {
+				mapped = append(mapped, key)
+			}
+		}
+		c.fanOutStorageClass(mapped)
+	}
+}
+
+// newExtraSuper creates the source of an extra super master and fans out its storageclass events to the tenant
+// clusters mapped to it.
+func (c *controller) newExtraSuper(id string, informer cache.SharedIndexInformer, lister listersv1.StorageClassLister, isFake bool) *superSource {
+	super := &superSource{id: id, lister: lister, store: informer.GetStore(), synced: informer.HasSynced}
+	if isFake {
+		super.synced = func() bool { return true }
+	}
+	c.MultiClusterController.Watch(informer, c.filterStorageClass, c.fanOutStorageClassOf(id))
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if newObj.(*v1.StorageClass).ResourceVersion != oldObj.(*v1.StorageClass).ResourceVersion {
+				return
+			}
+			c.resetReconciled()
+			if c.patrolOnRelist {
+				c.Patroller.Trigger()
+			}
+		},
+	})
+	return super
+}
+
+// publicStorageClassesOf returns the number of public storageclasses of the super master counted at the start of
+// the last patrol.
+func (c *controller) publicStorageClassesOf(super *superSource) uint64 {
+	if super.id == "" {
+		return atomic.LoadUint64(&c.numPublicStorageClasses)
+	}
+	return atomic.LoadUint64(&super.numPublic)
+}
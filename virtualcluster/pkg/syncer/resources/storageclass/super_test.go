@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageclass
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func TestStorageClassExtraSuperClusters(t *testing.T) {
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+	newTenant := func(name, superCluster string) *v1alpha1.VirtualCluster {
+		vc := &v1alpha1.VirtualCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "tenant-1",
+				UID:       types.UID("7374a172-c35d-45b1-9c8e-bf5c5b61493" + name),
+			},
+		}
+		if superCluster != "" {
+			vc.Annotations = map[string]string{constants.LabelTenantSuperCluster: superCluster}
+		}
+		return vc
+	}
+
+	superClient := fake.NewSimpleClientset()
+	superInformer := informers.NewSharedInformerFactory(superClient, 0)
+	eastClient := fake.NewSimpleClientset()
+	eastInformer := informers.NewSharedInformerFactory(eastClient, 0)
+	r, err := NewStorageClassController(&config.SyncerConfiguration{}, superClient, superInformer, nil, nil, manager.ResourceSyncerOptions{
+		IsFake:             true,
+		ExtraSuperClusters: map[string]plugin.SuperCluster{"east": {Client: eastClient, Informer: eastInformer}},
+	})
+	if err != nil {
+		t.Fatalf("error creating controller: %v", err)
+	}
+	c := r.(*controller)
+	c.maxDeletePercentPerPass = 100
+	superInformer.Storage().V1().StorageClasses().Informer().GetStore().Add(makeStorageClass("slow", "1", public))
+	eastInformer.Storage().V1().StorageClasses().Informer().GetStore().Add(makeStorageClass("fast", "2", public))
+
+	// tenant a is mapped to the default super master, b to east, and c to an unknown one.
+	tenantClients := map[string]*fake.Clientset{}
+	clusterNames := map[string]string{}
+	for name, superCluster := range map[string]string{"a": "", "b": "east", "c": "west"} {
+		vc := newTenant(name, superCluster)
+		tenantClients[name] = fake.NewSimpleClientset()
+		tenantCluster, err := cluster.NewFakeTenantCluster(vc, tenantClients[name], fakeClient.NewFakeClient(makeStorageClass("slow", "11", managed)))
+		if err != nil {
+			t.Fatalf("error creating tenant cluster: %v", err)
+		}
+		c.GetListener().AddCluster(tenantCluster)
+		c.addClusterContext(tenantCluster.GetClusterName())
+		clusterNames[name] = conversion.ToClusterKey(vc)
+	}
+
+	for name, expected := range map[string]string{"a": "", "b": "east"} {
+		super, err := c.superOf(clusterNames[name])
+		if err != nil {
+			t.Fatalf("unexpected error getting the super master of tenant %s: %v", name, err)
+		}
+		if super.id != expected {
+			t.Errorf("expected tenant %s mapped to super master %q, got %q", name, expected, super.id)
+		}
+	}
+	if _, err := c.superOf(clusterNames["c"]); err == nil {
+		t.Errorf("expected an error getting the unknown super master of tenant c")
+	}
+
+	deleted := func(name string) []string {
+		var names []string
+		for _, action := range tenantClients[name].Actions() {
+			if action.Matches("delete", "storageclasses") {
+				names = append(names, action.(core.DeleteAction).GetName())
+			}
+		}
+		return names
+	}
+	// slow is public in the default super master only, hence it is an orphan of tenant b.
+	if _, ok := c.checkStorageClassOfTenantCluster(context.TODO(), clusterNames["a"]); !ok {
+		t.Errorf("expected tenant a to be checked")
+	}
+	if _, ok := c.checkStorageClassOfTenantCluster(context.TODO(), clusterNames["b"]); !ok {
+		t.Errorf("expected tenant b to be checked")
+	}
+	if _, ok := c.checkStorageClassOfTenantCluster(context.TODO(), clusterNames["c"]); ok {
+		t.Errorf("expected tenant c mapped to an unknown super master to be skipped")
+	}
+	for name, expected := range map[string][]string{"a": nil, "b": {"slow"}, "c": nil} {
+		if got := deleted(name); !equality.Semantic.DeepEqual(got, expected) {
+			t.Errorf("expected storageclasses %v deleted in tenant %s, got %v", expected, name, got)
+		}
+	}
+
+	// the events of a super master are only fanned out to its tenants.
+	c.fanOutStorageClassOf("east")([]string{clusterNames["a"] + "/fast", clusterNames["b"] + "/fast", clusterNames["c"] + "/fast"})
+	if n := c.UpwardController.Queue.Len(); n != 1 {
+		t.Fatalf("expected 1 key enqueued, got %d", n)
+	}
+	if key, _ := c.UpwardController.Queue.Get(); key != clusterNames["b"]+"/fast" {
+		t.Errorf("expected key %s/fast, got %v", clusterNames["b"], key)
+	}
+
+	// each tenant is expected to have the public storageclasses of its own super master.
+	c.sweep.Start()
+	c.requeueMissingStorageClasses([]string{clusterNames["a"], clusterNames["b"], clusterNames["c"]})
+	c.sweep.Finish(nil)
+	if clusters := c.ClustersMissingObject("storageclass", "fast"); !equality.Semantic.DeepEqual(clusters, []string{clusterNames["b"]}) {
+		t.Errorf("expected fast to be missing in tenant b only, got %v", clusters)
+	}
+	if clusters := c.ClustersMissingObject("storageclass", "slow"); len(clusters) != 0 {
+		t.Errorf("expected slow not to be missing, got %v", clusters)
+	}
+
+	if err := c.backPopulate(clusterNames["b"], "fast"); err != nil {
+		t.Fatalf("unexpected error back populating fast to tenant b: %v", err)
+	}
+	created := false
+	for _, action := range tenantClients["b"].Actions() {
+		if action.Matches("create", "storageclasses") && action.(core.CreateAction).GetObject().(*v1.StorageClass).Name == "fast" {
+			created = true
+		}
+	}
+	if !created {
+		t.Errorf("expected fast to be created in tenant b from super master east")
+	}
+	if err := c.backPopulate(clusterNames["c"], "fast"); err == nil {
+		t.Errorf("expected an error back populating to tenant c mapped to an unknown super master")
+	}
+}
@@ -17,23 +17,30 @@ limitations under the License.
 package storageclass
 
 import (
-	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
 
-	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	utilerrors "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
 // StartUWS starts the upward syncer
 // and blocks until an empty struct is sent to the stop channel.
 func (c *controller) StartUWS(stopCh <-chan struct{}) error {
-	if !cache.WaitForCacheSync(stopCh, c.storageclassSynced) {
+	if c.apiAbsent {
+		<-stopCh
+		return nil
+	}
+	if !cache.WaitForCacheSync(stopCh, c.superCachesSynced()...) {
 		return fmt.Errorf("failed to wait for caches to sync storageclass")
 	}
 	return c.UpwardController.Start(stopCh)
@@ -42,28 +49,74 @@ func (c *controller) StartUWS(stopCh <-chan struct{}) error {
 func (c *controller) BackPopulate(key string) error {
 	// The key format is clustername/scName.
 	clusterName, scName, _ := cache.SplitMetaNamespaceKey(key)
+	if err := c.backPopulate(clusterName, scName); err != nil {
+		return err
+	}
+	c.syncLag.Synced(clusterName, key)
+	return nil
+}
+
+func (c *controller) backPopulate(clusterName, scName string) error {
+	if !c.storageClassServed(clusterName) {
+		klog.V(4).Infof("cluster %s does not serve storage.k8s.io/v1 storageclass, skip %s", clusterName, scName)
+		return nil
+	}
 
 	op := reconciler.AddEvent
-	pStorageClass, err := c.storageclassLister.Get(scName)
+	// superDeleted is true if the super master storageclass is gone. The key is queued by its delete event
+	// or by the patroller, in either case the lister is the source of truth, so a stale key never recreates
+	// the tenant storageclass.
+	superDeleted := false
+	super, err := c.superOf(clusterName)
+	if err != nil {
+		return err
+	}
+	pStorageClass, err := super.lister.Get(scName)
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			return err
 		}
 		op = reconciler.DeleteEvent
+		superDeleted = true
+	} else if !c.storageClassAllowed(scName) {
+		// storageclass denied by allow list or deny list should not exist in tenant masters.
+		op = reconciler.DeleteEvent
 	}
 
 	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
 	if err != nil {
+		if utilerrors.IsClusterNotFound(err) {
+			// the key was queued before the cluster is removed, let the upward controller drop it.
+			return err
+		}
 		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
 	}
 
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.getClusterContext(clusterName)
+	owner, err := c.storageClassOwner(ctx, clusterName)
+	if err != nil {
+		return err
+	}
+
 	vStorageClass := &v1.StorageClass{}
 	if err := c.MultiClusterController.Get(clusterName, "", scName, vStorageClass); err != nil {
 		if errors.IsNotFound(err) {
 			if op == reconciler.AddEvent {
 				// Available in super, hence create a new in tenant master
 				vStorageClass := conversion.BuildVirtualStorageClass(clusterName, pStorageClass)
-				_, err := tenantClient.StorageV1().StorageClasses().Create(context.TODO(), vStorageClass, metav1.CreateOptions{})
+				conversion.TranslateObjectMeta(c.Config, vStorageClass)
+				vStorageClass.AllowedTopologies = conversion.TenantAllowedTopologies(c.Config, pStorageClass.AllowedTopologies)
+				conversion.SetTenantDefaultStorageClass(vc, vStorageClass)
+				vStorageClass.Parameters = conversion.TenantStorageClassParameters(vc, scName, vStorageClass.Parameters)
+				if owner != nil {
+					conversion.SetOwnerReference(vStorageClass, *owner)
+				}
+				_, err := tenantClient.StorageV1().StorageClasses().Create(ctx, vStorageClass, metav1.CreateOptions{})
 				if err != nil {
 					return err
 				}
@@ -74,17 +127,39 @@ func (c *controller) BackPopulate(key string) error {
 	}
 
 	if op == reconciler.DeleteEvent {
+		if !conversion.IsSyncerManaged(vStorageClass) {
+			klog.Infof("storageclass %s in cluster %s is not managed by syncer, leave it alone", scName, clusterName)
+			return nil
+		}
+		if conversion.IsProtected(vStorageClass) {
+			klog.Infof("storageclass %s in cluster %s is protected, leave it alone", scName, clusterName)
+			return nil
+		}
+		if superDeleted && c.Config.DeferStorageClassDeletionToPatrol {
+			klog.V(4).Infof("storageclass %s is deleted in super master, leave its copy in cluster %s to the patroller", scName, clusterName)
+			return nil
+		}
+		policy := c.deletionPolicyOf(clusterName, vStorageClass)
 		opts := &metav1.DeleteOptions{
-			PropagationPolicy: &constants.DefaultDeletionPolicy,
+			PropagationPolicy: &policy,
 		}
-		err := tenantClient.StorageV1().StorageClasses().Delete(context.TODO(), scName, *opts)
+		err := tenantClient.StorageV1().StorageClasses().Delete(ctx, scName, *opts)
 		if err != nil {
 			return err
 		}
 	} else {
-		updatedStorageClass := conversion.Equality(c.Config, nil).CheckStorageClassEquality(pStorageClass, vStorageClass)
+		// the tenant storageclass not created by syncer is owned by tenant, it is never taken over.
+		if !conversion.IsSyncerManaged(vStorageClass) {
+			c.reportConflict(clusterName, vStorageClass)
+			return nil
+		}
+		var updatedStorageClass *v1.StorageClass
+		// the tenant copy of a mirror only storageclass is created once, the tenant edits to it are kept.
+		if !conversion.MirrorOnly(pStorageClass) {
+			updatedStorageClass = conversion.Equality(c.Config, vc).WithOwnerReference(owner).CheckStorageClassEquality(pStorageClass, vStorageClass)
+		}
 		if updatedStorageClass != nil {
-			_, err := tenantClient.StorageV1().StorageClasses().Update(context.TODO(), updatedStorageClass, metav1.UpdateOptions{})
+			_, err := tenantClient.StorageV1().StorageClasses().Update(ctx, updatedStorageClass, metav1.UpdateOptions{})
 			if err != nil {
 				return err
 			}
@@ -92,3 +167,19 @@ func (c *controller) BackPopulate(key string) error {
 	}
 	return nil
 }
+
+// reportConflict reports the tenant storageclass which has the name of a super master storageclass but is not
+// managed by syncer. It is left alone, the conflict is logged, counted and recorded as an event in tenant master.
+func (c *controller) reportConflict(clusterName string, vStorageClass *v1.StorageClass) {
+	klog.Warningf("storageclass %s in cluster %s is not managed by syncer, leave it alone instead of syncing it from super master", vStorageClass.Name, clusterName)
+	metrics.RecordUWSConflict("storageclass")
+	err := c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+		Kind:       "StorageClass",
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Name:       vStorageClass.Name,
+		UID:        vStorageClass.UID,
+	}, corev1.EventTypeWarning, "NotManagedBySyncer", "StorageClass %s is not managed by syncer, it is not synced from the super master storageclass of the same name", vStorageClass.Name)
+	if err != nil {
+		klog.Errorf("failed to record event for storageclass %s in cluster %s: %v", vStorageClass.Name, clusterName, err)
+	}
+}
@@ -21,16 +21,25 @@ import (
 	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 func makeStorageClass(name, uid string, mFuncs ...func(*v1.StorageClass)) *v1.StorageClass {
@@ -55,6 +64,24 @@ func makeStorageClass(name, uid string, mFuncs ...func(*v1.StorageClass)) *v1.St
 	return sc
 }
 
+func managed(class *v1.StorageClass) {
+	conversion.SetSyncerManaged(class)
+}
+
+func mirrorOnly(class *v1.StorageClass) {
+	if class.Annotations == nil {
+		class.Annotations = map[string]string{}
+	}
+	class.Annotations[constants.AnnotationMirrorOnly] = "true"
+}
+
+func protected(class *v1.StorageClass) {
+	if class.Annotations == nil {
+		class.Annotations = map[string]string{}
+	}
+	class.Annotations[constants.AnnotationProtected] = "true"
+}
+
 func TestUWPVCreation(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -91,7 +118,7 @@ func TestUWPVCreation(t *testing.T) {
 				makeStorageClass("sc", "12345"),
 			},
 			ExistingObjectInTenant: []runtime.Object{
-				makeStorageClass("sc", "123456"),
+				makeStorageClass("sc", "123456", managed),
 			},
 			EnqueuedKey:         defaultClusterKey + "/sc",
 			ExpectedNoOperation: true,
@@ -168,6 +195,7 @@ func TestUWPVUpdate(t *testing.T) {
 		ExpectedUpdatedObject  []runtime.Object
 		ExpectedError          string
 		ExpectedNoOperation    bool
+		ExpectedConflict       bool
 	}{
 		"pSC exists, vSC exists with different spec": {
 			ExistingObjectInSuper: []runtime.Object{
@@ -176,18 +204,56 @@ func TestUWPVUpdate(t *testing.T) {
 				}),
 			},
 			ExistingObjectInTenant: []runtime.Object{
-				makeStorageClass("sc", "123456", func(class *v1.StorageClass) {
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
 					class.Provisioner = "b"
 				}),
 			},
 			EnqueuedKey: defaultClusterKey + "/sc",
 			ExpectedUpdatedObject: []runtime.Object{
-				makeStorageClass("sc", "123456", func(class *v1.StorageClass) {
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
 					class.ResourceVersion = "999"
 					class.Provisioner = "a"
 				}),
 			},
 		},
+		"pSC mirror only, vSC exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", mirrorOnly, func(class *v1.StorageClass) {
+					class.Provisioner = "a"
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", managed, func(class *v1.StorageClass) {
+					class.Provisioner = "b"
+				}),
+			},
+			EnqueuedKey:         defaultClusterKey + "/sc",
+			ExpectedNoOperation: true,
+		},
+		"pSC mirror only, vSC exists without managed label": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", mirrorOnly),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456"),
+			},
+			EnqueuedKey:      defaultClusterKey + "/sc",
+			ExpectedConflict: true,
+		},
+		"pSC exists, vSC exists with different spec without managed label": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345", func(class *v1.StorageClass) {
+					class.Provisioner = "a"
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "123456", func(class *v1.StorageClass) {
+					class.Provisioner = "b"
+				}),
+			},
+			EnqueuedKey:      defaultClusterKey + "/sc",
+			ExpectedConflict: true,
+		},
 	}
 
 	for k, tc := range testcases {
@@ -206,6 +272,32 @@ func TestUWPVUpdate(t *testing.T) {
 				return
 			}
 
+			if tc.ExpectedConflict {
+				// the tenant storageclass is left alone, only the conflict event is recorded.
+				if reconcileErr != nil {
+					t.Errorf("%s: expected no error, but got \"%v\"", k, reconcileErr)
+				}
+				recorded := false
+				for _, action := range actions {
+					if action.GetResource().Resource != "events" {
+						t.Errorf("%s: Expect the storageclass left alone, got %v", k, action)
+						continue
+					}
+					if !action.Matches("create", "events") {
+						continue
+					}
+					event := action.(core.CreateAction).GetObject().(*corev1.Event)
+					if event.Type != corev1.EventTypeWarning || event.Reason != "NotManagedBySyncer" {
+						t.Errorf("%s: Expect a NotManagedBySyncer warning event, got %s %s", k, event.Type, event.Reason)
+					}
+					recorded = true
+				}
+				if !recorded {
+					t.Errorf("%s: Expect a conflict event, got %v", k, actions)
+				}
+				return
+			}
+
 			if reconcileErr != nil {
 				if tc.ExpectedError == "" {
 					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
@@ -241,6 +333,10 @@ func TestUWPVUpdate(t *testing.T) {
 	}
 }
 
+func deferDeletionToPatrol(rs manager.ResourceSyncer) {
+	rs.(*controller).Config.DeferStorageClassDeletionToPatrol = true
+}
+
 func TestUWPVDeletion(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -262,12 +358,56 @@ func TestUWPVDeletion(t *testing.T) {
 		ExpectedDeletedObject  []string
 		ExpectedError          string
 		ExpectedNoOperation    bool
+		StateModifyFunc        func(manager.ResourceSyncer)
 	}{
 		"pSC not found, vSC exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed),
+			},
+			EnqueuedKey: defaultClusterKey + "/sc",
+			ExpectedDeletedObject: []string{
+				"sc",
+			},
+		},
+		"pSC not found, vSC exists but protected": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed, protected),
+			},
+			EnqueuedKey:         defaultClusterKey + "/sc",
+			ExpectedNoOperation: true,
+		},
+		"pSC not found, vSC exists but not managed": {
 			ExistingObjectInTenant: []runtime.Object{
 				makeStorageClass("sc", "12345"),
 			},
+			EnqueuedKey:         defaultClusterKey + "/sc",
+			ExpectedNoOperation: true,
+		},
+		"pSC not found, vSC already deleted": {
+			// a patrol requeue processed after the delete event must not recreate the storageclass.
+			EnqueuedKey:         defaultClusterKey + "/sc",
+			ExpectedNoOperation: true,
+		},
+		"pSC not found, vSC exists, deletion deferred to patrol": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed),
+			},
+			EnqueuedKey:         defaultClusterKey + "/sc",
+			StateModifyFunc:     deferDeletionToPatrol,
+			ExpectedNoOperation: true,
+		},
+		"pSC denied, vSC exists, deletion deferred to patrol": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeStorageClass("sc", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345", managed),
+			},
 			EnqueuedKey: defaultClusterKey + "/sc",
+			StateModifyFunc: func(rs manager.ResourceSyncer) {
+				deferDeletionToPatrol(rs)
+				rs.(*controller).Config.SyncStorageClassDenyList = []string{"sc"}
+			},
 			ExpectedDeletedObject: []string{
 				"sc",
 			},
@@ -276,7 +416,7 @@ func TestUWPVDeletion(t *testing.T) {
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			actions, reconcileErr, err := util.RunUpwardSync(NewStorageClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, nil)
+			actions, reconcileErr, err := util.RunUpwardSync(NewStorageClassController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueuedKey, tc.StateModifyFunc)
 			if err != nil {
 				t.Errorf("%s: error running upward sync: %v", k, err)
 				return
@@ -322,3 +462,66 @@ func TestUWPVDeletion(t *testing.T) {
 		})
 	}
 }
+
+// newFakeController returns a controller whose super master informer holds the given storageclasses,
+// along with an unregistered fake tenant cluster.
+func newFakeController(t *testing.T, testTenant *v1alpha1.VirtualCluster, existingObjectInSuper ...*v1.StorageClass) (*controller, mc.ClusterInterface) {
+	superClient := fake.NewSimpleClientset()
+	superInformer := informers.NewSharedInformerFactory(superClient, 0)
+	r, err := NewStorageClassController(&config.SyncerConfiguration{}, superClient, superInformer, nil, nil, manager.ResourceSyncerOptions{IsFake: true})
+	if err != nil {
+		t.Fatalf("error creating controller: %v", err)
+	}
+	store := superInformer.Storage().V1().StorageClasses().Informer().GetStore()
+	for _, each := range existingObjectInSuper {
+		store.Add(each)
+	}
+
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient())
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	return r.(*controller), tenantCluster
+}
+
+func TestClusterChangeListener(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+	clusterName := conversion.ToClusterKey(testTenant)
+	public := func(class *v1.StorageClass) {
+		class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+	}
+
+	c, tenantCluster := newFakeController(t, testTenant, makeStorageClass("sc1", "1", public), makeStorageClass("sc2", "2"))
+	l := c.GetListener()
+	l.AddCluster(tenantCluster)
+	if c.getClusterContext(clusterName).Err() == nil {
+		t.Errorf("expected the context of the unwatched cluster to be cancelled")
+	}
+	l.WatchCluster(tenantCluster)
+	if c.getClusterContext(clusterName).Err() != nil {
+		t.Errorf("expected the context of the watched cluster not to be cancelled")
+	}
+
+	queue := c.UpwardController.Queue
+	if queue.Len() != 1 {
+		t.Fatalf("expected 1 public storageclass to be enqueued, got %d", queue.Len())
+	}
+	if key, _ := queue.Get(); key != clusterName+"/sc1" {
+		t.Errorf("expected key %s/sc1, got %v", clusterName, key)
+	}
+
+	ctx := c.getClusterContext(clusterName)
+	l.RemoveCluster(tenantCluster)
+	if ctx.Err() == nil {
+		t.Errorf("expected the context of the removed cluster to be cancelled")
+	}
+	if c.MultiClusterController.GetCluster(clusterName) != nil {
+		t.Errorf("expected cluster %s to be removed", clusterName)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumesnapshotclass
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	snapshotv1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/snapshot/v1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if c.apiAbsent {
+		<-stopCh
+		return nil
+	}
+	if !cache.WaitForCacheSync(stopCh, c.vscSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting VolumeSnapshotClass checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if VolumeSnapshotClass keeps consistency between super master and tenant masters.
+func (c *controller) PatrollerDo(ctx context.Context) {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "volumesnapshotclass")
+		pa.ReportFailure(ctx)
+		return
+	}
+	clusterNames = pa.ActiveClusters(clusterNames)
+
+	wg := sync.WaitGroup{}
+	atomic.StoreUint64(&c.numMissMatchedVolumeSnapshotClasses, 0)
+
+	// sem bounds the number of tenant clusters being checked at the same time.
+	sem := make(chan struct{}, c.patrolConcurrency)
+	for _, clusterName := range clusterNames {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(clusterName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.checkVolumeSnapshotClassOfTenantCluster(ctx, clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		klog.Infof("volumesnapshotclass patrol is cancelled: %v", ctx.Err())
+		return
+	}
+
+	pVolumeSnapshotClassList := &snapshotv1.VolumeSnapshotClassList{}
+	if err := c.vscReader.List(ctx, pVolumeSnapshotClassList); err != nil {
+		klog.Errorf("error listing volumesnapshotclass from super master informer cache: %v", err)
+		pa.ReportFailure(ctx)
+		return
+	}
+
+	for i := range pVolumeSnapshotClassList.Items {
+		pVolumeSnapshotClass := &pVolumeSnapshotClassList.Items[i]
+		if !publicVolumeSnapshotClass(pVolumeSnapshotClass) {
+			continue
+		}
+		for _, clusterName := range clusterNames {
+			if err := c.MultiClusterController.Get(clusterName, "", pVolumeSnapshotClass.Name, &snapshotv1.VolumeSnapshotClass{}); err != nil {
+				if errors.IsNotFound(err) {
+					if c.patrollerDryRun {
+						klog.Infof("[dry-run] would requeue volumesnapshotclass %s for cluster %s", pVolumeSnapshotClass.Name, clusterName)
+						metrics.CheckerDryRunStats.WithLabelValues("RequeuedSuperMasterVolumeSnapshotClasses").Inc()
+						continue
+					}
+					metrics.CheckerRemedyStats.WithLabelValues("RequeuedSuperMasterVolumeSnapshotClasses").Inc()
+					c.UpwardController.AddToQueue(clusterName + "/" + pVolumeSnapshotClass.Name)
+				}
+				klog.Errorf("fail to get volumesnapshotclass from cluster %s: %v", clusterName, err)
+			}
+		}
+	}
+
+	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedVolumeSnapshotClasses").Set(float64(atomic.LoadUint64(&c.numMissMatchedVolumeSnapshotClasses)))
+}
+
+func (c *controller) checkVolumeSnapshotClassOfTenantCluster(ctx context.Context, clusterName string) {
+	defer metrics.RecordCheckerClusterScanDuration("VolumeSnapshotClass", clusterName, time.Now())
+	vscList := &snapshotv1.VolumeSnapshotClassList{}
+	if err := c.MultiClusterController.List(clusterName, vscList); err != nil {
+		klog.Errorf("error listing volumesnapshotclass from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check volumesnapshotclass consistency in cluster %s", clusterName)
+
+	for i := range vscList.Items {
+		vVolumeSnapshotClass := &vscList.Items[i]
+		if ctx.Err() != nil {
+			klog.V(4).Infof("stop checking volumesnapshotclass in cluster %s: %v", clusterName, ctx.Err())
+			return
+		}
+		pVolumeSnapshotClass := &snapshotv1.VolumeSnapshotClass{}
+		err := c.vscReader.Get(ctx, client.ObjectKey{Name: vVolumeSnapshotClass.Name}, pVolumeSnapshotClass)
+		// volumesnapshotclass which is no longer public is treated as orphan.
+		if errors.IsNotFound(err) || (err == nil && !publicVolumeSnapshotClass(pVolumeSnapshotClass)) {
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would delete orphan volumesnapshotclass %s in cluster %s", vVolumeSnapshotClass.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("DeletedOrphanTenantVolumeSnapshotClasses").Inc()
+				continue
+			}
+			// super master is the source of the truth for volumesnapshotclass object, delete tenant master obj
+			tenantClient, err := c.tenantClient(clusterName)
+			if err != nil {
+				klog.Errorf("error getting cluster %s client: %v", clusterName, err)
+				continue
+			}
+			deleteCtx, cancel := context.WithTimeout(ctx, c.patrolOpTimeout)
+			err = tenantClient.Delete(deleteCtx, vVolumeSnapshotClass, client.PropagationPolicy(constants.DefaultDeletionPolicy))
+			cancel()
+			if err != nil {
+				klog.Errorf("error deleting volumesnapshotclass %v in cluster %s: %v", vVolumeSnapshotClass.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanTenantVolumeSnapshotClasses").Inc()
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pVolumeSnapshotClass %s from super master cache: %v", vVolumeSnapshotClass.Name, err)
+			continue
+		}
+
+		updatedVolumeSnapshotClass := conversion.ConfigEquality(c.Config).CheckVolumeSnapshotClassEquality(pVolumeSnapshotClass, vVolumeSnapshotClass)
+		if updatedVolumeSnapshotClass != nil {
+			atomic.AddUint64(&c.numMissMatchedVolumeSnapshotClasses, 1)
+			klog.Warningf("spec of volumesnapshotclass %v diff in super&tenant master", vVolumeSnapshotClass.Name)
+			if c.patrollerDryRun {
+				klog.Infof("[dry-run] would requeue volumesnapshotclass %s for cluster %s", pVolumeSnapshotClass.Name, clusterName)
+				metrics.CheckerDryRunStats.WithLabelValues("RequeuedDiffVolumeSnapshotClasses").Inc()
+				continue
+			}
+			c.UpwardController.AddToQueue(clusterName + "/" + pVolumeSnapshotClass.Name)
+		}
+	}
+}
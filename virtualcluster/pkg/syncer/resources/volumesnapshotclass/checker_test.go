@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumesnapshotclass
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	snapshotv1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/snapshot/v1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+)
+
+func TestVolumeSnapshotClassPatrol(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedDeletedVObject []string
+		ExpectedRequeuedKeys   []string
+		DryRun                 bool
+	}{
+		"pVolumeSnapshotClass not public": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345"),
+			},
+		},
+		"pVolumeSnapshotClass exists, vVolumeSnapshotClass does not exist": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			ExpectedRequeuedKeys: []string{defaultClusterKey + "/snapshot"},
+		},
+		"pVolumeSnapshotClass not found, vVolumeSnapshotClass exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public),
+			},
+			ExpectedDeletedVObject: []string{"snapshot"},
+		},
+		"pVolumeSnapshotClass not public, vVolumeSnapshotClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public),
+			},
+			ExpectedDeletedVObject: []string{"snapshot"},
+		},
+		"pVolumeSnapshotClass exists, vVolumeSnapshotClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public),
+			},
+		},
+		"pVolumeSnapshotClass exists, vVolumeSnapshotClass exists with different driver": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public, func(class *snapshotv1.VolumeSnapshotClass) {
+					class.Driver = "csi.other.com"
+				}),
+			},
+			ExpectedRequeuedKeys: []string{defaultClusterKey + "/snapshot"},
+		},
+		"pVolumeSnapshotClass exists, vVolumeSnapshotClass exists with different deletionPolicy": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public, func(class *snapshotv1.VolumeSnapshotClass) {
+					class.DeletionPolicy = snapshotv1.VolumeSnapshotContentRetain
+				}),
+			},
+			ExpectedRequeuedKeys: []string{defaultClusterKey + "/snapshot"},
+		},
+		"pVolumeSnapshotClass exists, vVolumeSnapshotClass exists with different parameters": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public, func(class *snapshotv1.VolumeSnapshotClass) {
+					class.Parameters = map[string]string{"type": "full"}
+				}),
+			},
+			ExpectedRequeuedKeys: []string{defaultClusterKey + "/snapshot"},
+		},
+		"dry run, pVolumeSnapshotClass exists, vVolumeSnapshotClass does not exist": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			DryRun: true,
+		},
+		"dry run, pVolumeSnapshotClass not found, vVolumeSnapshotClass exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public),
+			},
+			DryRun: true,
+		},
+		"dry run, pVolumeSnapshotClass exists, vVolumeSnapshotClass exists with different driver": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public, func(class *snapshotv1.VolumeSnapshotClass) {
+					class.Driver = "csi.other.com"
+				}),
+			},
+			DryRun: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c, tenantClient := newFakeController(t, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant)
+			c.patrollerDryRun = tc.DryRun
+
+			c.PatrollerDo(context.TODO())
+
+			deleted := sets.NewString()
+			for _, obj := range tc.ExistingObjectInTenant {
+				vVolumeSnapshotClass := obj.(*snapshotv1.VolumeSnapshotClass)
+				if err := tenantClient.Get(context.TODO(), client.ObjectKey{Name: vVolumeSnapshotClass.Name}, &snapshotv1.VolumeSnapshotClass{}); errors.IsNotFound(err) {
+					deleted.Insert(vVolumeSnapshotClass.Name)
+				}
+			}
+			if expected := sets.NewString(tc.ExpectedDeletedVObject...); !deleted.Equal(expected) {
+				t.Errorf("%s: expected deleted vVolumeSnapshotClasses %v, got %v", k, expected.List(), deleted.List())
+			}
+
+			requeued := sets.NewString()
+			queue := c.UpwardController.Queue
+			for queue.Len() > 0 {
+				key, _ := queue.Get()
+				requeued.Insert(key.(string))
+				queue.Done(key)
+			}
+			if expected := sets.NewString(tc.ExpectedRequeuedKeys...); !requeued.Equal(expected) {
+				t.Errorf("%s: expected requeued keys %v, got %v", k, expected.List(), requeued.List())
+			}
+		})
+	}
+}
+
+func TestVolumeSnapshotClassAPIAbsent(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+	}
+
+	superClient := fake.NewSimpleClientset()
+	superClient.Resources = []*metav1.APIResourceList{
+		{GroupVersion: snapshotv1.SchemeGroupVersion.String(), APIResources: []metav1.APIResource{{Name: "volumesnapshots"}}},
+	}
+	// the super master rest config is not needed while the syncer is a no-op.
+	r, err := NewVolumeSnapshotClassController(&config.SyncerConfiguration{}, superClient, informers.NewSharedInformerFactory(superClient, 0), nil, nil, manager.ResourceSyncerOptions{})
+	if err != nil {
+		t.Fatalf("expected the controller to be a no-op, got error: %v", err)
+	}
+	c := r.(*controller)
+	if !c.apiAbsent {
+		t.Fatalf("expected the volumesnapshotclass API to be absent")
+	}
+
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), fakeClient.NewFakeClient())
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	l := c.GetListener()
+	l.AddCluster(tenantCluster)
+	l.WatchCluster(tenantCluster)
+	if c.MultiClusterController.GetCluster(conversion.ToClusterKey(testTenant)) != nil {
+		t.Errorf("expected the tenant cluster not to be watched")
+	}
+
+	stopCh := make(chan struct{})
+	close(stopCh)
+	if err := c.StartPatrol(stopCh); err != nil {
+		t.Errorf("expected the patroller to stop cleanly, got %v", err)
+	}
+	if err := c.StartUWS(stopCh); err != nil {
+		t.Errorf("expected the upward syncer to stop cleanly, got %v", err)
+	}
+}
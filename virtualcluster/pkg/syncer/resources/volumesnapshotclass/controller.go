@@ -0,0 +1,236 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumesnapshotclass
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	rinformer "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	snapshotv1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/snapshot/v1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	utilruntime.Must(snapshotv1.AddToScheme(scheme.Scheme))
+
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "volumesnapshotclass",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewVolumeSnapshotClassController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptionsFromConfig(ctx.Config.(*config.SyncerConfiguration)))
+		},
+		Disable: true,
+	})
+}
+
+type controller struct {
+	manager.BaseResourceSyncer
+	// apiAbsent indicates that super master does not serve snapshot.storage.k8s.io/v1, in which case
+	// the syncer does nothing.
+	apiAbsent bool
+	// super master volumesnapshotclasses cache/synced functions
+	vscCache  rinformer.Cache
+	vscSynced cache.InformerSynced
+	// vscReader reads the super master volumesnapshotclasses, it is vscCache unless replaced in tests.
+	vscReader client.Reader
+	// tenantClient returns the client writing the volumesnapshotclasses of a tenant master.
+	tenantClient func(clusterName string) (client.Client, error)
+	// patrollerDryRun indicates that the patroller only logs the remediation it would take.
+	patrollerDryRun bool
+	// patrolConcurrency is the max number of tenant clusters checked in parallel.
+	patrolConcurrency int
+	// patrolOpTimeout is the timeout of each tenant operation issued by the patroller.
+	patrolOpTimeout time.Duration
+	// numMissMatchedVolumeSnapshotClasses is the number of mismatched volumesnapshotclasses found in the last patrol.
+	numMissMatchedVolumeSnapshotClasses uint64
+}
+
+func NewVolumeSnapshotClassController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		patrollerDryRun:   options.PatrollerDryRun,
+		patrolConcurrency: constants.DefaultPatrolConcurrency,
+		patrolOpTimeout:   constants.DefaultPatrolOpTimeout,
+	}
+	if options.PatrolConcurrency > 0 {
+		c.patrolConcurrency = options.PatrolConcurrency
+	}
+	if options.PatrolOpTimeout > 0 {
+		c.patrolOpTimeout = options.PatrolOpTimeout
+	}
+	c.tenantClient = c.getTenantClient
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&snapshotv1.VolumeSnapshotClass{}, &snapshotv1.VolumeSnapshotClassList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	served, err := volumeSnapshotClassServed(client.Discovery())
+	if err != nil {
+		return nil, err
+	}
+	if !served {
+		klog.Warningf("super master does not serve %s volumesnapshotclasses, volumesnapshotclass syncer is a no-op", snapshotv1.SchemeGroupVersion)
+		c.apiAbsent = true
+		return c, nil
+	}
+
+	c.UpwardController, err = uw.NewUWController(&snapshotv1.VolumeSnapshotClass{}, c, uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&snapshotv1.VolumeSnapshotClass{}, c, pa.WithResourcePeriod(config, "volumesnapshotclass"), pa.WithResyncOnStart(config), pa.WithStartJitter(config), pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	if options.IsFake {
+		c.vscSynced = func() bool { return true }
+		return c, nil
+	}
+
+	if config.RestConfig == nil {
+		return nil, fmt.Errorf("cannot get super master restful config")
+	}
+	c.vscCache, err = rinformer.New(config.RestConfig, rinformer.Options{})
+	if err != nil {
+		return nil, err
+	}
+	c.vscReader = c.vscCache
+	vscInformer, err := c.vscCache.GetInformer(context.Background(), &snapshotv1.VolumeSnapshotClass{})
+	if err != nil {
+		return nil, err
+	}
+	c.vscSynced = vscInformer.HasSynced
+
+	vscInformer.AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *snapshotv1.VolumeSnapshotClass:
+					return publicVolumeSnapshotClass(t)
+				case cache.DeletedFinalStateUnknown:
+					if e, ok := t.Obj.(*snapshotv1.VolumeSnapshotClass); ok {
+						return publicVolumeSnapshotClass(e)
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *v1.VolumeSnapshotClass", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super master volumesnapshotclass controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueVolumeSnapshotClass,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newVolumeSnapshotClass := newObj.(*snapshotv1.VolumeSnapshotClass)
+					oldVolumeSnapshotClass := oldObj.(*snapshotv1.VolumeSnapshotClass)
+					if newVolumeSnapshotClass.ResourceVersion != oldVolumeSnapshotClass.ResourceVersion {
+						c.enqueueVolumeSnapshotClass(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueVolumeSnapshotClass,
+			},
+		})
+	return c, nil
+}
+
+// volumeSnapshotClassServed checks whether super master serves volumesnapshotclasses. The resource is
+// defined by a CRD installed together with the CSI snapshot controller, which may be absent.
+func volumeSnapshotClassServed(dc discovery.DiscoveryInterface) (bool, error) {
+	resources, err := dc.ServerResourcesForGroupVersion(snapshotv1.SchemeGroupVersion.String())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == "volumesnapshotclasses" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *controller) GetListener() listener.ClusterChangeListener {
+	if c.apiAbsent {
+		return noopListener{}
+	}
+	return c.BaseResourceSyncer.GetListener()
+}
+
+// noopListener ignores tenant cluster changes, it is used when super master does not serve volumesnapshotclasses.
+type noopListener struct{}
+
+var _ listener.ClusterChangeListener = noopListener{}
+
+func (noopListener) AddCluster(cluster mc.ClusterInterface)    {}
+func (noopListener) WatchCluster(cluster mc.ClusterInterface)  {}
+func (noopListener) RemoveCluster(cluster mc.ClusterInterface) {}
+
+func publicVolumeSnapshotClass(e *snapshotv1.VolumeSnapshotClass) bool {
+	// We only backpopulate specific volumesnapshotclass to tenant masters
+	return e.Labels[constants.PublicObjectKey] == "true"
+}
+
+func (c *controller) enqueueVolumeSnapshotClass(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.Infof("No tenant masters, stop backpopulate volumesnapshotclass %v", key)
+		return
+	}
+
+	for _, clusterName := range clusterNames {
+		c.UpwardController.AddToQueue(clusterName + "/" + key)
+	}
+}
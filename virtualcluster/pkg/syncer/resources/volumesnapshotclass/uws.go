@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumesnapshotclass
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	snapshotv1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/snapshot/v1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	utilerrors "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if c.apiAbsent {
+		<-stopCh
+		return nil
+	}
+	go c.vscCache.Start(context.Background())
+
+	if !cache.WaitForCacheSync(stopCh, c.vscSynced) {
+		return fmt.Errorf("failed to wait for caches to sync volumesnapshotclass")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+func (c *controller) BackPopulate(key string) error {
+	// The key format is clustername/volumesnapshotclassName.
+	clusterName, name, _ := cache.SplitMetaNamespaceKey(key)
+
+	op := reconciler.AddEvent
+	pVolumeSnapshotClass := &snapshotv1.VolumeSnapshotClass{}
+	if err := c.vscReader.Get(context.TODO(), client.ObjectKey{Name: name}, pVolumeSnapshotClass); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		op = reconciler.DeleteEvent
+	} else if !publicVolumeSnapshotClass(pVolumeSnapshotClass) {
+		op = reconciler.DeleteEvent
+	}
+
+	tenantClient, err := c.tenantClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+
+	vVolumeSnapshotClass := &snapshotv1.VolumeSnapshotClass{}
+	if err := c.MultiClusterController.Get(clusterName, "", name, vVolumeSnapshotClass); err != nil {
+		if errors.IsNotFound(err) {
+			if op == reconciler.AddEvent {
+				// Available in super, hence create a new in tenant master
+				vVolumeSnapshotClass := conversion.BuildVirtualVolumeSnapshotClass(clusterName, pVolumeSnapshotClass)
+				if err := tenantClient.Create(context.TODO(), vVolumeSnapshotClass); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return err
+	}
+
+	if op == reconciler.DeleteEvent {
+		opts := []client.DeleteOption{
+			client.PropagationPolicy(constants.DefaultDeletionPolicy),
+			client.Preconditions{UID: &vVolumeSnapshotClass.UID},
+		}
+		if err := tenantClient.Delete(context.TODO(), vVolumeSnapshotClass, opts...); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	} else {
+		updatedVolumeSnapshotClass := conversion.ConfigEquality(c.Config).CheckVolumeSnapshotClassEquality(pVolumeSnapshotClass, vVolumeSnapshotClass)
+		if updatedVolumeSnapshotClass != nil {
+			if err := tenantClient.Update(context.TODO(), updatedVolumeSnapshotClass); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getTenantClient returns a client of the tenant master. The client-go clientset does not
+// know volumesnapshotclasses, hence a controller-runtime client is used.
+func (c *controller) getTenantClient(clusterName string) (client.Client, error) {
+	cluster := c.MultiClusterController.GetCluster(clusterName)
+	if cluster == nil {
+		return nil, utilerrors.NewClusterNotFound(clusterName)
+	}
+	restConfig := cluster.GetRestConfig()
+	if restConfig == nil {
+		return nil, fmt.Errorf("cannot get virtual cluster restful config")
+	}
+	return client.New(restConfig, client.Options{})
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumesnapshotclass
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	snapshotv1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/snapshot/v1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+)
+
+func makeVolumeSnapshotClass(name, uid string, mFuncs ...func(*snapshotv1.VolumeSnapshotClass)) *snapshotv1.VolumeSnapshotClass {
+	class := &snapshotv1.VolumeSnapshotClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VolumeSnapshotClass",
+			APIVersion: snapshotv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(uid),
+		},
+		Driver:         "csi.example.com",
+		DeletionPolicy: snapshotv1.VolumeSnapshotContentDelete,
+		Parameters: map[string]string{
+			"type": "incremental",
+		},
+	}
+
+	for _, f := range mFuncs {
+		f(class)
+	}
+	return class
+}
+
+func public(class *snapshotv1.VolumeSnapshotClass) {
+	class.Labels = map[string]string{
+		constants.PublicObjectKey: "true",
+	}
+}
+
+// snapshotResources are the discovered super master resources which include volumesnapshotclasses.
+var snapshotResources = []*metav1.APIResourceList{
+	{GroupVersion: snapshotv1.SchemeGroupVersion.String(), APIResources: []metav1.APIResource{{Name: "volumesnapshotclasses"}}},
+}
+
+// newFakeController returns a controller whose super master holds existingObjectInSuper, along with the
+// client of a registered tenant cluster holding existingObjectInTenant.
+func newFakeController(t *testing.T, testTenant *v1alpha1.VirtualCluster, existingObjectInSuper, existingObjectInTenant []runtime.Object) (*controller, client.Client) {
+	superClient := fake.NewSimpleClientset()
+	superClient.Resources = snapshotResources
+	r, err := NewVolumeSnapshotClassController(&config.SyncerConfiguration{}, superClient, informers.NewSharedInformerFactory(superClient, 0), nil, nil, manager.ResourceSyncerOptions{IsFake: true})
+	if err != nil {
+		t.Fatalf("error creating controller: %v", err)
+	}
+	c := r.(*controller)
+	c.vscReader = fakeClient.NewFakeClient(existingObjectInSuper...)
+
+	tenantClient := fakeClient.NewFakeClient(existingObjectInTenant...)
+	tenantCluster, err := cluster.NewFakeTenantCluster(testTenant, fake.NewSimpleClientset(), tenantClient)
+	if err != nil {
+		t.Fatalf("error creating tenant cluster: %v", err)
+	}
+	c.GetListener().AddCluster(tenantCluster)
+	c.tenantClient = func(string) (client.Client, error) {
+		return tenantClient, nil
+	}
+	return c, tenantClient
+}
+
+func TestUWVolumeSnapshotClass(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedTenantObject   *snapshotv1.VolumeSnapshotClass
+	}{
+		"pVolumeSnapshotClass public, vVolumeSnapshotClass does not exist": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			ExpectedTenantObject: makeVolumeSnapshotClass("snapshot", "", public),
+		},
+		"pVolumeSnapshotClass not public, vVolumeSnapshotClass does not exist": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345"),
+			},
+		},
+		"pVolumeSnapshotClass not found, vVolumeSnapshotClass exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public),
+			},
+		},
+		"pVolumeSnapshotClass not public, vVolumeSnapshotClass exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public),
+			},
+		},
+		"pVolumeSnapshotClass exists, vVolumeSnapshotClass exists with different spec": {
+			ExistingObjectInSuper: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "12345", public),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				makeVolumeSnapshotClass("snapshot", "123456", public, func(class *snapshotv1.VolumeSnapshotClass) {
+					class.Driver = "csi.other.com"
+					class.DeletionPolicy = snapshotv1.VolumeSnapshotContentRetain
+					class.Parameters = nil
+				}),
+			},
+			ExpectedTenantObject: makeVolumeSnapshotClass("snapshot", "123456", public),
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c, tenantClient := newFakeController(t, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant)
+
+			if err := c.BackPopulate(defaultClusterKey + "/snapshot"); err != nil {
+				t.Fatalf("%s: error back populating: %v", k, err)
+			}
+
+			vVolumeSnapshotClass := &snapshotv1.VolumeSnapshotClass{}
+			err := tenantClient.Get(context.TODO(), client.ObjectKey{Name: "snapshot"}, vVolumeSnapshotClass)
+			if tc.ExpectedTenantObject == nil {
+				if !errors.IsNotFound(err) {
+					t.Errorf("%s: expected no vVolumeSnapshotClass, got %v", k, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: error getting vVolumeSnapshotClass: %v", k, err)
+			}
+			expected := tc.ExpectedTenantObject
+			if vVolumeSnapshotClass.Driver != expected.Driver || vVolumeSnapshotClass.DeletionPolicy != expected.DeletionPolicy ||
+				!equality.Semantic.DeepEqual(vVolumeSnapshotClass.Parameters, expected.Parameters) {
+				t.Errorf("%s: expected vVolumeSnapshotClass %+v, got %+v", k, expected, vVolumeSnapshotClass)
+			}
+		})
+	}
+}
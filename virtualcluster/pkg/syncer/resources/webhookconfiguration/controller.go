@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookconfiguration
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+// The admission of the tenant objects happens in super master, where the namespaces of all tenants are served
+// by the same apiserver. The tenant webhooks are hence only populated to super master if the syncer is configured
+// to do so, in which case they are scoped to the namespaces of the tenant. Otherwise they are ignored and a warning
+// event tells the tenant why.
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "mutatingwebhookconfiguration",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewMutatingWebhookConfigurationController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+	})
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "validatingwebhookconfiguration",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewValidatingWebhookConfigurationController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+	})
+}
+
+// ignoredReporter records a warning event for each tenant webhook configuration which is not populated to super
+// master. The event is recorded once per generation so that the periodic resyncs do not flood tenant masters.
+type ignoredReporter struct {
+	kind       string
+	apiVersion string
+	sync.Mutex
+	// reported is the last reported generation of each tenant object, keyed by clusterName/name.
+	reported map[string]int64
+}
+
+func newIgnoredReporter(kind, apiVersion string) *ignoredReporter {
+	return &ignoredReporter{
+		kind:       kind,
+		apiVersion: apiVersion,
+		reported:   make(map[string]int64),
+	}
+}
+
+func (r *ignoredReporter) report(mcc *mc.MultiClusterController, clusterName, name string, uid types.UID, generation int64) {
+	key := clusterName + "/" + name
+	r.Lock()
+	if last, exists := r.reported[key]; exists && last == generation {
+		r.Unlock()
+		return
+	}
+	r.reported[key] = generation
+	r.Unlock()
+
+	err := mcc.Eventf(clusterName, &corev1.ObjectReference{
+		Kind:       r.kind,
+		APIVersion: r.apiVersion,
+		Name:       name,
+		UID:        uid,
+	}, corev1.EventTypeWarning, "WebhookIgnored",
+		"%s %s is not honored: tenant objects are admitted by the super cluster shared by all tenants, "+
+			"the syncer populates tenant webhooks scoped to the tenant namespaces only if --sync-tenant-webhooks is set", r.kind, name)
+	if err != nil {
+		klog.Errorf("failed to record event for %s %s in cluster %s: %v", r.kind, name, clusterName, err)
+	}
+}
+
+// forget drops the record of a deleted tenant object.
+func (r *ignoredReporter) forget(clusterName, name string) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.reported, clusterName+"/"+name)
+}
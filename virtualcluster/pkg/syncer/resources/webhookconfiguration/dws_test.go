@@ -0,0 +1,338 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookconfiguration
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+var testTenant = &v1alpha1.VirtualCluster{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "test",
+		Namespace: "tenant-1",
+		UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+	},
+	Spec: v1alpha1.VirtualClusterSpec{},
+	Status: v1alpha1.VirtualClusterStatus{
+		Phase: v1alpha1.ClusterRunning,
+	},
+}
+
+// syncWebhooks returns a constructor which populates the tenant webhooks to super master.
+func syncWebhooks(newFn manager.ResourceSyncerNew) manager.ResourceSyncerNew {
+	return func(cfg *config.SyncerConfiguration, client clientset.Interface, informer informers.SharedInformerFactory,
+		vcClient vcclient.Interface, vcInformer vcinformers.VirtualClusterInformer, options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+		cfg.SyncTenantWebhooks = true
+		return newFn(cfg, client, informer, vcClient, vcInformer, options)
+	}
+}
+
+func tenantMutatingWebhookConfiguration(name, uid string) *v1.MutatingWebhookConfiguration {
+	scope := v1.AllScopes
+	return &v1.MutatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MutatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(uid),
+		},
+		Webhooks: []v1.MutatingWebhook{
+			{
+				Name: "inject.example.com",
+				ClientConfig: v1.WebhookClientConfig{
+					Service: &v1.ServiceReference{Namespace: "webhook", Name: "injector"},
+				},
+				Rules: []v1.RuleWithOperations{
+					{
+						Operations: []v1.OperationType{v1.Create},
+						Rule:       v1.Rule{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}, Scope: &scope},
+					},
+				},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{corev1.LabelMetadataName: "default"},
+				},
+			},
+		},
+	}
+}
+
+func superMutatingWebhookConfiguration(name, uid, clusterKey string) *v1.MutatingWebhookConfiguration {
+	return &v1.MutatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MutatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: conversion.ToSuperMasterClusterScopedName(clusterKey, name),
+			Annotations: map[string]string{
+				constants.LabelUID:         uid,
+				constants.LabelCluster:     clusterKey,
+				constants.LabelVCName:      testTenant.Name,
+				constants.LabelVCNamespace: testTenant.Namespace,
+			},
+		},
+	}
+}
+
+// recordEvents collects the events created in tenant master.
+func recordEvents(events *[]*corev1.Event, lock *sync.Mutex) util.FakeClientSetMutator {
+	return func(tenantClientset, superClientset *fake.Clientset) {
+		tenantClientset.PrependReactor("create", "events", func(action core.Action) (bool, runtime.Object, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			*events = append(*events, action.(core.CreateAction).GetObject().(*corev1.Event))
+			return false, nil, nil
+		})
+	}
+}
+
+func TestMutatingWebhookConfigurationIgnored(t *testing.T) {
+	var events []*corev1.Event
+	var lock sync.Mutex
+	vObj := tenantMutatingWebhookConfiguration("injector", "12345")
+	actions, reconcileErr, err := util.RunDownwardSync(NewMutatingWebhookConfigurationController, testTenant, nil, []runtime.Object{vObj}, vObj, recordEvents(&events, &lock))
+	if err != nil {
+		t.Fatalf("error running downward sync: %v", err)
+	}
+	if reconcileErr != nil {
+		t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+	}
+	if len(actions) != 0 {
+		t.Errorf("Expect no operation in super master, got %v", actions)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(events) != 1 {
+		t.Fatalf("Expect 1 event in tenant master, got %v", events)
+	}
+	if events[0].Type != corev1.EventTypeWarning || events[0].Reason != "WebhookIgnored" || events[0].InvolvedObject.Name != "injector" {
+		t.Errorf("Unexpected event %+v", events[0])
+	}
+}
+
+func TestMutatingWebhookConfigurationDWS(t *testing.T) {
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	targetName := conversion.ToSuperMasterClusterScopedName(defaultClusterKey, "injector")
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueueObject          runtime.Object
+		ExpectedCreatedPObject []string
+		ExpectedUpdatedPObject []string
+		ExpectedDeletedPObject []string
+		ExpectedError          string
+		ExpectedNoOperation    bool
+	}{
+		"new webhook configuration": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantMutatingWebhookConfiguration("injector", "12345"),
+			},
+			EnqueueObject:          tenantMutatingWebhookConfiguration("injector", "12345"),
+			ExpectedCreatedPObject: []string{targetName},
+		},
+		"webhook configuration exists with different webhooks": {
+			ExistingObjectInSuper: []runtime.Object{
+				superMutatingWebhookConfiguration("injector", "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantMutatingWebhookConfiguration("injector", "12345"),
+			},
+			EnqueueObject:          tenantMutatingWebhookConfiguration("injector", "12345"),
+			ExpectedUpdatedPObject: []string{targetName},
+		},
+		"webhook configuration exists with different uid": {
+			ExistingObjectInSuper: []runtime.Object{
+				superMutatingWebhookConfiguration("injector", "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantMutatingWebhookConfiguration("injector", "12345"),
+			},
+			EnqueueObject: tenantMutatingWebhookConfiguration("injector", "12345"),
+			ExpectedError: "delegated UID is different",
+		},
+		"deleted webhook configuration": {
+			ExistingObjectInSuper: []runtime.Object{
+				superMutatingWebhookConfiguration("injector", "12345", defaultClusterKey),
+			},
+			EnqueueObject:          tenantMutatingWebhookConfiguration("injector", "12345"),
+			ExpectedDeletedPObject: []string{targetName},
+		},
+		"deleted webhook configuration not in super master": {
+			EnqueueObject:       tenantMutatingWebhookConfiguration("injector", "12345"),
+			ExpectedNoOperation: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(syncWebhooks(NewMutatingWebhookConfigurationController), testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedNoOperation {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+				}
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else if tc.ExpectedError != "" {
+				t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+			}
+
+			for _, expectedName := range tc.ExpectedCreatedPObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("create", "mutatingwebhookconfigurations") {
+						continue
+					}
+					created := action.(core.CreateAction).GetObject().(*v1.MutatingWebhookConfiguration)
+					if created.Name != expectedName {
+						t.Errorf("%s: Expected created %s, got %s", k, expectedName, created.Name)
+					}
+					checkTenantScoped(t, created.Webhooks[0].ClientConfig, created.Webhooks[0].Rules, created.Webhooks[0].NamespaceSelector, defaultClusterKey)
+					matched = true
+				}
+				if !matched {
+					t.Errorf("%s: Expect created %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedUpdatedPObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("update", "mutatingwebhookconfigurations") {
+						continue
+					}
+					updated := action.(core.UpdateAction).GetObject().(*v1.MutatingWebhookConfiguration)
+					if updated.Name != expectedName {
+						t.Errorf("%s: Expected updated %s, got %s", k, expectedName, updated.Name)
+					}
+					checkTenantScoped(t, updated.Webhooks[0].ClientConfig, updated.Webhooks[0].Rules, updated.Webhooks[0].NamespaceSelector, defaultClusterKey)
+					matched = true
+				}
+				if !matched {
+					t.Errorf("%s: Expect updated %s but not found", k, expectedName)
+				}
+			}
+
+			for _, expectedName := range tc.ExpectedDeletedPObject {
+				matched := false
+				for _, action := range actions {
+					if !action.Matches("delete", "mutatingwebhookconfigurations") {
+						continue
+					}
+					if name := action.(core.DeleteAction).GetName(); name != expectedName {
+						t.Errorf("%s: Expected deleted %s, got %s", k, expectedName, name)
+					}
+					matched = true
+				}
+				if !matched {
+					t.Errorf("%s: Expect deleted %s but not found", k, expectedName)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatingWebhookConfigurationDWS(t *testing.T) {
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	mutating := tenantMutatingWebhookConfiguration("policy", "12345")
+	vObj := &v1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ValidatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: mutating.ObjectMeta,
+		Webhooks: []v1.ValidatingWebhook{
+			{
+				Name:         "policy.example.com",
+				ClientConfig: mutating.Webhooks[0].ClientConfig,
+				Rules:        mutating.Webhooks[0].Rules,
+			},
+		},
+	}
+
+	actions, reconcileErr, err := util.RunDownwardSync(syncWebhooks(NewValidatingWebhookConfigurationController), testTenant, nil, []runtime.Object{vObj}, vObj, nil)
+	if err != nil {
+		t.Fatalf("error running downward sync: %v", err)
+	}
+	if reconcileErr != nil {
+		t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+	}
+	if len(actions) != 1 || !actions[0].Matches("create", "validatingwebhookconfigurations") {
+		t.Fatalf("Expect to create validatingwebhookconfiguration, got %v", actions)
+	}
+	created := actions[0].(core.CreateAction).GetObject().(*v1.ValidatingWebhookConfiguration)
+	if expected := conversion.ToSuperMasterClusterScopedName(defaultClusterKey, "policy"); created.Name != expected {
+		t.Errorf("Expected created %s, got %s", expected, created.Name)
+	}
+	checkTenantScoped(t, created.Webhooks[0].ClientConfig, created.Webhooks[0].Rules, created.Webhooks[0].NamespaceSelector, defaultClusterKey)
+}
+
+func checkTenantScoped(t *testing.T, clientConfig v1.WebhookClientConfig, rules []v1.RuleWithOperations, selector *metav1.LabelSelector, clusterKey string) {
+	t.Helper()
+	if expected := conversion.ToSuperMasterNamespace(clusterKey, "webhook"); clientConfig.Service.Namespace != expected {
+		t.Errorf("Expected webhook service namespace %s, got %s", expected, clientConfig.Service.Namespace)
+	}
+	for _, rule := range rules {
+		if rule.Scope == nil || *rule.Scope != v1.NamespacedScope {
+			t.Errorf("Expected namespaced rule scope, got %v", rule.Scope)
+		}
+	}
+	if selector == nil {
+		t.Fatalf("Expected namespace selector, got nil")
+	}
+	if selector.MatchLabels[constants.LabelVCName] != testTenant.Name || selector.MatchLabels[constants.LabelVCNamespace] != testTenant.Namespace {
+		t.Errorf("Expected namespace selector of the tenant, got %v", selector.MatchLabels)
+	}
+	if name, exists := selector.MatchLabels[corev1.LabelMetadataName]; exists && name != conversion.ToSuperMasterNamespace(clusterKey, "default") {
+		t.Errorf("Expected namespace name in super master, got %s", name)
+	}
+}
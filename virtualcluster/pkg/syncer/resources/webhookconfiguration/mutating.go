@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookconfiguration
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	v1admissionregistration "k8s.io/client-go/kubernetes/typed/admissionregistration/v1"
+	listersv1 "k8s.io/client-go/listers/admissionregistration/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+type mutatingController struct {
+	manager.BaseResourceSyncer
+	// super master mutatingwebhookconfiguration client
+	client v1admissionregistration.MutatingWebhookConfigurationsGetter
+	// super master mutatingwebhookconfiguration informer lister/synced function
+	lister listersv1.MutatingWebhookConfigurationLister
+	synced cache.InformerSynced
+	// ignored reports the tenant objects which are not populated to super master.
+	ignored *ignoredReporter
+}
+
+func NewMutatingWebhookConfigurationController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &mutatingController{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		client:  client.AdmissionregistrationV1(),
+		ignored: newIgnoredReporter("MutatingWebhookConfiguration", v1.SchemeGroupVersion.String()),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&v1.MutatingWebhookConfiguration{}, &v1.MutatingWebhookConfigurationList{}, c, mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	// super master webhook configurations are only watched if the tenant webhooks are populated.
+	if !config.SyncTenantWebhooks {
+		return c, nil
+	}
+	c.lister = informer.Admissionregistration().V1().MutatingWebhookConfigurations().Lister()
+	if options.IsFake {
+		c.synced = func() bool { return true }
+	} else {
+		c.synced = informer.Admissionregistration().V1().MutatingWebhookConfigurations().Informer().HasSynced
+	}
+	return c, nil
+}
+
+func (c *mutatingController) StartDWS(stopCh <-chan struct{}) error {
+	if c.Config.SyncTenantWebhooks && !cache.WaitForCacheSync(stopCh, c.synced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+// The reconcile logic for tenant master mutatingwebhookconfiguration informer
+func (c *mutatingController) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	klog.V(4).Infof("reconcile mutatingwebhookconfiguration %s event for cluster %s", request.Name, request.ClusterName)
+
+	vExists := true
+	vObj := &v1.MutatingWebhookConfiguration{}
+	if err := c.MultiClusterController.Get(request.ClusterName, "", request.Name, vObj); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		vExists = false
+	}
+
+	if !c.Config.SyncTenantWebhooks {
+		if vExists {
+			c.ignored.report(c.MultiClusterController, request.ClusterName, request.Name, vObj.UID, vObj.Generation)
+		} else {
+			c.ignored.forget(request.ClusterName, request.Name)
+		}
+		return reconciler.Result{}, nil
+	}
+
+	targetName := conversion.ToSuperMasterClusterScopedName(request.ClusterName, request.Name)
+	pExists := true
+	pObj, err := c.lister.Get(targetName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		pExists = false
+	}
+
+	if vExists && !pExists {
+		err := c.reconcileCreate(request.ClusterName, targetName, request.UID, vObj)
+		if err != nil {
+			klog.Errorf("failed reconcile mutatingwebhookconfiguration %s CREATE of cluster %s %v", request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if !vExists && pExists {
+		err := c.reconcileRemove(request.ClusterName, targetName, request.UID, pObj)
+		if err != nil {
+			klog.Errorf("failed reconcile mutatingwebhookconfiguration %s DELETE of cluster %s %v", request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else if vExists && pExists {
+		err := c.reconcileUpdate(request.ClusterName, targetName, request.UID, pObj, vObj)
+		if err != nil {
+			klog.Errorf("failed reconcile mutatingwebhookconfiguration %s UPDATE of cluster %s %v", request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	} else {
+		// object is gone.
+	}
+	return reconciler.Result{}, nil
+}
+
+func (c *mutatingController) reconcileCreate(clusterName, targetName, requestUID string, vObj *v1.MutatingWebhookConfiguration) error {
+	vcName, vcNS, _, err := c.MultiClusterController.GetOwnerInfo(clusterName)
+	if err != nil {
+		return err
+	}
+	newObj, err := conversion.BuildMetadata(clusterName, vcNS, vcName, "", vObj)
+	if err != nil {
+		return err
+	}
+
+	pObj := newObj.(*v1.MutatingWebhookConfiguration)
+	pObj.Name = targetName
+	conversion.MutateMutatingWebhooks(pObj.Webhooks, clusterName, vcName, vcNS)
+
+	pObj, err = c.client.MutatingWebhookConfigurations().Create(context.TODO(), pObj, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		if pObj.Annotations[constants.LabelUID] == requestUID {
+			klog.Infof("mutatingwebhookconfiguration %s of cluster %s already exist in super master", targetName, clusterName)
+			return nil
+		} else {
+			return fmt.Errorf("pMutatingWebhookConfiguration %s exists but its delegated object UID is different.", targetName)
+		}
+	}
+	return err
+}
+
+func (c *mutatingController) reconcileUpdate(clusterName, targetName, requestUID string, pObj, vObj *v1.MutatingWebhookConfiguration) error {
+	if pObj.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("pMutatingWebhookConfiguration %s delegated UID is different from updated object.", targetName)
+	}
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	updated := conversion.Equality(c.Config, vc).CheckMutatingWebhookConfigurationEquality(pObj, vObj)
+	if updated != nil {
+		_, err = c.client.MutatingWebhookConfigurations().Update(context.TODO(), updated, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *mutatingController) reconcileRemove(clusterName, targetName, requestUID string, pObj *v1.MutatingWebhookConfiguration) error {
+	if pObj.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("To be deleted pMutatingWebhookConfiguration %s delegated UID is different from deleted object.", targetName)
+	}
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+	}
+	err := c.client.MutatingWebhookConfigurations().Delete(context.TODO(), targetName, *opts)
+	if errors.IsNotFound(err) {
+		klog.Warningf("mutatingwebhookconfiguration %s of cluster %s not found in super master", targetName, clusterName)
+		return nil
+	}
+	return err
+}
@@ -18,9 +18,12 @@ package syncer
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -32,10 +35,12 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	certutil "k8s.io/client-go/util/cert"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
@@ -49,6 +54,8 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/generic"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
 	utilconst "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
@@ -79,6 +86,16 @@ type Syncer struct {
 	// clusterSet holds the cluster collection in which cluster is running.
 	mu         sync.Mutex
 	clusterSet map[string]mc.ClusterInterface
+	// clusterSelector decides which virtual clusters are managed by this syncer.
+	clusterSelector *ClusterSelector
+	// patrolTriggers are the resource syncers whose patroller can be run on demand, keyed by plugin ID.
+	patrolTriggers map[string]manager.PatrolTrigger
+	// publicNameCheckers are the resource syncers of the public cluster scoped objects, keyed by plugin ID.
+	publicNameCheckers map[string]manager.PublicNameChecker
+	// patrolHealthCheckers are the resource syncers whose patroller liveness is probed, keyed by plugin ID.
+	patrolHealthCheckers map[string]manager.PatrolHealthChecker
+	// stopped is closed once the resource syncers stop, it is nil if the syncer is not run.
+	stopped chan struct{}
 }
 
 type virtualclusterGetter struct {
@@ -98,7 +115,13 @@ func (v *virtualclusterGetter) GetObject(namespace, name string) (client.Object,
 // Bootstrap is a bootstrapping interface for syncer, targets the initialization protocol
 type Bootstrap interface {
 	ListenAndServe(address, certFile, keyFile string)
+	// ListenAndServeAdmin serves the endpoints changing the syncer state to the clients authenticated by clientCAFile.
+	ListenAndServeAdmin(address, certFile, keyFile, clientCAFile string)
 	Run(<-chan struct{})
+	// PatrolHealthz is the health check which fails if a patroller is unhealthy.
+	PatrolHealthz(r *http.Request) error
+	// WaitForShutdown blocks until the resource syncers stop after the syncer is stopped.
+	WaitForShutdown()
 }
 
 func New(
@@ -108,16 +131,27 @@ func New(
 	metaClusterClient clientset.Interface,
 	superClusterClient clientset.Interface,
 	superClusterInformers informers.SharedInformerFactory,
+	extraSuperClusters map[string]plugin.SuperCluster,
 	recorder record.EventRecorder,
 ) (*Syncer, error) {
+	clusterSelector, err := NewClusterSelector(config)
+	if err != nil {
+		return nil, err
+	}
+
 	syncer := &Syncer{
-		config:      config,
-		metaClient:  metaClusterClient,
-		superClient: superClusterClient,
-		recorder:    recorder,
-		queue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "virtual_cluster"),
-		workers:     constants.UwsControllerWorkerLow,
-		clusterSet:  make(map[string]mc.ClusterInterface),
+		config:          config,
+		metaClient:      metaClusterClient,
+		superClient:     superClusterClient,
+		recorder:        recorder,
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "virtual_cluster"),
+		workers:         constants.UwsControllerWorkerLow,
+		clusterSet:      make(map[string]mc.ClusterInterface),
+		clusterSelector: clusterSelector,
+
+		patrolTriggers:       make(map[string]manager.PatrolTrigger),
+		publicNameCheckers:   make(map[string]manager.PublicNameChecker),
+		patrolHealthCheckers: make(map[string]manager.PatrolHealthChecker),
 	}
 
 	// Handle VirtualCluster add&delete
@@ -138,6 +172,8 @@ func New(
 	syncer.lister = virtualClusterInformer.Lister()
 	syncer.virtualClusterSynced = virtualClusterInformer.Informer().HasSynced
 
+	metrics.SetPerClusterLabels(config.MetricsPerClusterLabels)
+
 	// Create the multi cluster controller manager
 	multiClusterControllerManager := manager.New()
 	syncer.controllerManager = multiClusterControllerManager
@@ -150,6 +186,8 @@ func New(
 		Informer:   superClusterInformers,
 		VCClient:   virtualClusterClient,
 		VCInformer: virtualClusterInformer,
+
+		ExtraSuperClusters: extraSuperClusters,
 	}
 
 	for _, p := range plugins {
@@ -165,25 +203,74 @@ func New(
 		s, ok := instance.(manager.ResourceSyncer)
 		if ok {
 			multiClusterControllerManager.AddResourceSyncer(s)
+			if t, ok := s.(manager.PatrolTrigger); ok {
+				syncer.patrolTriggers[p.ID] = t
+			}
+			if n, ok := s.(manager.PublicNameChecker); ok {
+				syncer.publicNameCheckers[p.ID] = n
+			}
+			if h, ok := s.(manager.PatrolHealthChecker); ok {
+				syncer.patrolHealthCheckers[p.ID] = h
+			}
 		} else {
 			klog.Warningf("unrecognized plugin %q", p.ID)
 		}
 	}
 
+	if len(config.GenericSyncingResources) > 0 {
+		if config.RestConfig == nil {
+			return nil, fmt.Errorf("cannot get super master restful config for the generic resource syncers")
+		}
+		dynamicClient, err := dynamic.NewForConfig(config.RestConfig)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range config.GenericSyncingResources {
+			klog.Infof("loading generic resource syncer of %s.%s/%s...", r.Resource, r.Group, r.Version)
+			s, err := generic.NewGenericController(config, r, dynamicClient, manager.ResourceSyncerOptions{})
+			if err != nil {
+				klog.Errorf("failed to load generic resource syncer of %s.%s/%s", r.Resource, r.Group, r.Version)
+				return nil, err
+			}
+			multiClusterControllerManager.AddResourceSyncer(s)
+		}
+	}
+
 	return syncer, nil
 }
 
+// LoadPlugins returns the resource syncers to run. A resource syncer is enabled by default unless it is registered
+// as disabled, the disabled ones are enabled by config.ExtraSyncingResources. config.SyncerFeatureGates overrides
+// both. The disabled resource syncers are never initialized, hence neither their syncers nor patrollers run.
 func LoadPlugins(config *config.SyncerConfiguration) []*plugin.Registration {
 	allPlugin := plugin.SyncerResourceRegister.List()
 	var enablePlugin []*plugin.Registration
 	extraSets := sets.NewString(config.ExtraSyncingResources...)
 
+	// The gates are keyed by the resource names in any case, e.g., StorageClass, the plugin IDs are lower case.
+	gates := make(map[string]bool, len(config.SyncerFeatureGates))
+	for k, v := range config.SyncerFeatureGates {
+		gates[strings.ToLower(k)] = v
+	}
+
 	for i, r := range allPlugin {
-		if !r.Disable || extraSets.Has(r.ID) {
+		enabled := !r.Disable || extraSets.Has(r.ID)
+		if gate, ok := gates[r.ID]; ok {
+			enabled = gate
+			delete(gates, r.ID)
+			if !gate {
+				klog.Infof("resource syncer %s is disabled by syncer feature gates", r.ID)
+			}
+		}
+		if enabled {
 			enablePlugin = append(enablePlugin, allPlugin[i])
 		}
 	}
 
+	for id := range gates {
+		klog.Warningf("unknown resource %q in syncer feature gates, ignore it", id)
+	}
+
 	return enablePlugin
 }
 
@@ -227,7 +314,12 @@ func (s *Syncer) Run(stopChan <-chan struct{}) {
 			os.Exit(1)
 		}
 	}
+	stopped := make(chan struct{})
+	s.mu.Lock()
+	s.stopped = stopped
+	s.mu.Unlock()
 	go func() {
+		defer close(stopped)
 		if err := s.controllerManager.Start(stopChan); err != nil {
 			klog.V(1).Infof("controller manager exit: %v", err)
 		}
@@ -254,11 +346,23 @@ func (s *Syncer) Run(stopChan <-chan struct{}) {
 	return
 }
 
+// WaitForShutdown blocks until the resource syncers stop once the stop channel passed to Run is closed, i.e.,
+// their in-flight remediations are finished or the shutdown grace period expires. It returns immediately if the
+// syncer is not run, e.g., the replica never becomes the leader.
+func (s *Syncer) WaitForShutdown() {
+	s.mu.Lock()
+	stopped := s.stopped
+	s.mu.Unlock()
+	if stopped == nil {
+		return
+	}
+	<-stopped
+}
+
 // ListenAndServe initializes a server to respond to HTTP network requests on the syncer.
 func (s *Syncer) ListenAndServe(address, certFile, keyFile string) {
 	metrics.Register()
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
+	mux := s.serverMux()
 	if certFile != "" && keyFile != "" {
 		klog.Fatal(http.ListenAndServeTLS(address, certFile, keyFile, mux))
 	} else {
@@ -266,6 +370,57 @@ func (s *Syncer) ListenAndServe(address, certFile, keyFile string) {
 	}
 }
 
+// ListenAndServeAdmin initializes a separate server for the endpoints which change the syncer state, e.g.,
+// running a patrol round on demand or pausing the patrol of a tenant. Only the clients presenting a certificate signed by clientCAFile are served.
+func (s *Syncer) ListenAndServeAdmin(address, certFile, keyFile, clientCAFile string) {
+	tlsConfig, err := adminTLSConfig(clientCAFile)
+	if err != nil {
+		klog.Fatalf("failed to serve the admin endpoints: %v", err)
+	}
+	server := &http.Server{
+		Addr:      address,
+		Handler:   s.adminMux(),
+		TLSConfig: tlsConfig,
+	}
+	klog.Fatal(server.ListenAndServeTLS(certFile, keyFile))
+}
+
+// adminTLSConfig requires the admin clients to present a certificate signed by the client CA.
+func adminTLSConfig(clientCAFile string) (*tls.Config, error) {
+	clientCAs, err := certutil.CertsFromFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load admin client CA file: %v", err)
+	}
+	certPool := x509.NewCertPool()
+	for _, cert := range clientCAs {
+		certPool.AddCert(cert)
+	}
+	return &tls.Config{
+		ClientCAs:  certPool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	}, nil
+}
+
+// adminMux serves the endpoints which change the syncer state. They are never served along with the metrics,
+// which are open to anyone reaching the syncer.
+func (s *Syncer) adminMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/patrol", &patrolHandler{triggers: s.patrolTriggers})
+	mux.Handle("/patrol/pause", &patrolPauseHandler{pause: true, clusterKnown: s.hasClusterName})
+	mux.Handle("/patrol/resume", &patrolPauseHandler{pause: false, clusterKnown: s.hasClusterName})
+	return mux
+}
+
+// serverMux serves the metrics and the admission webhooks of the syncer.
+func (s *Syncer) serverMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	if s.config.ValidateTenantPublicNames {
+		mux.Handle("/validate-public-names", &publicNameHandler{checkers: s.publicNameCheckers, syncerUsers: sets.NewString(s.config.TenantSyncerUsers...)})
+	}
+	return mux
+}
+
 // run runs a run thread that just dequeues items, processes them, and marks them done.
 // It enforces that the syncHandler is never invoked concurrently with the same key.
 func (s *Syncer) run() {
@@ -308,6 +463,15 @@ func (s *Syncer) syncVirtualCluster(key string) error {
 		return nil
 	}
 
+	if !s.clusterSelector.Matches(vc) {
+		// the cluster may be relabeled out of the selector after it is added.
+		if s.hasCluster(key) {
+			klog.Infof("Cluster %s no longer matches the cluster selector", key)
+			s.removeCluster(key)
+		}
+		return nil
+	}
+
 	switch vc.Status.Phase {
 	case v1alpha1.ClusterRunning:
 		return s.addCluster(key, vc)
@@ -339,6 +503,30 @@ func (s *Syncer) removeCluster(key string) {
 	}
 
 	delete(s.clusterSet, key)
+	metrics.ClusterOwner.DeleteLabelValues(vc.GetClusterName(), s.config.Identity)
+	// the pause of a removed cluster is dropped along with its metric.
+	pa.ResumePatrol(vc.GetClusterName())
+}
+
+// hasCluster returns true if the cluster is running.
+func (s *Syncer) hasCluster(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exist := s.clusterSet[key]
+	return exist
+}
+
+// hasClusterName returns true if a running cluster is named clusterName, which is the key of the cluster used
+// by the resource syncers.
+func (s *Syncer) hasClusterName(clusterName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.clusterSet {
+		if c.GetClusterName() == clusterName {
+			return true
+		}
+	}
+	return false
 }
 
 // addCluster registers and start an informer cache for the given VirtualCluster
@@ -346,18 +534,28 @@ func (s *Syncer) addCluster(key string, vc *v1alpha1.VirtualCluster) error {
 	klog.Infof("Add cluster %s", key)
 
 	s.mu.Lock()
-	if _, exist := s.clusterSet[key]; exist {
-		s.mu.Unlock()
-		return nil
-	}
+	existing, exist := s.clusterSet[key]
 	s.mu.Unlock()
 
 	clusterName := conversion.ToClusterKey(vc)
 
 	adminKubeConfigBytes, err := conversion.GetKubeConfigOfVC(s.metaClient.CoreV1(), vc)
 	if err != nil {
+		if exist {
+			// Keep the running cluster, the kubeconfig is checked again by the next sync.
+			klog.Warningf("failed to get kubeconfig of running cluster %s: %v", key, err)
+			return nil
+		}
 		return err
 	}
+	if exist {
+		if !kubeConfigChanged(existing, adminKubeConfigBytes) {
+			return nil
+		}
+		// The tenant caches and clients are rebuilt with the new kubeconfig, the checkers recover by the next patrol.
+		klog.Infof("kubeconfig of cluster %s is rotated, rebuild the cluster", key)
+		s.removeCluster(key)
+	}
 	tenantCluster, err := cluster.NewCluster(clusterName, vc.Namespace, vc.Name, string(vc.UID), &virtualclusterGetter{lister: s.lister}, adminKubeConfigBytes, cluster.Options{})
 	if err != nil {
 		return fmt.Errorf("failed to new tenant cluster %s/%s: %v", vc.Namespace, vc.Name, err)
@@ -371,12 +569,19 @@ func (s *Syncer) addCluster(key string, vc *v1alpha1.VirtualCluster) error {
 	s.mu.Lock()
 	s.clusterSet[key] = tenantCluster
 	s.mu.Unlock()
+	metrics.ClusterOwner.WithLabelValues(clusterName, s.config.Identity).Set(1)
 
 	go s.runCluster(tenantCluster, vc)
 
 	return nil
 }
 
+// kubeConfigChanged returns true if the running cluster is not built from the kubeconfig.
+func kubeConfigChanged(c mc.ClusterInterface, configBytes []byte) bool {
+	k, ok := c.(interface{ KubeConfigChanged([]byte) bool })
+	return ok && k.KubeConfigChanged(configBytes)
+}
+
 func (s *Syncer) runCluster(cluster *cluster.Cluster, vc *v1alpha1.VirtualCluster) {
 	go func() {
 		err := cluster.Start()
@@ -408,10 +613,10 @@ func (s *Syncer) runCluster(cluster *cluster.Cluster, vc *v1alpha1.VirtualCluste
 
 func (s *Syncer) healthPatrol() {
 	defer metrics.RecordCheckerScanDuration("TenantMaster", time.Now())
-	var clusters []mc.ClusterInterface
+	clusters := make(map[string]mc.ClusterInterface)
 	s.mu.Lock()
-	for _, c := range s.clusterSet {
-		clusters = append(clusters, c)
+	for key, c := range s.clusterSet {
+		clusters[key] = c
 	}
 	s.mu.Unlock()
 
@@ -420,12 +625,15 @@ func (s *Syncer) healthPatrol() {
 
 	if len(clusters) != 0 {
 		wg := sync.WaitGroup{}
-		for _, c := range clusters {
+		for key, c := range clusters {
 			wg.Add(1)
-			go func(cluster mc.ClusterInterface) {
+			go func(key string, cluster mc.ClusterInterface) {
 				defer wg.Done()
-				s.checkTenantClusterHealth(cluster)
-			}(c)
+				if !s.checkTenantClusterHealth(cluster) {
+					// The kubeconfig of the cluster may have been rotated, it is checked by syncing the virtualcluster.
+					s.queue.Add(key)
+				}
+			}(key, c)
 		}
 		wg.Wait()
 	}
@@ -434,18 +642,19 @@ func (s *Syncer) healthPatrol() {
 	metrics.ClusterHealthStats.WithLabelValues("unhealth").Set(float64(numUnHealthCluster))
 }
 
-// checkTenantClusterHealth checks if we can connect to tenant apiserver.
-func (s *Syncer) checkTenantClusterHealth(cluster mc.ClusterInterface) {
+// checkTenantClusterHealth checks if we can connect to tenant apiserver. It returns false if the tenant
+// apiserver is unreachable.
+func (s *Syncer) checkTenantClusterHealth(cluster mc.ClusterInterface) bool {
 	cs, err := cluster.GetClientSet()
 	if err != nil {
 		klog.Warningf("[checkClusterHealth] fails to get cluster %v clientset: %v", cluster.GetClusterName(), err)
-		return
+		return true
 	}
 
 	_, discoveryErr := cs.Discovery().ServerVersion()
 	if discoveryErr == nil {
 		atomic.AddUint64(&numHealthCluster, 1)
-		return
+		return true
 	}
 
 	atomic.AddUint64(&numUnHealthCluster, 1)
@@ -458,4 +667,5 @@ func (s *Syncer) checkTenantClusterHealth(cluster mc.ClusterInterface) {
 		Name:      name,
 		UID:       types.UID(uid),
 	}, v1.EventTypeWarning, "ClusterUnHealth", "VirtualCluster %v unhealth: %v", cluster.GetClusterName(), discoveryErr.Error())
+	return false
 }
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func TestLoadPlugins(t *testing.T) {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{ID: "fakedefault"})
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{ID: "fakeextra", Disable: true})
+
+	testcases := map[string]struct {
+		extraSyncingResources []string
+		syncerFeatureGates    map[string]bool
+		expected              []string
+	}{
+		"default": {
+			expected: []string{"fakedefault"},
+		},
+		"extra syncing resources": {
+			extraSyncingResources: []string{"fakeextra"},
+			expected:              []string{"fakedefault", "fakeextra"},
+		},
+		"enabled by gate": {
+			syncerFeatureGates: map[string]bool{"FakeExtra": true},
+			expected:           []string{"fakedefault", "fakeextra"},
+		},
+		"disabled by gate": {
+			syncerFeatureGates: map[string]bool{"FakeDefault": false},
+			expected:           []string{},
+		},
+		"gate overrides extra syncing resources": {
+			extraSyncingResources: []string{"fakeextra"},
+			syncerFeatureGates:    map[string]bool{"fakeextra": false},
+			expected:              []string{"fakedefault"},
+		},
+		"unknown gate is ignored": {
+			syncerFeatureGates: map[string]bool{"Unknown": true},
+			expected:           []string{"fakedefault"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			loaded := sets.NewString()
+			for _, r := range LoadPlugins(&config.SyncerConfiguration{
+				ExtraSyncingResources: tc.extraSyncingResources,
+				SyncerFeatureGates:    tc.syncerFeatureGates,
+			}) {
+				loaded.Insert(r.ID)
+			}
+			if !loaded.Equal(sets.NewString(tc.expected...)) {
+				t.Errorf("expected plugins %v, got %v", tc.expected, loaded.List())
+			}
+		})
+	}
+}
+
+func tenantKubeConfig(token string) []byte {
+	return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://tenant-apiserver:6443
+  name: tenant
+contexts:
+- context:
+    cluster: tenant
+    user: admin
+  name: tenant
+current-context: tenant
+users:
+- name: admin
+  user:
+    token: ` + token + "\n")
+}
+
+func TestKubeConfigChanged(t *testing.T) {
+	c, err := cluster.NewCluster("tenant-1", "default", "tenant", "uid", nil, tenantKubeConfig("old"), cluster.Options{})
+	if err != nil {
+		t.Fatalf("failed to new cluster: %v", err)
+	}
+	if kubeConfigChanged(c, tenantKubeConfig("old")) {
+		t.Errorf("expected kubeconfig unchanged")
+	}
+	if !kubeConfigChanged(c, tenantKubeConfig("new")) {
+		t.Errorf("expected rotated kubeconfig to be detected")
+	}
+	fake, err := cluster.NewFakeTenantCluster(&v1alpha1.VirtualCluster{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to new fake cluster: %v", err)
+	}
+	if kubeConfigChanged(fake, tenantKubeConfig("new")) {
+		t.Errorf("expected clusters not built from kubeconfig to be kept")
+	}
+}
+
+func TestRemoveClusterOwner(t *testing.T) {
+	metrics.ClusterOwner.Reset()
+	defer metrics.ClusterOwner.Reset()
+
+	s := &Syncer{
+		config:     &config.SyncerConfiguration{Identity: "syncer-0"},
+		clusterSet: make(map[string]mc.ClusterInterface),
+	}
+	var clusterNames []string
+	for _, name := range []string{"tenant-1", "tenant-2"} {
+		c, err := cluster.NewFakeTenantCluster(&v1alpha1.VirtualCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: "uid"}}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to new fake cluster: %v", err)
+		}
+		s.clusterSet["default/"+name] = c
+		clusterNames = append(clusterNames, c.GetClusterName())
+		metrics.ClusterOwner.WithLabelValues(c.GetClusterName(), "syncer-0").Set(1)
+	}
+
+	s.removeCluster("default/tenant-1")
+	if n := testutil.CollectAndCount(metrics.ClusterOwner); n != 1 {
+		t.Errorf("expected the owner of the remaining cluster only, got %d series", n)
+	}
+	if v := testutil.ToFloat64(metrics.ClusterOwner.WithLabelValues(clusterNames[1], "syncer-0")); v != 1 {
+		t.Errorf("expected %s owned by syncer-0, got %v", clusterNames[1], v)
+	}
+}
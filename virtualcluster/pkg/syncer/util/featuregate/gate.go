@@ -45,12 +45,17 @@ const (
 	// vn-agent to run as a load balanced deployment proxy to the super
 	// cluster API Server
 	VNodeProviderService = "VNodeProviderService"
+
+	// StructuredLogging makes the periodic checkers emit their logs as key/value
+	// pairs, in the format of klog InfoS, so that they can be ingested by log pipelines.
+	StructuredLogging = "StructuredLogging"
 )
 
 var defaultFeatures = FeatureList{
 	SuperClusterPooling:        {Default: false},
 	SuperClusterServiceNetwork: {Default: false},
 	VNodeProviderService:       {Default: false},
+	StructuredLogging:          {Default: false},
 }
 
 type Feature string
@@ -36,3 +36,20 @@ func GetVirtualClusterObject(mc *mc.MultiClusterController, clustername string)
 
 	return vc, nil
 }
+
+// SetVirtualClusterCondition adds the condition to the virtualcluster status or replaces the one of the same type.
+// The last transition time is kept if the condition status does not change.
+func SetVirtualClusterCondition(vc *v1alpha1.VirtualCluster, condition v1alpha1.ClusterCondition) {
+	for i := range vc.Status.Conditions {
+		existing := &vc.Status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	vc.Status.Conditions = append(vc.Status.Conditions, condition)
+}
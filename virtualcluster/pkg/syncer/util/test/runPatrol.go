@@ -17,6 +17,7 @@ limitations under the License.
 package util
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -42,10 +43,10 @@ type fakePatrolReconciler struct {
 	errCh          chan error
 }
 
-func (r *fakePatrolReconciler) PatrollerDo() {
+func (r *fakePatrolReconciler) PatrollerDo(ctx context.Context) {
 	var err error
 	if r.resourceSyncer != nil {
-		r.resourceSyncer.PatrollerDo()
+		r.resourceSyncer.PatrollerDo(ctx)
 		err = nil
 	} else {
 		err = fmt.Errorf("fake patrol reconciler is not initialized")
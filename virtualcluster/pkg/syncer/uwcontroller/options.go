@@ -37,6 +37,7 @@ func WithOptions(o *Options) OptConfig {
 		WithWorkQueue(o.Queue)(options)
 		WithJitterPeriod(o.JitterPeriod)(options)
 		WithMaxConcurrentReconciles(o.MaxConcurrentReconciles)(options)
+		WithShutdownGracePeriod(o.ShutdownGracePeriod)(options)
 	}
 }
 
@@ -84,3 +85,12 @@ func WithMaxConcurrentReconciles(n int) OptConfig {
 		}
 	}
 }
+
+// WithShutdownGracePeriod sets the time the workers are given to drain the queue once the controller is stopped.
+func WithShutdownGracePeriod(t time.Duration) OptConfig {
+	return func(options *Options) {
+		if t > 0 {
+			options.ShutdownGracePeriod = t
+		}
+	}
+}
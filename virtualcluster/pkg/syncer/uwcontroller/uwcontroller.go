@@ -19,6 +19,7 @@ package uwcontroller
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -54,6 +55,9 @@ type Options struct {
 	Reconciler reconciler.UWReconciler
 	// Queue can be used to override the default queue.
 	Queue workqueue.RateLimitingInterface
+	// ShutdownGracePeriod is the time the workers are given to drain the queued keys once the controller is
+	// stopped.
+	ShutdownGracePeriod time.Duration
 
 	name string
 }
@@ -74,6 +78,7 @@ func NewUWController(objectType client.Object, rc reconciler.UWReconciler, opts
 			MaxConcurrentReconciles: constants.UwsControllerWorkerLow,
 			Reconciler:              rc,
 			Queue:                   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
+			ShutdownGracePeriod:     constants.DefaultShutdownGracePeriod,
 		},
 	}
 
@@ -91,19 +96,46 @@ func NewUWController(objectType client.Object, rc reconciler.UWReconciler, opts
 func (c *UpwardController) Start(stop <-chan struct{}) error {
 	klog.Infof("start uw-controller %s", c.name)
 	defer utilruntime.HandleCrash()
-	defer c.Queue.ShutDown()
 
+	var workers sync.WaitGroup
 	for i := 0; i < c.MaxConcurrentReconciles; i++ {
-		go wait.Until(c.worker, c.JitterPeriod, stop)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			wait.Until(c.worker, c.JitterPeriod, stop)
+			// drain the queue once it is shut down, even if the worker has not started before stopped.
+			c.worker()
+		}()
 	}
 
 	<-stop
 	klog.Infof("shutting down uw-controller %s", c.name)
+	// the queue stops taking new keys, the workers keep going until the queued ones are reconciled. The keys
+	// waiting for their delay or rate limit are left to the patrollers of the next run.
+	c.Queue.ShutDown()
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	t := time.NewTimer(c.ShutdownGracePeriod)
+	defer t.Stop()
+	select {
+	case <-drained:
+	case <-t.C:
+		klog.Warningf("uw-controller %s does not drain its queue in %v, %d keys are left", c.name, c.ShutdownGracePeriod, c.Queue.Len())
+	}
 	return nil
 }
 
 func (c *UpwardController) AddToQueue(key string) {
 	c.Queue.Add(key)
+	metrics.RecordUWSQueueDepth(c.objectKind, c.Queue.Len())
+}
+
+// AddToQueueAfter adds the key to the queue after the given delay.
+func (c *UpwardController) AddToQueueAfter(key string, delay time.Duration) {
+	c.Queue.AddAfter(key, delay)
 }
 
 func (c *UpwardController) worker() {
@@ -117,6 +149,11 @@ func (c *UpwardController) processNextWorkItem() bool {
 		return false
 	}
 	defer c.Queue.Done(obj)
+	// the keys added after a delay or rate limited are counted once a worker picks up the next key.
+	metrics.RecordUWSQueueDepth(c.objectKind, c.Queue.Len())
+	active := metrics.UWSActiveReconciles.WithLabelValues(c.objectKind)
+	active.Inc()
+	defer active.Dec()
 
 	key, ok := obj.(string)
 	if !ok {
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uwcontroller
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// blockingReconciler blocks each back populate until it is released.
+type blockingReconciler struct {
+	started chan string
+	release chan struct{}
+}
+
+func (r blockingReconciler) BackPopulate(key string) error {
+	r.started <- key
+	<-r.release
+	return nil
+}
+
+func TestQueueMetrics(t *testing.T) {
+	metrics.UWSQueueDepth.Reset()
+	metrics.UWSActiveReconciles.Reset()
+
+	rc := blockingReconciler{started: make(chan string), release: make(chan struct{})}
+	c, err := NewUWController(&v1.Service{}, rc)
+	if err != nil {
+		t.Fatalf("error creating uw-controller: %v", err)
+	}
+	for _, key := range []string{"cluster1/a", "cluster1/b", "cluster2/a"} {
+		c.AddToQueue(key)
+	}
+	if depth := testutil.ToFloat64(metrics.UWSQueueDepth.WithLabelValues("Service")); depth != 3 {
+		t.Errorf("expected queue depth 3, got %v", depth)
+	}
+
+	done := make(chan bool)
+	go func() { done <- c.processNextWorkItem() }()
+	<-rc.started
+	if depth := testutil.ToFloat64(metrics.UWSQueueDepth.WithLabelValues("Service")); depth != 2 {
+		t.Errorf("expected queue depth 2, got %v", depth)
+	}
+	if active := testutil.ToFloat64(metrics.UWSActiveReconciles.WithLabelValues("Service")); active != 1 {
+		t.Errorf("expected 1 active reconcile, got %v", active)
+	}
+	close(rc.release)
+	<-done
+	if active := testutil.ToFloat64(metrics.UWSActiveReconciles.WithLabelValues("Service")); active != 0 {
+		t.Errorf("expected no active reconcile, got %v", active)
+	}
+}
+
+// gatedReconciler counts the back populated keys, each of them waits for the gate to open.
+type gatedReconciler struct {
+	gate      chan struct{}
+	populated *int32
+}
+
+func (r gatedReconciler) BackPopulate(string) error {
+	<-r.gate
+	atomic.AddInt32(r.populated, 1)
+	return nil
+}
+
+func TestStartDrainsQueue(t *testing.T) {
+	var populated int32
+	rc := gatedReconciler{gate: make(chan struct{}), populated: &populated}
+	c, err := NewUWController(&v1.Service{}, rc, WithMaxConcurrentReconciles(1))
+	if err != nil {
+		t.Fatalf("error creating uw-controller: %v", err)
+	}
+	for _, key := range []string{"cluster1/a", "cluster1/b", "cluster2/a"} {
+		c.AddToQueue(key)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		c.Start(stop)
+		close(done)
+	}()
+	// the keys are queued when the controller is stopped.
+	close(stop)
+	time.Sleep(50 * time.Millisecond)
+	close(rc.gate)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the uw-controller to stop")
+	}
+	if got := atomic.LoadInt32(&populated); got != 3 {
+		t.Errorf("expected the 3 queued keys to be drained, got %d", got)
+	}
+}
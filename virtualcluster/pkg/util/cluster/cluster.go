@@ -17,12 +17,15 @@ limitations under the License.
 package cluster
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -59,6 +62,9 @@ type Cluster struct {
 	// Config is the rest.config used to talk to the apiserver.  Required.
 	RestConfig *rest.Config
 
+	// kubeConfig is the kubeconfig RestConfig is built from.
+	kubeConfig []byte
+
 	// getter is used to get cluster CRD object.
 	getter mccontroller.Getter
 
@@ -72,13 +78,18 @@ type Cluster struct {
 	delegatingClient client.Client
 
 	// a clientset client for unwatched tenant master objects (rw directly to tenant apiserver)
-	client *clientset.Clientset
+	clientLock sync.Mutex
+	client     *clientset.Clientset
 
 	options Options
 
 	// a flag indicates that the cluster cache has been synced
 	synced bool
 
+	// the version of the tenant apiserver, it is discovered once and cached.
+	versionLock sync.Mutex
+	version     *version.Info
+
 	cancelContext context.CancelFunc
 
 	context context.Context
@@ -129,12 +140,19 @@ func NewCluster(key, namespace, name, uid string, getter mccontroller.Getter, co
 		uid:        uid,
 		getter:     getter,
 		RestConfig: clusterRestConfig,
+		kubeConfig: configBytes,
 		options:    o,
 		synced:     false,
 		context:    context.Background(),
 	}, nil
 }
 
+// KubeConfigChanged returns true if the cluster is not built from the kubeconfig, e.g., the credentials or the
+// serving certs of the tenant apiserver are rotated, in which case the cluster should be rebuilt.
+func (c *Cluster) KubeConfigChanged(configBytes []byte) bool {
+	return !bytes.Equal(c.kubeConfig, configBytes)
+}
+
 // GetClusterName returns the unique cluster name, aka, the root namespace name.
 func (c *Cluster) GetClusterName() string {
 	return c.key
@@ -159,6 +177,8 @@ func (c *Cluster) getScheme() *runtime.Scheme {
 
 // GetClientSet returns a clientset client without any informer caches. All client requests go to apiserver directly.
 func (c *Cluster) GetClientSet() (clientset.Interface, error) {
+	c.clientLock.Lock()
+	defer c.clientLock.Unlock()
 	if c.client != nil {
 		return c.client, nil
 	}
@@ -170,6 +190,25 @@ func (c *Cluster) GetClientSet() (clientset.Interface, error) {
 	return c.client, nil
 }
 
+// GetClusterVersion returns the version of the tenant apiserver. The version is discovered at the first call.
+func (c *Cluster) GetClusterVersion() (*version.Info, error) {
+	c.versionLock.Lock()
+	defer c.versionLock.Unlock()
+	if c.version != nil {
+		return c.version, nil
+	}
+	cs, err := c.GetClientSet()
+	if err != nil {
+		return nil, err
+	}
+	info, err := cs.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the version of cluster %s: %v", c.key, err)
+	}
+	c.version = info
+	return c.version, nil
+}
+
 // getMapper returns a lazily created apimachinery RESTMapper.
 func (c *Cluster) getMapper() (meta.RESTMapper, error) {
 	if c.mapper != nil {
@@ -320,6 +359,11 @@ func (c *Cluster) SetSynced() {
 	c.synced = true
 }
 
+// Synced returns true if the Cluster's cache has been synced.
+func (c *Cluster) Synced() bool {
+	return c.synced
+}
+
 func (c *Cluster) SetKey(k string) {
 	c.key = k
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package cluster
 
 import (
+	"k8s.io/apimachinery/pkg/version"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	clientgocache "k8s.io/client-go/tools/cache"
@@ -85,6 +86,10 @@ func (c *fakeCluster) WaitForCacheSync() bool {
 	return true
 }
 
+func (c *fakeCluster) Synced() bool {
+	return true
+}
+
 func (c *fakeCluster) Stop() {
 	return
 }
@@ -93,3 +98,8 @@ func (c *fakeCluster) Stop() {
 func (c *fakeCluster) GetRestConfig() *rest.Config {
 	return nil
 }
+
+// GetClusterVersion returns a fixed recent version, the fake clientset would record a discovery action otherwise.
+func (c *fakeCluster) GetClusterVersion() (*version.Info, error) {
+	return &version.Info{Major: "1", Minor: "21", GitVersion: "v1.21.1"}, nil
+}
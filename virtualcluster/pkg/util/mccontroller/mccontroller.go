@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/version"
 	clientset "k8s.io/client-go/kubernetes"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
@@ -92,6 +93,8 @@ type Options struct {
 type Cache interface {
 	Start() error
 	WaitForCacheSync() bool
+	// Synced returns true once the cache has been synced.
+	Synced() bool
 	Stop()
 }
 
@@ -110,6 +113,8 @@ type ClusterInterface interface {
 	GetClientSet() (clientset.Interface, error)
 	GetDelegatingClient() (client.Client, error)
 	GetRestConfig() *rest.Config
+	// GetClusterVersion returns the version of the cluster apiserver.
+	GetClusterVersion() (*version.Info, error)
 	Cache
 }
 
@@ -194,6 +199,52 @@ func (c *MultiClusterController) TeardownClusterResource(cluster ClusterInterfac
 	delete(c.clusters, cluster.GetClusterName())
 }
 
+// Watch fans out the events of a super master informer to the tenant clusters watched by the controller. Each
+// event of an object accepted by predicate is turned into a <cluster>/<key> key per tenant cluster, and the keys
+// are handed to enqueue in a batch, e.g., to the queue of an upward controller, so that a super master change is
+// synced without waiting for the next patrol. Updates which keep the resource version, i.e., informer relists,
+// are not fanned out, the patroller compares the objects in full instead.
+func (c *MultiClusterController) Watch(informer clientgocache.SharedInformer, predicate func(obj interface{}) bool, enqueue func(keys []string)) {
+	fanOut := func(obj interface{}) {
+		key, err := clientgocache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			klog.Errorf("mccontroller %q: couldn't get key for object %v: %v", c.name, obj, err)
+			return
+		}
+		clusterNames := c.GetClusterNames()
+		if len(clusterNames) == 0 {
+			klog.V(4).Infof("mccontroller %q: no tenant clusters, skip fanning out %s %s", c.name, c.objectKind, key)
+			return
+		}
+		keys := make([]string, 0, len(clusterNames))
+		for _, clusterName := range clusterNames {
+			keys = append(keys, clusterName+"/"+key)
+		}
+		enqueue(keys)
+	}
+
+	informer.AddEventHandler(clientgocache.FilteringResourceEventHandler{
+		FilterFunc: predicate,
+		Handler: clientgocache.ResourceEventHandlerFuncs{
+			AddFunc: fanOut,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldMeta, err := meta.Accessor(oldObj)
+				if err != nil {
+					return
+				}
+				newMeta, err := meta.Accessor(newObj)
+				if err != nil {
+					return
+				}
+				if newMeta.GetResourceVersion() != oldMeta.GetResourceVersion() {
+					fanOut(newObj)
+				}
+			},
+			DeleteFunc: fanOut,
+		},
+	})
+}
+
 // Start starts the ClustersController's control loops (as many as MaxConcurrentReconciles) in separate channels
 // and blocks until an empty struct is sent to the stop channel.
 func (c *MultiClusterController) Start(stop <-chan struct{}) error {
@@ -288,6 +339,25 @@ func (c *MultiClusterController) GetOwnerInfo(clusterName string) (string, strin
 	return name, namespace, uid, nil
 }
 
+// GetClusterVersion returns the version of the tenant cluster apiserver, so that resource
+// controllers can skip the resources the tenant cluster does not serve.
+func (c *MultiClusterController) GetClusterVersion(clusterName string) (*version.Info, error) {
+	cluster := c.GetCluster(clusterName)
+	if cluster == nil {
+		return nil, errors.NewClusterNotFound(clusterName)
+	}
+	return cluster.GetClusterVersion()
+}
+
+// IsClusterReady returns true if the cluster is managed and its informer cache has been synced.
+func (c *MultiClusterController) IsClusterReady(clusterName string) bool {
+	cluster := c.GetCluster(clusterName)
+	if cluster == nil {
+		return false
+	}
+	return cluster.Synced()
+}
+
 // GetClusterNames returns the name list of all managed tenant clusters
 func (c *MultiClusterController) GetClusterNames() []string {
 	c.Lock()
@@ -34,4 +34,13 @@ type InitContext struct {
 	Informer   informers.SharedInformerFactory
 	VCClient   vcclient.Interface
 	VCInformer vcinformers.VirtualClusterInformer
+	// ExtraSuperClusters are the super masters other than Client, keyed by super cluster ID. The resource syncers
+	// which support them take the objects of a tenant from the super master it is mapped to.
+	ExtraSuperClusters map[string]SuperCluster
+}
+
+// SuperCluster is the client and the informer factory of a super master.
+type SuperCluster struct {
+	Client   clientset.Interface
+	Informer informers.SharedInformerFactory
 }
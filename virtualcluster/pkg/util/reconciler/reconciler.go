@@ -17,6 +17,8 @@ limitations under the License.
 package reconciler
 
 import (
+	"context"
+
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -56,5 +58,6 @@ type UWReconciler interface {
 
 // PatrolReconciler is the interface used by a peroidic checker to ensure the object consistency between tenant and super master.
 type PatrolReconciler interface {
-	PatrollerDo()
+	// PatrollerDo runs a single patrol pass. The ctx is cancelled when the patroller stops.
+	PatrollerDo(ctx context.Context)
 }